@@ -0,0 +1,170 @@
+package main
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Number of access events kept per paste, the oldest are dropped first.
+const maxAccessEvents = 200
+
+// Length user agents are truncated to.
+const maxUserAgentLength = 128
+
+// AccessEvent is one access to a paste. The client IP is truncated to its
+// network, so the log tells whether a link has been used, not by whom.
+type AccessEvent struct {
+	Time      time.Time `json:"time"`
+	Network   string    `json:"network"`
+	UserAgent string    `json:"user_agent,omitempty"`
+}
+
+// AccessPage is the data of the access log template.
+type AccessPage struct {
+	Checksum string
+	Events   []AccessEvent
+	Message  string
+	Status   string
+}
+
+// accessLog buffers access events in memory and appends them to the
+// per-paste records in the storage when the view counts are flushed.
+type accessLog struct {
+	sync.Mutex
+	pending map[string][]AccessEvent
+}
+
+var accesses = accessLog{pending: map[string][]AccessEvent{}}
+
+func accessKey(checksum string) string {
+	return "access-" + service.ObjectKey(checksum)
+}
+
+// truncateIP returns the /24 network of IPv4 addresses and the /48 network
+// of IPv6 addresses.
+func truncateIP(addr string) string {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return ""
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return (&net.IPNet{IP: ip4.Mask(net.CIDRMask(24, 32)), Mask: net.CIDRMask(24, 32)}).String()
+	}
+	return (&net.IPNet{IP: ip.Mask(net.CIDRMask(48, 128)), Mask: net.CIDRMask(48, 128)}).String()
+}
+
+// Add records an access to the paste, if access logging is enabled.
+func (l *accessLog) Add(checksum string, r *http.Request) {
+	if !*accessLogFlag {
+		return
+	}
+	ua := r.UserAgent()
+	if len(ua) > maxUserAgentLength {
+		ua = ua[:maxUserAgentLength]
+	}
+	l.Lock()
+	l.pending[checksum] = append(l.pending[checksum], AccessEvent{
+		Time:      time.Now().UTC(),
+		Network:   truncateIP(clientIP(r)),
+		UserAgent: ua,
+	})
+	l.Unlock()
+}
+
+func storedAccesses(checksum string) []AccessEvent {
+	var events []AccessEvent
+	retrieveJSON(accessKey(checksum), &events)
+	return events
+}
+
+func lastAccesses(events []AccessEvent) []AccessEvent {
+	if len(events) > maxAccessEvents {
+		events = events[len(events)-maxAccessEvents:]
+	}
+	return events
+}
+
+// Get returns the access events of the paste, including events that are
+// not flushed yet, oldest first.
+func (l *accessLog) Get(checksum string) []AccessEvent {
+	l.Lock()
+	pending := l.pending[checksum]
+	l.Unlock()
+	return lastAccesses(append(storedAccesses(checksum), pending...))
+}
+
+// Flush appends the buffered events to the records in the storage.
+func (l *accessLog) Flush() {
+	l.Lock()
+	pending := l.pending
+	l.pending = map[string][]AccessEvent{}
+	l.Unlock()
+
+	for checksum, events := range pending {
+		all := lastAccesses(append(storedAccesses(checksum), events...))
+		if err := storeJSON(accessKey(checksum), all); err != nil {
+			log.Printf("Unable to store access log of %s: %s\n", checksum, err)
+			// Keep the events for the next flush
+			l.Lock()
+			l.pending[checksum] = append(events, l.pending[checksum]...)
+			l.Unlock()
+		}
+	}
+}
+
+func (l *accessLog) Run(interval time.Duration) {
+	for range time.Tick(interval) {
+		l.Flush()
+	}
+}
+
+// ownerAccesses returns the access log of the paste if token is one of its
+// delete tokens.
+func ownerAccesses(checksum, token string) ([]AccessEvent, error) {
+	m, err := service.Meta(checksum)
+	if err != nil || !m.ValidDeleteToken(token) {
+		return nil, errInvalidDeleteToken
+	}
+	return accesses.Get(checksum), nil
+}
+
+// readAccessLog shows the access log to the owner of the paste, who posts
+// the delete token from the paste page.
+func readAccessLog(w http.ResponseWriter, r *http.Request) {
+	checksum := mux.Vars(r)["checksum"]
+
+	page := AccessPage{Checksum: checksum}
+	events, err := ownerAccesses(checksum, deleteToken(r))
+	if err != nil {
+		log.Printf("Unable to read access log of %s: %s\n", checksum, err)
+		w.WriteHeader(http.StatusForbidden)
+		page.Message = "Unable to read the access log: " + err.Error()
+		page.Status = "error"
+	}
+	page.Events = events
+	renderTemplate(w, r, "templates/access.html", "access", page)
+}
+
+func adminAccessLog(w http.ResponseWriter, r *http.Request) {
+	checksum := mux.Vars(r)["checksum"]
+	renderTemplate(w, r, "templates/access.html", "access", AccessPage{
+		Checksum: checksum,
+		Events:   accesses.Get(checksum),
+	})
+}
+
+func apiAccessLog(w http.ResponseWriter, r *http.Request) {
+	checksum := mux.Vars(r)["checksum"]
+
+	events, err := ownerAccesses(checksum, deleteToken(r))
+	if err != nil {
+		writeJSON(w, http.StatusForbidden, BatchResult{Checksum: checksum, Status: "error", Message: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, events)
+}
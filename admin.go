@@ -0,0 +1,173 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const recentKey = "recent-pastes"
+
+// Number of pastes listed as recent on the admin page, and kept in the
+// index of the pastes created last.
+const recentPastes = 20
+
+// RecentPaste is a paste in the index of the pastes created last, with its
+// checksum sealed.
+type RecentPaste struct {
+	Checksum string    `json:"checksum"`
+	Size     int64     `json:"size"`
+	Created  time.Time `json:"created"`
+}
+
+// AdminPaste is a paste as listed on the admin page. Pastes that require
+// a key are listed without a link, as only their creators have the key.
+type AdminPaste struct {
+	Paste
+	Size        int64
+	Created     time.Time
+	Pinned      bool
+	KeyRequired bool
+}
+
+// AdminStats is the data shown on the admin page.
+type AdminStats struct {
+	// Counted as pastes are created and purged, and limited by
+	// -storage-max-bytes
+	Usage    Usage
+	MaxBytes int64
+	Recent   []AdminPaste
+	Message  string
+	Status   string
+}
+
+// The index of recent pastes is stored as a single record, newest first.
+var recentMu sync.Mutex
+
+// addRecent adds a paste that was stored to the index of the pastes
+// created last, so that the admin page lists them without walking the
+// data directories.
+func addRecent(checksum string, stored int64) {
+	recentMu.Lock()
+	defer recentMu.Unlock()
+	var recent []RecentPaste
+	if err := retrieveJSON(recentKey, &recent); err != nil && !isNotFound(err) {
+		log.Printf("Unable to add %s to the recent pastes: %s\n", checksum, err)
+		return
+	}
+	sealed, err := service.SealChecksum(checksum)
+	if err != nil {
+		log.Printf("Unable to add %s to the recent pastes: %s\n", checksum, err)
+		return
+	}
+	recent = append([]RecentPaste{{Checksum: sealed, Size: stored, Created: time.Now().UTC()}}, recent...)
+	if len(recent) > recentPastes {
+		recent = recent[:recentPastes]
+	}
+	if err := storeJSON(recentKey, recent); err != nil {
+		log.Printf("Unable to add %s to the recent pastes: %s\n", checksum, err)
+	}
+}
+
+// collectStats returns the storage usage as counted, and the recent pastes
+// from their index, leaving out those deleted since.
+func collectStats() (AdminStats, error) {
+	stats := AdminStats{Usage: usage.Get(), MaxBytes: storageMaxBytesFlag.Get()}
+	recentMu.Lock()
+	var recent []RecentPaste
+	err := retrieveJSON(recentKey, &recent)
+	recentMu.Unlock()
+	if err != nil && !isNotFound(err) {
+		return stats, err
+	}
+	for _, rp := range recent {
+		if rp.Checksum, err = service.OpenChecksum(rp.Checksum); err != nil {
+			continue
+		}
+		m, err := service.Meta(rp.Checksum)
+		if err != nil || m.Deleted {
+			continue
+		}
+		var p Paste
+		p.Checksum = rp.Checksum
+		p.Views = views.Get(rp.Checksum)
+		stats.Recent = append(stats.Recent, AdminPaste{
+			Paste:       p,
+			Size:        rp.Size,
+			Created:     rp.Created,
+			Pinned:      m.Pinned,
+			KeyRequired: service.RequiresKey(m),
+		})
+	}
+	return stats, nil
+}
+
+func adminIndex(w http.ResponseWriter, r *http.Request) {
+	stats, err := collectStats()
+	if err != nil {
+		log.Printf("Unable to collect statistics: %s\n", err)
+		stats.Message = "Unable to collect statistics: " + err.Error()
+		stats.Status = "error"
+	}
+	if stats.Status == "" && storageFull() {
+		stats.Message = "The storage is full, no pastes can be created until pastes are purged or -storage-max-bytes is raised."
+		stats.Status = "error"
+	}
+	renderTemplate(w, r, "templates/admin.html", "admin", stats)
+}
+
+// adminPin pins or unpins a paste.
+func adminPin(w http.ResponseWriter, r *http.Request) {
+	checksum := r.FormValue("checksum")
+	pinned := r.FormValue("action") == "pin"
+
+	if err := service.Pin(checksum, pinned); err != nil {
+		log.Printf("Unable to pin %s: %s\n", checksum, err)
+		stats, _ := collectStats()
+		stats.Message = "Unable to change the pin of " + checksum + ": " + err.Error()
+		stats.Status = "error"
+		renderTemplate(w, r, "templates/admin.html", "admin", stats)
+		return
+	}
+	audit(r, r.FormValue("action"), checksum, "")
+	http.Redirect(w, r, basePath()+"/admin", http.StatusSeeOther)
+}
+
+// adminDelete moves a paste listed on the admin page to the trash.
+func adminDelete(w http.ResponseWriter, r *http.Request) {
+	if !sameOrigin(r) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	checksum := r.FormValue("checksum")
+
+	if err := trashPaste(r, checksum); err != nil {
+		log.Printf("Unable to delete %s: %s\n", checksum, err)
+		stats, _ := collectStats()
+		stats.Message = "Unable to delete " + checksum + ": " + err.Error()
+		stats.Status = "error"
+		renderTemplate(w, r, "templates/admin.html", "admin", stats)
+		return
+	}
+	http.Redirect(w, r, basePath()+"/admin", http.StatusSeeOther)
+}
+
+// apiPin pins a paste with PUT and unpins it with DELETE.
+func apiPin(w http.ResponseWriter, r *http.Request) {
+	checksum := mux.Vars(r)["checksum"]
+
+	if err := service.Pin(checksum, r.Method == "PUT"); err != nil {
+		log.Printf("Unable to pin %s: %s\n", checksum, err)
+		writeJSON(w, http.StatusNotFound, BatchResult{Checksum: checksum, Status: "error", Message: err.Error()})
+		return
+	}
+	if r.Method == "PUT" {
+		audit(r, "pin", checksum, "")
+	} else {
+		audit(r, "unpin", checksum, "")
+	}
+	writeJSON(w, http.StatusOK, BatchResult{Checksum: checksum, Status: "success"})
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestCollectStats(t *testing.T) {
+	h := newTestServer(t)
+	kept, _ := createPlain(t, h, "kept")
+	deleted, token := createPlain(t, h, "deleted")
+	if rec := request(h, "DELETE", "/api/v1/pastes"+deleted, "", map[string]string{"X-Delete-Token": token}); rec.Code != http.StatusOK {
+		t.Fatalf("Delete returned %d: %s", rec.Code, rec.Body.String())
+	}
+
+	stats, err := collectStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats.Recent) != 1 || "/"+stats.Recent[0].Checksum != kept {
+		t.Fatalf("Recent pastes are %v, want only %s", stats.Recent, kept)
+	}
+	if stats.Recent[0].Size != int64(len("kept")) {
+		t.Errorf("Size is %d, want %d", stats.Recent[0].Size, len("kept"))
+	}
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Number of days daily counts are kept for.
+const analyticsDays = 90
+
+// Upper bounds of the paste size buckets, in bytes. Larger pastes are
+// counted in the last bucket.
+var sizeBuckets = []struct {
+	Name  string
+	Limit int
+}{
+	{"< 1 KiB", 1 << 10},
+	{"< 10 KiB", 10 << 10},
+	{"< 100 KiB", 100 << 10},
+	{"< 1 MiB", 1 << 20},
+	{">= 1 MiB", 0},
+}
+
+// DayStats are the counts of one day.
+type DayStats struct {
+	Date    string `json:"date"`
+	Creates int64  `json:"creates"`
+	Views   int64  `json:"views"`
+	Bytes   int64  `json:"bytes"`
+}
+
+// Analytics are aggregate counts of pastes, without any information about
+// who created or viewed them.
+type Analytics struct {
+	Days  map[string]*DayStats `json:"days"`
+	Sizes map[string]int64     `json:"sizes"`
+}
+
+// AnalyticsPage is the data of the stats template.
+type AnalyticsPage struct {
+	Days    []DayStats
+	Sizes   []SizeCount
+	Message string
+	Status  string
+}
+
+// SizeCount is the number of pastes in a size bucket.
+type SizeCount struct {
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+const analyticsKey = "analytics"
+
+// analyticsCounter buffers the counts in memory and adds them to the record
+// in the storage periodically, so the stats are kept up to date without
+// scanning the storage.
+type analyticsCounter struct {
+	sync.Mutex
+	pending Analytics
+}
+
+var analytics = analyticsCounter{pending: newAnalytics()}
+
+func newAnalytics() Analytics {
+	return Analytics{Days: map[string]*DayStats{}, Sizes: map[string]int64{}}
+}
+
+func today() string {
+	return time.Now().UTC().Format("2006-01-02")
+}
+
+func sizeBucket(size int) string {
+	for _, b := range sizeBuckets {
+		if b.Limit == 0 || size < b.Limit {
+			return b.Name
+		}
+	}
+	return ""
+}
+
+func (a *Analytics) day(date string) *DayStats {
+	d, ok := a.Days[date]
+	if !ok {
+		d = &DayStats{Date: date}
+		a.Days[date] = d
+	}
+	return d
+}
+
+// merge adds the counts of other to a, and forgets days that are older
+// than analyticsDays.
+func (a *Analytics) merge(other Analytics) {
+	for date, d := range other.Days {
+		sum := a.day(date)
+		sum.Creates += d.Creates
+		sum.Views += d.Views
+		sum.Bytes += d.Bytes
+	}
+	for name, n := range other.Sizes {
+		a.Sizes[name] += n
+	}
+	oldest := time.Now().UTC().AddDate(0, 0, -analyticsDays).Format("2006-01-02")
+	for date := range a.Days {
+		if date < oldest {
+			delete(a.Days, date)
+		}
+	}
+}
+
+// Created counts a new paste of the given size.
+func (c *analyticsCounter) Created(size int) {
+	if *anonymousFlag {
+		return
+	}
+	c.Lock()
+	defer c.Unlock()
+	d := c.pending.day(today())
+	d.Creates++
+	d.Bytes += int64(size)
+	c.pending.Sizes[sizeBucket(size)]++
+}
+
+// Viewed counts a view of a paste.
+func (c *analyticsCounter) Viewed() {
+	if *anonymousFlag {
+		return
+	}
+	c.Lock()
+	defer c.Unlock()
+	c.pending.day(today()).Views++
+}
+
+func storedAnalytics() Analytics {
+	a := newAnalytics()
+	retrieveJSON(analyticsKey, &a)
+	if a.Days == nil {
+		a.Days = map[string]*DayStats{}
+	}
+	if a.Sizes == nil {
+		a.Sizes = map[string]int64{}
+	}
+	return a
+}
+
+// Get returns the stored counts together with the counts that are not
+// flushed yet.
+func (c *analyticsCounter) Get() Analytics {
+	a := storedAnalytics()
+	c.Lock()
+	a.merge(c.pending)
+	c.Unlock()
+	return a
+}
+
+// Flush adds the buffered counts to the record in the storage.
+func (c *analyticsCounter) Flush() {
+	c.Lock()
+	pending := c.pending
+	c.pending = newAnalytics()
+	c.Unlock()
+
+	if len(pending.Days) == 0 && len(pending.Sizes) == 0 {
+		return
+	}
+	a := storedAnalytics()
+	a.merge(pending)
+	if err := storeJSON(analyticsKey, a); err != nil {
+		log.Printf("Unable to store analytics: %s\n", err)
+		// Keep the counts for the next flush
+		c.Lock()
+		c.pending.merge(pending)
+		c.Unlock()
+	}
+}
+
+func (c *analyticsCounter) Run(interval time.Duration) {
+	for range time.Tick(interval) {
+		c.Flush()
+	}
+}
+
+// page returns the counts in the order they are shown, newest day first
+// and sizes from small to large.
+func (a Analytics) page() AnalyticsPage {
+	var page AnalyticsPage
+	for _, d := range a.Days {
+		page.Days = append(page.Days, *d)
+	}
+	sort.Slice(page.Days, func(i, j int) bool {
+		return page.Days[i].Date > page.Days[j].Date
+	})
+	for _, b := range sizeBuckets {
+		page.Sizes = append(page.Sizes, SizeCount{Name: b.Name, Count: a.Sizes[b.Name]})
+	}
+	return page
+}
+
+func adminAnalytics(w http.ResponseWriter, r *http.Request) {
+	renderTemplate(w, r, "templates/stats.html", "stats", analytics.Get().page())
+}
+
+func apiAnalytics(w http.ResponseWriter, r *http.Request) {
+	page := analytics.Get().page()
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"days":  page.Days,
+		"sizes": page.Sizes,
+	})
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"regexp"
+)
+
+// Addresses that are removed from log lines in anonymous mode: IPv4
+// addresses and bracketed or bare IPv6 addresses, with or without port.
+var addressPattern = regexp.MustCompile(`\[[0-9a-fA-F:.]+\](:\d+)?|\b\d{1,3}(\.\d{1,3}){3}(:\d+)?\b|\b([0-9a-fA-F]{1,4}:){3,7}[0-9a-fA-F]{1,4}\b|[0-9a-fA-F]{0,4}::[0-9a-fA-F]{0,4}(:[0-9a-fA-F]{1,4})*`)
+
+// redactingWriter removes client addresses from what is written to it.
+type redactingWriter struct {
+	w io.Writer
+}
+
+func (r redactingWriter) Write(p []byte) (int, error) {
+	if _, err := r.w.Write(addressPattern.ReplaceAll(p, []byte("[redacted]"))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// checkAnonymous refuses anonymous mode with features that record
+// visitors, need JavaScript or contact third parties.
+func checkAnonymous() error {
+	if !*anonymousFlag {
+		return nil
+	}
+	if *accessLogFlag {
+		return errors.New("the access log can not be enabled in anonymous mode")
+	}
+	if *captchaProviderFlag != "" {
+		return errors.New("CAPTCHAs can not be used in anonymous mode, they need JavaScript and a third party")
+	}
+	if powEnabled() {
+		return errors.New("proof of work can not be required in anonymous mode, the form solves it with JavaScript")
+	}
+	return nil
+}
+
+// anonymousHeaders asks browsers not to send the address of the pastebin
+// to the sites that pastes link to.
+func anonymousHeaders(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Referrer-Policy", "no-referrer")
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+
+	"github.com/espebra/pastebin/apitypes"
+	"github.com/espebra/pastebin/pastebin"
+	"github.com/gorilla/mux"
+)
+
+// Room for other form fields and multipart headers in request bodies, on
+// top of the paste itself.
+const requestOverhead = 1 << 20
+
+// requestBodyLimit returns the largest request body that is read. URL
+// encoding can triple the size of a paste sent as a form.
+func requestBodyLimit() int64 {
+	return 3*maxPasteSizeFlag.Get() + requestOverhead
+}
+
+// errPasteTooLarge is returned for pastes larger than -max-paste-size.
+var errPasteTooLarge = errors.New("the paste is too large")
+
+// BatchResult reports the outcome of storing one paste in a batch.
+type BatchResult = apitypes.BatchResult
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Unable to write response: %s\n", err)
+	}
+}
+
+// readBatch returns the contents of a batch request, which is either a JSON
+// array of pastes or a zip archive with one paste per file.
+func readBatch(w http.ResponseWriter, r *http.Request) ([]string, error) {
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, requestBodyLimit()))
+	if err != nil {
+		return nil, err
+	}
+
+	var contents []string
+	if r.Header.Get("Content-Type") == "application/zip" {
+		zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range zr.File {
+			if f.FileInfo().IsDir() {
+				continue
+			}
+			rc, err := f.Open()
+			if err != nil {
+				return nil, err
+			}
+			data, err := ioutil.ReadAll(io.LimitReader(rc, maxPasteSizeFlag.Get()+1))
+			rc.Close()
+			if err != nil {
+				return nil, err
+			}
+			if int64(len(data)) > maxPasteSizeFlag.Get() {
+				return nil, errPasteTooLarge
+			}
+			contents = append(contents, string(data))
+		}
+		return contents, nil
+	}
+
+	var pastes []Paste
+	if err := json.Unmarshal(body, &pastes); err != nil {
+		return nil, err
+	}
+	for _, p := range pastes {
+		if int64(len(p.Content)) > maxPasteSizeFlag.Get() {
+			return nil, errPasteTooLarge
+		}
+		contents = append(contents, p.Content)
+	}
+	return contents, nil
+}
+
+func apiBatchCreate(w http.ResponseWriter, r *http.Request) {
+	if err := checkPow(r); err != nil {
+		writeJSON(w, http.StatusForbidden, BatchResult{Status: "error", Message: err.Error()})
+		return
+	}
+	if err := checkCaptcha(r, true); err != nil {
+		writeJSON(w, http.StatusForbidden, BatchResult{Status: "error", Message: err.Error()})
+		return
+	}
+	contents, err := readBatch(w, r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, BatchResult{
+			Status:  "error",
+			Message: "Invalid batch: " + err.Error(),
+		})
+		return
+	}
+
+	status := http.StatusCreated
+	results := make([]BatchResult, len(contents))
+	for i, content := range contents {
+		var p Paste
+		p.Content = content
+		p.Checksum = p.GetName()
+
+		_, token, err := createPaste(&p, *defaultVisibilityFlag, nil)
+		if err != nil {
+			log.Printf("Unable to write data: %s\n", err)
+			results[i] = BatchResult{
+				Checksum: p.Checksum,
+				Status:   "error",
+				Message:  "Unable to save " + p.Checksum,
+			}
+			if err == errBlocked {
+				results[i].Message = "This content is not allowed."
+			}
+			if err == errSpam {
+				results[i].Message = "The paste looks like spam and was not saved."
+			}
+			if err == errStorageFull {
+				results[i].Message = "The storage is full, no pastes can be created."
+			}
+			status = http.StatusMultiStatus
+			continue
+		}
+		results[i] = BatchResult{
+			Checksum:    p.Checksum,
+			URL:         absURL(p.Location()),
+			DeleteToken: token,
+			Duplicate:   p.Duplicate,
+			Status:      "success",
+		}
+	}
+
+	writeJSON(w, status, results)
+}
+
+func apiReadPaste(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	checksum := vars["checksum"]
+
+	var p Paste
+	var err error
+	if service.Authorize(checksum, vars["key"]) {
+		p, err = retrievePaste(checksum, vars["key"])
+	} else {
+		err = fmt.Errorf("invalid key for %s", checksum)
+	}
+	if err != nil {
+		log.Println(err)
+		if err == errStorageUnavailable {
+			writeJSON(w, http.StatusServiceUnavailable, BatchResult{Checksum: checksum, Status: "error", Message: "The storage is unavailable, try again later."})
+			return
+		}
+		writeJSON(w, http.StatusNotFound, BatchResult{Checksum: checksum, Status: "error", Message: "Paste " + checksum + " does not exist."})
+		return
+	}
+
+	scope := service.Scope(checksum, vars["key"])
+	views.Add(scope)
+	accesses.Add(checksum, r)
+	p.Views = views.Get(scope)
+	p.Status = "success"
+	writeJSON(w, http.StatusOK, p)
+}
+
+// apiClonePaste returns a draft of a new paste from the paste, the API
+// equivalent of the clone action: its content, language and tags, without
+// the checksum and key of the original, to edit and create as a new paste.
+func apiClonePaste(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	checksum := vars["checksum"]
+
+	var p Paste
+	var err error
+	if service.Authorize(checksum, vars["key"]) {
+		p, err = retrievePaste(checksum, vars["key"])
+	} else {
+		err = fmt.Errorf("invalid key for %s", checksum)
+	}
+	if err != nil {
+		log.Println(err)
+		if err == errStorageUnavailable {
+			writeJSON(w, http.StatusServiceUnavailable, BatchResult{Checksum: checksum, Status: "error", Message: "The storage is unavailable, try again later."})
+			return
+		}
+		writeJSON(w, http.StatusNotFound, BatchResult{Checksum: checksum, Status: "error", Message: "Paste " + checksum + " does not exist."})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Paste{
+		Content:   p.Content,
+		Tags:      p.Tags,
+		Language:  p.Language,
+		Encrypted: p.Encrypted,
+		Message:   "Cloned from " + checksum + ". Edit and create it as a new paste.",
+		Status:    "success",
+	})
+}
+
+// apiOpenAPI describes the API as OpenAPI, which clients in other
+// languages are generated from.
+func apiOpenAPI(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, apitypes.OpenAPI(requestURL(r, "")))
+}
+
+// PasteMeta is what is known about a paste, without its content.
+type PasteMeta = apitypes.PasteMeta
+
+// apiPasteMeta returns the metadata of the paste without reading the
+// paste itself, for clients that only need to know that it exists.
+func apiPasteMeta(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	checksum := vars["checksum"]
+
+	notFound := BatchResult{Checksum: checksum, Status: "error", Message: "Paste " + checksum + " does not exist."}
+	if !pastebin.IsChecksum(checksum) || !service.Authorize(checksum, vars["key"]) || blocked.Contains(checksum) {
+		writeJSON(w, http.StatusNotFound, notFound)
+		return
+	}
+	m, err := service.Meta(checksum)
+	if err != nil || m.Deleted {
+		if storageUnavailable(w, err) {
+			return
+		}
+		writeJSON(w, http.StatusNotFound, notFound)
+		return
+	}
+
+	var p Paste
+	p.Checksum = checksum
+	if service.RequiresKey(m) {
+		p.Key = vars["key"]
+	}
+	pm := PasteMeta{
+		Checksum:    checksum,
+		URL:         absURL(p.Location()),
+		Size:        m.Size,
+		ContentType: m.ContentType,
+		Binary:      m.Binary,
+		Encrypted:   m.Encrypted,
+		Lines:       m.Lines,
+		Language:    m.Language,
+		Charset:     m.Charset,
+		Preview:     m.Preview,
+		Visibility:  service.Visibility(m),
+		Tags:        m.Tags,
+		Views:       views.Get(service.Scope(checksum, vars["key"])),
+	}
+	if created, ok := m.KeyCreated(vars["key"]); ok && service.RequiresKey(m) {
+		// Every creation of a paste that requires a key is a paste of
+		// its own to the readers with its key
+		pm.Created, pm.Updated = &created, &created
+	} else if !m.Created.IsZero() {
+		pm.Created, pm.Updated = &m.Created, &m.Updated
+	}
+	writeJSON(w, http.StatusOK, pm)
+}
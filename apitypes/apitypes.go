@@ -0,0 +1,106 @@
+// Package apitypes has the requests and responses of the pastebin JSON
+// API, shared by the server, the Go client and the OpenAPI description
+// that clients in other languages are generated from.
+package apitypes
+
+import "time"
+
+// BatchResult reports the outcome of storing one paste in a batch. Errors
+// of the API are answered with it too, with the status error.
+type BatchResult struct {
+	Checksum    string `json:"checksum,omitempty"`
+	URL         string `json:"url,omitempty"`
+	DeleteToken string `json:"delete_token,omitempty"`
+	Duplicate   bool   `json:"duplicate,omitempty"`
+	Status      string `json:"status"`
+	Message     string `json:"message,omitempty"`
+}
+
+// Paste is a paste with its content, as read from the API or sent to
+// create it in a batch.
+type Paste struct {
+	Content     string   `json:"content"`
+	Checksum    string   `json:"checksum"`
+	Key         string   `json:"key,omitempty"`
+	Views       int64    `json:"views"`
+	Tags        []string `json:"tags,omitempty"`
+	Visibility  string   `json:"visibility,omitempty"`
+	ContentType string   `json:"content_type,omitempty"`
+	Binary      bool     `json:"binary,omitempty"`
+	Encrypted   bool     `json:"encrypted,omitempty"`
+	Lines       int      `json:"lines,omitempty"`
+	Language    string   `json:"language,omitempty"`
+	Charset     string   `json:"charset,omitempty"`
+	DeleteToken string   `json:"delete_token,omitempty"`
+	Duplicate   bool     `json:"duplicate,omitempty"`
+	Message     string   `json:"message"`
+	Status      string   `json:"status"`
+}
+
+// PasteMeta is what is known about a paste, without its content.
+type PasteMeta struct {
+	Checksum    string     `json:"checksum"`
+	URL         string     `json:"url"`
+	Size        int64      `json:"size,omitempty"`
+	ContentType string     `json:"content_type,omitempty"`
+	Binary      bool       `json:"binary,omitempty"`
+	Encrypted   bool       `json:"encrypted,omitempty"`
+	Lines       int        `json:"lines,omitempty"`
+	Language    string     `json:"language,omitempty"`
+	Charset     string     `json:"charset,omitempty"`
+	Preview     string     `json:"preview,omitempty"`
+	Visibility  string     `json:"visibility"`
+	Tags        []string   `json:"tags,omitempty"`
+	Views       int64      `json:"views"`
+	Created     *time.Time `json:"created,omitempty"`
+	Updated     *time.Time `json:"updated,omitempty"`
+}
+
+// PasteSummary is a paste as shown in listings.
+type PasteSummary struct {
+	Checksum  string   `json:"checksum"`
+	URL       string   `json:"url"`
+	Tags      []string `json:"tags,omitempty"`
+	Thumbnail string   `json:"thumbnail,omitempty"`
+	Preview   string   `json:"preview,omitempty"`
+}
+
+// SignedURL is the response of the signed URL API.
+type SignedURL struct {
+	URL     string    `json:"url"`
+	Expires time.Time `json:"expires"`
+}
+
+// Upload is a paste uploaded in chunks, which can be resumed from its
+// offset when a request fails. The paste is created when the last chunk
+// arrives.
+type Upload struct {
+	ID          string    `json:"id"`
+	Length      int64     `json:"length"`
+	Offset      int64     `json:"offset"`
+	Chunks      int       `json:"chunks"`
+	Visibility  string    `json:"visibility,omitempty"`
+	Tags        []string  `json:"tags,omitempty"`
+	SHA256      string    `json:"sha256,omitempty"`
+	Created     time.Time `json:"created"`
+	Updated     time.Time `json:"updated"`
+	Checksum    string    `json:"checksum,omitempty"`
+	URL         string    `json:"url,omitempty"`
+	DeleteToken string    `json:"delete_token,omitempty"` // Only in the response completing the upload
+}
+
+// UploadRequest starts an upload of length bytes. The paste is checked
+// against the SHA-256, when given, before it is created.
+type UploadRequest struct {
+	Length     int64  `json:"length"`
+	Visibility string `json:"visibility"`
+	Tags       string `json:"tags"`
+	SHA256     string `json:"sha256"`
+}
+
+// PowChallenge is a challenge to find a nonce such that the SHA-256 of the
+// challenge, a colon and the nonce starts with Difficulty zero bits.
+type PowChallenge struct {
+	Challenge  string `json:"challenge"`
+	Difficulty int    `json:"difficulty"`
+}
@@ -0,0 +1,241 @@
+package apitypes
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Parameter is a header or query parameter of an operation.
+type Parameter struct {
+	Name        string
+	In          string // header or query
+	Description string
+}
+
+// Operation is an endpoint of the API, as described to generate clients.
+// Request and Response are values of the JSON bodies, or nil for bodies
+// that are not JSON, which are described by their content type.
+type Operation struct {
+	ID          string
+	Method      string
+	Path        string
+	Summary     string
+	Parameters  []Parameter
+	Request     interface{}
+	RequestType string
+	// Statuses of successful responses, 200 when none are given
+	Statuses []int
+	Response interface{}
+	// Content type of a response that is not JSON
+	ResponseType string
+}
+
+// Parameters shared by the operations creating pastes.
+var createParameters = []Parameter{
+	{"Idempotency-Key", "header", "Answers a retried request like the first, instead of creating the pastes again"},
+	{"X-PoW-Challenge", "header", "Challenge from getChallenge, when proof of work is required"},
+	{"X-PoW-Nonce", "header", "Nonce solving the challenge"},
+	{"X-Captcha-Response", "header", "Response of the CAPTCHA, when the pastebin requires one"},
+}
+
+// Operations are the operations of the API that clients are generated
+// for. Paths with {key} are those of private pastes.
+var Operations = []Operation{
+	{ID: "getChallenge", Method: "GET", Path: "/api/v1/challenge", Summary: "Get a proof of work challenge", Response: PowChallenge{}},
+	{ID: "listPastes", Method: "GET", Path: "/api/v1/pastes", Summary: "List the public pastes with a tag",
+		Parameters: []Parameter{{"tag", "query", "Tag of the pastes"}}, Response: []PasteSummary{}},
+	{ID: "createPastes", Method: "POST", Path: "/api/v1/pastes/batch", Summary: "Create pastes",
+		Parameters: createParameters, Request: []Paste{}, Statuses: []int{http.StatusCreated, http.StatusMultiStatus}, Response: []BatchResult{}},
+	{ID: "getPaste", Method: "GET", Path: "/api/v1/pastes/{checksum}", Summary: "Get a paste", Response: Paste{}},
+	{ID: "getPrivatePaste", Method: "GET", Path: "/api/v1/pastes/{checksum}/{key}", Summary: "Get a private paste", Response: Paste{}},
+	{ID: "deletePaste", Method: "DELETE", Path: "/api/v1/pastes/{checksum}", Summary: "Delete a paste",
+		Parameters: []Parameter{{"X-Delete-Token", "header", "Delete token or passphrase of the paste"}}, Response: BatchResult{}},
+	{ID: "getPasteMeta", Method: "GET", Path: "/api/v1/pastes/{checksum}/meta", Summary: "Get the metadata of a paste", Response: PasteMeta{}},
+	{ID: "getPrivatePasteMeta", Method: "GET", Path: "/api/v1/pastes/{checksum}/{key}/meta", Summary: "Get the metadata of a private paste", Response: PasteMeta{}},
+	{ID: "signURL", Method: "POST", Path: "/api/v1/pastes/{checksum}/signed-url", Summary: "Create a signed raw URL of a paste",
+		Parameters: []Parameter{{"ttl", "query", "How long the URL is valid, like 1h"}}, Response: SignedURL{}},
+	{ID: "signPrivateURL", Method: "POST", Path: "/api/v1/pastes/{checksum}/{key}/signed-url", Summary: "Create a signed raw URL of a private paste",
+		Parameters: []Parameter{{"ttl", "query", "How long the URL is valid, like 1h"}}, Response: SignedURL{}},
+	{ID: "clonePaste", Method: "POST", Path: "/api/v1/pastes/{checksum}/clone", Summary: "Get a draft of a new paste from a paste", Response: Paste{}},
+	{ID: "clonePrivatePaste", Method: "POST", Path: "/api/v1/pastes/{checksum}/{key}/clone", Summary: "Get a draft of a new paste from a private paste", Response: Paste{}},
+	{ID: "createUpload", Method: "POST", Path: "/api/v1/uploads", Summary: "Start an upload in chunks",
+		Parameters: createParameters, Request: UploadRequest{}, Statuses: []int{http.StatusCreated}, Response: Upload{}},
+	{ID: "getUpload", Method: "GET", Path: "/api/v1/uploads/{id}", Summary: "Get an upload, to resume it", Response: Upload{}},
+	{ID: "appendUpload", Method: "PATCH", Path: "/api/v1/uploads/{id}", Summary: "Append a chunk to an upload",
+		Parameters:  []Parameter{{"Upload-Offset", "header", "Offset of the chunk, which must be that of the upload"}},
+		RequestType: "application/offset+octet-stream", Statuses: []int{http.StatusOK, http.StatusCreated}, Response: Upload{}},
+	{ID: "quickPaste", Method: "PUT", Path: "/q", Summary: "Create a paste of the body and get its URL",
+		Parameters: append([]Parameter{
+			{"lang", "query", "Language of the paste, instead of the detected one"},
+			{"visibility", "query", "public, unlisted or private"},
+			{"tags", "query", "Tags separated by commas"},
+		}, createParameters...),
+		RequestType: "text/plain", Statuses: []int{http.StatusOK, http.StatusCreated}, ResponseType: "text/plain"},
+	{ID: "downloadPaste", Method: "GET", Path: "/raw/{checksum}", Summary: "Download a paste", ResponseType: "application/octet-stream"},
+	{ID: "downloadPrivatePaste", Method: "GET", Path: "/raw/{checksum}/{key}", Summary: "Download a private paste", ResponseType: "application/octet-stream"},
+}
+
+// OpenAPI returns the OpenAPI 3 description of the operations of the API
+// of the pastebin at serverURL, if known, with the schemas of their bodies read
+// from the types of this package, so that they can not drift apart.
+func OpenAPI(serverURL string) map[string]interface{} {
+	schemas := map[string]interface{}{}
+	paths := map[string]interface{}{}
+	for _, op := range Operations {
+		operation := map[string]interface{}{
+			"operationId": op.ID,
+			"summary":     op.Summary,
+		}
+
+		var params []interface{}
+		for _, name := range pathParameters(op.Path) {
+			params = append(params, map[string]interface{}{
+				"name": name, "in": "path", "required": true, "schema": map[string]interface{}{"type": "string"},
+			})
+		}
+		for _, p := range op.Parameters {
+			params = append(params, map[string]interface{}{
+				"name": p.Name, "in": p.In, "description": p.Description, "schema": map[string]interface{}{"type": "string"},
+			})
+		}
+		if len(params) > 0 {
+			operation["parameters"] = params
+		}
+
+		if op.Request != nil {
+			operation["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content":  jsonContent(reflect.TypeOf(op.Request), schemas),
+			}
+		} else if op.RequestType != "" {
+			operation["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content":  binaryContent(op.RequestType),
+			}
+		}
+
+		responses := map[string]interface{}{
+			"default": map[string]interface{}{
+				"description": "Error",
+				"content":     jsonContent(reflect.TypeOf(BatchResult{}), schemas),
+			},
+		}
+		statuses := op.Statuses
+		if len(statuses) == 0 {
+			statuses = []int{http.StatusOK}
+		}
+		for _, status := range statuses {
+			response := map[string]interface{}{"description": http.StatusText(status)}
+			if op.Response != nil {
+				response["content"] = jsonContent(reflect.TypeOf(op.Response), schemas)
+			} else if op.ResponseType != "" {
+				response["content"] = binaryContent(op.ResponseType)
+			}
+			responses[strconv.Itoa(status)] = response
+		}
+		operation["responses"] = responses
+
+		item, ok := paths[op.Path].(map[string]interface{})
+		if !ok {
+			item = map[string]interface{}{}
+			paths[op.Path] = item
+		}
+		item[strings.ToLower(op.Method)] = operation
+	}
+
+	spec := map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   "pastebin",
+			"version": "1",
+		},
+		"paths":      paths,
+		"components": map[string]interface{}{"schemas": schemas},
+	}
+	// Without a server, clients are given the URL of the pastebin
+	if serverURL != "" {
+		spec["servers"] = []interface{}{map[string]interface{}{"url": serverURL}}
+	}
+	return spec
+}
+
+func pathParameters(path string) []string {
+	var names []string
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			names = append(names, segment[1:len(segment)-1])
+		}
+	}
+	return names
+}
+
+func jsonContent(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"application/json": map[string]interface{}{"schema": schemaOf(t, schemas)},
+	}
+}
+
+func binaryContent(contentType string) map[string]interface{} {
+	return map[string]interface{}{
+		contentType: map[string]interface{}{
+			"schema": map[string]interface{}{"type": "string", "format": "binary"},
+		},
+	}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaOf returns the schema of values of the type as encoding/json
+// encodes them. Structs are added to schemas by their name and referred
+// to. No fields are required, as the types are both requests, where the
+// server has defaults for most fields, and responses.
+func schemaOf(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	if t.Kind() == reflect.Ptr {
+		schema := schemaOf(t.Elem(), schemas)
+		if _, ref := schema["$ref"]; !ref {
+			schema["nullable"] = true
+		}
+		return schema
+	}
+	if t == timeType {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int32:
+		return map[string]interface{}{"type": "integer", "format": "int32"}
+	case reflect.Int64:
+		return map[string]interface{}{"type": "integer", "format": "int64"}
+	case reflect.Slice:
+		return map[string]interface{}{"type": "array", "items": schemaOf(t.Elem(), schemas)}
+	case reflect.Struct:
+		ref := map[string]interface{}{"$ref": "#/components/schemas/" + t.Name()}
+		if _, ok := schemas[t.Name()]; ok {
+			return ref
+		}
+		properties := map[string]interface{}{}
+		schema := map[string]interface{}{"type": "object", "properties": properties}
+		// Added before the fields, for types referring to themselves
+		schemas[t.Name()] = schema
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			tag := strings.Split(f.Tag.Get("json"), ",")
+			if f.PkgPath != "" || tag[0] == "-" {
+				continue
+			}
+			name := tag[0]
+			if name == "" {
+				name = f.Name
+			}
+			properties[name] = schemaOf(f.Type, schemas)
+		}
+		return ref
+	}
+	return map[string]interface{}{}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ManifestEntry describes one paste in an archive download.
+type ManifestEntry struct {
+	Checksum string `json:"checksum"`
+	File     string `json:"file,omitempty"`
+	Size     int    `json:"size"`
+	Status   string `json:"status"`
+	Message  string `json:"message,omitempty"`
+}
+
+// archiveWriter hides the differences between the zip and tar formats.
+type archiveWriter interface {
+	add(name string, data []byte) error
+	Close() error
+}
+
+type zipArchive struct {
+	*zip.Writer
+}
+
+func (a zipArchive) add(name string, data []byte) error {
+	f, err := a.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	return err
+}
+
+type tarArchive struct {
+	*tar.Writer
+	gz *gzip.Writer
+}
+
+func (a tarArchive) add(name string, data []byte) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}
+	if err := a.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := a.Write(data)
+	return err
+}
+
+func (a tarArchive) Close() error {
+	if err := a.Writer.Close(); err != nil {
+		return err
+	}
+	return a.gz.Close()
+}
+
+func newArchive(w io.Writer, format string) archiveWriter {
+	if format == "tar.gz" {
+		gz := gzip.NewWriter(w)
+		return tarArchive{tar.NewWriter(gz), gz}
+	}
+	return zipArchive{zip.NewWriter(w)}
+}
+
+// downloadArchive returns the pastes given as checksum query parameters as
+// a zip or tar.gz archive, together with a manifest.json describing them.
+// When -private is enabled each checksum must be given as checksum/key.
+func downloadArchive(w http.ResponseWriter, r *http.Request) {
+	checksums := r.URL.Query()["checksum"]
+	if len(checksums) == 0 {
+		http.Error(w, "No checksums given", http.StatusBadRequest)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "tar.gz" {
+		format = "zip"
+	}
+
+	contentType := "application/zip"
+	if format == "tar.gz" {
+		contentType = "application/gzip"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", "attachment; filename=\"pastes."+format+"\"")
+
+	a := newArchive(w, format)
+	manifest := make([]ManifestEntry, 0, len(checksums))
+	for _, param := range checksums {
+		parts := strings.SplitN(param, "/", 2)
+		checksum, key := parts[0], ""
+		if len(parts) == 2 {
+			key = parts[1]
+		}
+
+		entry := ManifestEntry{Checksum: checksum}
+		var p Paste
+		var err error
+		if service.Authorize(checksum, key) {
+			p, err = retrievePaste(checksum, key)
+		} else {
+			err = fmt.Errorf("invalid key for %s", checksum)
+		}
+		if err != nil {
+			log.Println(err)
+			entry.Status = "error"
+			entry.Message = "Paste " + checksum + " does not exist."
+			manifest = append(manifest, entry)
+			continue
+		}
+
+		entry.File = p.Checksum + ".txt"
+		entry.Size = len(p.Content)
+		entry.Status = "success"
+		if err := a.add(entry.File, []byte(p.Content)); err != nil {
+			log.Printf("Unable to write archive: %s\n", err)
+			return
+		}
+		manifest = append(manifest, entry)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		log.Printf("Unable to encode manifest: %s\n", err)
+		return
+	}
+	if err := a.add("manifest.json", data); err != nil {
+		log.Printf("Unable to write archive: %s\n", err)
+		return
+	}
+	if err := a.Close(); err != nil {
+		log.Printf("Unable to write archive: %s\n", err)
+	}
+}
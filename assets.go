@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// The embedded templates and static files are generated from the
+// templates and static directories of the source tree. With -assets-dir
+// pointing at the source tree they are read from disk instead, and since
+// templates are parsed for every request, changes show up on the next
+// page load without rebuilding.
+
+// readTemplate returns the template file, from -template-dir when it has
+// a file of the same name, then from -assets-dir, and otherwise the
+// embedded one.
+func readTemplate(file string) ([]byte, error) {
+	if *templateDirFlag != "" {
+		data, err := ioutil.ReadFile(filepath.Join(*templateDirFlag, filepath.Base(file)))
+		if err == nil {
+			return data, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	if *assetsDirFlag != "" {
+		return ioutil.ReadFile(filepath.Join(*assetsDirFlag, filepath.FromSlash(file)))
+	}
+	return Asset(file)
+}
+
+// staticFiles returns the file system /static/ is served from.
+func staticFiles() http.FileSystem {
+	if *assetsDirFlag != "" {
+		return http.Dir(filepath.Join(*assetsDirFlag, "static"))
+	}
+	return assetFS()
+}
+
+// checkAssetsDir reports an error unless -assets-dir has the templates
+// and static directories.
+func checkAssetsDir() error {
+	if *assetsDirFlag == "" {
+		return nil
+	}
+	for _, dir := range []string{"templates", "static"} {
+		fi, err := os.Stat(filepath.Join(*assetsDirFlag, dir))
+		if err != nil || !fi.IsDir() {
+			return fmt.Errorf("-assets-dir %s has no %s directory", *assetsDirFlag, dir)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const auditKey = "audit"
+
+// AuditEntry is an action taken by a moderator or admin. Each entry
+// carries a hash over its content and the hash of the entry before it,
+// so that entries changed or removed after the fact break the chain.
+type AuditEntry struct {
+	Seq    int       `json:"seq"`
+	Time   time.Time `json:"time"`
+	Actor  string    `json:"actor"`
+	Action string    `json:"action"`
+	Target string    `json:"target,omitempty"`
+	Detail string    `json:"detail,omitempty"`
+	Hash   string    `json:"hash"`
+}
+
+// AuditLog is the response of the audit log API. Verified is false when
+// the chain of hashes is broken, and BrokenAt is the first entry that
+// does not match.
+type AuditLog struct {
+	Entries  []AuditEntry `json:"entries"`
+	Verified bool         `json:"verified"`
+	BrokenAt int          `json:"broken_at,omitempty"`
+}
+
+// The audit log is stored as a single record. Entries are only ever
+// appended to it.
+var auditMu sync.Mutex
+
+func retrieveAudit() ([]AuditEntry, error) {
+	var entries []AuditEntry
+	err := retrieveJSON(auditKey, &entries)
+	if isNotFound(err) {
+		return nil, nil
+	}
+	return entries, err
+}
+
+// auditHash returns the hash of the entry, chained to the hash of the
+// entry before it.
+func auditHash(prev string, e AuditEntry) string {
+	e.Hash = ""
+	data, _ := json.Marshal(e)
+	sum := sha256.Sum256(append([]byte(prev), data...))
+	return hex.EncodeToString(sum[:])
+}
+
+// verifyAudit returns the sequence number of the first entry that does
+// not match its hash, and 0 when the whole chain matches.
+func verifyAudit(entries []AuditEntry) int {
+	prev := ""
+	for i, e := range entries {
+		if e.Seq != i+1 || e.Hash != auditHash(prev, e) {
+			return i + 1
+		}
+		prev = e.Hash
+	}
+	return 0
+}
+
+// audit records an action taken by the user signed in to the request, or
+// by the system when r is nil. Failing to record it is logged, but does
+// not undo the action.
+func audit(r *http.Request, action, target, detail string) {
+	actor := "system"
+	if r != nil {
+		actor, _, _ = r.BasicAuth()
+	}
+
+	auditMu.Lock()
+	defer auditMu.Unlock()
+	entries, err := retrieveAudit()
+	if err != nil {
+		log.Printf("Unable to record %s of %s by %s in the audit log: %s\n", action, target, actor, err)
+		return
+	}
+	e := AuditEntry{
+		Seq:    len(entries) + 1,
+		Time:   time.Now().UTC(),
+		Actor:  actor,
+		Action: action,
+		Target: target,
+		Detail: detail,
+	}
+	prev := ""
+	if len(entries) > 0 {
+		prev = entries[len(entries)-1].Hash
+	}
+	e.Hash = auditHash(prev, e)
+	if err := storeJSON(auditKey, append(entries, e)); err != nil {
+		log.Printf("Unable to record %s of %s by %s in the audit log: %s\n", action, target, actor, err)
+	}
+}
+
+// apiAudit returns the audit log, optionally only the entries of an
+// actor, action or target, since a time, and the last limit of them. The
+// chain is always verified over the whole log.
+func apiAudit(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var since time.Time
+	if s := q.Get("since"); s != "" {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, BatchResult{Status: "error", Message: "Invalid since, expected RFC 3339: " + s})
+			return
+		}
+		since = t
+	}
+	limit := 0
+	if s := q.Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			writeJSON(w, http.StatusBadRequest, BatchResult{Status: "error", Message: "Invalid limit " + s})
+			return
+		}
+		limit = n
+	}
+
+	auditMu.Lock()
+	entries, err := retrieveAudit()
+	auditMu.Unlock()
+	if err != nil {
+		log.Printf("Unable to read the audit log: %s\n", err)
+		if storageUnavailable(w, err) {
+			return
+		}
+		writeJSON(w, http.StatusInternalServerError, BatchResult{Status: "error", Message: "Unable to read the audit log"})
+		return
+	}
+
+	l := AuditLog{Entries: []AuditEntry{}}
+	l.BrokenAt = verifyAudit(entries)
+	l.Verified = l.BrokenAt == 0
+	for _, e := range entries {
+		if q.Get("actor") != "" && e.Actor != q.Get("actor") ||
+			q.Get("action") != "" && e.Action != q.Get("action") ||
+			q.Get("target") != "" && e.Target != q.Get("target") ||
+			e.Time.Before(since) {
+			continue
+		}
+		l.Entries = append(l.Entries, e)
+	}
+	if limit > 0 && len(l.Entries) > limit {
+		l.Entries = l.Entries[len(l.Entries)-limit:]
+	}
+	writeJSON(w, http.StatusOK, l)
+}
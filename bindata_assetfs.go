@@ -570,7 +570,19 @@
 // static/codemirror/theme/zenburn.css
 // static/custom.css
 // static/custom.js
+// static/print.css
+// templates/access.html
+// templates/admin.html
+// templates/embed.html
+// templates/error.html
+// templates/jobs.html
 // templates/pastebin.html
+// templates/reports.html
+// templates/set.html
+// templates/stats.html
+// templates/stream.html
+// templates/tag.html
+// templates/trash.html
 // DO NOT EDIT!
 
 package main
@@ -11999,7 +12011,7 @@ func staticCodemirrorThemeZenburnCss() (*asset, error) {
 	return a, nil
 }
 
-var _staticCustomCss = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\xd2\x73\xce\x4f\x49\xf5\xcd\x2c\x2a\xca\x2f\x52\xa8\xe6\xe2\xcc\x48\xcd\x4c\xcf\x28\xb1\x52\x48\x2c\x2d\xc9\xb7\xe6\xaa\xe5\xe2\x02\x04\x00\x00\xff\xff\x6c\x5a\x2c\x76\x20\x00\x00\x00")
+var _staticCustomCss = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x85\x54\xcb\x6e\xdb\x30\x10\x3c\xc7\x5f\x41\x38\x97\x34\x30\x15\x59\x71\x9a\x44\x41\x81\x02\xed\xb5\x5f\xd0\xf6\x40\x8a\x2b\x8b\x35\xc5\x15\x48\xca\x8f\x16\xf9\xf7\x2e\xa5\xc8\x91\x5d\x35\x81\x0d\xd8\x4b\xce\xcc\xbe\x46\x4a\xbe\xa0\x82\x6f\xda\x39\x74\xec\xcf\xec\xa2\x02\xbd\xae\x42\xce\x44\x1b\xf0\x69\xf6\x3c\x9b\xcd\x6e\xae\xd9\x57\xe1\x36\xac\x40\x43\x90\x50\x41\x0d\x0b\x56\x54\xe8\xc1\x32\xd8\x37\x46\x17\x3a\x98\x03\xa3\xbb\x12\x8d\xc1\x9d\xb6\xeb\x88\x62\xd2\xe1\xce\x83\x4b\xd8\xf5\xcd\xec\xbb\x12\x41\xf0\x8e\xfb\x69\xae\x48\x6d\xfe\x93\x49\x54\x87\xc5\xe4\x4d\x52\xa2\xab\x79\x81\x36\x38\x34\xd3\x90\x00\xfb\x20\x1c\x88\x58\xb2\x14\xc5\x66\xed\xb0\xb5\x8a\x77\x35\xe6\xec\x72\x09\xd9\x32\x5b\x3d\xcd\x2e\x86\x03\x48\xe3\xa7\x6b\x68\x32\xa3\x15\x5b\x29\x1c\x37\xb1\xf9\x63\x24\x9d\xb0\x2a\x66\x98\x50\xf9\x5c\x83\xd2\x82\x5d\x35\x0e\x4a\x70\xbe\xcf\xcc\x7d\x11\x65\x73\x16\x65\x3f\x44\xe6\x49\xb2\x38\xd3\x63\xe3\x93\x57\x67\x9d\x4f\x62\xc6\xad\xbf\xd9\xfb\x3f\x65\x5f\x50\xdd\xd3\x69\xdf\x6e\x7f\x4a\x88\xa4\x92\x0a\xf6\xaa\xad\x9b\x08\xa9\xc5\x9e\x0f\xd6\xb9\x4d\xa1\x26\x0c\x6e\xc1\x95\xe4\x87\x91\x97\x92\x46\xf8\x00\x9c\x66\xb6\xd5\xb0\x3b\xe7\x2d\xb3\x33\x5e\xa5\x95\x02\xdb\x33\x1d\x58\x05\x0e\xd4\x39\x69\x35\x9d\x8c\x30\x6e\xad\x2d\x97\x18\x02\xd6\xa4\x1d\x51\x51\xe7\x97\x47\xcb\x83\x03\x60\xad\x89\x5a\x46\xfb\xc0\x7d\x38\x18\xda\x9a\x45\x0b\x47\x6a\xce\x62\xa3\x8d\x50\x8a\x0c\xcd\x0d\x94\xb1\xc2\xe4\xee\x44\x67\x03\x87\xb1\x3d\x1e\x60\xb5\x12\x6a\x74\xef\x83\x8b\x4f\xc3\x08\x92\xdd\x0b\xf8\x98\x8e\x20\xb6\xad\x25\xb8\xc5\x4b\x64\x74\x00\x27\xcc\x09\xe3\xf1\x21\x95\x8f\x3d\xc3\x62\x00\x89\xb8\xa1\x5d\x2b\x98\xb6\xbe\x5b\x4b\x71\x95\x2e\xd8\xcb\x37\x49\xef\x3e\xbc\xb6\x41\x3d\xbd\x76\x70\x14\x83\xe1\xd9\x1f\x52\x16\xe9\xed\x63\x26\x87\x22\x39\x5a\x43\x7e\x4d\x1a\xea\x25\x74\x41\xc4\x2a\xed\x1b\x23\x0e\xc3\xd0\x7a\x28\x1b\xe0\x27\x08\x6d\x8d\xb6\xc0\xa5\xc1\x62\xd3\x21\x45\x5e\x62\xd1\x7a\xd2\x94\xc1\x1e\xff\x8f\xad\x3f\x1c\xfa\xb6\xa6\x6d\x1c\xfa\x30\x8a\x62\x1b\xa2\x58\xce\xb2\x66\xcf\x3c\x1a\xad\xd8\x65\x9a\xdd\xdf\xa9\x87\xa7\xe3\x25\xc7\xb2\xf4\x10\x3a\x4c\x5f\x19\x9d\x6e\x74\xa9\x7b\xf7\xec\x2a\x1a\x32\xf7\x8d\x28\x48\x86\xac\xc8\x77\x4e\x34\xc4\xde\xa1\x53\xe4\x79\x10\x9b\x9c\x75\x3f\x5c\x18\xd3\xf3\x4b\xaa\x8a\xfb\x9a\xe2\x28\xd0\x47\xfa\x37\xc4\x71\x3e\xb8\x61\x9e\xdd\xb1\x21\xef\xc0\x19\x68\x99\x64\x77\x47\x94\xaf\x70\xc7\xbb\x12\xba\x0a\x58\x52\xd4\x7d\x2d\x8b\xe9\xbb\x20\x64\x94\x6b\xd0\xeb\xa0\x91\x5c\xe9\xc0\x88\xa0\xb7\xf0\x8e\x5c\x9e\x4b\xa0\x89\xbe\x21\x3b\x20\x4e\xe5\x85\xa4\xa9\xb6\x01\x46\xef\x4e\x21\x44\xb4\x10\xd2\xfa\xc1\x71\xd8\x82\x0d\x7e\xbc\xf7\x77\x6b\xe8\xbd\x45\x6c\x4b\x4b\x99\xff\x48\x53\x79\x3f\xff\x3f\xf5\xac\xb4\x11\x31\x5b\x3e\x66\x3d\x91\xd6\x76\xce\x4d\xe2\x1a\x9b\x37\x57\xfc\x3c\xfb\x0b\x91\x07\x37\xec\xed\x06\x00\x00")
 
 func staticCustomCssBytes() ([]byte, error) {
 	return bindataRead(
@@ -12014,12 +12026,12 @@ func staticCustomCss() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "static/custom.css", size: 32, mode: os.FileMode(420), modTime: time.Unix(1488484492, 0)}
+	info := bindataFileInfo{name: "static/custom.css", size: 1773, mode: os.FileMode(436), modTime: time.Unix(1792157575, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
 
-var _staticCustomJs = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x54\xc8\xc1\x0a\x82\x40\x10\x06\xe0\xfb\x3e\xc5\xe0\x49\x41\x7c\x00\xa3\x83\x45\x07\x21\x3b\x05\x9d\x37\xf7\x57\x06\x74\x56\xc6\xd1\x92\xe8\xdd\xa3\x53\x74\xfb\xf8\x56\xaf\x84\xc0\x16\x95\xf6\x74\x8c\x01\x0d\xab\x46\x2d\x3a\x8d\xe3\x15\x4f\xab\x14\x3e\x0d\xb1\x5d\x46\x88\x15\x3d\xec\x34\xe0\xcb\xc3\x56\x87\x34\x69\xa3\x18\xc4\x92\x2c\xa7\x97\x23\x9a\x6d\x1b\x50\xb5\xc6\x2b\xce\x2c\x28\xc9\x74\x41\xee\x88\x06\x16\x5c\x96\xf1\x0e\x9d\xff\xf3\xa6\x7e\x9a\x58\xfa\xdf\xae\x8c\xc7\x14\xd5\x1a\xaf\x3d\x4b\x49\xb5\x74\x2c\x6c\x9b\x7b\x67\x3b\xe7\x3e\x01\x00\x00\xff\xff\x26\x6c\xb5\x81\xaf\x00\x00\x00")
+var _staticCustomJs = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\xa5\x59\x6d\x73\x1b\xb7\x11\xfe\xae\x5f\x81\x5c\x3a\xc9\xb1\x22\x4f\x92\x9b\x64\x52\x39\xca\x8c\xac\x38\x8d\x53\xbf\x8d\xa5\x34\x9d\xaa\x1a\x0f\x78\x07\x92\xb0\x8e\x87\x2b\x00\x8a\x61\x12\xfd\xf7\x3e\x8b\x97\x3b\x1c\x49\x29\x49\x9b\x89\x6d\x12\x58\xec\x3b\x76\x9f\x05\xef\xb8\x66\xa5\x6a\xac\x68\x2c\x3b\x63\x95\x2a\x57\x4b\x7c\x2c\xe6\xc2\x3e\xaf\x05\x7d\x7c\xb6\x79\x51\xe5\x59\x20\xc9\x46\x4f\x0f\xee\x70\x42\x54\xd2\x2a\x8d\x03\x17\xaa\x12\xaf\xa4\xd6\x4a\x17\x33\xad\x96\x57\xe2\x27\x7b\xae\x05\xcf\x03\xfd\x98\xfd\x72\xc0\x98\x5d\x80\xd3\x69\x14\x43\xbc\xcf\xad\xd5\x72\xba\xb2\x22\xcf\x2a\x6e\xf9\x64\x21\xe7\x8b\x1a\x7f\x20\x80\xfd\xfa\x2b\xcb\x2a\x31\xe3\xab\xda\x66\x63\x9c\x36\x76\x53\x8b\xf3\xd2\xca\x3b\xf1\x52\x36\xe0\x63\xf5\x4a\xd0\x46\x8d\x6f\xaf\x57\xcb\xa9\xd0\x66\xb8\xf8\xa3\xe6\x6d\x2b\x9b\xf9\xa3\x22\xd7\x20\x82\xb4\x8f\xce\xce\x58\x36\xe3\xb5\x11\x4e\xd8\x9d\x14\xeb\x56\x69\xfb\x8a\xeb\xb9\x6c\x4e\xd9\x8b\x66\x26\x1b\x69\x37\x07\xf7\xb0\xdc\x5b\x4d\xcc\x9c\x04\xa1\x83\x8b\xf2\x51\x51\xd6\xdc\x98\x97\xd2\xd8\x82\x57\x55\xb4\xde\xaf\xbe\xe6\x4b\x81\xc3\x07\x47\x47\xec\xb2\xe5\xa5\x30\x8c\x6b\xc1\x96\x5c\xdf\x8a\x8a\x4d\x37\x8c\x37\x4c\xdd\x09\x5d\xf3\xcd\x98\x59\x3e\xdd\xde\xee\x3d\xcc\x78\x0d\xd7\x56\x9b\xe2\x40\xce\x58\xfe\x98\x69\x0b\x69\x85\x21\x59\x30\xf0\x8c\x0c\x24\xef\xe0\x33\x05\xe3\x77\x1b\x91\x99\x85\x5a\x0f\x78\x3d\xed\x8f\x83\xe0\x8d\x57\x3a\x27\xa6\x88\xb1\xba\x15\xf0\xd7\x6c\xd5\x20\x52\xaa\xc9\x8d\x85\xae\x4b\x2f\x92\xfe\x23\x95\xfd\x5a\x21\xb8\xcd\x33\x96\x8d\xfa\x4d\xc6\xb4\xb0\x2b\xdd\xb0\xcc\x4b\x7a\x1a\xd6\xef\xc3\xbf\xe1\xa0\xb9\x95\xed\x95\x72\x67\x29\x4b\x06\xab\xcf\x9b\x2a\x1f\xc5\x73\x81\x5b\xb3\xaa\x6b\xbf\x44\x8c\x28\x82\xf7\x07\x2e\x0e\x6f\xb9\x81\x51\xac\x84\xeb\xa7\x82\x89\xa6\xd4\x9b\xd6\x7a\x77\x23\x57\xd9\x54\xab\xb5\x11\x1a\x7b\x33\x85\x58\x60\x69\xe3\x82\x62\xc8\xdf\xec\x0a\x14\xb7\x62\x43\x7c\xa4\x61\xed\xca\x32\xd9\xb8\x63\x33\xcd\xe7\xe4\x49\xa6\x66\xee\xfb\x0f\xef\x5e\xfa\x75\xa5\x97\x8e\x54\x19\x12\x62\xd5\x98\xc1\xab\xe5\x82\x36\x89\x4b\x14\x77\x2b\x44\x6b\xb0\xa8\xd5\x6a\xee\x36\x61\x47\x25\xb5\x28\x2d\xce\xb8\xef\x2d\xe9\x3d\x66\x08\x35\x6b\x04\xdc\x4f\x1a\x55\x26\xec\x12\x2b\xb0\xc1\x72\x71\x10\xc3\x80\xad\x67\xdc\x88\x2f\x3e\xcb\xa7\x1b\x58\xec\x3d\x4e\x17\xd8\xe0\xee\x66\xce\xcf\xd0\x8e\xe5\xb4\x24\xb1\x74\xfc\x14\xff\x7c\xc5\x1c\x71\x51\x8b\x66\x6e\x17\x58\x39\x3c\x8c\xa1\x32\xec\xf0\x8c\x5d\x22\xdb\x9a\xb9\xbb\xef\x17\x0b\xae\x29\x41\x3d\xfb\x6b\x79\xe3\x42\x40\xde\x0e\x21\x98\x5a\xc5\x73\xe3\x3d\xdf\x29\x45\x27\x83\x5a\x89\x4a\x53\xb8\xf1\x8c\x71\xab\xa6\xee\x40\x58\x24\xbe\x58\x6e\xc4\x9a\xfd\x20\x1b\xfb\xe5\xb9\xd6\xc8\x39\xd0\x06\xf5\x46\x0f\xda\xd0\x91\x0c\x2c\x88\x8a\x82\x90\x28\xca\x60\xc0\xb9\xcd\xe5\x8e\xee\x44\xea\x34\xe7\x66\xd3\x94\x5d\x6e\xc7\x84\x71\x59\x94\x5b\x94\xbc\xde\x08\x24\x06\x19\xb1\xe6\xd2\x32\x47\xa4\x0a\xb3\x9a\xda\x5a\xe0\xc2\x35\x42\x73\x2b\xfe\x2e\x70\x65\x1a\x4e\x05\x31\x3b\x7f\x7e\x39\xf9\xdb\xc5\xab\x6c\xcc\xbc\xa6\xa7\xec\xc9\xe7\x5f\xdc\x8f\x7d\x31\x63\xd7\x59\x10\x94\xdd\x74\xfe\x90\x77\x60\x1f\x18\xe3\x0a\xbf\xe3\x4d\xa5\x96\xff\xe0\xf5\x4a\x98\x7c\xcb\x47\x27\x4f\x46\xdd\xb1\x52\xb6\x0b\xa1\x49\xd5\x07\xb4\x0b\x92\xf6\x68\x26\xef\x4e\xf1\x07\x4a\xc1\xb4\xb1\x8b\x03\x15\xf9\xe7\x4d\x09\xaf\x69\x14\x0d\xe1\x3e\x79\x37\x74\xf2\x34\x5f\xef\x06\x6d\xaf\xdc\x9f\xa8\xda\x92\x4f\x32\x9c\xc9\x9c\x14\xcf\x26\x04\xc1\x47\x2d\x14\x3b\x28\xd6\x4e\xc5\xc9\x69\xc6\x0e\xfb\xcc\x96\x77\x23\x7c\xcd\x86\x8b\x5b\xa2\x7b\xfb\x47\xa3\xb1\xe3\x08\x39\xa7\x3d\x39\x64\x8f\x0a\x2d\xda\x1a\xd5\x27\x3f\xfa\xf7\xe1\xd1\x7c\xcc\xb2\x49\x96\xae\x1d\xb9\xb5\xf7\xe9\xda\xd9\xe1\x9f\x8e\xb0\x96\x8d\x28\x6f\xf6\x25\x4a\x25\x92\x44\xe9\x5a\x62\xac\x13\x7d\xd6\xb4\x5c\x5b\xca\xf2\x58\xd3\x4d\x2d\xc1\x3f\xd8\x1a\x13\xbd\x30\x6d\x2d\x51\x39\x4f\xb3\x2d\x37\x27\xd7\x29\xb2\xee\x75\x9c\x38\xb5\x0f\x53\xb5\xdf\xbb\xa5\xa3\xac\x0f\xd7\xc3\x59\x2b\x97\x5b\xf1\xc1\xdf\xe3\x34\x3d\x5c\xeb\xa4\x5c\x0d\xb6\x26\xb9\x0a\x71\x88\xc0\xc3\x39\x17\x4e\x3c\x90\x73\x89\x55\xce\x3d\xd7\xc7\x37\xa3\x98\x85\x3b\x7b\x27\x37\x83\xa4\x89\x59\xfa\x8d\x88\x59\x5a\xb9\x4f\x79\xa7\x92\xaf\x48\x0e\xca\xf8\xcc\xff\x56\x8a\xba\x7a\x0c\x01\xc5\xbb\x88\x93\xd4\xcb\x06\xc7\x3e\xf9\x84\xad\x25\x6e\xe2\xba\xf0\x16\xd2\xc2\xc0\xd6\x3e\xd4\x7d\xb3\x39\xf3\xbe\x23\xb5\x51\xb9\xdf\x89\x39\x1a\xaf\xd0\xd4\x85\xba\xb6\xc3\x5a\xad\xd0\x4c\xf0\xff\x5a\xe9\xdb\xd8\x36\xd0\x4a\x8c\xaa\xef\x40\xa9\x9a\xd2\xd1\x79\x16\x11\xc6\x61\xbf\x93\x72\xd0\xdd\x9d\x82\xda\x10\xf5\xed\xe7\x77\xf8\x46\x5d\x9e\x0a\x12\xba\xfc\x6a\xba\x94\x96\x22\x19\x5b\xb7\x88\xb5\x92\xec\xfc\x28\x35\x14\xe5\x52\x94\x84\x4b\xd0\x7d\x3b\x11\x7d\x1b\xf7\xde\xef\x3b\x2e\xd0\x42\xd1\x6a\x41\xf2\xbe\xf1\x80\x2e\xb6\x68\x51\x18\xab\xda\x17\xcb\x25\x1a\x1c\x6a\xe2\x5b\xad\x5a\x3e\xe7\x4e\x7a\xa0\x20\x5f\xb9\xc6\x79\x36\x30\x20\x1c\x4f\x0b\x70\x0f\x68\x5c\x1d\xcc\x47\xa3\x02\x2e\x69\xf2\xce\x1e\x2d\x0c\x44\xf7\x6a\xa6\x21\xa0\x4a\x1b\x51\x43\x60\x64\x22\x23\x7f\xae\x08\xe2\x3b\x70\xe1\xfd\xe8\x2f\xf8\x59\xfa\x2d\x5e\xd0\x8f\xb3\x11\x92\x95\x6a\xd2\xc7\x54\x93\x02\x1b\x24\xee\x80\x83\x16\xff\x41\xd1\xb6\x97\xce\xfd\xb9\x28\x7c\x1c\x90\x01\x41\xd0\xbd\x6f\x47\x94\xa8\x4c\x20\x4f\xd8\x76\xd6\x79\x83\x10\xf7\x1f\xc5\xf4\xc2\xa7\x1d\x22\xaf\x9a\x1a\x60\xe5\x8e\xcb\x9a\x4f\x6b\x81\xaf\xc0\x04\xe5\x0a\xe9\xa4\xb4\x04\xa2\x35\x07\x8c\xed\x8d\x68\x92\x8d\x83\x7d\xdc\x2f\x18\xff\x1a\x97\xa7\x70\x40\xbc\xa8\x24\xcc\xe4\x54\x2f\xb2\x46\x35\x84\xd5\x70\x91\x7e\x03\x90\x76\x1e\x1f\xe0\x51\x77\x4b\xc2\xa1\x3b\xf2\xb8\x07\xe3\x59\x7f\x59\x42\x85\xb8\xb4\xdc\xae\xcc\x63\x97\x33\x10\x4e\x8c\xa3\xf4\x05\x72\x5f\xf1\xf5\x72\xd0\x6e\x55\xe9\xb2\xad\x58\x70\xb3\x08\xe5\xf6\x64\x27\x71\xfa\xd6\xbe\x9b\x1c\xa1\x88\x50\x84\x0a\xf0\x2a\x17\xfd\xb1\x78\x64\xa0\x7d\x3f\x0b\x90\xe7\x78\x8d\x6e\xc4\xdc\xdf\x93\x8a\x37\x73\xa1\x03\xe6\x1d\x9e\x21\x21\x17\xfd\x70\x36\xd8\xdb\xe7\x66\x9a\x12\xb2\x3e\x6f\xfc\xcc\x41\xa5\x62\xb7\x98\x30\x20\x9f\x9a\x3a\x8b\x48\xeb\x4d\x84\xaa\x31\x19\x2b\x80\x4d\x87\x74\x31\x05\x55\x00\x7d\x8c\xb3\x26\x14\x1d\x6e\xd9\x1c\x23\x19\xc6\x15\x46\x4e\x44\x01\xb4\x0b\xa4\x8e\xc3\xae\x35\xc6\x14\xa2\xfe\x59\x68\x05\x98\x65\x4d\x82\x48\xc3\xde\xbf\xb0\xf5\x0c\x3b\xdb\xc0\xb4\x71\x08\xee\x8f\xe2\x52\xca\xbf\x1e\xd9\x21\x8b\x8e\xfb\xeb\xde\x10\x66\xfd\x32\xde\x3d\x4a\x04\xd9\xc4\x4b\xef\xab\x54\xec\x1a\xb8\xac\xaf\xb8\x5d\x20\x54\x3f\xff\xe5\x49\x0f\x69\xd9\x84\x3d\xf9\x6c\x0b\x1b\x36\xfb\xda\xbd\xab\xca\x79\xe7\xd8\x31\xab\xe4\x6c\x26\x4b\xdc\xfe\xcd\xa0\x01\x50\x4f\x0a\xf0\x68\x80\xa5\x06\x66\x7b\x3f\x3b\xd3\x9f\xfa\x2f\xbd\xb9\x0e\xc1\xaf\x96\x0f\xf5\x55\x39\x47\x61\xc9\xb3\xcb\xef\xce\x27\xc0\x93\x28\xed\x41\x66\xc4\x6a\x7d\xe8\x23\x6e\x72\xfc\x47\xa1\xf2\x90\x33\xb7\xa3\xb4\x85\xa7\x20\x1c\xe3\xdb\xd7\x67\x3b\x16\x26\xee\xf4\x63\x42\xee\x59\x27\x63\x58\x6c\xbc\x6e\x23\xb6\xdd\x41\x93\x42\x61\xd4\x9b\x4b\x51\x63\xf2\x51\x3a\xff\x54\x36\x18\xb2\xae\x09\x24\x9c\x65\xad\x5a\xbf\x77\x07\xb3\x9b\x4f\x43\x27\xee\xf9\x78\x15\xfe\xbf\x7e\xd7\x73\xdb\x53\x93\xfe\x58\x93\x7b\xb4\x85\xb9\xf2\xd6\x39\x0f\x24\x28\xb4\x46\xbc\xc0\x1c\x9e\x68\xb0\xe7\x8e\xf7\x67\xb2\xd1\x98\x9d\x1c\x07\x59\x3e\xf5\x9c\xc9\xc2\x17\x46\x73\xed\x9c\xd5\xc5\x3a\xbb\x89\xc5\x2f\x89\xd9\x56\xc1\xf3\xb1\xea\x6f\xce\xb6\x2f\xce\xfc\xd2\xff\xda\xc7\x5c\x3d\xa2\xf1\xb9\x54\xed\x86\xa6\x58\xab\x08\xab\x09\x8c\xb0\xdf\xa3\x65\x5d\x96\x5a\xb6\xc0\xc7\x06\x8d\xcc\x76\xbd\x8c\x5e\x21\x1a\x20\x1f\xd1\xd0\x2a\x95\x2e\x54\x93\xae\x11\xc4\x0f\xa1\x1b\x6c\x3f\x61\x7c\x30\xf1\xc5\xca\x89\x7c\xf4\x81\xab\xdd\x44\x6c\xe7\x68\xd1\x9c\x1a\x7e\x27\x81\x4a\x50\xf6\xcb\x5a\xb6\x53\xc5\x75\x97\x62\xed\x66\x4f\x6a\x81\xaa\xbc\x4d\x33\x2b\xba\x72\x0f\xa3\x62\xad\xa5\x15\x74\xfd\xf7\x00\x98\xa1\xc7\x5e\x72\x8d\xab\x3a\x93\x75\x78\x3a\x5a\xb5\xb5\xe2\x15\x7a\x36\x06\xe6\x72\xb1\x6a\xe0\x10\x76\xee\x3f\xf9\xba\x3c\x43\xf7\x37\xe4\x40\xe4\xaa\x96\x20\x24\xbc\x4c\x9c\xa8\xc4\xab\xd9\x0c\x4d\xcc\x7d\xf4\xaf\x05\x54\xbe\x9d\xd3\x39\x9b\xd5\xfc\x76\x43\xc9\xda\x08\x5f\xd1\x5c\x04\x6a\x65\x84\x71\x07\x9c\x0c\xf7\xf0\x81\x21\xde\xbd\xd7\x61\x1e\xc0\x86\x26\xb9\xfe\x11\x64\xbd\x50\x75\x54\xb1\xf0\x9e\xa7\x43\x97\xf2\x67\x4a\x9f\x13\xf6\xd5\x57\xec\x09\xea\x7b\xdf\x0f\x4c\x2d\x44\x9b\x2f\x43\x0b\x48\x00\x3c\x40\xe1\x52\x1a\x31\xc0\x71\x94\xe5\x20\x84\xe6\xf6\x4a\x2e\x85\x5a\xd9\xb8\x3a\x66\x60\xf1\x74\x2b\xcf\x82\x81\x0e\xe3\x78\x0b\xbc\x62\x0c\x60\x13\x58\x08\x49\x86\xb1\x2b\x94\x49\xe7\x02\xe7\x3e\x0e\x0f\x68\xbd\x72\xf0\x19\x8c\xc2\x11\xb8\xb3\x51\x96\x19\x0e\xe0\x5d\xb0\x17\xd6\xbd\x1e\x39\xff\x4c\x29\xa1\x97\xa8\x51\x0e\x91\x6f\xe1\xad\x62\xa7\x49\x2c\x38\xa4\x7d\x27\x7e\xca\x29\xa2\xde\x6a\x07\xb4\x87\x13\x04\x10\xf6\x47\x7b\x46\x88\xfe\xa5\x2c\x8b\x1d\xa9\x6f\x06\xbf\x63\xde\xde\xed\x0d\x9e\x8a\x74\x29\x38\x1d\x7b\xb6\x9a\xcd\xa8\x19\x0d\x06\x2a\xc7\x10\x65\x4e\x59\x65\x37\xad\x28\x96\xbc\x05\xea\xa9\x6b\x6a\x04\x49\xbe\x4f\xb7\x94\xcc\xb3\x63\xea\x2e\xd3\xc2\xaa\xd0\x0d\x4e\xbe\x00\xca\xf2\x78\x6b\xf2\x24\xe2\xa7\x0f\x4a\x36\x79\x96\x8d\xf6\xf5\x54\xef\xfd\x6f\xa1\x5e\xbe\xd2\xf5\xd8\x29\x3a\x76\x95\x67\x4c\x80\x66\x8e\xf0\x27\xe8\x61\x81\xb6\x25\x34\x41\xc5\x5f\xb2\x00\x9c\x26\x57\x50\x38\xc3\x78\xc9\x5b\xc0\x73\x0f\xfb\x8e\x3e\x18\xd5\x64\xf7\x61\xf4\x72\x15\xa9\x6b\x89\xe1\xf1\x2e\x22\xa1\x5a\xcc\xac\x6b\xca\x71\xf1\x60\xbb\x4f\xa4\x4d\xb9\xec\x5a\x72\xf0\xfe\x4c\x10\x34\x8c\x46\x3c\x50\xd3\x3b\xe1\x13\x98\x88\xb1\x1c\x1e\x31\xfd\x24\x14\x6c\xba\xce\xfe\x39\x79\xab\x7e\x9c\x5c\xf4\x05\x9d\x1a\x4b\xd1\x1d\xde\x4b\xfd\xda\xf7\xca\x4e\xad\x00\x51\x8a\x04\xa4\x94\x45\xd2\x10\xd2\xa4\x2a\x79\x0b\xe5\x79\x1c\x72\x86\x5d\x39\xf3\x0d\x79\x31\x09\x54\x13\x04\xa2\x55\x8d\x11\x37\x63\xe6\xb7\xca\xd9\x84\x92\xc0\x58\x44\xac\xdf\xf5\xf0\xd4\x55\x59\x7f\x30\xba\x2f\x51\xfc\x22\xb0\x7c\x17\x0e\x79\x4b\xfd\xa2\xef\x44\xa9\x9a\xda\x3d\x10\x0e\xdc\x4d\x89\xe2\xb9\x2e\x85\x5d\xa8\x0a\xd1\x7f\xfb\xe6\xf2\x2a\x1b\xa7\x92\x4e\xe3\x07\xbf\x3a\x55\xd5\xe6\x94\x7d\x7f\xf9\xe6\x35\x06\x1d\xca\x55\x39\x8b\x6f\xdb\xac\x7b\x9e\x73\xd7\xc4\xa0\x96\x8d\xc3\xc6\x9d\x34\x72\x2a\x31\xf6\xe1\xec\x56\xf7\xed\xb7\xba\xd6\x1b\x0e\x59\x3e\x37\x3b\xe4\xb4\xb8\x4d\xe8\x6b\xc5\x69\x8c\xdd\xb0\x72\x84\xee\x7a\x10\x5b\x2c\xf9\x22\xd4\xaa\xe8\x0e\xb8\x26\x49\x25\x57\x65\x68\x49\xdd\x46\xa7\xd3\x63\xf3\xda\xd5\x8d\xe7\x34\x3b\x84\x44\x2d\x96\xb8\x54\x7c\x2e\xba\x6c\x80\x43\xc1\x93\xfe\x3e\xa4\x87\x23\xfc\x1d\x08\x65\x85\x52\x1e\x90\x0e\x9a\xce\xca\xc7\xc2\xe1\xf7\xf5\x02\x4a\x42\x60\xa0\xf4\x35\x78\xd5\xfd\x22\x10\xef\x6e\x14\x19\xda\x52\x2c\xb7\xe9\xeb\x2e\xd4\xd4\x9b\x04\x7f\x3d\x12\xee\x61\xc8\xcf\xaf\x2e\xbe\xcb\xc6\xdd\x46\x17\xf7\x5f\xb2\x1f\x9c\x90\xc9\x1b\x27\x14\xc5\x21\x54\xa7\x81\x2e\xa3\xfb\xfe\xa8\x4f\x0e\x1f\x7d\x57\xbc\x06\x94\x63\x36\x34\xe2\xb0\xef\x79\xa3\xf8\x4b\x46\xf7\x7c\x40\x51\xa0\x20\xf8\x51\x95\x30\xf4\xe7\xc7\xc7\x54\xee\x93\x45\x9a\x60\x3e\x3b\xfe\x6b\xfa\x03\xc9\x76\xa4\x7a\xea\xab\x38\x89\xa6\xbf\x98\xb8\xb7\x73\x28\xfd\x40\x2a\x74\xaf\x3b\xc3\x74\xd8\x27\x88\xb8\x0c\x12\x22\x15\xd3\xe5\x1b\x51\x75\xb0\x70\x2b\x13\x40\xce\xdc\x88\xcc\x72\x4c\xa8\xc3\x9f\x84\x0e\x0f\x3b\xea\xaf\xd9\xe7\xbb\x7a\xe0\xc0\xb6\xcc\x70\x19\x1c\x6c\x38\x39\x86\xef\xfe\xdc\x49\x4c\x7e\x00\xda\x93\x24\x3b\x21\x18\x1a\xfe\xe8\xdd\xe9\x15\xb8\x0f\x77\xa2\x4b\xe0\x93\xb4\x51\x7a\x26\xdd\xe3\x62\x52\xf8\x1f\x03\x9f\x9e\x2c\xc2\xcf\xe4\x90\xd7\x2f\x6d\x1f\x7b\x50\xe7\x82\x1e\x10\xf6\xc1\x4e\x77\x2d\xe9\x16\x9e\x0d\x58\x38\x24\x79\x7d\x7c\xd3\x0f\x22\x53\xae\x7f\x5b\xbd\x49\xb4\x38\x4b\xe6\x1b\xf3\x9b\x4f\x33\xe1\x70\xfa\x32\x13\x72\xaf\xc7\x3f\xfb\xe7\xaa\x54\xe7\x4a\x1a\x7a\xcf\x1a\x3e\xd9\x41\xed\x62\x21\xab\x4a\x34\xe9\xf3\x15\x0b\x4a\x6d\xbd\x9e\x64\xe1\x85\x25\x05\x14\x8f\xb7\x65\xd7\x8c\x23\xe2\x18\x38\xd0\xa1\x8f\xce\xdd\x95\x6a\x12\x3b\x48\xa9\x38\x2d\xd1\x4e\x9c\x82\xb6\x46\x2d\xcf\xaf\x3f\x46\xce\xac\x25\x10\x7c\xe2\xca\x52\x0b\x6e\x45\xfc\x7d\x36\xe3\x59\x97\x8a\x44\x59\x2c\xb4\x98\x75\xa1\x2d\xa0\xed\x60\x77\x68\xfd\x2e\x51\x70\x12\xfd\x04\xdc\x54\x17\x28\xd6\x55\x4e\xe7\x06\x97\x24\x9c\xaa\xd0\xa5\xac\x78\xef\x7e\xe2\x4d\x6f\xcc\x1e\x16\x5b\xba\x53\x6d\xa2\xb7\xc3\x3c\x63\xb9\xe7\xe2\x7f\x28\x66\x49\x0f\x49\xb9\x53\x7f\x19\x65\xa3\xed\x2b\xb7\xf3\xc4\x36\xa8\x23\x7b\xc3\x0d\x8a\x58\xb5\xba\x08\xcc\x64\x03\xd4\xb3\xd9\x7d\xa9\x7b\x30\xd7\x92\xa4\x4a\xe7\xd8\xff\x02\x86\x19\x4c\xc4\x35\x21\x00\x00")
 
 func staticCustomJsBytes() ([]byte, error) {
 	return bindataRead(
@@ -12034,12 +12046,132 @@ func staticCustomJs() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "static/custom.js", size: 175, mode: os.FileMode(420), modTime: time.Unix(1488487128, 0)}
+	info := bindataFileInfo{name: "static/custom.js", size: 8501, mode: os.FileMode(436), modTime: time.Unix(1792159919, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
 
-var _templatesPastebinHtml = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\xac\x94\x41\x6f\xac\x38\x0c\xc7\xcf\x33\x9f\xc2\xeb\xf3\x03\x5e\xf7\xb4\x07\xc2\xa5\xbb\xc7\x55\x2b\x4d\x2f\x7b\x34\x89\x19\xb2\x0d\x09\x4d\x0c\xd3\x11\xe2\xbb\xaf\x80\xe9\xb4\x95\x2a\xb5\xfb\xd4\x0b\xc1\xc4\xfe\xff\x1c\x3b\x66\x9a\x0c\x37\xd6\x33\x60\x4f\x49\x18\xe7\x79\x5f\xfe\xf6\xe7\xdd\xed\xc3\x3f\xf7\x7f\x41\x2b\x9d\xab\xf6\xe5\xb2\x80\x23\x7f\x54\xc8\x1e\xab\xfd\xae\x6c\x99\x4c\xb5\xdf\xed\xca\x8e\x85\x40\xb7\x14\x13\x8b\xc2\x41\x9a\xec\x0f\x7c\xdd\xf0\xd4\xb1\xc2\xd1\xf2\xa9\x0f\x51\x10\x74\xf0\xc2\x5e\x14\x9e\xac\x91\x56\x19\x1e\xad\xe6\x6c\x35\x7e\x80\xf5\x56\x2c\xb9\x2c\x69\x72\xac\x6e\x7e\x40\x6a\xa3\xf5\x8f\x99\x84\xac\xb1\xa2\x7c\x78\x23\xdc\x8a\xf4\x19\x3f\x0d\x76\x54\xf8\x9c\x0d\x94\xe9\xd0\xf5\x24\xb6\x76\xfc\x86\x62\x59\xb1\x39\xf2\x16\xe7\xac\x7f\x84\xc8\x4e\x61\x92\xb3\xe3\xd4\x32\x0b\x42\x1b\xb9\x51\x58\x24\x21\xb1\xba\xa8\x43\x90\x24\x91\xfa\x42\xa7\xf4\x6a\xe5\x9d\xf5\xb9\x4e\xe9\xeb\x42\x3a\x18\xee\x6c\x8c\x21\x16\xce\xd6\x6f\xcc\xff\x29\x33\x24\x09\xdd\x6b\x4c\xd2\xd1\xf6\x02\x29\xea\xaf\xa0\xfe\x4d\x58\x95\xc5\x16\xb3\x34\xad\xb8\x74\xad\xac\x83\x39\xaf\x7a\x9e\x46\xd0\x8e\x52\x52\xe8\x69\xac\x29\xc2\xb6\x64\xce\x1e\x5b\x81\xfa\x98\x35\x64\xd8\xac\xf0\x5d\xd9\xde\xbc\x77\xce\xea\x48\xde\x40\x57\x67\x3f\xb1\xba\x5f\x6e\x4f\x6d\x7d\x59\xb4\x37\xab\x76\xe1\x69\xac\xf6\xcb\x5b\x13\x62\x07\xa4\xc5\x06\xaf\xb0\x98\x26\xc8\x6f\x5b\xd6\x8f\x69\xe8\x60\x9e\x11\x3a\x96\x36\x18\x85\xf7\x77\x87\x87\xed\x98\xc2\xcf\x42\x91\x09\x62\x38\x25\x85\xbf\xff\x44\xb0\x46\xe1\xa5\xaf\x78\xb9\x56\x57\xb3\x77\xa4\xb9\x0d\xce\x70\x54\x78\x08\x1d\xc3\x22\x00\x2d\x47\xce\xf3\x1c\xab\x69\x02\xdb\x80\x67\xc8\x6f\xb7\x10\x40\x84\x79\x5e\x13\xb9\x7c\x58\x2d\xf6\x06\xe6\xb9\x2c\x5e\xf0\x6b\x2e\x75\x2c\xde\xad\xd6\xf7\x83\xbc\xd4\xa1\x16\x0f\xb5\xf8\xac\x8f\xb6\xa3\x78\x46\x90\x73\xcf\x0a\xd3\x50\x77\xf6\x9a\x67\xa2\x91\x11\x46\x72\x03\x2b\x3c\x2c\xc6\x56\x9f\xa5\x2c\x4b\x81\xb6\xf4\xf8\x09\xf2\x83\x90\x0c\x09\xf0\x44\xd1\x5b\x7f\x5c\x92\x5c\x3c\x8d\xbd\x76\x89\x1c\x47\x81\xf5\x99\x5d\xbd\x62\x70\x7c\xd9\xda\x3a\xb5\x9c\xec\x6f\x4e\x89\x8e\x7c\x91\x28\x8c\x1d\xab\x15\xb5\x1d\xf2\x43\x2c\x2f\xb7\xe6\x13\xa8\x21\x7f\xe4\xf8\x7d\xcc\x34\x68\xcd\x29\x7d\x42\xbd\x7a\x7d\x17\xd6\xfa\x26\x7c\xc2\xdc\x5c\x7e\x0d\x58\x16\x97\x11\xfb\x78\x62\xb7\xa9\x7e\x3f\x9e\x65\xb1\xfd\x6c\xa7\x89\xbd\x99\xe7\xfd\x7f\x01\x00\x00\xff\xff\x2a\x0c\x33\x55\x98\x05\x00\x00")
+var _staticPrintCss = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x55\x8f\xb1\x6e\xc3\x30\x0c\x44\xe7\xe8\x2b\x58\x74\x0b\x24\xa7\x5d\xdd\xb1\x73\x80\xee\x45\x07\xc6\xa2\x61\x21\xb2\x28\x50\x74\xdd\xb4\xe8\xbf\x57\x72\xd2\x21\x8b\x40\x3c\x1e\x75\x77\x87\x3d\xbc\x49\x48\x0a\x9c\xe2\x05\x74\x22\xc8\x58\x94\x2c\x84\x04\xe3\x12\x23\x60\xf2\xb0\x06\x9d\x78\xd1\x6d\x3d\x70\x52\xe1\x58\x00\x85\x97\xba\x0b\xda\xc1\xfe\x60\x12\x7e\x5a\x33\xb2\xcc\xd0\xb5\xd7\xdd\x64\xff\xec\xa4\xe9\x36\x16\x8a\x34\xa8\x35\xdd\x2b\x7b\x3a\x06\x11\x16\x6b\x94\xbe\x14\x85\xb0\xe2\x22\xae\x45\xb1\xe6\x71\xe0\x79\xa6\xa4\x05\xda\x9d\x35\x9e\x14\x43\xf5\xfd\x31\x3b\x1f\x4a\x8e\x78\xe9\x21\x71\x22\x78\x08\x73\x66\x51\x4c\xfa\x62\x7e\x8d\xe9\x72\xeb\xb3\x7d\x72\xa7\x3d\x45\x1e\xce\x77\xe2\xdd\x3a\x05\x25\x57\x32\x0e\xd4\x43\x16\x72\xab\x60\x6e\x9c\xc5\x6f\x73\xbd\xaa\xa9\xce\xae\x81\xca\xc7\x5a\xca\x95\xf0\x5d\xd5\xcf\x4f\xf9\x6a\x87\xef\x93\xd0\xf8\xd1\xf7\x38\x2a\x49\x73\x6c\xd5\x6b\xee\x6b\xba\xa6\xf9\x03\x8c\x22\x75\x90\x64\x01\x00\x00")
+
+func staticPrintCssBytes() ([]byte, error) {
+	return bindataRead(
+		_staticPrintCss,
+		"static/print.css",
+	)
+}
+
+func staticPrintCss() (*asset, error) {
+	bytes, err := staticPrintCssBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "static/print.css", size: 356, mode: os.FileMode(420), modTime: time.Unix(1792155063, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _templatesAccessHtml = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x9d\x54\x3d\x6f\xdb\x30\x10\x9d\xe3\x5f\x71\x25\xd0\x2d\x94\x9a\x74\xe9\x20\x19\x08\xd2\x6c\x6d\x5a\xa0\xee\xd0\x91\x12\x4f\x12\x11\x89\x74\xc8\xb3\x9d\xc0\xf0\x7f\xef\x91\x92\x1d\x2b\x4d\x96\x2e\x22\x79\x77\x7c\xef\xdd\x07\xb5\xdf\x6b\x6c\x8c\x45\x10\xaa\xae\x31\x04\x71\x38\x2c\x8a\x0f\x5f\x7f\xdc\xae\xfe\xfc\xbc\x83\x8e\x86\x7e\xb9\x28\xe2\x02\xbd\xb2\x6d\x29\xf6\xfb\xb4\x81\xc3\x41\x80\x56\xa4\x24\x75\x38\x60\xb2\x07\x24\x32\xb6\x0d\xd9\x2a\x9a\x62\xc4\x72\x71\x51\x74\xa8\x34\xaf\x17\xc5\x80\xa4\xa0\xee\x94\xe7\xb8\x52\x6c\xa8\x91\x5f\xc4\x8b\xc3\xaa\x88\xb2\x35\xb8\x5b\x3b\x4f\x02\x6a\x67\x09\x2d\x07\xee\x8c\xa6\xae\xd4\xb8\x35\x35\xca\x74\xb8\x04\x63\x0d\x19\xd5\xcb\x50\xab\x1e\xcb\xab\x4b\x08\x9d\x37\xf6\x41\x92\x93\x8d\xa1\xd2\xba\x33\xe0\x8e\x68\x2d\xf1\x71\x63\xb6\xa5\x78\x92\x1b\x25\x6b\x37\xac\x15\x99\xaa\xc7\x33\x16\x83\x25\xea\x16\xff\x11\xe4\x5d\xe5\x28\x9c\x05\x5a\x67\xac\xc6\xa7\x31\xb0\x67\x52\xf0\xd8\x97\x22\xd0\x73\x8f\xa1\x43\x64\xe9\x9d\xc7\x26\x15\xa4\x52\x21\x96\x21\x0f\xc4\x7c\x75\x5e\x39\x86\x22\xaf\xd6\x79\x1d\xc2\xcb\x29\x1b\x8c\xcd\xd8\xf2\x3f\x90\xf5\x26\x90\x1b\x8e\xb7\x8b\x7c\x2a\x76\x51\x39\xfd\x9c\xe0\xac\xda\x42\xdd\xab\x10\x58\xb9\xda\x56\xca\xc3\xb8\xc8\xde\xb4\x1d\x41\xd5\xca\x46\x69\xd4\x89\x9b\x7b\x75\x35\x0f\x96\x95\x57\x56\xc3\x50\xc9\x4f\x62\xc9\xec\x3b\x43\x1d\x24\x5b\xf6\xcd\xb5\x8e\x85\x14\x66\x68\x21\xf8\x3a\x89\xcb\xd2\x54\xa8\x9e\xcb\xc4\x92\x31\x32\x94\xe2\x33\x5f\x05\x76\x22\x03\x1d\x0e\x31\x85\x74\xff\x5e\xa5\x11\x61\xc9\x57\x49\x68\xce\x8c\xcb\xc5\xe2\x82\x03\x4c\x03\xf8\x08\xd9\x2f\x4e\x71\x13\x40\xa0\xf7\xce\x0b\x8e\x8d\x61\xda\x9c\xf2\xe1\xd6\x7b\x82\xf4\x95\x9a\x47\x12\x39\xc8\x3b\x9e\x87\xd1\x33\xa6\x14\x55\x7d\xe7\xb1\x56\x2d\x4e\x08\x39\x43\x2c\x13\x0f\xf6\xe1\x68\xec\xae\x63\x7a\x2b\x10\x37\xe9\x11\x40\xef\x5a\x70\x0d\x7c\xe4\xce\x67\xb7\x1d\xd6\x0f\x61\x33\x8c\x6a\xaf\x23\xec\x28\x32\xbb\xdb\xf2\x48\x84\x09\x82\x14\x4f\xd4\x51\x5b\x3a\x4c\x45\xa5\xe3\x0b\x88\x7b\x3f\x6e\xa2\x75\x62\x5c\x99\x01\x45\xc2\x66\xd3\x6b\xe7\x3d\xd2\xce\xf9\x87\x77\xfd\xbf\x03\x7a\xe0\xdc\x2c\xcd\x43\x78\x37\x32\x45\xd3\x91\xbe\xa0\xe3\x58\xc4\x04\x7c\x2c\xd9\x3c\x87\xb9\x40\x1d\x39\xf8\x89\x73\x50\xd4\x38\xe2\xeb\xb9\x3b\x9b\x04\xbe\xed\x8c\xea\x6e\xa2\xb8\x99\xfb\xa4\xed\x34\x14\x93\xd0\xd3\xd0\xe6\xa9\x7c\x53\xa1\xcf\xba\xf4\x4e\xf3\x8d\x6d\xdc\xdb\xad\x8f\x15\x74\x30\xfe\xd8\x30\x80\xf2\x18\x3b\xdb\xa2\xce\xc4\x7c\x1a\xce\xb5\x9c\x6d\x8b\x7c\xd4\xc4\x7d\x4f\x3f\xc2\xfd\x9e\x3d\xec\xf8\x0b\x8b\xd2\x09\xda\x35\x05\x00\x00")
+
+func templatesAccessHtmlBytes() ([]byte, error) {
+	return bindataRead(
+		_templatesAccessHtml,
+		"templates/access.html",
+	)
+}
+
+func templatesAccessHtml() (*asset, error) {
+	bytes, err := templatesAccessHtmlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "templates/access.html", size: 1333, mode: os.FileMode(420), modTime: time.Unix(1792156637, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _templatesAdminHtml = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\xb5\x56\x5b\x6f\xdb\x36\x14\x7e\x4e\x7e\x05\xc7\xe7\x48\x72\x82\x75\x18\x0a\x49\xc0\x96\x6c\xc0\x2e\x5d\x83\x24\x1d\xb0\xbd\x51\xe2\xb1\xc5\x95\x22\x55\x92\x4a\xe2\x79\xf9\xef\x3b\xbc\x48\x8e\x1d\xa7\xd9\xba\xf5\xc1\xe6\xe1\xd1\xb9\x5f\x3e\x69\xb3\xe1\xb0\x14\x0a\x08\x65\xbc\x17\x8a\x3e\x3c\x1c\x97\x5f\x5c\xbc\x3d\xbf\xf9\xed\xf2\x3b\xd2\xb9\x5e\xd6\xc7\xa5\x3f\x88\x64\x6a\x55\xd1\xcd\x26\x10\xe4\xe1\x81\x12\xce\x1c\xcb\x5c\x07\x3d\x04\xbe\x05\xe7\x84\x5a\xd9\xfc\xc6\xb3\xbc\x44\x7d\x7c\x54\x76\xc0\x38\x9e\x47\x65\x0f\x8e\x91\xb6\x63\x06\xe5\x2a\x3a\xba\x65\xf6\x35\xdd\x3e\x50\xcc\x5b\xb9\x15\x70\x37\x68\xe3\x28\x69\xb5\x72\xa0\x50\xf0\x4e\x70\xd7\x55\x1c\x6e\x45\x0b\x59\xb8\x9c\x10\xa1\x84\x13\x4c\x66\xb6\x65\x12\xaa\xd3\x13\x62\x3b\x23\xd4\xfb\xcc\xe9\x6c\x29\x5c\xa5\xf4\x23\xc3\x9d\x73\x43\x06\x1f\x46\x71\x5b\xd1\xfb\x6c\x64\x59\xab\xfb\x81\x39\xd1\x48\x78\xe4\x45\x40\x05\x7c\x05\x51\x4f\xa2\x2d\x62\x40\x56\xd4\xba\xb5\x04\xdb\x01\x60\x44\x9d\x81\x65\xc8\xb3\x61\xd6\x67\x57\x58\x87\x66\xda\xa2\xd1\xda\x59\x67\xd8\x50\xb4\xd6\x6e\x6f\x39\x16\x33\x47\xce\xa7\x98\x6c\x47\xeb\x74\x3f\x69\x97\x45\xaa\x61\xd9\x68\xbe\x0e\xe6\x14\xbb\x25\xad\x64\xd6\x56\x14\xc9\x86\x19\x12\x8f\x4c\x8a\x55\xe7\x48\xb3\xca\x96\x8c\x03\x0f\xbe\xb1\x05\xa7\xbb\xc2\x59\x63\x98\xe2\xa4\x6f\xb2\x05\xad\xbd\x77\x7f\xcd\x7f\x61\xa1\x69\x24\x8c\x01\xfa\x3c\x0d\x9e\x0a\x54\xa9\x8f\x8f\x8f\x50\x4c\x2c\x09\x7c\x20\xf9\x35\xc6\x38\x5a\x42\xc1\x18\x6d\x28\x6a\x78\x31\x2e\xe6\x80\xb0\x25\xc6\x91\xf0\x9f\x71\x1c\x15\x40\x21\xa3\xb1\x4f\xf1\x49\x8c\x09\xcd\xe5\x6f\xc0\x5a\xb6\x82\x64\xa1\x40\x13\x75\xf0\x03\x18\x1b\xf2\x3c\x73\xa8\x4b\xf6\xb4\x4a\x21\xc2\xc2\x80\x9f\x14\xac\xd0\x55\x20\x80\x93\x81\x59\x07\xb6\x2c\x58\x1d\xa3\x6d\x99\x22\xd4\x97\xd4\xfa\x30\xc9\x5f\xe4\x79\x63\x51\xaa\xbe\x60\x42\xae\x49\xb8\x24\x33\x31\x98\x8f\xea\x62\xb7\x6d\x47\xeb\x1b\x7f\xec\x39\xff\x43\x37\x2f\xfb\x0e\x42\xf5\x8f\xf8\xbf\xe3\xb3\x2c\x86\x3a\x14\x81\xcb\xd8\x46\xee\xea\xcb\x94\x21\x92\x91\xc5\xbd\x7c\xfe\xce\xd7\x31\x8f\x0f\x83\x26\xe7\xb3\xca\xb5\xd3\xc6\x57\x79\xb4\x58\x21\x66\x31\x39\x6d\x80\x3f\x63\xe2\xdb\x75\xb4\x40\x1a\x4f\xc4\x44\xf2\x37\xec\x7e\xe6\xeb\x25\x09\xad\x7b\xc4\x0a\xa2\xd8\x6f\xa9\xef\x80\xcf\xd1\x47\x5d\xa5\xdd\x64\xfa\x5c\x8f\xb8\x6b\x3c\xff\xc1\xfe\x0e\x46\xa3\xc4\x09\x02\x89\x75\xb8\x82\x81\x4f\xb6\x41\x4c\x92\xdf\x6b\xd3\x33\x47\xe8\xd9\x62\xf1\x55\xb6\x38\xcd\x16\x67\xe4\xf4\xd5\xeb\xc5\x97\xaf\x17\xaf\x68\xf4\x30\x15\x2a\xa6\x8b\xa7\x8c\x15\xeb\xce\xea\x4b\xa1\x08\x8b\x13\x81\xb3\x7c\x56\xc7\x69\x42\xae\x9a\x07\x25\xb4\xc8\x47\xd8\x00\xe1\x20\xc1\x47\xa1\x0d\x19\x46\xb3\x42\x0a\x63\x10\x92\x20\xba\xad\x09\x33\x58\x3e\x35\x04\xdd\x3c\xb4\x05\x8d\x2d\x31\xba\x69\xe4\x3d\x9d\x09\x85\x4b\x8e\x98\xc2\x5a\x27\xb4\x3a\xd0\x67\x34\x40\x09\x62\x52\xa7\x79\x45\x2f\xdf\x5e\xdf\x44\x68\x10\x6a\x18\xdd\x8e\x25\x8f\x4a\xb8\x31\x94\xb8\xf5\x80\x6b\xe3\xe0\x1e\xb1\x22\x02\x64\xdb\x41\xfb\xde\x8e\x3d\x25\x83\x64\x2d\x74\x5a\x72\x30\x15\x3d\x9f\xd9\xcc\x08\x96\x49\xd6\x78\xa8\xd9\x72\x8d\x07\x40\x6c\x7b\x70\xd8\x8c\xce\x69\x35\x79\x6c\x9c\x22\xf8\xcb\x2c\xa0\x5b\xce\xcc\x7a\x72\x6b\xc7\xa6\x17\xb3\xe3\x98\x15\x25\xb7\x4c\x8e\x78\xf5\xb9\xf8\x6a\x96\x45\xb4\xf6\xbf\x19\x0e\x75\xa6\xf5\x3b\x7f\xec\x18\x2f\x7c\x69\xe6\xf6\x5e\x41\x8b\xb8\x3d\x6f\x7c\x6a\xb0\x63\x88\xea\x93\xff\x70\x49\x08\xe8\xa6\xb7\x90\xa7\x4d\x24\x3c\x37\x2e\x54\x59\x20\xb5\xe5\x5d\x8b\x3f\xf7\x59\xbf\xe2\x7b\xc9\xee\xf1\xce\x0d\x30\xe7\x37\x69\x87\xfb\x4f\xaf\x48\xc5\x38\x3c\x6b\x0a\xae\x74\x13\xc2\x7b\x1c\x34\x1e\x3c\x49\x9e\x52\x0d\x28\xb9\x1b\x3e\x4f\x50\x93\xff\x04\xeb\xab\xd4\xe1\xb8\x18\xf9\xd4\xfa\xb4\x27\x32\xcc\xe1\x01\x08\xf2\xb2\x3f\xeb\x96\xf9\x1e\x84\x37\xf6\x9e\xf2\x1e\x22\x39\xbe\xeb\x3c\xf7\xb5\x3a\xfc\x24\x94\xec\xf0\xa3\x54\xb9\x97\x16\xfc\xa9\xe6\x13\x4c\x7f\x1e\x54\x59\xdb\xe2\xeb\xa5\xd8\x4b\x87\xd6\xdf\x04\x3e\x91\x7a\xf5\xb1\xd4\x22\x95\x96\xfc\x5f\x2e\x74\x50\x8c\x4b\x1d\xe7\xbd\x13\x9c\x83\x7a\xba\xc1\x69\xe2\x9f\x84\x98\x6c\xa4\xde\x26\xc8\x4a\xed\x7f\x79\xcb\xe2\xad\xff\x4f\xcb\x96\xdc\xa7\xb1\xf9\x0c\x8e\x0f\xc1\xc7\xe4\x54\x3d\x4e\x36\x6d\x7d\xba\x7c\x42\x8f\x22\xb0\x7f\xc6\x36\x3d\x53\x96\xf8\xe5\x73\xb8\x26\xf5\x45\x08\x6a\x3f\xf9\x67\x92\x9d\x91\x62\xa7\x38\xc8\x9d\xbf\x06\x8b\x00\x75\xe1\x4b\x31\xf2\x10\x10\xc3\x87\xfb\x66\x83\x0a\x28\xff\x37\x3c\xba\xdb\xe7\xe4\x0b\x00\x00")
+
+func templatesAdminHtmlBytes() ([]byte, error) {
+	return bindataRead(
+		_templatesAdminHtml,
+		"templates/admin.html",
+	)
+}
+
+func templatesAdminHtml() (*asset, error) {
+	bytes, err := templatesAdminHtmlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "templates/admin.html", size: 3044, mode: os.FileMode(420), modTime: time.Unix(1792161571, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _templatesEmbedHtml = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x9d\x53\x4d\x6f\xdb\x30\x0c\x3d\x27\xbf\x42\xd3\xa9\x05\x62\x1b\xbb\x0d\x9d\x1d\x60\xcd\x7a\x28\xb0\xac\x03\x16\x0c\xd8\x69\x50\x24\x3a\xd6\x2a\x4b\x9e\x44\xb7\x35\x8c\xfc\xf7\x51\xb2\xd3\x04\xdb\x69\x3b\x89\x26\xf9\x1e\xbf\x9e\xc7\x51\x41\xad\x2d\x30\x0e\xed\x1e\x14\x3f\x1e\x97\xe5\x9b\x8f\x0f\x9b\xdd\xf7\x2f\x77\xac\xc1\xd6\xac\x97\x65\x7c\x98\x11\xf6\x50\xf1\x71\x4c\x06\x3b\x1e\x39\x53\x02\x45\x86\x0d\xb4\x90\xfc\x01\x10\xb5\x3d\x84\x7c\x17\x5d\x31\x63\xbd\x5c\x94\x0d\x08\x45\xef\xa2\x6c\x01\x05\x93\x8d\xf0\x94\x57\xf1\x1e\xeb\xec\x1d\x3f\x07\xac\x88\x2c\x4f\x1a\x9e\x3b\xe7\x91\x33\xe9\x2c\x82\xa5\xc4\x67\xad\xb0\xa9\x14\x3c\x69\x09\x59\xfa\x58\x31\x6d\x35\x6a\x61\xb2\x20\x85\x81\xea\xed\x8a\x85\xc6\x6b\xfb\x98\xa1\xcb\x6a\x8d\x95\x75\x13\xb1\x21\x1f\xf3\x60\x2a\x1e\x70\x30\x10\x1a\x00\x62\x6e\x3c\xd4\xa9\xdf\xbd\x08\xb1\xcb\x22\xa0\x40\x2d\x0b\xe9\x14\xb4\xda\x7b\xe7\x0b\xa3\xf7\x17\x9f\xb9\x0c\xe1\xbf\x08\xfb\x80\xae\x3d\xa3\x83\xf4\xba\x43\x16\xbc\xfc\xb7\xf2\x3f\x09\x5f\x16\x13\x3a\x6e\xb4\x98\x57\x5a\xee\x9d\x1a\xe8\x25\x2e\x5d\x33\xf8\xc5\xf2\xaf\x44\xd5\x07\xba\x64\xc4\x71\x22\x8f\x65\xbb\x35\x25\xe4\x5b\x08\x41\x1c\x62\xc1\xb2\xe8\x26\x10\x98\xd4\x00\xe5\x4c\x0c\xf9\xad\xb6\xc2\x0f\x27\x98\x07\x26\x8d\x08\xa1\xe2\x0d\xbc\xa8\xbe\xed\x78\x24\x9a\x6d\x96\x6f\xa6\x03\x4d\x84\x1e\xd6\x13\xcd\x99\xb3\x44\x78\x41\xe1\x41\x30\xad\xaa\x59\x5b\xa9\x93\x4b\xe0\x29\xe7\x84\xb6\xea\xb5\xe9\x52\xfc\xb5\xd8\x88\xfe\xe4\x24\xad\xcb\xd9\x24\x40\xc2\x1e\xa2\x98\x7e\xec\x49\x93\x8f\x7c\x3a\x8d\x75\xae\x03\x0b\x3e\x55\xdb\x31\xfe\x8d\x34\xc5\x3a\x11\x10\x78\xaa\x29\xd6\xd3\x06\xe6\xa9\xad\xc3\x3f\x26\x7f\xdd\xf4\x62\xb1\xa1\x2b\x6c\xa7\x2b\xd4\xde\xb5\x3b\x6a\xf7\x03\xb5\x7b\xa5\x9c\xec\x5b\x1a\x22\xa7\xf2\x77\x06\xa2\x79\x3b\xdc\xab\xab\x79\xce\xeb\x15\x1b\x23\x7c\x41\x82\x81\xcf\x3d\xf9\x7c\xb8\x61\xe8\x7b\x58\x25\x37\x51\xa8\x07\x6b\x86\x4b\xdf\x49\xfa\x5b\x9a\x49\xdb\x1b\x76\x6f\xeb\x28\xf3\x21\x06\x8f\xd7\xef\x63\x63\x67\x0d\x5c\x2e\xeb\xc2\x2c\x8b\x49\x12\x24\x91\xf4\xe3\x8e\x23\x45\x28\xf0\x1b\x5f\xdd\xfa\xdd\xe4\x03\x00\x00")
+
+func templatesEmbedHtmlBytes() ([]byte, error) {
+	return bindataRead(
+		_templatesEmbedHtml,
+		"templates/embed.html",
+	)
+}
+
+func templatesEmbedHtml() (*asset, error) {
+	bytes, err := templatesEmbedHtmlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "templates/embed.html", size: 996, mode: os.FileMode(420), modTime: time.Unix(1792155018, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _templatesErrorHtml = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x9d\x53\x3d\x8f\xdb\x30\x0c\x9d\x2f\xbf\x82\xd5\x7c\xb2\x1b\x74\x29\x0a\xdb\x4b\xda\x2d\xfd\x40\x91\x0e\x1d\x69\x9b\xb6\x85\xca\x96\x2b\x31\xc9\x1d\x82\xfc\xf7\x52\x72\x72\xc9\xe1\xb6\x2e\x16\xcd\x8f\xc7\x47\x3e\xe9\x74\x6a\xa9\x33\x13\x81\x22\xef\x9d\x57\xe7\xf3\xaa\x78\xf7\xf9\xfb\x66\xf7\xfb\xc7\x17\x18\x78\xb4\xd5\xaa\x88\x07\x58\x9c\xfa\x52\x9d\x4e\xc9\x80\xf3\x59\x41\x8b\x8c\x9a\x07\x1a\x29\xf9\x03\x31\x9b\xa9\x0f\xd9\x2e\xba\x62\x46\xb5\x7a\x28\x06\xc2\x56\xce\x87\x62\x24\x46\x68\x06\xf4\x92\x57\xaa\x3d\x77\xfa\xa3\xba\x05\x26\x8c\x28\x07\x43\xc7\xd9\x79\x56\xd0\xb8\x89\x69\x92\xc4\xa3\x69\x79\x28\x5b\x3a\x98\x86\x74\xfa\x79\x04\x33\x19\x36\x68\x75\x68\xd0\x52\xb9\x7e\x84\x30\x78\x33\xfd\xd1\xec\x74\x67\xb8\x9c\xdc\x1d\xf0\xc0\x3c\x6b\xfa\xbb\x37\x87\x52\x3d\xe9\x3d\xea\xc6\x8d\x33\xb2\xa9\x2d\xdd\x75\x31\x54\x52\xdb\xd3\x52\x67\x05\x0b\x3c\xd9\x52\x05\x7e\xb6\x14\x06\x22\x61\x34\x78\xea\xd2\x9c\x35\x86\x38\x5d\x1e\x58\x60\x9a\xbc\x76\x8e\x03\x7b\x9c\xf3\x26\x84\xdb\x5f\x36\x9a\x29\x13\xcf\xff\x40\x36\xfb\xc0\x6e\xbc\x56\x17\xf9\x65\x87\x45\xed\xda\xe7\x04\x37\xe1\x01\x1a\x8b\x21\x94\x4a\xcc\x1a\x3d\x2c\x87\xb6\xa6\x1f\x18\xea\x5e\x77\xd8\x52\x9b\x7a\x8b\x04\xeb\xd7\xc9\xba\xf6\x38\xb5\x30\xd6\xfa\xbd\xaa\xa4\xfb\xd1\xf0\x00\xc9\x97\x6d\x5d\xef\x84\x48\x61\xc6\x1e\x82\x6f\x12\xb9\x2c\x89\x8d\x56\xd6\x24\x94\x29\x76\x28\xd5\x07\x29\x05\x09\x92\x00\x9d\xcf\x71\x84\x54\xff\x0d\x93\xf2\x42\x79\x9d\x88\xe6\xd2\xb1\x5a\x45\xab\x35\x2f\x94\x45\x34\xcf\x90\xbe\xba\x95\xcb\x44\x5e\x81\x77\xa2\xe4\x12\x59\x58\x0b\xe4\x0e\xb2\xaf\x14\x02\xf6\x11\x32\xa1\x09\x48\x82\x9d\xab\x02\xdf\x6e\x2f\x0d\xb3\x03\xb5\xf1\x84\x4c\x20\x97\x8a\x8e\x30\x63\x60\x51\x3a\x72\xc2\xaa\xc8\xe7\xc4\xe6\xd5\xd0\x0b\x7f\xd3\x81\xf3\x90\x6d\x45\xa8\x00\xd9\x46\x2e\x06\x36\x7c\xe9\xdb\x89\xa8\xe4\xaf\xf4\x99\x9e\x58\x8f\x7b\xbe\xee\x57\x8a\x7d\x9c\xe2\x5a\x2c\xad\xee\xc8\x65\xbf\x7e\x6e\xd3\x5b\x88\xf6\xce\xb0\xa5\x0b\x97\xdb\xf6\x2e\x20\x89\xd1\x5d\xe7\x17\x94\x11\x8d\x65\xf7\xe9\x2a\x45\x75\x31\x22\xc8\x3d\x46\x91\x2f\x3c\xab\x65\xc0\x17\x65\xae\x09\x45\xbe\xdc\x1f\x11\x27\x3d\xeb\xd3\x49\x22\x12\xf8\x07\xdb\x8c\xe6\x2d\x02\x04\x00\x00")
+
+func templatesErrorHtmlBytes() ([]byte, error) {
+	return bindataRead(
+		_templatesErrorHtml,
+		"templates/error.html",
+	)
+}
+
+func templatesErrorHtml() (*asset, error) {
+	bytes, err := templatesErrorHtmlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "templates/error.html", size: 1026, mode: os.FileMode(420), modTime: time.Unix(1792156637, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _templatesJobsHtml = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x9d\x54\xdd\x6f\xd3\x30\x10\x7f\x5e\xff\x0a\x63\x89\xb7\x39\x49\x2b\x06\x68\x4a\x2a\xa1\x7d\x48\x80\x80\x49\xec\x85\x47\x27\xbe\x34\x86\xc4\xce\xec\x6b\xc7\x54\xf5\x7f\xe7\xec\x34\x6d\x57\x15\x4d\xda\x43\x6b\xdf\xcf\x77\xbf\xfb\xcc\xad\xd7\x0a\x6a\x6d\x80\xf1\xdf\xb6\xf4\x7c\xb3\x99\xe4\x6f\xae\x7f\x5c\xdd\xff\xba\xbb\x61\x0d\x76\xed\x7c\x92\x87\x83\xb5\xd2\x2c\x0a\xbe\x5e\xc7\x0b\xdb\x6c\x38\x53\x12\xa5\xc0\x06\x3a\x88\xb8\x07\x44\x6d\x16\x3e\xb9\x0f\x50\xd0\x98\x4f\xce\xf2\x06\xa4\xa2\xf3\x2c\xef\x00\x25\xab\x1a\xe9\x48\xaf\xe0\x4b\xac\xc5\x47\xbe\x7f\x30\x32\xb0\xac\x34\x3c\xf6\xd6\x21\x67\x95\x35\x08\x86\x14\x1f\xb5\xc2\xa6\x50\xb0\xd2\x15\x88\x28\x9c\x33\x6d\x34\x6a\xd9\x0a\x5f\xc9\x16\x8a\xe9\x39\xf3\x8d\xd3\xe6\x8f\x40\x2b\x6a\x8d\x85\xb1\x07\xc4\x0d\x62\x2f\xe0\x61\xa9\x57\x05\xff\x2b\x96\x52\x54\xb6\xeb\x25\xea\xb2\x85\x03\x2f\x1a\x0a\x50\x0b\x18\xec\x5a\xe2\x62\x0e\xda\x82\x7b\x7c\x6a\xc1\x37\x00\x14\x51\xe3\xa0\x8e\x79\x96\xd2\x87\xec\x52\x8f\x44\x53\xa5\xa5\xb5\xe8\xd1\xc9\x3e\xad\xbc\xdf\x4b\x49\xa7\x4d\x42\xc8\x6b\x28\xab\xa5\x47\xdb\x8d\xd6\x79\xba\xad\x61\x5e\x5a\xf5\x14\xe9\x8c\x5c\xb1\xaa\x95\xde\x17\x9c\xae\xa5\x74\x6c\x38\x44\xab\x17\x0d\xb2\x72\x21\x6a\xa9\x40\x45\xdf\xd4\x82\xe9\x73\x65\x51\x3a\x69\x14\xeb\x4a\x91\xf1\x79\xf0\x1e\xc4\xe4\xbb\x8c\x4d\x63\x52\x51\xe4\xe4\x73\x1a\x3d\xa5\x64\x32\x9f\x4c\xce\x48\x4d\xd7\x0c\x1e\x58\xf2\x93\x62\x5c\x7a\xc6\xc1\x39\xeb\x38\x59\x04\x35\xa5\x77\x01\x51\x4b\x1c\xb2\xf8\x2f\x14\x8d\x0a\x90\x92\xb3\xd4\xa7\xe1\x65\x88\x89\xe8\x92\x6f\xe0\xbd\x5c\xc0\x96\x21\x25\x8a\x79\xf4\x03\x14\x1b\x61\xa7\x7c\xfa\x65\x55\x91\xd5\x0b\x5e\x77\x5a\xaf\x73\x4b\x05\x9b\xcd\xbf\xd0\xb7\x40\x45\x98\xc5\x22\xa0\xa4\x69\x19\x3d\x45\x61\x5b\x59\x1c\xa7\x3b\xdc\xdd\x70\x09\x68\x30\xcf\x53\x3a\xf7\xc8\x57\x6d\xd4\x11\x34\x64\x75\x04\x7e\x42\x84\xae\xc7\x63\xf8\xca\x81\x44\x38\x66\xb8\xa5\x2f\x81\x66\xe9\x18\xbe\x73\x76\xe1\x28\xcd\x3d\x4c\xb7\x21\xbc\x00\x8d\x31\xe7\x38\x0e\x54\xc8\xdf\x85\x5e\xb1\x24\x24\x3e\xd4\xe6\x79\x4e\x2a\x4c\x4a\xf2\xf9\x9a\xde\x88\x43\x1d\xe1\x21\xbb\xd3\x2f\xdb\xd6\x6d\x36\x43\x37\x93\x9b\x30\x36\x24\x5e\xb2\xf0\x3a\x4a\xbb\xfa\x9f\x60\x18\x2b\x72\xfa\x75\x5b\x98\xe4\xd6\xba\x4e\x22\xe3\xb3\x2c\x7b\x2f\xb2\xa9\xc8\x66\x6c\x7a\x71\x99\xbd\xbb\xcc\x2e\xf8\x49\xcb\x10\xcc\x58\xbf\x21\x82\x9d\xf8\x12\xd9\xff\xa2\xcd\x2b\xab\x20\x70\xf7\xb4\x8e\xb0\x66\xfc\x2d\x0d\x61\x32\x76\x23\xea\x47\x8d\xbd\xd9\xae\x2f\x81\xb2\xf5\x70\x58\x79\x62\xa4\x05\xd5\xfa\x5e\x9a\x82\x7f\xe0\x73\x5a\xab\x0e\x98\xa4\x9f\xb1\x2c\xec\xea\x24\xf2\x3c\xa3\x18\x66\x78\x20\xde\x6d\x8b\x34\x8e\x6c\xdc\x24\x03\x46\x83\x1d\x17\xfb\x7a\x4d\x06\xa4\xff\x0f\x85\x5c\x41\x7a\x03\x06\x00\x00")
+
+func templatesJobsHtmlBytes() ([]byte, error) {
+	return bindataRead(
+		_templatesJobsHtml,
+		"templates/jobs.html",
+	)
+}
+
+func templatesJobsHtml() (*asset, error) {
+	bytes, err := templatesJobsHtmlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "templates/jobs.html", size: 1539, mode: os.FileMode(420), modTime: time.Unix(1792156637, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _templatesPastebinHtml = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\xd5\x5b\x6d\x73\xdb\x36\x12\xfe\xec\xfc\x0a\x1c\xcf\x99\x4b\xe6\x44\xc9\x76\x33\x99\x9e\x47\x72\x27\x75\xd2\x69\xe7\x9c\xb6\x13\xbb\xed\x74\xee\x3a\x19\x88\x84\x24\xc4\x24\xc1\x82\xa0\x65\xd5\xe3\xff\x7e\xbb\x78\x23\x28\x52\xb2\x64\xa7\x77\x73\x1f\x12\x91\x20\xb0\x58\x2c\xf6\xe5\xd9\x05\x7c\x77\x97\xb2\x19\x2f\x18\x89\x4a\x5a\x29\x16\xdd\xdf\x3f\x1b\xff\xe5\xed\x0f\xe7\x57\xbf\xfe\xf8\x8e\x2c\x54\x9e\x9d\x3d\x1b\xe3\x0f\xc9\x68\x31\x9f\x44\x77\x77\xfa\x81\xdc\xdf\x47\x24\xa5\x8a\xc6\x6a\xc1\x72\xa6\xdb\x2b\xa6\x14\x2f\xe6\xd5\xf0\x0a\x9b\xb0\xc7\xd9\xb3\x83\xf1\x82\xd1\x14\x7e\x0f\xc6\x39\x53\x94\x24\x0b\x2a\xa1\xdf\x24\xaa\xd5\x2c\xfe\x32\x6a\x3e\x14\x14\xa9\xdc\x70\xb6\x2c\x85\x54\x11\x49\x44\xa1\x58\x01\x1d\x97\x3c\x55\x8b\x49\xca\x6e\x78\xc2\x62\xfd\x32\x20\xbc\xe0\x8a\xd3\x2c\xae\x12\x9a\xb1\xc9\xf1\x80\x54\x0b\xc9\x8b\xeb\x58\x89\x78\xc6\xd5\xa4\x10\x01\xe1\x85\x52\x65\xcc\x7e\xaf\xf9\xcd\x24\xba\x8d\x6b\x1a\x27\x22\x2f\xa9\xe2\xd3\x8c\x05\xb3\x70\x36\x61\xe9\x9c\x99\x71\x19\xd0\x22\x92\x65\x93\xa8\x52\xab\x8c\x55\x0b\xc6\x80\xa3\x85\x64\x33\xbd\xce\x29\xad\x70\x75\xa3\x4a\x01\x99\x64\x34\x15\x42\x55\x4a\xd2\x72\x94\x54\x55\xf3\x36\xcc\x79\x31\x84\x96\xc7\x90\x4c\x44\xca\x72\x2e\xa5\x90\xa3\x8c\x4f\x83\x57\x4f\x10\xc6\x2c\xb9\x5a\x78\x99\xc3\x60\x68\xe2\x33\x02\x1b\x39\xfc\x96\xcf\x17\x19\xfc\x53\x24\x82\xbd\xa5\x75\x06\x53\xc1\xb6\x3e\x85\x0d\xbd\xcb\x23\xe8\x12\x10\xbf\xbf\x0f\xd9\x61\x45\x6a\x98\x30\x0f\x8f\x98\xad\xae\x94\xc8\x1f\x2b\xb2\x12\x14\x40\xe9\xc1\x24\x67\x29\xa7\x93\x48\xb7\x18\x52\x55\x22\x79\xa9\x48\x25\x93\xfd\xa4\xfd\x09\x58\x19\x8f\xcc\x68\xbb\x4c\x2b\xe2\xf3\x05\x4b\xae\xab\x3a\x27\x51\x57\xb4\x34\x53\x4c\x16\x14\x6c\x89\xa8\x55\x09\x5a\x4d\xcb\x32\xe3\x09\xcc\x25\x8a\xd1\xa7\x4a\x14\x7f\x17\x2c\x9f\xb2\xb4\x67\x31\xe6\xc3\x57\xb5\xcc\x26\xd0\x0a\x3f\x64\x78\x21\xcc\x48\x6b\x4f\x56\xab\x4b\x29\x4a\x26\xd5\x6a\x12\x89\xf9\x29\xce\x12\xe8\x32\x95\xb0\xaa\x8c\x6d\xea\x5d\x71\xc5\x3e\xa2\xb9\x05\x43\x7e\x44\xd3\x9f\xf2\x62\xe3\x0c\x5c\x65\x9d\xfe\x04\xd5\xc1\x0b\x62\x33\x7b\x29\x33\x12\x84\x45\x04\x24\x8c\x2c\x87\x5f\xf3\x82\xca\x15\x8c\xb6\x0f\x33\x9e\xb1\x81\xa1\x6c\x7a\x5e\xc1\xe2\xac\x62\x65\x95\x7d\x2a\x25\x43\x4f\xe1\xfb\x84\x8a\xb7\x89\x0b\x10\x66\x7b\xf6\x5d\xa5\xcb\x73\x3a\x67\xdd\xa1\xd1\x28\xa1\x32\x8d\xcc\xd4\x3b\xd3\x39\xd5\x1e\x2c\xa0\x76\x7c\x72\x74\xb4\x7d\xc4\x82\xa1\xb5\x05\x43\x5e\x7f\x71\xd4\x71\x9c\x0a\xbc\x01\x68\xdd\xa9\xe1\xc9\x77\x85\x8d\xc9\x41\xaa\x1f\x33\x2a\xe7\xec\xa3\x59\x49\xcb\x5e\x03\x57\x92\xd0\x52\x81\x7b\xf6\x9e\x64\x78\x69\xac\xc6\xa8\xf7\x9a\x09\x35\x1f\x23\x42\xab\x55\x91\x10\xf0\x34\x4c\xae\x5b\x4b\xc7\x29\x8c\x47\x36\x1e\x8c\xa7\x22\x5d\xe9\x55\x40\x50\xc8\x68\x55\x01\xb3\x32\x16\x45\xb6\x22\xf6\x37\x9e\x09\x70\x09\x54\xfb\x69\x63\x28\x7f\xb5\xeb\x8a\xce\x80\xe2\x15\x89\x2e\xaf\x79\x49\x94\x70\xcb\xc5\xbd\x18\x8f\xa8\x26\x5a\xd0\x1b\x47\x16\x1e\xa7\x54\x12\xf3\x13\x1b\xd7\x35\x9d\xc7\x33\x9a\x82\x09\x62\x67\x88\x51\xc7\xed\xce\xf1\x54\x52\x60\x38\x9f\xc6\x47\x7a\x2e\x2d\x1f\xdd\x06\x1b\x3d\x17\x38\x0f\xcf\xe7\x8d\x30\x8c\x18\x32\x10\x38\xb0\xaa\xb7\x6b\x12\xe1\x26\x91\x50\x04\x66\xfc\xf7\x54\x87\x46\x90\xc3\xb1\x9e\xbb\xc7\x91\x6b\x96\x66\x42\xe6\x8e\x29\x7c\x8e\x79\x91\x61\x94\x9e\x65\x82\xaa\x58\x1a\x95\xa0\x09\xea\x5c\xdb\xa7\x59\x3a\xe8\x06\xd5\x42\xa4\x60\xab\x3f\x5c\x5e\x99\x75\x1e\x8c\x79\x51\xd6\xca\x7a\xa5\x05\x4f\x53\x06\x16\x69\x14\x48\x32\x55\x4b\x78\xbb\xa1\x59\x6d\xc2\xf9\xf0\x47\x0a\x7c\x59\x7d\x46\x0d\x60\x19\x4b\x54\x8b\x27\x14\xbc\x14\x19\x09\x5f\xe2\x2a\x77\x34\x75\xd0\x00\x2e\x25\xa7\x71\x46\xa7\xe8\x1b\xcd\xc6\x9d\x8b\x4c\x48\x62\x3f\xfb\x19\x0e\xc6\x42\x3b\x09\xc7\x03\xad\x95\xb0\x6e\x82\xfd\x4e\x2c\xaa\x30\xad\x30\x88\x18\x7e\x58\xea\x65\x6c\xb5\xe2\x8d\xed\x30\x1e\x19\x72\xfd\xc4\xb5\x1e\x74\xa9\x9b\xe6\x6d\xe4\x2f\x5c\x8f\xed\xf4\x53\x2a\xaf\xbb\xe4\x75\xeb\x36\xea\x6f\x6d\x87\x36\x71\xb0\x2a\xdd\xdf\xbc\x41\xcf\xc3\x85\x8f\xc2\xa7\x93\x76\x4c\x7e\xec\x66\x79\x8a\x7d\x1b\xd6\x4c\xd0\xd9\x34\xe8\x00\x8a\x3d\x67\xc4\x13\xd0\x6b\x75\x8a\xdc\x11\x8c\xb3\x97\x46\x36\xe1\x6a\x36\x89\x66\xd8\x15\x78\xe8\xc3\xfa\x44\xa4\x61\x2a\x4a\xe7\xc2\xe0\xd5\xc7\x0a\x06\xe9\xf4\xc9\x04\xc9\xd6\x3a\x34\xf4\x08\xe3\x10\x51\xc4\x80\x1c\x22\x09\x64\x22\x13\x08\x55\xb7\x09\x45\x8f\x68\x09\xc6\xb4\x1c\x5a\xb8\xdd\x2f\x17\x33\xc1\x5e\xb2\xb1\x4e\xc0\x4a\x60\xaa\x0a\x02\xff\x60\xbd\xfa\x47\xd4\x0a\xdd\x4c\x5c\x31\x10\x04\x68\xeb\xca\x61\x98\xaa\x9e\xe6\x5c\x85\xfe\x01\x4d\x0d\x80\xcd\xaa\x59\xff\x78\x84\x22\x74\x6e\xad\xc1\x81\x23\xf0\xaa\x67\xcf\x9e\x39\xaf\xd6\xe3\xb5\xd6\x91\x44\xdb\x79\x01\xa9\xc4\x70\x91\x03\x96\xe5\x25\x00\x1c\x3d\x53\x8c\x09\x88\x09\x85\x9b\x1d\x9b\xaa\x42\xa6\x0b\xb1\x74\xec\x3a\xcf\x5b\xea\xa6\xed\x44\xa0\xcf\x47\x88\x8d\x59\xc6\x0a\xdc\xf0\x40\x95\xcf\x5d\xab\x8f\xfc\xdb\xa9\x14\xa2\x48\x1a\x0a\x36\x87\x4a\xf9\x6c\xc6\x13\x58\xdb\xca\x10\x7d\xeb\xdf\x03\x66\x1b\x79\xa6\xdc\x07\xb7\x34\x06\x82\xce\xc7\x9a\xd9\x60\xc9\xb2\x76\x99\x0c\x2a\xac\x73\x5c\x8c\xde\x30\xb0\x5f\x5e\x01\xc8\x62\x59\x4a\x58\x5e\x2a\xbd\x7b\xa4\xc5\xb3\x62\xb7\xca\x71\xbc\x64\x53\x84\x8b\xa0\x04\x14\xd0\x61\xca\x6e\x27\x51\x7c\x0c\xb3\x81\x8b\xc5\x34\x2a\x63\x0a\x3a\x89\xd9\x0c\x61\xb2\x99\x4b\xef\x37\x30\xd8\xc0\xe5\x06\xe2\xad\xf1\x0e\x06\x21\x15\xd1\xff\x43\x74\x9b\x81\xd3\x06\xc3\x63\xb6\x3d\x72\x6a\x04\x9c\x07\xd0\x90\xbc\x78\x5e\x0d\xc8\xf3\x94\x4c\x57\x8a\x55\x2f\x87\x51\x1b\x27\xbe\x80\xad\xf0\x2d\x2f\xf5\xd2\x68\x17\x65\x4b\xba\xec\x60\x36\xeb\x7e\xc5\xb2\x80\x00\x9b\x86\x50\xa2\xbd\x1a\x5e\x7d\x87\x28\x6a\x1d\x9e\xea\x8d\x07\x48\x60\x97\x06\x8f\xf1\x2c\xab\x39\x10\x5a\x4f\x3a\x7a\x26\x37\xd0\xc1\x70\x60\xa8\x3f\xaf\xa2\x2e\xb4\x6e\x20\x30\xce\x06\x18\xd8\xcd\xb6\x60\xb7\x69\x9d\x97\x7a\x11\xf6\x39\x84\xc6\x63\x48\x8e\x58\x47\x89\xf4\x76\xad\x41\xaf\x08\x7d\xe0\x24\x5a\x83\x59\x06\xec\xb7\x40\x96\xdf\xeb\x1e\xf8\x32\x46\x05\xa2\x92\xd1\xc6\xc9\x16\x2a\xc6\x35\x7f\x03\x0f\x97\xfc\x0f\x6d\x2a\xb0\xd7\x4b\xf8\x76\x72\x14\x11\x9e\x36\x53\x5a\xbd\xf3\xaf\xda\x3c\x7c\x78\x30\xd6\x11\x46\x3b\xdb\x63\x09\x09\xb7\xf9\xf8\x0b\x3c\x35\x5e\xb4\x10\xca\x37\x11\xd3\x09\xb5\xd5\x0b\x82\x94\x19\x4d\xd8\x42\x64\x29\x93\x6e\x07\x2e\x05\x78\x54\x5c\x03\xc8\x52\xb2\xe1\x70\x68\x1c\xdc\x78\xe4\xd6\x75\xb6\x86\x9e\x9b\x4d\x71\xd2\x90\xf0\x19\xc6\xa6\x3a\x6c\x69\xad\x07\xa4\xce\xb3\xca\x49\xc4\x7d\x8f\x08\xa0\x7d\xe3\xb3\xc7\x16\xaa\x5b\x99\x7f\x03\xee\x8d\x02\xa2\x4f\x89\x4e\x74\x40\xeb\x5f\x46\x28\x40\x6c\xd5\x3b\xe0\xba\x5b\x2b\x19\x7e\x7b\xf5\xfe\xc2\xb9\x5b\x3b\x5b\x0f\xd0\x47\x73\x7c\x57\x24\x72\x55\x22\xe9\x1d\x2c\x12\xf7\x26\x65\x7a\x40\x8c\xe9\x72\x5d\x39\x2b\x75\x6f\x5a\xfe\x0c\xd3\x65\x27\xc0\x5f\xa4\x80\x88\x05\x00\x2f\xe7\x55\x05\x4a\x41\xae\xd9\x8a\xf0\x02\xa1\x03\xc1\x2c\x79\xd8\x44\x0c\x33\xc0\x73\x34\x20\x76\x2e\x86\x06\x4e\x56\xa2\x96\x00\x9b\x41\x51\x98\x1c\x46\x7e\x75\xde\x1c\xff\x04\x65\x3e\xf4\x8a\x0c\x41\xbb\x8d\xc9\x37\xea\xb5\x1f\xf3\x99\x14\xbc\xa1\xb7\x4d\xd3\x9b\x5e\x4e\xe5\x6d\x87\x05\x38\xec\xaa\x04\xb5\x5e\xef\xe6\x3f\x34\xf6\xd1\x52\x06\xbb\x95\xae\xc5\xc6\x91\x30\x85\xb1\x16\xd5\x99\xfa\x89\xa6\x15\x94\x55\xac\xd3\x8a\x5c\x5e\xdd\x93\xe0\x07\x86\xe8\x95\x1a\xb3\xb5\x17\x6d\x02\x2f\xa1\x01\xad\xdf\xaf\xf0\x65\x68\x05\x1b\xc4\x32\x2e\x84\xcd\x5f\x43\xff\x5a\x2d\xc4\x32\x90\x2b\xd9\xba\xf1\x1d\xfa\x81\x88\xca\x00\xbd\x99\xc4\xb2\x21\xda\x71\xd8\x00\x9b\x1c\x33\x2d\x5d\x0f\x18\xd3\x25\x2e\xa3\xec\x67\x6d\x69\xf5\xfa\xfc\x16\x99\xa9\x1c\x9d\x85\xbf\x9b\x81\x71\xe4\x8b\xb3\x15\x9f\xf2\x8c\x23\x68\xe8\xc2\xe1\x9f\x83\xaf\x1e\x10\xb6\x51\xae\x8f\xb2\xa6\x28\x49\x6e\x5a\x43\x5a\x10\x76\x6d\x64\x59\x4f\x33\x9e\x78\x1b\xd6\x6f\x58\xf5\x85\x70\x8d\x9e\x25\xe3\x95\x4d\x7a\xb7\x93\xa9\x0b\xec\x89\x69\xbf\x21\xf4\x93\x7d\x1f\x80\x02\xad\x00\x47\x19\xef\xed\xfc\xd4\x83\x4c\x49\x7e\x83\x55\x3e\xc7\x95\x79\x1d\x10\x5d\xc4\xf0\x94\x00\x49\x43\x6e\xdd\x4b\x30\xc4\xe5\x6d\x17\xf6\xa9\xb2\xbb\xda\x42\x66\x09\x02\x82\xa9\xb8\x75\x1b\xe2\x4d\xd5\xe3\xc9\x63\xe3\x6c\xec\x87\x5e\x23\xd7\x44\xc2\x2c\x82\xb4\x9c\xb0\x73\xd4\xd6\xed\xae\x79\xc8\x76\xee\xd0\x56\x12\xc3\x23\x82\x34\xc7\x9f\xad\x9c\xd1\x24\x61\xa5\xb2\xaf\xa3\xb2\x98\x0f\xcc\xd3\xa7\x92\xb9\x47\xc0\x99\x65\x9f\x52\x19\x34\xf4\xc2\x88\x8c\x66\x03\x88\xac\xda\xad\x54\x9a\x47\xf4\x03\x2f\xbd\xb6\x8d\xc1\x8e\x8a\x8e\x00\xf7\x60\x1a\x25\x57\x97\x06\xfe\x69\x57\x88\x65\xd8\x10\xba\xd1\x92\x8f\x6e\x8e\x47\xa6\x8f\x0b\x7d\x3e\x2d\xd8\xd4\x3d\xc8\x1b\xfa\x92\x48\x09\x0b\x34\xc8\x56\x32\x08\xea\x58\xef\x22\x66\x86\x60\x69\xa5\x14\x73\xf8\x5c\x05\x3c\xc6\xae\x0d\x92\x25\x7a\xab\xb7\xde\x2a\x01\x44\x1c\x93\x09\x9c\xa1\x17\x30\x9d\x1a\x01\x05\x14\x7a\x03\x3a\x96\xf0\xa0\x9f\xd5\x4f\xf7\xf4\xa0\x08\xc3\xac\x41\x51\x34\xc5\x1e\xff\x7f\x05\x1f\x06\x10\x7c\x01\xba\x80\x5d\x40\x1e\x47\x51\x29\xfd\xf6\x9a\x05\xf7\x48\x69\xa7\x71\xfb\xf3\x59\x65\x35\x64\xf6\x25\xe2\x2c\x09\x69\xd3\xbf\x68\xfc\xc7\x51\xfc\x8f\xdf\xec\xef\xbf\xe3\xdf\xee\x4e\x06\xaf\x4f\xee\x7b\x97\x72\xae\x4f\x2b\xc8\x4f\x1f\x2e\x76\xe0\x7f\x73\xe7\xdd\x98\x2e\xe1\xcb\x52\x60\x65\xd7\x30\x9e\x32\xcc\xbc\x3e\x62\x73\xb9\x90\xa0\x6e\xa0\x03\xbc\x40\x25\x53\x8b\x49\xf4\xe5\x7a\x82\x56\xb0\x65\xdc\x90\xe8\x59\xcc\x5b\x4d\x8f\x34\xf4\x76\x58\xd3\x83\x63\xc2\xe8\xb2\xb1\xc6\x7c\x8e\x8b\xee\x02\x51\x1d\xcc\xec\x27\x6b\x67\x98\x87\x02\x90\xd4\xdf\x2c\x99\x4b\x68\xfa\x27\x5b\x59\x78\xbe\x8e\x0b\x5b\xd1\xae\xb7\xe6\x01\x0e\x3c\xef\x29\x71\x58\xe5\x80\x6c\x79\xad\xdc\x71\xa9\x9b\x9a\x6c\x6c\xe3\x59\x10\xed\x54\x57\x9a\x72\xca\x1e\xd9\xe8\x07\xba\x0c\x13\xd1\xbd\xa8\xa6\x36\x93\xed\xe4\x9a\xee\xc3\x96\x8c\x77\x5a\x2b\x25\x8a\x8d\xb3\x11\xe7\x5c\xad\xdc\x4c\x77\x07\x73\xcb\x95\x63\xff\x1c\x9f\x35\x59\xd3\x63\x43\xbe\xb8\xef\xd2\x82\xd2\xf4\x57\x1a\x77\x5a\x55\xb2\x40\xeb\xa8\xc9\xc8\x8e\xdb\x60\x2b\xe8\xe4\xd6\x5e\xfc\x4d\x69\x60\x86\xe1\x99\x55\xd1\xfa\x39\x93\x4e\x66\xd6\xbf\x5a\x10\xba\xff\x9e\xb4\x18\x6f\x50\xba\xe3\x2c\xc4\xa1\x0f\x2c\x22\xec\xea\x0a\xb4\x29\x0b\xd0\x64\xdf\x4a\x2e\x01\x2d\xf5\x74\x09\x97\xa3\x31\x2d\xc2\x58\x2c\x9b\x06\x98\x76\x3d\xba\xe2\x42\xe7\x52\xd4\xa5\x8b\x1d\xf6\xa5\xeb\x25\x90\x08\x41\x92\x01\x28\xdc\x2c\xb3\xa6\xf4\xa9\xb9\x88\xaa\x1c\x42\xa7\xae\x50\x61\xcd\xf0\x86\x35\xa2\xd8\x2a\x5c\x8d\xcf\x91\xc2\xc4\x10\x70\xc7\x45\x8e\x9a\x5d\xed\x3e\x9c\x14\x98\x79\x3f\x89\x15\x4b\xc1\xf2\xf2\xbd\xa7\xf7\x08\x66\xf4\x69\xde\x53\x78\x31\x04\xce\x02\x08\xd2\x2a\x78\xb9\xa8\xdf\xf2\xa0\xe1\xfe\x63\x10\x8d\xf3\xda\x21\x69\x9b\x16\xd2\x42\x14\xab\x5c\xd4\x95\x57\xb9\xe7\xa6\x7c\x81\xc5\xac\x9f\xf1\x17\x3e\x0c\x5a\x67\x62\x57\xf8\xc1\x25\x01\xee\xab\x19\xa8\x0b\x7c\x51\xb7\xa2\x67\x6d\xe0\x02\x4d\xb2\x3d\xc2\x5a\xa9\xff\x14\x4e\xa4\x9d\xce\xd0\x95\xec\xdd\xb8\x61\x6f\xaf\x73\x73\x47\xa5\xb7\x53\x4b\x38\xa6\xd4\x3f\x44\x74\xd2\xf1\x63\x34\x85\x4f\xfa\xff\xad\x6e\x1a\xa1\xd2\xc8\x1d\x89\x34\x07\x1d\xb4\x23\xfe\xbd\x9c\xff\x5a\x39\x7d\x94\x64\x90\xdc\x34\x01\x0b\x63\xa6\x8e\x55\x5f\x61\x40\xc5\xce\x26\x8a\xb6\x3d\x0d\x7a\xf0\x4c\x17\x97\xfb\x39\xb2\xc5\xfe\x67\x5b\x23\xe1\x86\x63\xcc\x68\x87\x43\x80\x51\x05\xfb\xc0\x7a\xce\x31\xb7\x54\xd8\x61\x3d\xad\xea\xbc\x43\x07\xfb\x43\x58\x95\xf5\x22\xa5\x77\xb7\x25\x07\x3c\x4d\xe8\x0c\x30\xe3\x80\xb0\xe1\x7c\x48\x4e\x5e\x2d\xf6\xc0\x72\x45\x9d\x4f\x31\xaf\x6a\x2e\x3c\x55\x1a\xbd\x69\xdc\xde\x33\xe3\x7b\x7a\xeb\x8c\xa8\x77\x8e\x1e\x7d\xd8\x72\x62\x73\x2e\x19\x60\x67\xa2\x45\xeb\x73\x52\x43\xd5\xef\xe7\xd3\x76\xcd\x60\xd3\xcd\xdb\xb6\xeb\x06\x88\x6b\xd6\x3e\x8e\x36\x80\xf3\x0a\xdb\xb5\xab\xdb\x8c\x63\xf5\x58\x2c\x40\x86\x00\x59\x97\xe4\xf0\x1e\x98\x64\xe9\x66\x29\xba\xf3\xaf\x14\x4d\x5b\x6e\x15\xe5\x5b\xbb\xd0\xae\xfc\x6c\x4d\x0a\xd2\xde\xaa\xba\x10\xf3\xcf\x60\x0a\x86\xd6\xff\xbb\x50\x77\x3c\x54\xd4\x6b\x25\x99\x98\x77\x64\x1b\x7a\xa0\xa7\x8a\x54\x32\x73\xd1\xf0\x7f\xe2\x5e\xc0\x0a\x2b\x84\xcc\x3d\xe2\xfe\x60\x3f\x6d\x17\x6d\xdf\x04\x2c\xa7\x3c\x0b\x0b\xcc\x20\x85\xde\x29\x7e\xc5\xba\xba\xee\xfd\x70\x3a\xfa\x08\xc3\xf8\x60\x25\xbb\xc9\x30\x30\x8f\x87\x60\x1e\xa6\x7d\x26\x75\x30\xcd\xee\x4e\xce\x89\x4f\x23\x6c\xbb\xb9\x36\x73\xe2\x0e\x0e\x6c\xf4\x3d\x6f\x51\x6b\x65\x91\xe6\x32\x54\x40\x5b\x9f\x42\x7d\xf7\x56\xcb\x56\x5f\x2a\x9c\x44\x90\x00\xce\x79\x11\x67\x6c\xa6\x4e\x89\xb1\x88\x52\xc3\x25\x96\xbb\x8b\x2f\x6b\x14\xe3\x69\x26\x92\x6b\x7f\x67\x65\xf1\xba\xf5\x11\x24\xa2\x2f\xcb\x91\x1e\x8c\x04\xdc\xbe\xa9\x41\xdb\x64\x53\xdf\xb6\xef\x5d\xb4\xfe\xc6\x81\xa9\x16\x4e\xd7\x80\x24\x45\x0f\x3e\x34\x9e\xdc\x60\x92\xc5\x6b\xc7\x4d\xd9\x62\xc6\xa8\x9c\x5d\xa9\x86\xfe\xb1\xc6\xfe\xa7\x78\x83\x4e\x1f\x25\x18\xcc\xf1\xb5\x48\x57\xa6\x68\xec\xe8\x04\x47\x48\x07\xdd\xd3\x29\xd8\xe1\xcc\xa6\x76\xe1\x39\xd4\xc1\xf6\x13\xfe\xc3\x36\x26\xf1\xdb\xda\x73\x51\x69\x1f\x2b\x3c\x0c\xcd\xf0\x81\xa1\x25\x84\xbd\xa2\xa5\xaf\x56\x1d\xd6\xc6\xee\x68\xc6\x54\xef\x9e\x2e\xc0\xb9\xe2\xcb\xeb\x57\xbd\x26\xa7\xaf\x7d\xf5\x18\x9b\x9e\xb3\x7b\xb0\xd4\x2d\xbe\x5b\x71\xb9\x63\xa5\x2f\x5a\x93\xbe\x3a\x3a\x3a\x0a\xbc\x45\xfb\x84\xe4\xc1\x3b\x1f\xbb\xb9\x65\xbf\xe7\x0d\xdf\xcd\x75\x8f\xb5\x53\xc7\x87\x35\x08\xf3\xd5\x2d\x0a\xb4\xbb\x4f\xef\x57\xaa\x91\xdf\xd9\xd1\x42\xcf\xf4\x27\xe9\xd8\x63\xe3\xee\xe1\xfe\x81\x77\xa6\x4b\xde\xe6\x8e\x7e\x27\x38\xec\x7c\xaf\x67\x07\xf7\xed\xb6\x66\xa7\x7d\xf6\x65\xb7\xe0\xa9\x75\xf9\x68\xd7\x2b\x3f\x5b\xfd\xc1\xe3\x42\xf2\x7f\xdf\x98\x3f\x97\x25\xf7\xd5\x9b\xdd\xa8\xd6\xd6\xaf\x59\xfa\x53\x32\x83\x37\x69\x4a\x92\x60\x92\xb5\x1b\x5d\x7d\xe7\xee\x21\x18\x6b\xae\xf3\x5d\xea\x73\x04\x12\x2d\xa9\x2c\x78\x31\x8f\xb6\x5f\x33\xf0\xbd\xfa\xee\xfe\x0c\xdf\x03\x12\x34\xf9\x7a\xc0\xc2\xf6\x69\xf5\x55\x84\x07\x26\x75\x56\xf0\xb9\xe6\xac\x6a\x8b\xcf\xb7\xce\xea\x7b\x7d\xae\x69\xcd\x15\x8d\x47\xde\xab\x7a\x78\xc2\x83\xd6\xcd\x67\x5f\x7a\x01\xb8\x82\x25\x96\xeb\xca\x94\x65\xc0\xb4\x7d\x82\x23\x20\x29\xee\xab\x11\xb5\x11\x9b\x19\x0c\xbe\x3f\xb8\x9a\x35\xc4\xb3\x11\x57\x02\xb9\xd2\x00\xca\x54\x1d\x48\xcb\x9b\x34\x35\x1a\x3f\xb3\xa7\x82\x78\x56\x89\xd3\xbe\x62\xca\x7a\xdd\x02\xf9\xdc\x7c\x43\xdd\xde\x4c\x7f\xe8\xaf\x46\xcc\x9f\xab\xb4\xff\x44\x04\xa0\x98\xfe\x8b\xa9\xbb\x3b\x20\x07\xd4\xfe\x03\xe1\xbb\x4b\xb4\x5d\x35\x00\x00")
 
 func templatesPastebinHtmlBytes() ([]byte, error) {
 	return bindataRead(
@@ -12054,7 +12186,127 @@ func templatesPastebinHtml() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "templates/pastebin.html", size: 1432, mode: os.FileMode(420), modTime: time.Unix(1488487083, 0)}
+	info := bindataFileInfo{name: "templates/pastebin.html", size: 13661, mode: os.FileMode(420), modTime: time.Unix(1792157661, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _templatesReportsHtml = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\xad\x55\xc1\x6e\xdb\x30\x0c\x3d\x27\x5f\xa1\xe9\x5c\xd9\x49\xb0\x0e\x43\x61\xfb\xb0\x76\xbb\x6d\x2d\xda\x5e\x76\x94\x2d\x26\x16\x6a\xcb\xa9\x44\xa7\x0b\x82\xfe\xfb\x28\xc9\x4e\x9a\x66\xc3\xb6\x62\x87\x44\x14\x45\xbe\x47\x8a\x14\xbd\xdb\x29\x58\x6a\x03\x8c\x5b\x58\x77\x16\x1d\x7f\x7e\x9e\x66\xef\xae\xae\x2f\xef\xbf\xdf\x7c\x66\x35\xb6\x4d\x31\xcd\xfc\xc2\x1a\x69\x56\x39\xdf\xed\x82\xc0\x9e\x9f\x39\x53\x12\xa5\xc0\x1a\x5a\x08\x7a\x07\x88\xda\xac\x5c\x72\xef\x55\xde\xa2\x98\x4e\xb2\x1a\xa4\xa2\x75\x92\xb5\x80\x92\x55\xb5\xb4\x64\x97\xf3\x1e\x97\xe2\x23\x3f\x1c\x18\xe9\x51\x36\x1a\x9e\x7c\x18\x9c\x55\x9d\x41\x30\x64\xf8\xa4\x15\xd6\xb9\x82\x8d\xae\x40\x84\xcd\x19\xd3\x46\xa3\x96\x8d\x70\x95\x6c\x20\x9f\x9f\x31\x57\x5b\x6d\x1e\x04\x76\x62\xa9\x31\x37\xdd\x0b\xe0\x1a\x71\x2d\xe0\xb1\xd7\x9b\x9c\xff\x10\xbd\x14\x55\xd7\xae\x25\xea\xb2\x81\x17\x2c\x1a\x72\x50\x2b\x88\x7e\x0d\x61\x31\x0b\x4d\xce\x1d\x6e\x1b\x70\x35\x00\x45\x54\x5b\x58\x86\x3c\x4b\xe9\x7c\x76\xa9\x43\x82\xa9\xd2\xb2\xeb\xd0\xa1\x95\xeb\xb4\x72\xee\xb0\x4b\x5a\x6d\x12\xd2\xbc\x05\xb2\xea\x1d\x76\xed\xe8\x9d\xa5\xc3\x1d\x66\x65\xa7\xb6\x01\xce\xc8\x0d\xab\x1a\xe9\x5c\xce\x49\x2c\xa5\x65\x71\x11\x8d\x5e\xd5\xc8\xca\x95\x58\x4a\x05\x2a\x70\x53\x09\xe6\xc7\xc6\xa2\xb4\xd2\x28\xd6\x96\x62\xc6\x0b\xcf\xee\xb7\xc9\x37\x19\x8a\xc6\xa4\xa2\xc8\x89\x73\x1e\x98\x52\x72\x29\xa6\xd3\x09\x99\xe9\x25\x83\x47\x96\xdc\x51\x8c\xbd\x63\x1c\xac\xed\x2c\x27\x0f\x6f\xa6\xf4\x3e\x20\x2a\x89\x45\x16\xfe\x85\xa2\x56\x01\x32\xb2\x1d\xd5\x29\x9e\xc4\x98\x08\x2e\xf9\x0a\xce\xc9\x15\x0c\x08\x29\x41\x14\x81\x07\x28\x36\xd2\xfd\x8a\xd3\xf5\x55\x45\x5e\x7f\x60\xdd\x5b\xbd\x8d\x96\x2e\x6c\x51\xdc\x86\xe7\x00\x8a\xad\xa5\x43\x70\x74\x1f\x8b\x70\x1f\x28\xa9\x71\x46\xd2\xb0\x19\x2e\x19\xc7\x46\xf7\xb2\x8d\x82\xd7\x16\x37\x1e\x20\x4b\x49\x3a\xe8\x6e\x41\xba\xce\xbc\x52\x5e\x52\x33\xca\x0a\x4f\x4c\x63\x20\xaf\xd4\x87\x2d\x49\x91\xce\xab\xc6\x18\x32\x1c\x7b\xc5\xa7\x66\x7d\x19\x58\x12\xa1\x5c\xcc\xfc\x38\x4c\x55\x64\xf2\xb4\x1d\xad\x7c\x4a\xfd\x95\x5d\xd6\x50\x3d\xb8\xbe\x0d\x6f\xfa\x95\x22\x4b\xa5\x0f\x46\xbd\x80\xf2\x16\x31\xc3\x70\x7e\x72\x36\x24\xfa\x9b\x43\x0b\x92\xd2\x4d\xbe\x74\xb6\x95\xc8\xf8\x62\x36\xfb\x20\x66\x73\x31\x5b\xb0\xf9\xf9\xc5\xec\xfd\xc5\xec\x9c\x9f\x7a\x46\x69\x92\x2d\xc9\x8b\x11\xb6\xee\xcc\x51\x26\xa1\xa9\xd3\x71\xc6\x31\x9a\x0c\x75\xa7\x72\x7e\x73\x7d\x77\xcf\xf7\xce\xda\xac\x7b\x64\xb8\x5d\x53\xd3\xd4\x5a\x29\x30\x7c\x98\x4b\xd5\x90\x2f\x67\x1b\xd9\xf4\x71\xdc\x1d\xdf\xca\x88\x51\xf6\x88\x94\xf7\xd0\x20\x25\x1a\x46\xbf\xe1\x1d\x04\xd1\x11\x48\xa4\x70\x7d\xd9\x6a\x1c\x29\x62\xd0\x7b\x82\xb2\xe9\xaa\x07\x5e\x7c\xf2\x4b\x96\x46\xd4\xff\x4e\xa2\xa0\x01\xa4\x06\xbe\x0a\xeb\x5f\xd2\x38\xa0\xa9\xa9\xa4\xdd\xfe\x13\x93\x76\xad\xf6\xe3\xec\x2a\x0a\x27\x5c\xa9\xaf\xdc\x58\xd0\x7d\x6d\xf7\xbd\x7d\x78\x9f\x51\xbb\x9f\x84\x69\x78\x83\x61\x4a\x46\x1d\xbd\xd4\xf0\xd1\xda\xed\xc8\x81\xec\x7f\x02\x29\xb3\x62\x43\xe2\x06\x00\x00")
+
+func templatesReportsHtmlBytes() ([]byte, error) {
+	return bindataRead(
+		_templatesReportsHtml,
+		"templates/reports.html",
+	)
+}
+
+func templatesReportsHtml() (*asset, error) {
+	bytes, err := templatesReportsHtmlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "templates/reports.html", size: 1762, mode: os.FileMode(420), modTime: time.Unix(1792160681, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _templatesSetHtml = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x9d\x55\x51\x8f\xdc\x34\x10\x7e\xbe\xfd\x15\xc6\xe2\x01\xa4\x26\xe1\xc4\x0b\xaa\x92\xad\xc4\x1d\x48\x48\x07\x54\xe5\xa8\xd4\xc7\x49\x32\x49\x2c\x9c\x38\xb5\x27\xbb\x77\x5d\xf2\xdf\x19\xdb\xc9\x6e\x16\xf5\x40\xea\xcb\xc6\x9e\xf1\x7c\xf3\xcd\x7c\x1e\xef\xe9\x54\x63\xa3\x06\x14\xd2\x21\xc9\x79\xde\xe5\x5f\xdd\xff\x7e\xf7\xf8\xe1\xed\x4f\xa2\xa3\x5e\xef\x77\xb9\xff\x08\x0d\x43\x5b\xc8\xd3\x29\x2c\xc4\x3c\x4b\x51\x03\x41\x42\x1d\xf6\x18\xec\x1c\x4d\x6a\x68\x5d\xfa\xe8\x4d\xfe\xc4\x7e\x77\x93\x77\x08\x35\x7f\x6f\xf2\x1e\x09\x44\xd5\x81\xe5\x73\x85\x9c\xa8\x49\x7e\x90\x17\xc7\x00\x1e\xe5\xa0\xf0\x38\x1a\x4b\x52\x54\x66\x20\x1c\xf8\xe0\x51\xd5\xd4\x15\x35\x1e\x54\x85\x49\xd8\xbc\x12\x6a\x50\xa4\x40\x27\xae\x02\x8d\xc5\xed\x2b\xe1\x3a\xab\x86\xbf\x12\x32\x49\xa3\xa8\x18\xcc\x06\xb8\x23\x1a\x13\xfc\x38\xa9\x43\x21\x9f\x92\x09\x92\xca\xf4\x23\x90\x2a\x35\x6e\xb2\x28\x2c\xb0\x6e\x31\xc6\x69\xc6\x12\x16\x75\x21\x1d\x3d\x6b\x74\x1d\x72\x5f\x44\x67\xb1\x09\x75\x96\xe0\x7c\x75\x99\x23\x86\xa9\xb2\xd2\x18\x72\x64\x61\xcc\x2a\xe7\x2e\xbb\xb4\x57\x43\xca\x96\x2f\x81\xac\x26\x47\xa6\x5f\xa3\xf3\x6c\xe9\x61\x5e\x9a\xfa\x39\xc0\x0d\x70\x10\x95\x06\xe7\x0a\xc9\xcb\x12\xac\x88\x9f\x44\xab\xb6\x23\x51\xb6\x49\x03\x35\xd6\x21\x37\x4b\x70\x7b\x7d\x38\x29\x2d\x0c\xb5\xe8\xcb\xe4\x3b\xb9\xe7\xec\x47\x45\x9d\x08\xb6\xf4\xc1\xb4\x86\x89\xe4\xaa\x6f\x85\xb3\x55\x20\x97\x06\xb1\x41\x73\x9b\x98\x32\xfa\x0c\x85\xfc\x9e\x43\x05\x3b\x91\x81\xe6\xd9\x97\x10\xe2\x7f\x83\xa0\x3c\x53\xbe\x0d\x44\x33\xce\xb8\xdf\xed\x6e\xf8\x80\x6a\x44\xfa\xb3\xe2\xda\xd9\xef\x5d\x93\xde\xb0\xf2\xfc\x13\x82\xd2\x49\x61\x0d\x6b\x2a\x79\xad\x95\xa3\x50\x01\x07\x7f\x5d\x4d\xd6\xb2\x52\xe2\x75\x21\xd2\xbb\x65\x1d\x70\xbc\x53\xd5\xc1\xfe\xcb\xfd\xd9\xc4\x64\x5a\xbc\xca\xe7\x35\xd8\x24\x4c\x14\x61\x1f\xfb\x73\x93\xc3\xd6\xe1\xa5\x8a\x74\xf1\xa3\x88\x05\x9d\xb3\xcf\xb3\x80\x8a\xd4\x01\xcf\x95\x7f\x4e\x44\xa4\x6c\x61\x35\xcf\x6f\x1a\xa6\x50\xf8\x2e\x2e\xad\x91\xff\x85\x6d\x15\xdf\xcf\xb8\x2f\xe4\x08\x7c\x21\xcf\x89\xf6\x1b\x8c\x3c\x83\xa8\x6c\xa6\xd5\xd2\xa0\x78\x28\xb4\x7c\xe2\x91\xdd\x98\xd6\xe6\xfb\x8c\x7f\xf0\xfd\x9a\x9c\x90\x68\xad\xb1\x72\x09\xa8\xd5\xf9\x32\xf1\x38\x59\x12\xe1\x37\xa9\x7d\x0b\xed\xaa\x47\xb0\xc5\x7e\x79\x22\xbf\xa2\x73\x4c\x6f\x4d\xc9\x10\x4b\x4e\xed\x56\xe3\x18\x29\xc2\x8b\x0d\x8a\x7a\x65\x16\x8e\x61\x77\x51\x35\x5c\xca\x47\x21\xdf\xc1\x51\x2e\xd5\x8a\xbf\x5f\x00\xf3\x91\x6f\xc1\x11\xf2\xd5\xad\x78\x78\xcc\xb0\x01\x78\xcf\xcf\x89\x18\xbd\xf7\x7f\x71\xfe\x45\x0a\x6c\xd5\xb1\xcc\x2b\xd0\xbd\x39\x0e\xda\x40\xcd\xad\xd1\xe2\x9b\x4f\x6a\xfc\xf6\x0b\x01\xdf\x34\xc6\xf6\x40\x05\x81\x4d\xdb\x4f\x9f\x87\x8f\xbe\x73\x86\xd0\xe0\x71\x91\xd9\x0f\x51\x2c\xf7\x47\x35\x80\x7d\x5e\x7b\x6d\x71\x95\xb0\xc3\xa7\x7a\xea\xc7\x00\xbd\xac\xd7\x90\xbb\xf8\xda\x05\x5c\x8e\x58\x6f\xce\x46\x32\x36\x5e\x1a\xfa\xc2\xf1\xe5\xa2\x6d\xef\xdc\xd5\x1b\x12\x45\x61\xa2\xc6\x8a\xf4\x81\x87\xc9\x89\x00\xc5\x83\xb3\xa4\x69\xf8\x8d\x44\xbb\x12\x26\x7c\xa2\xa4\x9f\x68\x7d\xae\x2e\xd3\x1b\x83\x39\xfd\xa6\xbb\xe9\x9f\xef\x1e\x56\x85\xd3\x47\x45\x7a\x9d\x07\xb1\x25\x74\x66\xb4\xc9\x7c\x46\xe9\x41\x69\x32\xaf\xd7\x97\x6d\xbf\x2c\x3c\xc8\xf5\x20\x45\x9e\x57\x75\x6f\x0e\xe4\x59\x7c\x8e\xf9\xad\x0b\xff\x92\xa7\x13\x7b\xd8\xf1\x0f\x33\x16\x24\x4c\x4f\x07\x00\x00")
+
+func templatesSetHtmlBytes() ([]byte, error) {
+	return bindataRead(
+		_templatesSetHtml,
+		"templates/set.html",
+	)
+}
+
+func templatesSetHtml() (*asset, error) {
+	bytes, err := templatesSetHtmlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "templates/set.html", size: 1871, mode: os.FileMode(420), modTime: time.Unix(1792156637, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _templatesStatsHtml = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x9d\x54\xb1\x72\xdb\x30\x0c\x9d\xe3\xaf\x60\x35\x87\x52\x93\xa9\x83\xa4\x21\x71\xd7\x34\x77\xcd\xf5\xae\x23\x24\x42\x12\x2f\x12\xa9\x92\xb0\x53\xd5\xe7\x7f\x2f\x48\x59\x76\xe2\x24\x8b\x17\x11\x7c\x20\x80\x87\x07\x91\xbb\x9d\xc2\x46\x1b\x14\x89\x27\x20\x9f\xec\xf7\xab\xfc\xcb\xfa\xc7\xfd\xd3\xef\xc7\xef\xa2\xa3\xa1\x2f\x57\x79\x58\x44\x0f\xa6\x2d\x92\xdd\x2e\x1a\x62\xbf\x4f\x84\x02\x02\x49\x1d\x0e\x18\x71\x8f\x44\xda\xb4\x3e\x7d\x0a\x50\x38\x51\xae\xae\xf2\x0e\x41\xf1\x7a\x95\x0f\x48\x20\xea\x0e\x1c\x9f\x2b\x92\x0d\x35\xf2\x5b\x72\x72\x18\x08\x59\xb6\x1a\x5f\x46\xeb\x28\x11\xb5\x35\x84\x86\x0f\xbe\x68\x45\x5d\xa1\x70\xab\x6b\x94\x71\x73\x2d\xb4\xd1\xa4\xa1\x97\xbe\x86\x1e\x8b\x9b\x6b\xe1\x3b\xa7\xcd\xb3\x24\x2b\x1b\x4d\x85\xb1\xaf\x12\x77\x44\xa3\xc4\x3f\x1b\xbd\x2d\x92\xbf\x72\x03\xb2\xb6\xc3\x08\xa4\xab\x1e\x5f\x55\xd1\x58\xa0\x6a\x71\x8e\xeb\x39\x97\x70\xd8\x17\x2c\xc9\xd4\xa3\xef\x10\x99\x51\xe7\xb0\x89\x7d\x56\xe0\x43\x77\x59\xd0\x4b\xd7\x59\x65\x2d\x79\x72\x30\x66\xb5\xf7\xa7\x5d\x3a\x68\x93\x32\x72\x49\xca\x7a\xe3\xc9\x0e\x4b\x74\x9e\x1d\x34\xcc\x2b\xab\xa6\x98\xce\xc0\x56\xd4\x3d\x78\x5f\x24\x6c\x56\xe0\xc4\xbc\xc8\x5e\xb7\x1d\x89\xaa\x95\x0d\x28\x54\xb1\x36\x8f\xe0\xe6\xed\x61\x59\x39\x30\x4a\x0c\x95\xfc\x9a\x94\xa1\x7a\xd8\xa6\x0f\x10\x87\x26\x40\x31\x73\xae\x79\x13\x2b\x65\x1c\x52\xae\x82\x35\x96\x39\xbc\x67\x1c\x4f\x27\xe5\x1d\xd4\xcf\x82\xac\xe0\xbf\x61\x4e\x20\x46\x68\x31\xcf\xa0\xcc\xb3\x71\x8e\xef\x6e\xcb\x47\xf0\x84\xc2\xeb\x7f\xe8\xb9\xc0\x6d\x2c\x40\xc0\x93\x58\xf8\xc5\xcd\x81\x35\x2d\x7f\x4e\xb0\xdd\x6c\x04\xb4\xfc\xc9\xf1\x79\xc6\xc6\x09\x8a\x89\xfd\x09\x64\x6b\x8e\x08\xd0\x92\x26\xa7\x45\xbf\x2b\x6e\x80\x7b\x6e\x51\xa4\x21\x99\xe7\x46\xde\xd5\x51\x41\x99\x45\x14\x4e\xa3\xce\x3c\xf7\x76\x63\xe8\x8d\xeb\x58\x94\xdd\xc8\xfa\xce\x49\x19\x3d\x8e\x2d\x8b\xfd\x1d\xe5\x58\x83\xee\x27\x01\x35\xe9\xad\xa6\x69\x51\x84\xa3\x75\x23\xd2\x35\x4c\x07\x5e\x17\x48\xb4\x06\x3a\x97\xe8\xde\x21\x83\xea\x0c\xfd\xc5\x17\xce\x9f\x61\x77\xd3\x85\x5a\x9e\x28\x7f\x24\x65\xe0\xf4\x89\x94\x91\x9a\xff\xd8\x19\x19\x7e\xec\x8a\x44\x2f\x1c\xc1\x7c\xa4\x8f\x3f\x71\xc0\x95\x3e\xde\x28\x7e\x53\x1c\x89\xf8\x95\xda\x34\x36\x11\xce\xf2\x33\x33\xe3\xb3\xf2\x0f\xf6\x38\x36\xa1\x3d\x5f\xec\xda\x3a\xbe\x6f\x62\x42\x4a\x63\x15\x4e\xb7\xd4\x38\xd0\xc8\xb3\x99\x03\x8f\x39\x3e\xaa\xbb\x1d\x7b\xd8\xf1\x1f\xc1\xdb\xbd\x06\x80\x05\x00\x00")
+
+func templatesStatsHtmlBytes() ([]byte, error) {
+	return bindataRead(
+		_templatesStatsHtml,
+		"templates/stats.html",
+	)
+}
+
+func templatesStatsHtml() (*asset, error) {
+	bytes, err := templatesStatsHtmlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "templates/stats.html", size: 1408, mode: os.FileMode(420), modTime: time.Unix(1792156637, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _templatesStreamHtml = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x9d\x56\x4d\x8f\xdb\x36\x10\x3d\xdb\xbf\x62\x4a\xa0\x80\x8c\xac\xe4\x2c\x7a\x29\x52\xc9\x40\xeb\x2c\xd0\x14\x6e\x52\x74\x9d\x43\x8f\xb4\x38\xb6\x88\x50\xa4\x42\x52\x76\x16\x86\xff\x7b\x87\xd4\x87\xe5\x64\xd3\xb4\xbd\xac\x25\x72\x66\xde\xf0\xbd\x37\xd4\x9e\xcf\x02\xf7\x52\x23\x30\xe7\x2d\xf2\x9a\x5d\x2e\xf3\xfc\xbb\xd7\xef\xd6\xdb\xbf\xfe\x78\x80\xca\xd7\x6a\x35\xcf\xc3\x0f\x28\xae\x0f\x05\x3b\x9f\xe3\x03\x5c\x2e\x0c\x04\xf7\x3c\xf5\x15\xd6\x18\xd7\x1d\x7a\x2f\xf5\xc1\x65\xdb\xb0\x14\x22\x56\xf3\x59\x5e\x21\x17\xf4\x3b\xcb\x6b\xf4\x1c\xca\x8a\x5b\x8a\x2b\x58\xeb\xf7\xe9\x8f\xec\xba\xa1\x79\xa8\x72\x94\x78\x6a\x8c\xf5\x0c\x4a\xa3\x3d\x6a\x0a\x3c\x49\xe1\xab\x42\xe0\x51\x96\x98\xc6\x97\x3b\x90\x5a\x7a\xc9\x55\xea\x4a\xae\xb0\xb8\xbf\x03\x57\x59\xa9\x3f\xa4\xde\xa4\x7b\xe9\x0b\x6d\x26\x85\x2b\xef\x9b\x14\x3f\xb6\xf2\x58\xb0\x4f\x69\xcb\xd3\xd2\xd4\x0d\xf7\x72\xa7\x70\x82\x22\xb1\x40\x71\xc0\x2e\x4f\x51\x2d\xb0\xa8\x0a\xe2\xe4\x49\xa1\xab\x10\xa9\xa3\xca\xe2\x3e\x9e\x73\xc7\x5d\x38\xdd\xd2\x79\x2a\x53\x2e\x77\xc6\x78\xe2\x8e\x37\xcb\xd2\xb9\xeb\x5b\x56\x4b\x9d\xd1\xca\xff\x29\x59\xb6\xce\x9b\x7a\xcc\xa6\x00\xb9\x07\xae\x05\x24\x24\x54\xf6\xe6\x35\x30\xb6\xa0\x67\xe3\x21\x5b\x2b\xe3\x50\x2c\x28\x39\xc0\x68\xe3\x4a\x2b\x1b\xbf\xfa\xf2\xec\x04\x65\x09\x77\x72\xe6\xfb\x97\x6c\x95\x2f\xc7\x94\x0e\x08\x09\x25\xd4\xca\x97\xbd\x70\xf9\xce\x88\xa7\x78\x06\xcd\x8f\x50\x2a\xee\x5c\xc1\xe8\x71\xc7\x2d\x74\x3f\xa9\x92\x87\xca\xc3\xee\x90\xee\xb9\x40\x11\x5b\x26\xdd\xef\x6f\x83\xd3\x9d\x0d\x27\xa8\x77\x29\xc1\x12\xd0\x49\xfa\x0a\xe2\x5a\xb6\x31\x07\x43\xa0\xb9\xac\x0f\xe0\x6c\x19\x19\xc9\xa2\xc3\xb8\xa2\x3e\x89\x27\x0c\x08\x05\xfb\x81\x52\x61\x6c\x32\xf0\x16\xf3\xdf\xf2\x68\x37\x6a\xf9\x3e\x36\xba\x24\xc4\xd5\x7c\xde\xf3\x36\x52\xd6\x73\xd4\x0c\x7d\x79\xfc\xe4\xd3\xba\xf5\xd4\x32\x48\x51\xf4\x03\x90\x06\x0d\x5a\xd7\xbb\x9b\x90\x50\xc4\x86\xb6\xc0\xb6\x95\x74\xd0\x45\x41\xc5\x1d\xc4\xcd\xa8\x4b\x5c\x37\x36\xbc\x39\xd6\x3b\xbf\x87\xef\x15\xea\xc0\x67\xff\xb5\x10\xe4\xfc\x0b\x97\x04\x76\x36\xa6\x24\xa7\x18\x1d\xb1\xc2\xc2\xba\xc2\xf2\x83\x6b\xeb\xc8\x03\x5f\x65\xbd\x9a\x2a\x66\x5c\x91\x37\xf2\x88\x34\x2e\x9e\x5b\x3a\x36\x7c\xef\x32\x06\x09\x1d\x94\x28\x5a\x53\x37\x7e\x74\xd2\xc4\x09\xc4\x67\x13\x69\x6d\x2c\x4e\x78\x1a\x08\x3a\x92\x97\xdc\x67\x26\x0e\xfb\xcb\xd0\x15\xf1\x4e\x0b\x5d\x4c\xdf\x67\xe7\xbe\xd8\x26\x15\x5c\xcd\x27\x50\x83\x62\xf8\x11\xb2\xc7\xa8\x02\x30\xb4\xd6\xd8\x41\x3a\x21\x47\x07\xd2\xe0\x5b\x0f\xf1\x6f\x2a\xe8\x42\x42\x0a\xb2\x86\x6e\x83\x6e\xa7\x33\x61\x00\xfc\x1d\x9d\xe3\x07\x1c\x8e\x42\x25\x6e\x30\x63\xd0\xd5\x8b\x1d\xbd\xd4\x83\xb1\x44\x32\x0d\xad\xeb\x5a\xe6\xa5\xef\x2b\xec\x69\xc0\xd1\x3e\xe3\xa1\x01\xd1\x86\x6e\x86\x64\x3a\xe6\x44\xc0\xec\xfd\x9f\x9b\x51\xb1\xad\xf4\x0a\x7b\xb9\x60\xda\xd0\xd8\xd1\x04\x79\xac\x52\x73\xa9\xbc\x79\x35\x4c\xc8\xaa\x7f\x08\x45\x6e\x35\xeb\xfa\xbc\x99\xea\x9b\xf1\xee\xc7\xfa\xdf\xde\x2d\xf9\x78\x4b\x1c\x69\xf0\x83\x15\x0a\x10\xa6\x6c\x6b\x12\x33\x3b\xa0\x7f\x50\x18\x1e\x7f\x79\x7a\x23\x92\xc1\x21\x8b\x9f\xba\xe8\x6e\xa2\xbe\x9d\x30\x8c\xde\x98\x67\x5a\x5b\x06\x20\x8d\x27\x78\x08\x26\x7a\x8c\x2b\x09\xc1\x87\x12\x3f\x7b\x6f\xe5\x8e\xc8\x4f\xd8\xc4\x8b\x6c\x11\xf2\xbb\xdc\x8c\x0b\x11\x13\x37\xd2\x91\xed\xd0\x12\x94\xe6\x8d\xab\x8c\x67\x77\xb0\x6f\x75\x19\x86\x28\xc1\x05\x9c\x89\xa8\x50\x36\x08\x3a\x78\xb4\x80\xdf\x1e\xdf\xbd\xcd\x9a\xf0\xc9\x4a\x30\x0b\x18\xa1\xf4\x65\x52\xdf\xe8\xba\xf7\x57\xf1\xcd\x72\x2f\xbe\x52\x6f\x76\x92\x5a\x98\x53\x46\x0c\x1b\xa5\xb6\x26\x79\x79\x77\x65\x2a\xc8\xd4\xef\xfc\x1a\x6f\xc1\xd8\xc1\x3f\x1d\x50\x18\x8d\xcf\x1c\xae\x8f\x2f\x83\xa6\x49\x84\x0d\x14\xc7\xef\xd2\x44\x98\x32\xde\x02\xbd\x36\x09\xe3\x51\x8b\x59\x88\xca\x82\xff\xbe\x46\x49\x17\x71\xcb\xdd\x98\x14\xf6\x3b\x65\x3f\x8b\xe8\x17\x9f\x53\x32\x5c\x88\xe4\xc3\x17\xc0\x80\x4d\xf2\x79\xd3\xd0\xd6\xba\x92\x4a\x24\xa1\xfe\x28\x47\xbe\x1c\xfd\x79\x75\x39\x7d\x10\xe2\xff\x2f\xe7\x33\x2d\xd0\xfb\xdf\x05\x8d\x53\x8f\xec\x08\x00\x00")
+
+func templatesStreamHtmlBytes() ([]byte, error) {
+	return bindataRead(
+		_templatesStreamHtml,
+		"templates/stream.html",
+	)
+}
+
+func templatesStreamHtml() (*asset, error) {
+	bytes, err := templatesStreamHtmlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "templates/stream.html", size: 2284, mode: os.FileMode(420), modTime: time.Unix(1792156637, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _templatesTagHtml = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x9d\x54\x4d\x6f\x9c\x30\x10\x3d\x67\x7f\x85\x6b\xa9\xb7\x18\x9a\xf4\x52\x55\xb0\x97\xb4\xb7\x28\x8d\xaa\xed\xa1\xc7\x01\x06\xb0\x02\x98\xda\xc3\x26\xd1\x6a\xff\x7b\xc7\x36\xec\xb2\xd2\xf6\xd2\x0b\x98\xf9\x78\xf3\x66\xe6\x99\xc3\xa1\xc2\x5a\x0f\x28\x24\x41\x23\x8f\xc7\x4d\xf6\xe1\xdb\x8f\x87\xdd\xef\xe7\xef\xa2\xa5\xbe\xdb\x6e\x32\xff\x12\x1d\x0c\x4d\x2e\x0f\x87\x70\x10\xc7\xa3\x14\x15\x10\x28\x6a\xb1\xc7\x60\x77\x48\xa4\x87\xc6\x25\x3b\x6f\xf2\x11\xdb\xcd\x4d\xd6\x22\x54\xfc\xbe\xc9\x7a\x24\x10\x65\x0b\x96\xe3\x72\x39\x51\xad\xbe\xc8\xb3\x63\x00\x8f\xb2\xd7\xf8\x3a\x1a\x4b\x52\x94\x66\x20\x1c\x38\xf0\x55\x57\xd4\xe6\x15\xee\x75\x89\x2a\x7c\xdc\x0a\x3d\x68\xd2\xd0\x29\x57\x42\x87\xf9\xdd\xad\x70\xad\xd5\xc3\x8b\x22\xa3\x6a\x4d\xf9\x60\x56\xc0\x2d\xd1\xa8\xf0\xcf\xa4\xf7\xb9\x7c\x53\x13\xa8\xd2\xf4\x23\x90\x2e\x3a\x5c\x55\xd1\x98\x63\xd5\x60\xcc\xeb\x18\x4b\x58\xec\x72\xe9\xe8\xbd\x43\xd7\x22\x32\xa3\xd6\x62\x1d\xfa\x2c\xc0\xf9\xee\x52\x47\x0c\x53\xa6\x85\x31\xe4\xc8\xc2\x98\x96\xce\x9d\xbf\x92\x5e\x0f\x09\x5b\xfe\x07\xb2\x9c\x1c\x99\x7e\xc9\xce\xd2\x79\x86\x59\x61\xaa\xf7\x00\x37\xc0\x5e\x94\x1d\x38\x97\x4b\x3e\x16\x60\x45\x7c\xa9\x4e\x37\x2d\x89\xa2\x51\x35\x54\x58\x85\xda\xbc\x82\xbb\xcb\x60\x55\x58\x18\x2a\xd1\x17\xea\x93\xdc\x72\xf5\x57\x4d\xad\x08\xb6\xe4\xd1\x34\x86\x89\x64\xba\x6f\x84\xb3\x65\x20\x97\x84\x65\x43\xc7\x63\x62\xca\xe8\x2b\xe4\xf2\x33\xa7\x0a\x76\x22\x03\x1d\x8f\xbe\x85\x90\xff\x04\x61\xf3\x4c\xf9\x2e\x10\x4d\xb9\xe2\x76\xe3\x4f\xed\xbd\x2f\xb5\x13\xf2\x19\x1c\xa1\x13\xac\xb5\x06\x2b\xf1\xd1\x49\x91\xec\xa0\x89\x49\xf7\x9c\xc4\x51\xba\x16\xc9\x1c\xc6\x72\xe4\xe4\xa9\xf3\x68\xec\xe1\x22\x0d\x5e\x3a\xfd\x70\x43\x9f\x4b\xe6\xae\x9d\xfa\x62\x00\xdd\x79\x4c\x38\x0f\x39\xf9\xf5\xf3\x31\xa8\x32\x74\x37\x4f\x84\x8f\x2c\xe1\x39\x41\x9e\x7b\x5e\x83\xcc\xcd\x47\xfa\x67\x87\xa9\x23\xfb\x87\x16\xcb\x17\x37\xf5\x11\x3b\x85\xed\x69\x2c\x81\xd5\x55\x0a\xfe\xbc\xca\xf3\x59\x4b\x0b\x73\x8b\x3c\x13\xb7\x82\x98\xe9\x16\xc0\x2a\x15\xe1\xa9\x1c\xb2\x7c\x2b\xb0\xef\x57\x84\xc4\xd3\x75\xe9\xb2\xbb\xed\x7c\x58\x57\x59\x11\x5c\x06\x6e\xd1\x5f\x3f\x1f\x37\x5a\x5c\x0a\x8e\x7e\xd2\x6a\x8c\xbe\x88\xb4\x0a\x4c\xd9\x71\xd9\x6e\x96\xc6\x6d\xac\x8d\x59\x1a\xf6\xe7\x4d\x5d\xa0\xe7\x6d\x95\x3e\x49\x98\x2f\xb1\x25\x11\x9e\x4a\x0f\xb5\x91\xc2\x1a\xbe\xd7\xd1\x1e\x35\x1c\x67\xff\x64\xc4\x18\x37\x0f\xcc\xf0\xa4\xa0\xe4\x24\xa1\x50\x8c\x91\xe7\x6a\x0b\x81\x0b\x8d\x47\xb9\x72\xc7\xc6\x8a\xe4\x91\xef\xa5\xe3\x55\xf0\x7f\x00\x4a\x9a\x11\x6a\xbe\xc3\x68\x17\x76\x84\x6f\xa4\xfa\x89\x96\xeb\x74\x5e\x51\x4c\xfe\x87\xc8\x82\x88\x34\x75\x38\x0f\x5e\x5c\x8c\x69\x61\xb4\xaa\x7c\x42\xe9\x59\x5c\x64\xbe\x5e\xdb\xde\xe5\x54\x23\xcf\x8b\x69\xaf\x02\xb2\x34\xfe\x2e\xf8\x5a\x85\xbf\xf8\xe1\xc0\x1e\x76\xfc\x05\x9a\x94\x8c\x0c\xef\x05\x00\x00")
+
+func templatesTagHtmlBytes() ([]byte, error) {
+	return bindataRead(
+		_templatesTagHtml,
+		"templates/tag.html",
+	)
+}
+
+func templatesTagHtml() (*asset, error) {
+	bytes, err := templatesTagHtmlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "templates/tag.html", size: 1519, mode: os.FileMode(420), modTime: time.Unix(1792156637, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _templatesTrashHtml = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x9d\x55\xc1\x6e\xe3\x36\x10\x3d\xdb\x5f\x31\xe5\x39\x92\xec\xa0\x29\x8a\x85\x24\xa0\xd8\x6d\x81\x1e\xba\x6b\x34\xb9\xb4\x37\x4a\x1c\x5b\xec\x4a\x94\x96\x1c\x39\xeb\x1a\xf9\xf7\x0e\x49\xc9\x76\xbc\x59\x04\xcd\xc5\x26\x87\x33\xf3\x1e\x67\xde\x88\xc7\xa3\xc2\xad\x36\x08\x82\xac\x74\x8d\x78\x7a\x5a\xe6\x3f\x7c\xf8\xf4\xfe\xe1\xaf\xcd\xaf\xd0\x50\xd7\x96\xcb\xdc\xff\x41\x2b\xcd\xae\x10\xc7\x63\x58\xc0\xd3\x93\x00\x25\x49\x26\xd4\x60\x87\xc1\xee\x90\x48\x9b\x9d\x4b\x1f\xbc\xc9\x7b\x94\xcb\x45\xde\xa0\x54\xfc\xbf\xc8\x3b\x24\x09\x75\x23\x2d\xfb\x15\x62\xa4\x6d\xf2\xb3\x38\x1f\x18\xe9\xb3\xec\x35\x3e\x0e\xbd\x25\x01\x75\x6f\x08\x0d\x3b\x3e\x6a\x45\x4d\xa1\x70\xaf\x6b\x4c\xc2\xe6\x06\xb4\xd1\xa4\x65\x9b\xb8\x5a\xb6\x58\xac\x6f\xc0\x35\x56\x9b\xcf\x09\xf5\xc9\x56\x53\x61\xfa\x8b\xc4\x0d\xd1\x90\xe0\x97\x51\xef\x0b\xf1\x35\x19\x65\x52\xf7\xdd\x20\x49\x57\x2d\x5e\xa0\x68\x2c\x50\xed\x30\xc6\xb5\x9c\x0b\x2c\xb6\x85\x70\x74\x68\xd1\x35\x88\xcc\xa8\xb1\xb8\x0d\xf7\xac\xa4\xf3\xb7\xcb\x1c\x71\x9a\x3a\xab\xfa\x9e\x1c\xd7\x6e\xc8\x6a\xe7\xce\xbb\xb4\xd3\x26\x65\xcb\x5b\x52\xd6\xa3\xa3\xbe\x9b\xa3\xf3\x6c\xaa\x61\x5e\xf5\xea\x10\xd2\x19\xb9\x87\xba\x95\xce\x15\x82\x97\x95\xb4\x10\xff\x92\x56\xef\x1a\x82\x6a\x97\x6c\xa5\x42\x15\xb0\xb9\x05\xeb\xe7\xce\x49\x65\xa5\x51\xd0\x55\xc9\x4a\x94\x1e\xdd\x6f\xd3\x8f\x32\x34\x0d\xa4\x62\xe6\x8c\xb9\x0e\x48\x19\x87\x94\xcb\xe5\x82\xdd\xf4\x16\xf0\x0b\xa4\xf7\xcc\x71\x74\x20\xd0\xda\xde\x0a\x8e\xf0\x6e\x4a\x9f\x08\x71\x4b\x2c\x41\xf8\x4d\x14\x4b\x05\xd9\xc9\xf6\xdc\xa7\x78\x12\x39\x71\xba\xf4\x0f\x74\x4e\xee\x70\xca\x90\x71\x8a\x32\xe0\x20\x73\x63\xdb\x4b\x98\x6e\xac\x6b\x8e\x7a\x05\xf5\xe4\xf5\x36\x58\x2e\xd8\x6d\xf9\xe0\x87\x81\xab\x70\x1b\xaa\x30\x94\x1f\xb0\x45\x42\x05\x83\x74\x84\x0e\x6a\x69\xa0\x42\xee\x28\x37\xca\xb2\x79\x34\xa4\x5b\xe0\x59\x38\x80\xb4\x08\xc3\x68\x77\xa8\xd2\x3c\x1b\x7c\x78\xbc\x87\x0f\x11\xff\xf4\xd5\x89\xfd\xb6\xb7\x1d\xc8\x9a\x74\x6f\x9e\x89\x20\x34\x20\xab\x5b\x94\x66\x1c\x04\xb0\x8a\x9b\x5e\x15\x62\xf3\xe9\xfe\x21\x8a\xa9\x1a\x89\x7a\x33\xdf\xbc\x22\xa6\x42\x26\x71\xc8\x6a\x56\xd2\x1e\x04\xd0\x61\xe0\x7b\xbb\xb1\xea\x34\x5f\x7c\xe3\xc9\x00\x7e\x1d\xb4\x3d\x5f\xc0\xf4\x8f\x79\x16\x13\xc5\x3e\x7b\x36\x13\xd9\xa9\x12\x6c\x25\xc9\x63\x32\x03\x85\xcd\x24\x29\x9a\xc7\xda\xaf\x6d\x5c\x78\x6b\xb9\xf1\xe9\xf3\x8c\x57\x67\xdb\xbd\xfe\xf7\xda\x34\x95\xf3\xca\x1a\x98\x5e\x1b\xcf\x5b\x5e\x45\x28\x6f\x9a\xf1\x73\x9a\xa7\xc2\x53\xb7\x5e\x70\x90\x6e\xe2\x25\xc3\x25\x9e\x33\x54\x5e\xf0\xe9\xfb\x06\xeb\xcf\x6e\xec\xd8\x83\x73\xa9\xab\x53\xcf\xf7\xc5\x13\xee\xa2\xe9\x09\xd2\x89\xfd\x2f\x94\xfe\xee\xfe\x46\xdb\xb3\xb3\x8f\x3b\x9b\x7f\xe3\x62\x4a\x02\x71\xbb\x5a\xfd\x94\xac\xd6\xc9\xea\x16\xd6\x77\xef\x56\x3f\xbe\x5b\xdd\x89\xe8\x1c\x6b\xfc\x22\x44\xba\xd1\xc6\xa0\x3f\xfe\x88\x7b\xb4\x37\x30\x84\xbd\x0f\x6a\x59\x21\xc1\xc3\x17\xea\x12\x3e\x78\xce\x1e\x91\xcd\xec\xf3\x46\x2e\x71\xf5\x9a\x4a\xe3\x9b\xf1\xad\x46\x43\xa8\x36\xc3\x48\x93\x18\x1b\xad\x14\x1a\x31\x7d\xe7\xeb\xa9\xfe\x02\xf6\xb2\x1d\xe3\xf3\x71\xd9\x94\x73\x8e\x57\xb4\x1e\x77\xdd\x95\xe4\x27\x94\xc8\xfa\x84\x31\x0d\xab\x28\xff\x8c\x8b\x4b\xfd\x2f\x16\xf3\x98\x86\x06\x9f\x3a\xf0\x1d\xfc\xf8\x5d\xfb\x3f\xe0\xe1\x8b\x30\xcd\xe2\x09\xf8\x72\xd6\xa2\xc0\xe7\x29\x8c\x22\x57\x57\xba\x3f\x37\xf8\xa4\x6b\x6e\x15\xbf\x62\xad\x1b\x24\xb7\xe7\x4e\x94\xfc\xf6\x42\x68\x0a\x68\x07\xd8\x0d\x74\x48\x43\xa2\x67\x39\x4e\x98\x6c\x3d\xbd\x29\x59\x98\xef\xf0\xde\x44\x1b\x7f\xfd\xc2\xf3\x7f\x3c\x72\x00\xfb\xff\x07\x01\xb1\xc0\x56\x2a\x08\x00\x00")
+
+func templatesTrashHtmlBytes() ([]byte, error) {
+	return bindataRead(
+		_templatesTrashHtml,
+		"templates/trash.html",
+	)
+}
+
+func templatesTrashHtml() (*asset, error) {
+	bytes, err := templatesTrashHtmlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "templates/trash.html", size: 2090, mode: os.FileMode(420), modTime: time.Unix(1792156885, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
@@ -12681,7 +12933,19 @@ var _bindata = map[string]func() (*asset, error){
 	"static/codemirror/theme/zenburn.css": staticCodemirrorThemeZenburnCss,
 	"static/custom.css": staticCustomCss,
 	"static/custom.js": staticCustomJs,
+	"static/print.css": staticPrintCss,
+	"templates/access.html": templatesAccessHtml,
+	"templates/admin.html": templatesAdminHtml,
+	"templates/embed.html": templatesEmbedHtml,
+	"templates/error.html": templatesErrorHtml,
+	"templates/jobs.html": templatesJobsHtml,
 	"templates/pastebin.html": templatesPastebinHtml,
+	"templates/reports.html": templatesReportsHtml,
+	"templates/set.html": templatesSetHtml,
+	"templates/stats.html": templatesStatsHtml,
+	"templates/stream.html": templatesStreamHtml,
+	"templates/tag.html": templatesTagHtml,
+	"templates/trash.html": templatesTrashHtml,
 }
 
 // AssetDir returns the file names below a certain
@@ -13609,9 +13873,21 @@ var _bintree = &bintree{nil, map[string]*bintree{
 		}},
 		"custom.css": &bintree{staticCustomCss, map[string]*bintree{}},
 		"custom.js": &bintree{staticCustomJs, map[string]*bintree{}},
+		"print.css": &bintree{staticPrintCss, map[string]*bintree{}},
 	}},
 	"templates": &bintree{nil, map[string]*bintree{
+		"access.html": &bintree{templatesAccessHtml, map[string]*bintree{}},
+		"admin.html": &bintree{templatesAdminHtml, map[string]*bintree{}},
+		"embed.html": &bintree{templatesEmbedHtml, map[string]*bintree{}},
+		"error.html": &bintree{templatesErrorHtml, map[string]*bintree{}},
+		"jobs.html": &bintree{templatesJobsHtml, map[string]*bintree{}},
 		"pastebin.html": &bintree{templatesPastebinHtml, map[string]*bintree{}},
+		"reports.html": &bintree{templatesReportsHtml, map[string]*bintree{}},
+		"set.html": &bintree{templatesSetHtml, map[string]*bintree{}},
+		"stats.html": &bintree{templatesStatsHtml, map[string]*bintree{}},
+		"stream.html": &bintree{templatesStreamHtml, map[string]*bintree{}},
+		"tag.html": &bintree{templatesTagHtml, map[string]*bintree{}},
+		"trash.html": &bintree{templatesTrashHtml, map[string]*bintree{}},
 	}},
 }}
 
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"sync"
+
+	"github.com/espebra/pastebin/pastebin"
+)
+
+const blocklistKey = "blocklist"
+
+var errBlocked = pastebin.ErrBlocked
+
+// blocklist holds the checksums of pastes taken down by an admin, and
+// regular expressions matching content that is not allowed. It is kept in
+// memory and written through to the storage on every change.
+type blocklist struct {
+	sync.RWMutex
+	Checksums map[string]bool `json:"checksums"`
+	Rules     []string        `json:"rules"`
+	rules     []*regexp.Regexp
+}
+
+var blocked = blocklist{Checksums: map[string]bool{}}
+
+func (b *blocklist) Load() error {
+	b.Lock()
+	defer b.Unlock()
+	if err := retrieveJSON(blocklistKey, b); err != nil {
+		return err
+	}
+	if b.Checksums == nil {
+		b.Checksums = map[string]bool{}
+	}
+	b.rules = nil
+	for _, rule := range b.Rules {
+		re, err := regexp.Compile(rule)
+		if err != nil {
+			return err
+		}
+		b.rules = append(b.rules, re)
+	}
+	return nil
+}
+
+func (b *blocklist) Contains(checksum string) bool {
+	b.RLock()
+	defer b.RUnlock()
+	return b.Checksums[checksum]
+}
+
+// Allows reports whether the paste is neither blocked by checksum nor
+// matched by any of the content rules.
+func (b *blocklist) Allows(p Paste) bool {
+	b.RLock()
+	defer b.RUnlock()
+	if b.Checksums[p.Checksum] {
+		return false
+	}
+	for _, re := range b.rules {
+		if re.MatchString(p.Content) {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *blocklist) Add(checksum string) error {
+	b.Lock()
+	defer b.Unlock()
+	b.Checksums[checksum] = true
+	return storeJSON(blocklistKey, b)
+}
+
+func (b *blocklist) Remove(checksum string) error {
+	b.Lock()
+	defer b.Unlock()
+	delete(b.Checksums, checksum)
+	return storeJSON(blocklistKey, b)
+}
+
+func (b *blocklist) AddRule(rule string) error {
+	re, err := regexp.Compile(rule)
+	if err != nil {
+		return err
+	}
+	b.Lock()
+	defer b.Unlock()
+	for _, existing := range b.Rules {
+		if existing == rule {
+			return nil
+		}
+	}
+	b.Rules = append(b.Rules, rule)
+	b.rules = append(b.rules, re)
+	return storeJSON(blocklistKey, b)
+}
+
+func (b *blocklist) RemoveRule(rule string) error {
+	b.Lock()
+	defer b.Unlock()
+	for i, existing := range b.Rules {
+		if existing == rule {
+			b.Rules = append(b.Rules[:i], b.Rules[i+1:]...)
+			b.rules = append(b.rules[:i], b.rules[i+1:]...)
+			break
+		}
+	}
+	return storeJSON(blocklistKey, b)
+}
+
+// BlocklistEntry is a blocklist change requested through the admin API.
+type BlocklistEntry struct {
+	Checksum string `json:"checksum,omitempty"`
+	Rule     string `json:"rule,omitempty"`
+}
+
+func apiBlocklist(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "GET" {
+		blocked.RLock()
+		defer blocked.RUnlock()
+		writeJSON(w, http.StatusOK, &blocked)
+		return
+	}
+
+	var entry BlocklistEntry
+	if err := json.NewDecoder(r.Body).Decode(&entry); err != nil {
+		writeJSON(w, http.StatusBadRequest, BatchResult{Status: "error", Message: "Invalid entry: " + err.Error()})
+		return
+	}
+
+	var err error
+	switch {
+	case entry.Checksum != "" && !pastebin.IsChecksum(entry.Checksum):
+		err = errors.New("invalid checksum " + entry.Checksum)
+	case entry.Checksum != "" && r.Method == "POST":
+		err = blocked.Add(entry.Checksum)
+	case entry.Checksum != "":
+		err = blocked.Remove(entry.Checksum)
+	case entry.Rule != "" && r.Method == "POST":
+		err = blocked.AddRule(entry.Rule)
+	case entry.Rule != "":
+		err = blocked.RemoveRule(entry.Rule)
+	default:
+		err = errors.New("either checksum or rule is required")
+	}
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, BatchResult{Status: "error", Message: err.Error()})
+		return
+	}
+	action := "block"
+	if r.Method == "DELETE" {
+		action = "unblock"
+	}
+	if entry.Checksum != "" {
+		audit(r, action, entry.Checksum, "")
+	} else {
+		audit(r, action, "", "rule "+entry.Rule)
+	}
+	writeJSON(w, http.StatusOK, BatchResult{Checksum: entry.Checksum, Status: "success"})
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Branding is how the instance presents itself on the public pages.
+type Branding struct {
+	Name    string
+	Logo    string
+	Contact string
+	Links   []FooterLink
+}
+
+// FooterLink is a link shown at the bottom of the public pages, like to
+// the terms of service.
+type FooterLink struct {
+	Title string
+	URL   string
+}
+
+// parseFooterLinks reads -footer-links, given as title=URL pairs
+// separated by commas.
+func parseFooterLinks(s string) ([]FooterLink, error) {
+	var links []FooterLink
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		i := strings.Index(item, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("footer link %q is not given as title=URL", item)
+		}
+		link := FooterLink{Title: strings.TrimSpace(item[:i]), URL: strings.TrimSpace(item[i+1:])}
+		if u, err := url.Parse(link.URL); err != nil || u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "" {
+			return nil, fmt.Errorf("footer link %q has an invalid URL", item)
+		}
+		links = append(links, link)
+	}
+	return links, nil
+}
+
+// branding returns the branding set with -instance-name, -logo-url,
+// -footer-links and -contact-email, or for the virtual host of the
+// request.
+func branding(r *http.Request) Branding {
+	links, _ := parseFooterLinks(hostOption(r, "footer-links"))
+	return Branding{
+		Name:    hostOption(r, "instance-name"),
+		Logo:    hostOption(r, "logo-url"),
+		Contact: hostOption(r, "contact-email"),
+		Links:   links,
+	}
+}
+
+// ErrorPage is the data shown on error pages.
+type ErrorPage struct {
+	Status  int
+	Message string
+}
+
+// notFound renders the error page for routes that do not exist. API
+// clients get JSON.
+func notFound(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.URL.Path, "/api/") {
+		writeJSON(w, http.StatusNotFound, BatchResult{Status: "error", Message: "Not found"})
+		return
+	}
+	w.WriteHeader(http.StatusNotFound)
+	renderTemplate(w, r, "templates/error.html", "error", ErrorPage{
+		Status:  http.StatusNotFound,
+		Message: "The page you are looking for does not exist.",
+	})
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/espebra/pastebin/pastebin"
+)
+
+// BulkFilter selects the pastes a bulk delete applies to. A paste must
+// match every filter that is set, and at least one must be set.
+type BulkFilter struct {
+	// Created longer ago than this, e.g. 720h
+	OlderThan string `json:"older_than,omitempty"`
+	// Larger than this many bytes
+	LargerThan int64  `json:"larger_than,omitempty"`
+	Tag        string `json:"tag,omitempty"`
+}
+
+// BulkProgress is the progress of a bulk delete.
+type BulkProgress struct {
+	Checked int `json:"checked"`
+	Matched int `json:"matched"`
+	Deleted int `json:"deleted"`
+	Pinned  int `json:"pinned"`
+	Failed  int `json:"failed"`
+}
+
+// matcher returns a function reporting whether a paste matches the
+// filter.
+func (f BulkFilter) matcher() (func(m Meta, size int64, modified time.Time) bool, error) {
+	var olderThan time.Duration
+	if f.OlderThan != "" {
+		d, err := time.ParseDuration(f.OlderThan)
+		if err != nil || d <= 0 {
+			return nil, errors.New("older_than must be a positive duration, e.g. 720h")
+		}
+		olderThan = d
+	}
+	if f.Tag != "" {
+		if _, err := pastebin.ParseTags(f.Tag); err != nil {
+			return nil, err
+		}
+	}
+	if olderThan == 0 && f.LargerThan <= 0 && f.Tag == "" {
+		return nil, errors.New("at least one of older_than, larger_than and tag is required")
+	}
+
+	return func(m Meta, size int64, modified time.Time) bool {
+		if olderThan > 0 {
+			created := m.Created
+			if created.IsZero() {
+				created = modified
+			}
+			if time.Since(created) < olderThan {
+				return false
+			}
+		}
+		if f.LargerThan > 0 && size <= f.LargerThan {
+			return false
+		}
+		if f.Tag != "" && !contains(m.Tags, f.Tag) {
+			return false
+		}
+		return true
+	}, nil
+}
+
+// bulkDeleteJob walks the data directories and moves the pastes matching
+// the filter to the trash. Pinned pastes are left alone.
+func bulkDeleteJob(ctx context.Context, payload json.RawMessage, progress func(v interface{})) error {
+	var f BulkFilter
+	if err := json.Unmarshal(payload, &f); err != nil {
+		return err
+	}
+	match, err := f.matcher()
+	if err != nil {
+		return err
+	}
+
+	var p BulkProgress
+	seen := map[string]bool{}
+	return walkPastes(func(dir, checksum string, data []byte, fi os.FileInfo) error {
+		// Interrupted jobs are queued again, and skip what was deleted
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if seen[checksum] {
+			return nil
+		}
+		seen[checksum] = true
+		p.Checked++
+		defer progress(p)
+
+		m, _ := service.Meta(checksum)
+		if m.Deleted || !match(m, int64(len(data)), fi.ModTime()) {
+			return nil
+		}
+		p.Matched++
+		switch err := service.Trash(checksum); err {
+		case nil:
+			p.Deleted++
+		case pastebin.ErrPinned:
+			p.Pinned++
+		default:
+			log.Printf("Unable to delete %s: %s\n", checksum, err)
+			p.Failed++
+		}
+		return nil
+	})
+}
+
+// apiBulkDelete queues a job deleting the pastes matching the filter in
+// the request body, and responds with the job to follow its progress.
+func apiBulkDelete(w http.ResponseWriter, r *http.Request) {
+	var f BulkFilter
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, requestOverhead)).Decode(&f); err != nil {
+		writeJSON(w, http.StatusBadRequest, BatchResult{Status: "error", Message: "Invalid filter: " + err.Error()})
+		return
+	}
+	if _, err := f.matcher(); err != nil {
+		writeJSON(w, http.StatusBadRequest, BatchResult{Status: "error", Message: err.Error()})
+		return
+	}
+	j, err := jobQueue.Enqueue("bulk-delete", f)
+	if err != nil {
+		log.Printf("Unable to queue bulk delete: %s\n", err)
+		writeJSON(w, http.StatusServiceUnavailable, BatchResult{Status: "error", Message: err.Error()})
+		return
+	}
+
+	filter, _ := json.Marshal(f)
+	audit(r, "bulk-delete", "job "+j.ID, string(filter))
+
+	w.Header().Set("Location", basePath()+"/api/v1/admin/jobs/"+j.ID)
+	writeJSON(w, http.StatusAccepted, j)
+}
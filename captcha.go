@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// captchaProvider describes a CAPTCHA service and how to verify its
+// responses.
+type captchaProvider struct {
+	Script    string
+	Class     string
+	Field     string
+	VerifyURL string
+}
+
+var captchaProviders = map[string]captchaProvider{
+	"hcaptcha": {
+		Script:    "https://js.hcaptcha.com/1/api.js",
+		Class:     "h-captcha",
+		Field:     "h-captcha-response",
+		VerifyURL: "https://api.hcaptcha.com/siteverify",
+	},
+	"turnstile": {
+		Script:    "https://challenges.cloudflare.com/turnstile/v0/api.js",
+		Class:     "cf-turnstile",
+		Field:     "cf-turnstile-response",
+		VerifyURL: "https://challenges.cloudflare.com/turnstile/v0/siteverify",
+	},
+}
+
+var errCaptcha = errors.New("CAPTCHA verification failed")
+
+var captchaClient = &http.Client{Timeout: 10 * time.Second}
+
+// captcha returns the configured CAPTCHA provider, if any.
+func captcha() (captchaProvider, bool) {
+	p, ok := captchaProviders[*captchaProviderFlag]
+	return p, ok
+}
+
+// checkCaptcha verifies the CAPTCHA of a request creating pastes, unless
+// its user is signed in, or it is an API request, one not from the form,
+// and -captcha-exempt-api exempts those. It succeeds without a request
+// when no provider is configured.
+func checkCaptcha(r *http.Request, api bool) error {
+	if _, ok := captcha(); !ok || (api && *captchaExemptAPIFlag) {
+		return nil
+	}
+	if role, ok := requestRole(r); ok && role > roleAnonymous {
+		return nil
+	}
+	return verifyCaptcha(r)
+}
+
+// verifyCaptcha checks the CAPTCHA response submitted with the form, or
+// in the X-Captcha-Response header by other clients.
+func verifyCaptcha(r *http.Request) error {
+	provider, ok := captcha()
+	if !ok {
+		return nil
+	}
+
+	response := r.Header.Get("X-Captcha-Response")
+	if response == "" {
+		response = r.FormValue(provider.Field)
+	}
+	if response == "" {
+		return errCaptcha
+	}
+
+	form := url.Values{}
+	form.Set("secret", *captchaSecretFlag)
+	form.Set("response", response)
+	form.Set("sitekey", *captchaSiteKeyFlag)
+	form.Set("remoteip", clientIP(r))
+
+	resp, err := captchaClient.PostForm(provider.VerifyURL, form)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Success bool `json:"success"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return err
+	}
+	if !result.Success {
+		return errCaptcha
+	}
+	return nil
+}
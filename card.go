@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"log"
+	"net/http"
+	"strings"
+	"unicode"
+
+	"github.com/gorilla/mux"
+)
+
+// Size of the social preview card, as recommended for Open Graph images.
+const (
+	cardWidth  = 1200
+	cardHeight = 630
+)
+
+// Length of the description in social previews.
+const previewLength = 200
+
+// preview returns the first lines of content as a single line of at most
+// previewLength characters, for use in social preview descriptions.
+func preview(content string) string {
+	s := strings.Join(strings.Fields(content), " ")
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, s)
+	if r := []rune(s); len(r) > previewLength {
+		s = string(r[:previewLength-1]) + "…"
+	}
+	return s
+}
+
+// renderCard draws an outline of the first lines of the paste, with one
+// bar per line following its indentation and length, much like the
+// minimap of an editor. It needs no fonts, which keeps it to the
+// standard library.
+func renderCard(content string) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, cardWidth, cardHeight))
+	draw.Draw(img, img.Bounds(), &image.Uniform{color.RGBA{0x26, 0x32, 0x38, 0xff}}, image.ZP, draw.Src)
+
+	const (
+		margin     = 60
+		lineHeight = 24
+		barHeight  = 12
+		charWidth  = 12
+	)
+	bar := &image.Uniform{color.RGBA{0x80, 0xcb, 0xc4, 0xff}}
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		y := margin + i*lineHeight
+		if y+barHeight > cardHeight-margin {
+			break
+		}
+		line = strings.Replace(line, "\t", "    ", -1)
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		length := len(strings.TrimSpace(line))
+		if length == 0 {
+			continue
+		}
+		x0 := margin + indent*charWidth
+		x1 := x0 + length*charWidth
+		if x1 > cardWidth-margin {
+			x1 = cardWidth - margin
+		}
+		if x0 >= x1 {
+			continue
+		}
+		draw.Draw(img, image.Rect(x0, y, x1, y+barHeight), bar, image.ZP, draw.Src)
+	}
+
+	var buf bytes.Buffer
+	err := png.Encode(&buf, img)
+	return buf.Bytes(), err
+}
+
+func cardImage(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	checksum := vars["checksum"]
+
+	var p Paste
+	var err error
+	if service.Authorize(checksum, vars["key"]) {
+		p, err = retrievePaste(checksum, vars["key"])
+	} else {
+		err = fmt.Errorf("invalid key for %s", checksum)
+	}
+	if err != nil {
+		log.Println(err)
+		http.NotFound(w, r)
+		return
+	}
+
+	data, err := renderCard(p.Content)
+	if err != nil {
+		log.Printf("Unable to render card for %s: %s\n", checksum, err)
+		http.Error(w, "Unable to render card", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	if m, _ := service.Meta(checksum); service.RequiresKey(m) {
+		w.Header().Set("Cache-Control", "private, max-age=86400")
+	} else {
+		w.Header().Set("Cache-Control", "public, max-age=86400")
+	}
+	w.Write(data)
+}
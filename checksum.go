@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/espebra/pastebin/pastebin"
+)
+
+// Clients can send the SHA-256 of the paste they upload in the header or
+// the form field, so that pastes truncated or corrupted on the way are
+// rejected rather than stored. The checksum of a paste is also its name.
+const (
+	checksumHeader = "X-Content-SHA256"
+	checksumField  = "sha256"
+)
+
+// ChecksumError is the error of an upload that does not match the
+// checksum sent by the client.
+type ChecksumError struct {
+	Status   string `json:"status"`
+	Message  string `json:"message"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual,omitempty"`
+	Size     int    `json:"size"`
+}
+
+func (e *ChecksumError) Error() string {
+	return e.Message
+}
+
+// expectedChecksum returns the checksum sent by the client, or an empty
+// string when it sent none.
+func expectedChecksum(r *http.Request) string {
+	if v := r.Header.Get(checksumHeader); v != "" {
+		return strings.ToLower(strings.TrimSpace(v))
+	}
+	return strings.ToLower(strings.TrimSpace(r.FormValue(checksumField)))
+}
+
+// verifyChecksum returns an error when the client sent a checksum that is
+// not the checksum of the content, or is not a checksum at all.
+func verifyChecksum(expected, content string) *ChecksumError {
+	if expected == "" {
+		return nil
+	}
+	e := &ChecksumError{Status: "error", Expected: expected, Size: len(content)}
+	if !pastebin.IsChecksum(expected) {
+		e.Message = "Invalid checksum " + expected + ", expected the SHA-256 of the paste in hex"
+		return e
+	}
+	if actual := pastebin.Checksum(content); actual != expected {
+		e.Actual = actual
+		e.Message = fmt.Sprintf("The %d bytes received have the checksum %s, not %s. The paste was not saved.", len(content), actual, expected)
+		return e
+	}
+	return nil
+}
@@ -0,0 +1,223 @@
+// Package client is a client of the pastebin JSON API, for Go programs
+// creating and reading pastes. Requests failing with server errors or rate
+// limits are retried with backoff, proof of work is solved when the server
+// asks for it, and large pastes are uploaded in chunks that are resumed
+// when a chunk fails.
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Defaults of clients made by New.
+const (
+	DefaultRetries = 3
+	DefaultBackoff = 500 * time.Millisecond
+)
+
+// Retry-After longer than this is not waited for, such as that of a daily
+// download quota that is used up.
+const maxRetryAfter = time.Minute
+
+// Error responses are read up to this many bytes for their message.
+const maxErrorBody = 64 << 10
+
+// Client sends requests to the pastebin at BaseURL. Its fields must not be
+// changed while it is in use.
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	// Basic auth credentials, for pastebins that require an account to
+	// create pastes
+	Username string
+	Password string
+	// Requests failing with server errors, rate limits or network errors
+	// are retried this many times, waiting Backoff, twice as long for each
+	// retry, or as long as the server asks with Retry-After
+	Retries   int
+	Backoff   time.Duration
+	UserAgent string
+}
+
+// New returns a client of the pastebin at baseURL, such as
+// https://paste.example.com.
+func New(baseURL string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		HTTPClient: http.DefaultClient,
+		Retries:    DefaultRetries,
+		Backoff:    DefaultBackoff,
+		UserAgent:  "pastebin-client",
+	}
+}
+
+// Error is a response of the pastebin that is not successful.
+type Error struct {
+	StatusCode int
+	Message    string
+	// How long to wait before retrying, for rate limits
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("pastebin: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("pastebin: %d %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound reports whether the error is a paste that does not exist.
+func IsNotFound(err error) bool {
+	e, ok := err.(*Error)
+	return ok && e.StatusCode == http.StatusNotFound
+}
+
+// request is a request to the pastebin. The body is kept in memory so that
+// it can be sent again when the request is retried.
+type request struct {
+	method string
+	path   string
+	header http.Header
+	body   []byte
+}
+
+func newRequest(method, path string) *request {
+	return &request{method: method, path: path, header: http.Header{}}
+}
+
+func (r *request) json(v interface{}) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	r.body = body
+	r.header.Set("Content-Type", "application/json")
+	return nil
+}
+
+// retryable reports whether a request answered with the status may
+// succeed when sent again.
+func retryable(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// do sends the request, retrying it, and returns the successful response,
+// whose body the caller must close. Responses that are not successful are
+// returned as *Error.
+func (c *Client) do(ctx context.Context, req *request) (*http.Response, error) {
+	var err error
+	for attempt := 0; ; attempt++ {
+		var resp *http.Response
+		resp, err = c.send(ctx, req)
+		if err == nil && resp.StatusCode < 400 {
+			return resp, nil
+		}
+		wait := c.Backoff << uint(attempt)
+		if err == nil {
+			e := errorFrom(resp)
+			if !retryable(e.StatusCode) || e.RetryAfter > maxRetryAfter {
+				return nil, e
+			}
+			if e.RetryAfter > 0 {
+				wait = e.RetryAfter
+			}
+			err = e
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if attempt >= c.Retries {
+			return nil, err
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (c *Client) send(ctx context.Context, req *request) (*http.Response, error) {
+	r, err := http.NewRequest(req.method, c.BaseURL+req.path, bytes.NewReader(req.body))
+	if err != nil {
+		return nil, err
+	}
+	r = r.WithContext(ctx)
+	for name, values := range req.header {
+		r.Header[name] = values
+	}
+	if r.Header.Get("Accept") == "" {
+		r.Header.Set("Accept", "application/json")
+	}
+	if c.UserAgent != "" {
+		r.Header.Set("User-Agent", c.UserAgent)
+	}
+	if c.Username != "" || c.Password != "" {
+		r.SetBasicAuth(c.Username, c.Password)
+	}
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return httpClient.Do(r)
+}
+
+// errorFrom reads the error of the response, which is JSON with a message
+// from the API and plain text from the other endpoints, and closes it.
+func errorFrom(resp *http.Response) *Error {
+	defer resp.Body.Close()
+	e := &Error{StatusCode: resp.StatusCode}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		e.RetryAfter = time.Duration(seconds) * time.Second
+	}
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	var result struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(body, &result) == nil && result.Message != "" {
+		e.Message = result.Message
+	} else if !bytes.HasPrefix(bytes.TrimSpace(body), []byte("{")) {
+		e.Message = strings.TrimSpace(string(body))
+	}
+	return e
+}
+
+// getJSON sends the request and decodes the response into v.
+func (c *Client) getJSON(ctx context.Context, req *request, v interface{}) error {
+	resp, err := c.do(ctx, req)
+	if err != nil {
+		return err
+	}
+	return decode(resp, v)
+}
+
+// decode decodes the JSON response into v, and closes it.
+func decode(resp *http.Response, v interface{}) error {
+	defer resp.Body.Close()
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// newIdempotencyKey returns a random key, so that a create that is retried
+// after the pastebin created the pastes does not create them again.
+func newIdempotencyKey() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
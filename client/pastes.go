@@ -0,0 +1,202 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/espebra/pastebin/apitypes"
+)
+
+// Paste is a paste read from the pastebin.
+type Paste = apitypes.Paste
+
+// PasteMeta is what the pastebin knows about a paste, without its content.
+type PasteMeta = apitypes.PasteMeta
+
+// PasteSummary is a paste in a list of pastes.
+type PasteSummary = apitypes.PasteSummary
+
+// Result is the result of creating or deleting a paste.
+type Result = apitypes.BatchResult
+
+// SignedURL is a raw URL of a paste that is valid until it expires.
+type SignedURL = apitypes.SignedURL
+
+// Options are the options of a paste being created.
+type Options struct {
+	// public, unlisted or private, or the default of the pastebin
+	Visibility string
+	Tags       []string
+	// Deletes the paste as well as the delete token, if set
+	DeletePassphrase string
+	// Language of text pastes, instead of the detected one
+	Language string
+}
+
+func (o *Options) query() string {
+	v := url.Values{}
+	if o != nil && o.Visibility != "" {
+		v.Set("visibility", o.Visibility)
+	}
+	if o != nil && len(o.Tags) > 0 {
+		v.Set("tags", strings.Join(o.Tags, ","))
+	}
+	if o != nil && o.Language != "" {
+		v.Set("lang", o.Language)
+	}
+	if len(v) == 0 {
+		return ""
+	}
+	return "?" + v.Encode()
+}
+
+// pastePath returns the path of the paste under prefix, with its key for
+// private pastes.
+func pastePath(prefix, checksum, key string) string {
+	path := prefix + "/" + url.PathEscape(checksum)
+	if key != "" {
+		path += "/" + url.PathEscape(key)
+	}
+	return path
+}
+
+// Create creates a paste and returns its URL and delete token. The
+// pastebin checks that it received the content it was sent.
+func (c *Client) Create(ctx context.Context, content []byte, opts *Options) (*Result, error) {
+	sum := sha256.Sum256(content)
+	req := newRequest("PUT", "/q"+opts.query())
+	req.body = content
+	req.header.Set("Accept", "text/plain")
+	req.header.Set("Content-Type", "text/plain")
+	req.header.Set("X-Content-SHA256", hex.EncodeToString(sum[:]))
+	if opts != nil && opts.DeletePassphrase != "" {
+		req.header.Set("X-Delete-Passphrase", opts.DeletePassphrase)
+	}
+	resp, err := c.create(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{
+		Checksum:    hex.EncodeToString(sum[:]),
+		URL:         strings.TrimSpace(string(body)),
+		DeleteToken: resp.Header.Get("X-Delete-Token"),
+		Duplicate:   resp.Header.Get("X-Duplicate") == "true",
+		Status:      "success",
+	}
+	return result, nil
+}
+
+// CreateBatch creates a paste of each of the contents with the default
+// visibility of the pastebin. The pastes that could not be created have
+// the status error and a message in their result.
+func (c *Client) CreateBatch(ctx context.Context, contents []string) ([]Result, error) {
+	type batchPaste struct {
+		Content string `json:"content"`
+	}
+	pastes := make([]batchPaste, len(contents))
+	for i, content := range contents {
+		pastes[i].Content = content
+	}
+	req := newRequest("POST", "/api/v1/pastes/batch")
+	if err := req.json(pastes); err != nil {
+		return nil, err
+	}
+	resp, err := c.create(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	var results []Result
+	err = decode(resp, &results)
+	return results, err
+}
+
+// Get returns the paste, with the key of private pastes.
+func (c *Client) Get(ctx context.Context, checksum, key string) (*Paste, error) {
+	var p Paste
+	if err := c.getJSON(ctx, newRequest("GET", pastePath("/api/v1/pastes", checksum, key)), &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Meta returns the metadata of the paste without reading the paste.
+func (c *Client) Meta(ctx context.Context, checksum, key string) (*PasteMeta, error) {
+	var m PasteMeta
+	if err := c.getJSON(ctx, newRequest("GET", pastePath("/api/v1/pastes", checksum, key)+"/meta"), &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// List returns the public pastes with the tag.
+func (c *Client) List(ctx context.Context, tag string) ([]PasteSummary, error) {
+	var pastes []PasteSummary
+	err := c.getJSON(ctx, newRequest("GET", "/api/v1/pastes?tag="+url.QueryEscape(tag)), &pastes)
+	return pastes, err
+}
+
+// Delete deletes the paste with its delete token or passphrase.
+func (c *Client) Delete(ctx context.Context, checksum, token string) error {
+	req := newRequest("DELETE", pastePath("/api/v1/pastes", checksum, ""))
+	req.header.Set("X-Delete-Token", token)
+	resp, err := c.do(ctx, req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// SignURL returns a raw URL of the paste that can be downloaded without
+// the key until it expires after ttl, or the longest the pastebin allows
+// when ttl is 0.
+func (c *Client) SignURL(ctx context.Context, checksum, key string, ttl time.Duration) (*SignedURL, error) {
+	path := pastePath("/api/v1/pastes", checksum, key) + "/signed-url"
+	if ttl > 0 {
+		path += "?ttl=" + url.QueryEscape(ttl.String())
+	}
+	var signed SignedURL
+	if err := c.getJSON(ctx, newRequest("POST", path), &signed); err != nil {
+		return nil, err
+	}
+	return &signed, nil
+}
+
+// Clone returns a draft of a new paste from the paste, with its content,
+// language and tags, to edit and create.
+func (c *Client) Clone(ctx context.Context, checksum, key string) (*Paste, error) {
+	var p Paste
+	if err := c.getJSON(ctx, newRequest("POST", pastePath("/api/v1/pastes", checksum, key)+"/clone"), &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Download returns the content of the paste as it is read from the
+// pastebin, for pastes too large to keep in memory. Only the request is
+// retried, not the reading of the content. The caller must close it.
+func (c *Client) Download(ctx context.Context, checksum, key string) (io.ReadCloser, error) {
+	req := newRequest("GET", pastePath("/raw", checksum, key))
+	req.header.Set("Accept", "*/*")
+	resp, err := c.do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, &Error{StatusCode: resp.StatusCode}
+	}
+	return resp.Body, nil
+}
@@ -0,0 +1,69 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"math/bits"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/espebra/pastebin/apitypes"
+)
+
+// needsPow reports whether the pastebin refused a request for the lack of
+// proof of work.
+func needsPow(err error) bool {
+	e, ok := err.(*Error)
+	return ok && e.StatusCode == http.StatusForbidden && strings.Contains(e.Message, "proof of work")
+}
+
+// solvePow asks the pastebin for a challenge and finds a nonce such that
+// the SHA-256 of the challenge, a colon and the nonce starts with as many
+// zero bits as its difficulty.
+func (c *Client) solvePow(ctx context.Context) (apitypes.PowChallenge, string, error) {
+	var ch apitypes.PowChallenge
+	if err := c.getJSON(ctx, newRequest("GET", "/api/v1/challenge"), &ch); err != nil {
+		return ch, "", err
+	}
+	for n := 0; ; n++ {
+		if n%4096 == 0 && ctx.Err() != nil {
+			return ch, "", ctx.Err()
+		}
+		nonce := strconv.Itoa(n)
+		sum := sha256.Sum256([]byte(ch.Challenge + ":" + nonce))
+		if leadingZeroBits(sum[:]) >= ch.Difficulty {
+			return ch, nonce, nil
+		}
+	}
+}
+
+func leadingZeroBits(sum []byte) int {
+	n := 0
+	for _, b := range sum {
+		if b != 0 {
+			return n + bits.LeadingZeros8(b)
+		}
+		n += 8
+	}
+	return n
+}
+
+// create sends a request creating pastes with an idempotency key. When the
+// pastebin requires proof of work, it is solved and the request sent again
+// with a new key, as the refusal is kept for the first.
+func (c *Client) create(ctx context.Context, req *request) (*http.Response, error) {
+	req.header.Set("Idempotency-Key", newIdempotencyKey())
+	resp, err := c.do(ctx, req)
+	if !needsPow(err) {
+		return resp, err
+	}
+	ch, nonce, err := c.solvePow(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req.header.Set("Idempotency-Key", newIdempotencyKey())
+	req.header.Set("X-PoW-Challenge", ch.Challenge)
+	req.header.Set("X-PoW-Nonce", nonce)
+	return c.do(ctx, req)
+}
@@ -0,0 +1,127 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/espebra/pastebin/apitypes"
+)
+
+// ChunkSize is the size of the chunks of uploads, the largest the
+// pastebin accepts.
+const ChunkSize = 8 << 20
+
+// Upload is an upload of a paste in chunks.
+type Upload = apitypes.Upload
+
+// ErrUploadRestarted is returned when the pastebin started an upload over,
+// as its chunks did not match its checksum, which a reader can not be
+// read again for.
+var ErrUploadRestarted = errors.New("pastebin: the upload did not match its checksum and was started over")
+
+// Upload creates a paste of length bytes read from r, sending it in chunks
+// so that pastes larger than a request can be created, and a chunk that
+// fails is sent again from where the pastebin received it. The SHA-256 of
+// the paste, in hex, is checked by the pastebin when given.
+func (c *Client) Upload(ctx context.Context, r io.Reader, length int64, checksum string, opts *Options) (*Result, error) {
+	start := apitypes.UploadRequest{Length: length, SHA256: checksum}
+	if opts != nil {
+		start.Visibility = opts.Visibility
+		start.Tags = strings.Join(opts.Tags, ",")
+	}
+	req := newRequest("POST", "/api/v1/uploads")
+	if err := req.json(start); err != nil {
+		return nil, err
+	}
+	resp, err := c.create(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	var u Upload
+	if err := decode(resp, &u); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, ChunkSize)
+	for u.Checksum == "" {
+		n, err := io.ReadFull(r, buf[:chunkLength(u)])
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return nil, err
+		}
+		if int64(n) < chunkLength(u) {
+			return nil, fmt.Errorf("pastebin: the upload ended after %d of %d bytes", u.Offset+int64(n), u.Length)
+		}
+		offset := u.Offset
+		u, err = c.sendChunk(ctx, u, buf[:n])
+		if err != nil {
+			return nil, err
+		}
+		// The part of the chunk the pastebin did not receive is sent again
+		received := int(u.Offset - offset)
+		for u.Checksum == "" && received < n {
+			if received < 0 {
+				return nil, ErrUploadRestarted
+			}
+			offset = u.Offset
+			if u, err = c.sendChunk(ctx, u, buf[received:n]); err != nil {
+				return nil, err
+			}
+			received += int(u.Offset - offset)
+		}
+	}
+	return &Result{
+		Checksum:    u.Checksum,
+		URL:         u.URL,
+		DeleteToken: u.DeleteToken,
+		Status:      "success",
+	}, nil
+}
+
+// chunkLength returns the length of the next chunk of the upload.
+func chunkLength(u Upload) int64 {
+	if n := u.Length - u.Offset; n < ChunkSize {
+		return n
+	}
+	return ChunkSize
+}
+
+// sendChunk appends the chunk to the upload at its offset, and returns the
+// upload as the pastebin has it afterwards. When the offset does not match
+// the upload, as when the response to a chunk that was received is lost,
+// the upload is returned as it is, to continue from its offset.
+func (c *Client) sendChunk(ctx context.Context, u Upload, chunk []byte) (Upload, error) {
+	req := newRequest("PATCH", "/api/v1/uploads/"+url.PathEscape(u.ID))
+	req.body = chunk
+	req.header.Set("Content-Type", "application/offset+octet-stream")
+	req.header.Set("Upload-Offset", strconv.FormatInt(u.Offset, 10))
+	resp, err := c.do(ctx, req)
+	if e, ok := err.(*Error); ok && e.StatusCode == http.StatusConflict {
+		return c.GetUpload(ctx, u.ID)
+	}
+	if e, ok := err.(*Error); ok && e.StatusCode == http.StatusBadRequest && len(chunk) > 0 && u.Offset+int64(len(chunk)) == u.Length {
+		if current, err := c.GetUpload(ctx, u.ID); err == nil && current.Offset == 0 {
+			return u, ErrUploadRestarted
+		}
+	}
+	if err != nil {
+		return u, err
+	}
+	var next Upload
+	if err := decode(resp, &next); err != nil {
+		return u, err
+	}
+	return next, nil
+}
+
+// GetUpload returns the upload, to resume it from its offset.
+func (c *Client) GetUpload(ctx context.Context, id string) (Upload, error) {
+	var u Upload
+	err := c.getJSON(ctx, newRequest("GET", "/api/v1/uploads/"+url.PathEscape(id)), &u)
+	return u, err
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/espebra/pastebin/pastebin"
+	"github.com/gorilla/mux"
+)
+
+// Limits of comments, to keep the sidecar records small.
+const (
+	maxCommentLength = 4000
+	maxAuthorLength  = 64
+	maxComments      = 500
+)
+
+var (
+	errInvalidComment  = errors.New("comments must be 1 to 4000 characters")
+	errTooManyComments = errors.New("the paste has too many comments")
+	errNoSuchComment   = errors.New("no such comment")
+)
+
+// Comment is a comment on a paste. Replies refer to the comment they
+// answer as their parent.
+type Comment struct {
+	ID      string    `json:"id"`
+	Parent  string    `json:"parent,omitempty"`
+	Author  string    `json:"author,omitempty"`
+	Body    string    `json:"body"`
+	Created time.Time `json:"created"`
+	Hidden  bool      `json:"hidden,omitempty"`
+
+	// Depth of the comment in its thread, as listed on the paste page
+	Depth   int `json:"-"`
+	replies []*Comment
+}
+
+// Comments are the comments on a paste, stored in a sidecar record next to
+// the paste. The revision changes with every update, and is part of the
+// ETag of the paste page.
+type Comments struct {
+	Revision int       `json:"revision"`
+	Comments []Comment `json:"comments"`
+}
+
+// Updates of comments read, modify and write the record, so they are
+// serialized.
+var commentsMu sync.Mutex
+
+var commentLimiter *rateLimiter
+
+func commentsKey(key string) string {
+	return "comments-" + key
+}
+
+// retrieveComments reads the comments kept under the scope of the paste
+// from whichever storage key they are stored under.
+func retrieveComments(scope string) (Comments, error) {
+	var c Comments
+	var err error
+	for _, key := range service.ScopeKeys(scope) {
+		if err = retrieveJSON(commentsKey(key), &c); err == nil {
+			return c, nil
+		}
+	}
+	return c, err
+}
+
+// updateComments applies fn to the comments kept under the scope of the
+// paste and stores the result.
+func updateComments(scope string, fn func(c *Comments) error) error {
+	commentsMu.Lock()
+	defer commentsMu.Unlock()
+	c, _ := retrieveComments(scope)
+	if err := fn(&c); err != nil {
+		return err
+	}
+	c.Revision++
+	return storeJSON(commentsKey(service.ScopeKey(scope)), c)
+}
+
+// thread lists the visible comments with each comment followed by its
+// replies, oldest first. Replies to hidden comments are hidden along with
+// them.
+func (c Comments) thread() []*Comment {
+	nodes := make(map[string]*Comment)
+	var roots []*Comment
+	for i := range c.Comments {
+		comment := c.Comments[i]
+		if comment.Hidden {
+			continue
+		}
+		node := &comment
+		nodes[comment.ID] = node
+		if comment.Parent == "" {
+			roots = append(roots, node)
+		} else if parent, ok := nodes[comment.Parent]; ok {
+			node.Depth = parent.Depth + 1
+			parent.replies = append(parent.replies, node)
+		}
+	}
+
+	var list []*Comment
+	var walk func(nodes []*Comment)
+	walk = func(nodes []*Comment) {
+		for _, node := range nodes {
+			list = append(list, node)
+			walk(node.replies)
+		}
+	}
+	walk(roots)
+	return list
+}
+
+// sameOrigin reports whether a form post comes from this site. Browsers
+// send the Origin header with posts, which stops cross-site requests from
+// commenting or moderating on behalf of visitors.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		origin = r.Header.Get("Referer")
+	}
+	if origin == "" {
+		// Not sent by a browser
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host == r.Host
+}
+
+// addComment stores a comment under the scope of the paste.
+func addComment(scope, parent, author, body string) error {
+	body = strings.TrimSpace(body)
+	author = strings.TrimSpace(author)
+	if body == "" || len(body) > maxCommentLength {
+		return errInvalidComment
+	}
+	if len(author) > maxAuthorLength {
+		author = author[:maxAuthorLength]
+	}
+	id, err := pastebin.NewToken()
+	if err != nil {
+		return err
+	}
+	return updateComments(scope, func(c *Comments) error {
+		if len(c.Comments) >= maxComments {
+			return errTooManyComments
+		}
+		if parent != "" {
+			found := false
+			for _, comment := range c.Comments {
+				if comment.ID == parent {
+					found = true
+				}
+			}
+			if !found {
+				return errNoSuchComment
+			}
+		}
+		c.Comments = append(c.Comments, Comment{
+			ID:      id[:12],
+			Parent:  parent,
+			Author:  author,
+			Body:    body,
+			Created: time.Now().UTC(),
+		})
+		return nil
+	})
+}
+
+// hideComment hides a comment and its replies. Only the owner of the
+// paste, who holds a delete token, can hide comments, and for pastes that
+// require a key only the comments of the creation the token is for.
+func hideComment(checksum, key, id, token string) error {
+	scope, ok := service.TokenScope(checksum, token)
+	if !ok || scope != service.Scope(checksum, key) {
+		return errInvalidDeleteToken
+	}
+	return updateComments(scope, func(c *Comments) error {
+		for i := range c.Comments {
+			if c.Comments[i].ID == id {
+				c.Comments[i].Hidden = true
+				return nil
+			}
+		}
+		return errNoSuchComment
+	})
+}
+
+// commentPaste adds a comment from the form on the paste page.
+func commentPaste(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	checksum := vars["checksum"]
+	key := r.FormValue("key")
+
+	if !*commentsFlag {
+		http.NotFound(w, r)
+		return
+	}
+	if !sameOrigin(r) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	if !commentLimiter.Allow(clientKey(clientIP(r))) {
+		http.Error(w, "Too many comments, try again later", http.StatusTooManyRequests)
+		return
+	}
+	if !service.Authorize(checksum, key) || !available(checksum) {
+		http.NotFound(w, r)
+		return
+	}
+
+	var p Paste
+	if err := addComment(service.Scope(checksum, key), r.FormValue("parent"), r.FormValue("author"), r.FormValue("comment")); err != nil {
+		log.Printf("Unable to comment on %s: %s\n", checksum, err)
+		w.WriteHeader(http.StatusBadRequest)
+		p.Message = "Unable to add comment: " + err.Error()
+		p.Status = "error"
+		renderPaste(w, r, p)
+		return
+	}
+
+	p = Paste{Checksum: checksum, Key: key}
+	http.Redirect(w, r, basePath()+p.Location()+"#comments", http.StatusSeeOther)
+}
+
+// moderateComment hides a comment, given the delete token of the paste.
+func moderateComment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	checksum := vars["checksum"]
+
+	if !*commentsFlag {
+		http.NotFound(w, r)
+		return
+	}
+	if !sameOrigin(r) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	var p Paste
+	if err := hideComment(checksum, r.FormValue("key"), vars["id"], deleteToken(r)); err != nil {
+		log.Printf("Unable to hide comment %s on %s: %s\n", vars["id"], checksum, err)
+		w.WriteHeader(http.StatusForbidden)
+		p.Message = "Unable to hide comment: " + err.Error()
+		p.Status = "error"
+		renderPaste(w, r, p)
+		return
+	}
+
+	p = Paste{Checksum: checksum, Key: r.FormValue("key")}
+	http.Redirect(w, r, basePath()+p.Location()+"#comments", http.StatusSeeOther)
+}
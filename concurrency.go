@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// concurrencyLimit caps the requests in flight to the paths with a prefix.
+// The prefix * matches the paths no other prefix does.
+type concurrencyLimit struct {
+	Prefix string
+	Slots  chan struct{}
+}
+
+// parseConcurrencyLimits reads -concurrency-limits, given as prefix=limit
+// separated by commas, longest prefix first.
+func parseConcurrencyLimits(s string) ([]concurrencyLimit, error) {
+	var limits []concurrencyLimit
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		prefix, value, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("concurrency limit %q is not given as prefix=limit", item)
+		}
+		prefix = strings.TrimSpace(prefix)
+		if prefix != "*" && !strings.HasPrefix(prefix, "/") {
+			return nil, fmt.Errorf("concurrency limit %q must be for a path starting with / or for *", item)
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("concurrency limit %q must be a positive number", item)
+		}
+		limits = append(limits, concurrencyLimit{Prefix: prefix, Slots: make(chan struct{}, n)})
+	}
+	sort.SliceStable(limits, func(i, j int) bool {
+		if limits[j].Prefix == "*" {
+			return limits[i].Prefix != "*"
+		}
+		return limits[i].Prefix != "*" && len(limits[i].Prefix) > len(limits[j].Prefix)
+	})
+	return limits, nil
+}
+
+// concurrencyMiddleware limits the requests in flight per path prefix to
+// -concurrency-limits, so that spikes of storage heavy requests can not
+// exhaust the storage or memory. Requests over the limit wait up to
+// -concurrency-wait for a slot, and are then shed with 503 and a
+// Retry-After header.
+func concurrencyMiddleware() Middleware {
+	limits, _ := parseConcurrencyLimits(*concurrencyLimitsFlag)
+	if len(limits) == 0 {
+		return passThrough
+	}
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var slots chan struct{}
+			for _, l := range limits {
+				if l.Prefix == "*" || strings.HasPrefix(r.URL.Path, l.Prefix) {
+					slots = l.Slots
+					break
+				}
+			}
+			if slots == nil {
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			select {
+			case slots <- struct{}{}:
+			default:
+				timer := time.NewTimer(*concurrencyWaitFlag)
+				select {
+				case slots <- struct{}{}:
+					timer.Stop()
+				case <-timer.C:
+					w.Header().Set("Retry-After", "1")
+					http.Error(w, "The server is busy, try again later", http.StatusServiceUnavailable)
+					return
+				case <-r.Context().Done():
+					timer.Stop()
+					return
+				}
+			}
+			defer func() { <-slots }()
+			h.ServeHTTP(w, r)
+		})
+	}
+}
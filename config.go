@@ -0,0 +1,537 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/espebra/pastebin/pastebin"
+)
+
+// reloadableInt is an integer flag that can change while the server
+// runs, when the config file is reloaded.
+type reloadableInt struct {
+	v atomic.Int64
+}
+
+func reloadableIntFlag(name string, value int64, usage string) *reloadableInt {
+	f := &reloadableInt{}
+	f.v.Store(value)
+	flag.Var(f, name, usage)
+	return f
+}
+
+func (f *reloadableInt) String() string {
+	return strconv.FormatInt(f.v.Load(), 10)
+}
+
+func (f *reloadableInt) Set(s string) error {
+	n, err := strconv.ParseInt(s, 0, 64)
+	if err != nil {
+		return err
+	}
+	// Checked here as well as at startup, since reloads are not validated
+	if n < 0 {
+		return errors.New("can not be negative")
+	}
+	f.v.Store(n)
+	return nil
+}
+
+func (f *reloadableInt) Get() int64 {
+	return f.v.Load()
+}
+
+func (f *reloadableInt) Int() int {
+	return int(f.v.Load())
+}
+
+// Flags that take effect when the config file is reloaded. Other flags
+// are only read at startup.
+var reloadableFlags = map[string]bool{
+	"max-paste-size": true,
+	"image-max-size": true,
+	"pow-difficulty": true,
+	"rate-limit":     true,
+	"comment-rate":   true,
+	"tcp-rate":       true,
+}
+
+// Where each flag got its value from, guarded by configMu.
+var (
+	configMu      sync.Mutex
+	configSources = map[string]string{}
+)
+
+// readConfigFile returns the flag values in the config file. The file is
+// TOML: options are given as name = value, and options in a [table] are
+// named table-name, so that origins in [cors] sets -cors-origins.
+// Underscores in names are read as hyphens. Arrays are joined with commas
+// for the flags that take lists.
+func readConfigFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	values := map[string]string{}
+	table := ""
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			name, rest, ok := strings.Cut(line[1:], "]")
+			if !ok || !emptyOrComment(rest) || strings.HasPrefix(name, "[") {
+				return nil, fmt.Errorf("%s:%d: invalid table", path, n)
+			}
+			table = strings.ReplaceAll(strings.TrimSpace(name), ".", "-")
+			continue
+		}
+		i := strings.Index(line, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("%s:%d: expected name = value", path, n)
+		}
+		name := strings.TrimSpace(line[:i])
+		if unquoted, err := strconv.Unquote(name); err == nil {
+			name = unquoted
+		}
+		if table != "" {
+			name = table + "-" + name
+		}
+		name = strings.ReplaceAll(name, "_", "-")
+		if flag.Lookup(name) == nil || name == "config" {
+			return nil, fmt.Errorf("%s:%d: unknown option %s", path, n, name)
+		}
+		value, err := parseConfigValue(strings.TrimSpace(line[i+1:]))
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %s: %s", path, n, name, err)
+		}
+		values[name] = value
+	}
+	return values, scanner.Err()
+}
+
+// parseConfigValue returns the flag value of a TOML value: a string,
+// number, boolean or an array of those. Values that span lines are not
+// supported. Bare words, like 10s, are taken as they are.
+func parseConfigValue(s string) (string, error) {
+	if !strings.HasPrefix(s, "[") {
+		value, rest, err := scanConfigValue(s, "#")
+		if err != nil {
+			return "", err
+		}
+		if !emptyOrComment(rest) {
+			return "", fmt.Errorf("unexpected %s", rest)
+		}
+		return value, nil
+	}
+
+	var items []string
+	s = strings.TrimSpace(s[1:])
+	for !strings.HasPrefix(s, "]") {
+		if s == "" || strings.HasPrefix(s, "#") {
+			return "", errors.New("arrays must be given on one line")
+		}
+		value, rest, err := scanConfigValue(s, ",]#")
+		if err != nil {
+			return "", err
+		}
+		items = append(items, value)
+		s = strings.TrimSpace(rest)
+		if strings.HasPrefix(s, ",") {
+			s = strings.TrimSpace(s[1:])
+		} else if emptyOrComment(s) {
+			return "", errors.New("arrays must be given on one line")
+		} else if !strings.HasPrefix(s, "]") {
+			return "", errors.New("expected , or ] in array")
+		}
+	}
+	if !emptyOrComment(s[1:]) {
+		return "", fmt.Errorf("unexpected %s", s[1:])
+	}
+	return strings.Join(items, ","), nil
+}
+
+// scanConfigValue reads a single value from the start of s, and returns
+// it with the rest of s. Bare values end at any of the stop characters.
+func scanConfigValue(s, stop string) (string, string, error) {
+	switch {
+	case strings.HasPrefix(s, "'"):
+		i := strings.Index(s[1:], "'")
+		if i < 0 {
+			return "", "", errors.New("unterminated string")
+		}
+		return s[1 : i+1], s[i+2:], nil
+	case strings.HasPrefix(s, `"`):
+		for i := 1; i < len(s); i++ {
+			switch s[i] {
+			case '\\':
+				i++
+			case '"':
+				value, err := strconv.Unquote(s[:i+1])
+				if err != nil {
+					return "", "", fmt.Errorf("invalid string %s", s[:i+1])
+				}
+				return value, s[i+1:], nil
+			}
+		}
+		return "", "", errors.New("unterminated string")
+	}
+
+	end := len(s)
+	if i := strings.IndexAny(s, stop); i >= 0 {
+		end = i
+	}
+	value := strings.TrimSpace(s[:end])
+	if value == "" {
+		return "", "", errors.New("missing value")
+	}
+	if integerPattern.MatchString(value) {
+		value = strings.ReplaceAll(value, "_", "")
+	}
+	return value, s[end:], nil
+}
+
+// TOML integers may group digits with underscores, like 1_000_000.
+var integerPattern = regexp.MustCompile(`^[+-]?[0-9]+(_[0-9]+)*$`)
+
+func emptyOrComment(s string) bool {
+	s = strings.TrimSpace(s)
+	return s == "" || strings.HasPrefix(s, "#")
+}
+
+// envName returns the environment variable that sets the flag, like
+// PASTEBIN_MAX_PASTE_SIZE for -max-paste-size.
+func envName(name string) string {
+	return "PASTEBIN_" + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name))
+}
+
+// loadEnv sets the flags that were not given on the command line from
+// the environment. The environment is only read at startup. PASTEBIN_
+// variables that do not name an option are refused, rather than silently
+// ignored.
+//
+// Secrets can also be read from the file named by the variable with
+// _FILE appended, like PASTEBIN_SECRET_FILE, as mounted by Docker and
+// Kubernetes secrets.
+func loadEnv() error {
+	configMu.Lock()
+	defer configMu.Unlock()
+	var errs []string
+	known := map[string]bool{}
+	flag.VisitAll(func(f *flag.Flag) {
+		known[envName(f.Name)] = true
+		if secretOption(f.Name) {
+			known[envName(f.Name)+"_FILE"] = true
+		}
+	})
+	for _, env := range os.Environ() {
+		name, _, _ := strings.Cut(env, "=")
+		if strings.HasPrefix(name, "PASTEBIN_") && !known[name] {
+			errs = append(errs, fmt.Sprintf("unknown option %s", name))
+		}
+	}
+	flag.VisitAll(func(f *flag.Flag) {
+		if configSources[f.Name] == "command line" {
+			return
+		}
+		value, ok := os.LookupEnv(envName(f.Name))
+		source := "environment"
+		if path, isFile := os.LookupEnv(envName(f.Name) + "_FILE"); isFile && secretOption(f.Name) {
+			if ok {
+				errs = append(errs, fmt.Sprintf("both %s and %s_FILE are set", envName(f.Name), envName(f.Name)))
+				return
+			}
+			b, err := os.ReadFile(path)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("unable to read %s_FILE: %s", envName(f.Name), err))
+				return
+			}
+			// Files written by editors and echo end with a newline
+			value, ok, source = strings.TrimRight(string(b), "\r\n"), true, "secret file"
+		}
+		if !ok {
+			return
+		}
+		if err := f.Value.Set(value); err != nil {
+			errs = append(errs, fmt.Sprintf("invalid value for %s: %s", envName(f.Name), err))
+			return
+		}
+		configSources[f.Name] = source
+	})
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// loadConfig sets the flags from -config that were not given on the
+// command line or in the environment, which take precedence. When
+// reloading, only reloadable flags change, and those left out of the file
+// return to their defaults.
+func loadConfig(reload bool) error {
+	values, err := readConfigFile(*configFlag)
+	if err != nil {
+		return err
+	}
+
+	configMu.Lock()
+	defer configMu.Unlock()
+	var errs []string
+	flag.VisitAll(func(f *flag.Flag) {
+		if source := configSources[f.Name]; source == "command line" || source == "environment" {
+			return
+		}
+		value, ok := values[f.Name]
+		source := "config file"
+		if !ok {
+			if !reload || !reloadableFlags[f.Name] {
+				return
+			}
+			value, source = f.DefValue, "default"
+		}
+		if reload && !reloadableFlags[f.Name] {
+			if value != f.Value.String() {
+				log.Printf("Changing -%s requires a restart\n", f.Name)
+			}
+			return
+		}
+		if err := f.Value.Set(value); err != nil {
+			errs = append(errs, fmt.Sprintf("invalid value %q for %s: %s", value, f.Name, err))
+			return
+		}
+		configSources[f.Name] = source
+	})
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// initConfig records which flags were given on the command line and
+// applies the environment and the config file, if any.
+func initConfig() error {
+	flag.Visit(func(f *flag.Flag) {
+		configSources[f.Name] = "command line"
+	})
+	if err := loadEnv(); err != nil {
+		return err
+	}
+	if *configFlag == "" {
+		return nil
+	}
+	return loadConfig(false)
+}
+
+// validateConfig checks the options that can not be checked as they are
+// parsed, since they depend on each other or have a limited range, and
+// returns every problem found.
+func validateConfig() error {
+	var errs []string
+	flag.VisitAll(func(f *flag.Flag) {
+		if negativeValue(f.Value) {
+			errs = append(errs, fmt.Sprintf("-%s can not be negative", f.Name))
+		}
+	})
+	if *bindPortFlag > 65535 {
+		errs = append(errs, fmt.Sprintf("-port %d is not a valid port", *bindPortFlag))
+	}
+	if _, ok := captchaProviders[*captchaProviderFlag]; *captchaProviderFlag != "" && !ok {
+		errs = append(errs, fmt.Sprintf("unknown CAPTCHA provider %s", *captchaProviderFlag))
+	}
+	if *captchaProviderFlag != "" && *tcpListenFlag != "" && !*captchaExemptAPIFlag {
+		errs = append(errs, "pastes received over -tcp-listen can not have a CAPTCHA, so it requires -captcha-exempt-api")
+	}
+	switch *spamActionFlag {
+	case "reject", "flag", "off":
+	default:
+		errs = append(errs, fmt.Sprintf("unknown spam action %s", *spamActionFlag))
+	}
+	if !pastebin.ValidVisibility(*defaultVisibilityFlag) {
+		errs = append(errs, fmt.Sprintf("unknown visibility %s", *defaultVisibilityFlag))
+	}
+	if (*privateFlag || *defaultVisibilityFlag == "private") && *secretFlag == "" {
+		errs = append(errs, "a secret is required when paste URLs are private")
+	}
+	for _, name := range strings.Split(*middlewareFlag, ",") {
+		name = strings.TrimSpace(name)
+		if _, ok := middlewares[name]; !ok && name != "" {
+			errs = append(errs, fmt.Sprintf("unknown middleware %s", name))
+		}
+	}
+	if _, err := parseFooterLinks(*footerLinksFlag); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if err := checkAssetsDir(); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if err := loadVirtualHosts(); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if err := checkDebugListen(); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if _, err := parseConcurrencyLimits(*concurrencyLimitsFlag); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if _, err := parseHotlinkHosts(*hotlinkAllowFlag); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if _, err := parseTrustedProxies(*trustedProxiesFlag); err != nil {
+		errs = append(errs, err.Error())
+	} else if *proxyProtocolFlag && *trustedProxiesFlag == "" {
+		errs = append(errs, "-proxy-protocol requires -trusted-proxies")
+	}
+	if *ipv6PrefixFlag < 1 || *ipv6PrefixFlag > 128 {
+		errs = append(errs, fmt.Sprintf("-ipv6-prefix %d must be from 1 to 128", *ipv6PrefixFlag))
+	}
+	if *purgeWorkersFlag < 1 {
+		errs = append(errs, "-purge-workers must be at least 1")
+	}
+	if _, err := parseUsers(*usersFlag); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if permissions, err := parsePermissions(*permissionsFlag); err != nil {
+		errs = append(errs, err.Error())
+	} else if permissions["create"] != roleAnonymous && *tcpListenFlag != "" {
+		errs = append(errs, "pastes received with -tcp-listen can not require signing in to create")
+	}
+	if *devSeedFlag && !*devFlag {
+		errs = append(errs, "-dev-seed requires -dev")
+	}
+	if err := checkAnonymous(); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// negativeValue reports whether a numeric or duration flag is negative.
+// Sizes, counts, rates and intervals all use 0 to mean disabled or
+// unlimited, so a negative value is always a mistake.
+func negativeValue(v flag.Value) bool {
+	if f, ok := v.(*reloadableInt); ok {
+		return f.Get() < 0
+	}
+	getter, ok := v.(flag.Getter)
+	if !ok {
+		return false
+	}
+	switch n := getter.Get().(type) {
+	case int:
+		return n < 0
+	case int64:
+		return n < 0
+	case time.Duration:
+		return n < 0
+	}
+	return false
+}
+
+// runConfigCommand runs pastebin config validate, which loads the options
+// like the server does and reports the first problem found.
+func runConfigCommand(args []string) bool {
+	if len(args) == 0 || args[0] != "config" {
+		return false
+	}
+	if len(args) < 2 || args[1] != "validate" {
+		log.Fatal("Usage: pastebin [-config file] config validate")
+	}
+	if err := initConfig(); err != nil {
+		log.Fatalf("Invalid configuration: %s\n", err)
+	}
+	if err := validateConfig(); err != nil {
+		log.Fatalf("Invalid configuration: %s\n", err)
+	}
+	fmt.Println("The configuration is valid")
+	return true
+}
+
+// reloadOnSignal reloads the config file and the virtual hosts on
+// SIGHUP. Requests in flight are not interrupted, and see the new values
+// as they read them. systemd is told while the reload runs.
+func reloadOnSignal() {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	for range hup {
+		sdNotify("RELOADING=1")
+		reload()
+		sdNotify("READY=1")
+	}
+}
+
+func reload() {
+	if *virtualHostsDirFlag != "" {
+		if err := loadVirtualHosts(); err != nil {
+			log.Printf("Unable to reload the virtual hosts: %s\n", err)
+		} else {
+			log.Println("Reloaded the virtual hosts")
+		}
+	}
+	if *configFlag == "" {
+		if *virtualHostsDirFlag == "" {
+			log.Println("Received SIGHUP, but there is no config file to reload")
+		}
+		return
+	}
+	if err := loadConfig(true); err != nil {
+		log.Printf("Unable to reload the config file: %s\n", err)
+		return
+	}
+	log.Println("Reloaded the config file")
+	audit(nil, "reload-config", *configFlag, "")
+}
+
+// ConfigValue is the effective value of an option.
+type ConfigValue struct {
+	Value      string `json:"value"`
+	Source     string `json:"source"`
+	Reloadable bool   `json:"reloadable,omitempty"`
+}
+
+// secretOption reports whether the value of the option must not be
+// shown.
+func secretOption(name string) bool {
+	return name == "auth" || name == "legacy-api-keys" || name == "users" || strings.Contains(name, "secret") || strings.Contains(name, "password")
+}
+
+// apiConfig returns the effective value of every option, with secrets
+// left out.
+func apiConfig(w http.ResponseWriter, r *http.Request) {
+	config := map[string]ConfigValue{}
+	configMu.Lock()
+	flag.VisitAll(func(f *flag.Flag) {
+		v := ConfigValue{
+			Value:      f.Value.String(),
+			Source:     configSources[f.Name],
+			Reloadable: reloadableFlags[f.Name],
+		}
+		if v.Source == "" {
+			v.Source = "default"
+		}
+		if secretOption(f.Name) && v.Value != "" {
+			v.Value = "(hidden)"
+		}
+		config[f.Name] = v
+	})
+	configMu.Unlock()
+	writeJSON(w, http.StatusOK, config)
+}
@@ -0,0 +1,17 @@
+package main
+
+import (
+	"encoding/hex"
+)
+
+// Number of bytes of binary pastes shown as a hexdump on the paste page.
+const hexdumpLength = 4096
+
+// hexdump returns a hexdump of the start of binary content, for showing it
+// on the paste page.
+func hexdump(content string) string {
+	if len(content) > hexdumpLength {
+		content = content[:hexdumpLength]
+	}
+	return hex.Dump([]byte(content))
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/espebra/pastebin/apitypes"
+)
+
+// checkContract checks the response of the operation against its
+// description in the OpenAPI document: the status must be one of the
+// operation, or an error answered with the default response, and a JSON
+// body must match the schema of that response.
+func checkContract(t *testing.T, spec map[string]interface{}, id string, rec *httptest.ResponseRecorder) {
+	t.Helper()
+	var op apitypes.Operation
+	for _, o := range apitypes.Operations {
+		if o.ID == id {
+			op = o
+		}
+	}
+	if op.ID == "" {
+		t.Fatalf("No operation %s", id)
+	}
+	paths := spec["paths"].(map[string]interface{})
+	operation := paths[op.Path].(map[string]interface{})[strings.ToLower(op.Method)].(map[string]interface{})
+	responses := operation["responses"].(map[string]interface{})
+	response, ok := responses[strconv.Itoa(rec.Code)].(map[string]interface{})
+	if !ok {
+		if rec.Code < 400 {
+			t.Fatalf("%s answered %d, which is not described: %s", id, rec.Code, rec.Body.String())
+		}
+		response = responses["default"].(map[string]interface{})
+	}
+
+	content, _ := response["content"].(map[string]interface{})
+	media, ok := content["application/json"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Errorf("%s answered with Content-Type %s, want application/json", id, ct)
+	}
+	var body interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("%s answered invalid JSON: %s", id, err)
+	}
+	schemas := spec["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+	for _, problem := range matchSchema(schemas, media["schema"].(map[string]interface{}), body, id) {
+		t.Error(problem)
+	}
+}
+
+// matchSchema returns how the decoded JSON value does not match the
+// schema. Fields missing from objects are fine, as none are required, but
+// fields the schema does not have are not.
+func matchSchema(schemas, schema map[string]interface{}, value interface{}, path string) []string {
+	if ref, ok := schema["$ref"].(string); ok {
+		schema = schemas[strings.TrimPrefix(ref, "#/components/schemas/")].(map[string]interface{})
+	}
+	if value == nil {
+		if schema["nullable"] == true {
+			return nil
+		}
+		return []string{path + " is null"}
+	}
+
+	var problems []string
+	switch schema["type"] {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return []string{path + " is not an object"}
+		}
+		properties := schema["properties"].(map[string]interface{})
+		for name, v := range object {
+			property, ok := properties[name].(map[string]interface{})
+			if !ok {
+				problems = append(problems, path+"."+name+" is not in the schema")
+				continue
+			}
+			problems = append(problems, matchSchema(schemas, property, v, path+"."+name)...)
+		}
+	case "array":
+		array, ok := value.([]interface{})
+		if !ok {
+			return []string{path + " is not an array"}
+		}
+		for i, v := range array {
+			problems = append(problems, matchSchema(schemas, schema["items"].(map[string]interface{}), v, path+"["+strconv.Itoa(i)+"]")...)
+		}
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return []string{path + " is not a string"}
+		}
+		if schema["format"] == "date-time" {
+			if _, err := time.Parse(time.RFC3339, s); err != nil {
+				problems = append(problems, path+" is not a date-time: "+err.Error())
+			}
+		}
+	case "integer":
+		if n, ok := value.(float64); !ok || n != math.Trunc(n) {
+			return []string{path + " is not an integer"}
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return []string{path + " is not a boolean"}
+		}
+	}
+	return problems
+}
+
+func TestAPIContract(t *testing.T) {
+	setFlag(t, secretFlag, "contract secret")
+	h := newTestServer(t)
+	spec := apitypes.OpenAPI("")
+	checked := map[string]bool{}
+	check := func(id string, rec *httptest.ResponseRecorder, status int) {
+		t.Helper()
+		if rec.Code != status {
+			t.Errorf("%s returned %d, want %d: %s", id, rec.Code, status, rec.Body.String())
+		}
+		checkContract(t, spec, id, rec)
+		checked[id] = true
+	}
+	accept := map[string]string{"Accept": "application/json", "User-Agent": "contract"}
+
+	rec := request(h, "POST", "/api/v1/pastes/batch", `[{"content":"batch\n"}]`, accept)
+	check("createPastes", rec, http.StatusCreated)
+	rec = request(h, "PUT", "/q?visibility=public&tags=contract", "contract\n", nil)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("PUT /q returned %d: %s", rec.Code, rec.Body.String())
+	}
+	token := rec.Header().Get("X-Delete-Token")
+	path := strings.TrimPrefix(strings.TrimSpace(rec.Body.String()), "http://example.com")
+
+	check("listPastes", request(h, "GET", "/api/v1/pastes?tag=contract", "", accept), http.StatusOK)
+	check("getPaste", request(h, "GET", "/api/v1/pastes"+path, "", accept), http.StatusOK)
+	check("getPasteMeta", request(h, "GET", "/api/v1/pastes"+path+"/meta", "", accept), http.StatusOK)
+	check("signURL", request(h, "POST", "/api/v1/pastes"+path+"/signed-url?ttl=1h", "", accept), http.StatusOK)
+	check("clonePaste", request(h, "POST", "/api/v1/pastes"+path+"/clone", "", accept), http.StatusOK)
+	check("downloadPaste", request(h, "GET", "/raw"+path, "", nil), http.StatusOK)
+	check("deletePaste", request(h, "DELETE", "/api/v1/pastes"+path, "", map[string]string{"X-Delete-Token": token}), http.StatusOK)
+	// Errors are answered with the default response
+	check("getPaste", request(h, "GET", "/api/v1/pastes/"+strings.Repeat("0", 64), "", accept), http.StatusNotFound)
+
+	rec = request(h, "PUT", "/q?visibility=private", "private contract\n", nil)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("PUT /q returned %d: %s", rec.Code, rec.Body.String())
+	}
+	check("quickPaste", rec, http.StatusCreated)
+	path = strings.TrimPrefix(strings.TrimSpace(rec.Body.String()), "http://example.com")
+	check("getPrivatePaste", request(h, "GET", "/api/v1/pastes"+path, "", accept), http.StatusOK)
+	check("getPrivatePasteMeta", request(h, "GET", "/api/v1/pastes"+path+"/meta", "", accept), http.StatusOK)
+	check("signPrivateURL", request(h, "POST", "/api/v1/pastes"+path+"/signed-url", "", accept), http.StatusOK)
+	check("clonePrivatePaste", request(h, "POST", "/api/v1/pastes"+path+"/clone", "", accept), http.StatusOK)
+	check("downloadPrivatePaste", request(h, "GET", "/raw"+path, "", nil), http.StatusOK)
+
+	rec = request(h, "POST", "/api/v1/uploads", `{"length":7}`, accept)
+	check("createUpload", rec, http.StatusCreated)
+	location := rec.Header().Get("Location")
+	check("getUpload", request(h, "GET", location, "", accept), http.StatusOK)
+	check("appendUpload", request(h, "PATCH", location, "upload\n", map[string]string{"Upload-Offset": "0"}), http.StatusCreated)
+
+	difficulty := powDifficultyFlag.String()
+	powDifficultyFlag.Set("4")
+	t.Cleanup(func() { powDifficultyFlag.Set(difficulty) })
+	check("getChallenge", request(h, "GET", "/api/v1/challenge", "", accept), http.StatusOK)
+
+	for _, op := range apitypes.Operations {
+		if !checked[op.ID] {
+			t.Errorf("The contract of %s is not tested", op.ID)
+		}
+	}
+}
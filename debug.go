@@ -0,0 +1,105 @@
+package main
+
+import (
+	"expvar"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"time"
+)
+
+// When the process started, for the uptime in the runtime statistics.
+var startTime = time.Now()
+
+// RuntimeStats is the response of the runtime statistics endpoint.
+type RuntimeStats struct {
+	GoVersion    string  `json:"go_version"`
+	Uptime       string  `json:"uptime"`
+	Goroutines   int     `json:"goroutines"`
+	CPUs         int     `json:"cpus"`
+	HeapAlloc    uint64  `json:"heap_alloc"`
+	HeapInuse    uint64  `json:"heap_inuse"`
+	HeapObjects  uint64  `json:"heap_objects"`
+	Sys          uint64  `json:"sys"`
+	NumGC        uint32  `json:"num_gc"`
+	PauseTotalNs uint64  `json:"pause_total_ns"`
+	GCCPUPercent float64 `json:"gc_cpu_percent"`
+}
+
+func runtimeStats(w http.ResponseWriter, r *http.Request) {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	writeJSON(w, http.StatusOK, RuntimeStats{
+		GoVersion:    runtime.Version(),
+		Uptime:       time.Since(startTime).Round(time.Second).String(),
+		Goroutines:   runtime.NumGoroutine(),
+		CPUs:         runtime.NumCPU(),
+		HeapAlloc:    m.HeapAlloc,
+		HeapInuse:    m.HeapInuse,
+		HeapObjects:  m.HeapObjects,
+		Sys:          m.Sys,
+		NumGC:        m.NumGC,
+		PauseTotalNs: m.PauseTotalNs,
+		GCCPUPercent: m.GCCPUFraction * 100,
+	})
+}
+
+// expvars serves the variables of expvar, except the command line, which
+// may have secrets in it.
+func expvars(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	fmt.Fprintf(w, "{\n")
+	first := true
+	expvar.Do(func(kv expvar.KeyValue) {
+		if kv.Key == "cmdline" {
+			return
+		}
+		if !first {
+			fmt.Fprintf(w, ",\n")
+		}
+		first = false
+		fmt.Fprintf(w, "%q: %s", kv.Key, kv.Value)
+	})
+	fmt.Fprintf(w, "\n}\n")
+}
+
+// debugHandler serves the profiles of net/http/pprof under /debug/pprof/,
+// the variables of expvar under /debug/vars and the runtime statistics
+// under /debug/runtime. The command line is left out, as it may have
+// secrets in it.
+func debugHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", http.NotFound)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.HandleFunc("/debug/vars", expvars)
+	mux.HandleFunc("/debug/runtime", runtimeStats)
+	return mux
+}
+
+// checkDebugListen reports an error when -debug-listen is not a loopback
+// address, as the debug endpoints are served there without signing in.
+func checkDebugListen() error {
+	if *debugListenFlag == "" {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(*debugListenFlag)
+	if err != nil {
+		return fmt.Errorf("-debug-listen %s: %s", *debugListenFlag, err)
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return fmt.Errorf("-debug-listen %s must be a loopback address, such as 127.0.0.1:6060", *debugListenFlag)
+	}
+	return nil
+}
+
+// serveDebug serves the debug endpoints on the listener of -debug-listen.
+func serveDebug(l net.Listener) error {
+	log.Println("Serving debug endpoints on " + l.Addr().String())
+	return http.Serve(l, debugHandler())
+}
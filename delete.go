@@ -0,0 +1,100 @@
+package main
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/espebra/pastebin/pastebin"
+	"github.com/gorilla/mux"
+)
+
+var (
+	errDeleted            = pastebin.ErrDeleted
+	errInvalidDeleteToken = pastebin.ErrInvalidDeleteToken
+)
+
+// available reports whether the paste can be served. It is checked before
+// answering conditional requests, which do not read the paste itself.
+func available(checksum string) bool {
+	if blocked.Contains(checksum) {
+		return false
+	}
+	m, _ := service.Meta(checksum)
+	return !m.Deleted
+}
+
+// deleteToken returns the delete token given in the X-Delete-Token header
+// or the token form value.
+func deleteToken(r *http.Request) string {
+	if token := r.Header.Get("X-Delete-Token"); token != "" {
+		return token
+	}
+	return r.FormValue("token")
+}
+
+// deletePaste deletes the paste with the delete token of the request, or
+// moves it to the trash without one for moderators, whoever created it.
+func deletePaste(r *http.Request, checksum string) error {
+	if !allowed(r, "moderate") || !sameOrigin(r) {
+		return service.Delete(checksum, deleteToken(r))
+	}
+	return trashPaste(r, checksum)
+}
+
+// trashPaste moves the paste to the trash for the moderator of the
+// request, without its delete token.
+func trashPaste(r *http.Request, checksum string) error {
+	if _, err := service.Meta(checksum); err != nil {
+		return err
+	}
+	if err := service.Trash(checksum); err != nil {
+		return err
+	}
+	audit(r, "delete", checksum, "")
+	return nil
+}
+
+func deletePasteForm(w http.ResponseWriter, r *http.Request) {
+	checksum := mux.Vars(r)["checksum"]
+
+	var p Paste
+	if err := deletePaste(r, checksum); err == pastebin.ErrShared {
+		p.Message = "Your delete token of " + checksum + " is revoked, but the paste is kept as it was created by others as well."
+		p.Status = "success"
+	} else if err != nil {
+		log.Printf("Unable to delete %s: %s\n", checksum, err)
+		w.WriteHeader(http.StatusForbidden)
+		p.Message = "Unable to delete " + checksum + ": " + err.Error()
+		p.Status = "error"
+	} else {
+		p.Message = "Paste " + checksum + " is deleted."
+		p.Status = "success"
+	}
+	renderPaste(w, r, p)
+}
+
+func apiDeletePaste(w http.ResponseWriter, r *http.Request) {
+	checksum := mux.Vars(r)["checksum"]
+
+	err := deletePaste(r, checksum)
+	if err == pastebin.ErrShared {
+		writeJSON(w, http.StatusOK, BatchResult{Checksum: checksum, Status: "success", Message: err.Error()})
+		return
+	}
+	if err != nil {
+		log.Printf("Unable to delete %s: %s\n", checksum, err)
+		status := http.StatusInternalServerError
+		if err == errInvalidDeleteToken {
+			status = http.StatusForbidden
+		}
+		if err == pastebin.ErrPinned {
+			status = http.StatusConflict
+		}
+		if isNotFound(err) {
+			status = http.StatusNotFound
+		}
+		writeJSON(w, status, BatchResult{Checksum: checksum, Status: "error", Message: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, BatchResult{Checksum: checksum, Status: "success"})
+}
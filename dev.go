@@ -0,0 +1,44 @@
+package main
+
+import (
+	"log"
+	"strings"
+)
+
+// Example pastes created with -dev-seed, covering the kinds of content
+// the pages render differently.
+var seedPastes = []struct {
+	content    string
+	visibility string
+	tags       []string
+}{
+	{"Hello, world!\n\nThis is an example paste created by -dev-seed.\n", "public", []string{"example"}},
+	{"package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"Hello, world!\")\n}\n", "public", []string{"example", "go"}},
+	{"# Example\n\nA *markdown* paste with a [link](https://example.com) and a list:\n\n- one\n- two\n", "public", []string{"example", "markdown"}},
+	{"{\n  \"name\": \"example\",\n  \"values\": [1, 2, 3]\n}\n", "unlisted", []string{"example", "json"}},
+}
+
+// seedExamples creates the example pastes and logs where they are.
+func seedExamples() {
+	for _, seed := range seedPastes {
+		var p Paste
+		p.Content = seed.content
+		p.Checksum = p.GetName()
+		if _, _, err := createPaste(&p, seed.visibility, seed.tags); err != nil {
+			log.Printf("Unable to create example paste: %s\n", err)
+			continue
+		}
+		log.Printf("Created example paste %s\n", absURL(p.Location()))
+	}
+}
+
+// devMiddleware logs every request in development mode, unless the
+// logging middleware already does.
+func devMiddleware(middleware []Middleware) []Middleware {
+	for _, name := range strings.Split(*middlewareFlag, ",") {
+		if strings.TrimSpace(name) == "logging" {
+			return middleware
+		}
+	}
+	return append([]Middleware{logRequests}, middleware...)
+}
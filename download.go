@@ -0,0 +1,53 @@
+package main
+
+import (
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/espebra/pastebin/pastebin"
+	"github.com/gorilla/mux"
+)
+
+// filename returns the name a paste is downloaded as, with an extension
+// matching its content type.
+func filename(p Paste) string {
+	if !p.Binary {
+		return p.Checksum + ".txt"
+	}
+	if exts, err := mime.ExtensionsByType(p.ContentType); err == nil && len(exts) > 0 {
+		return p.Checksum + exts[0]
+	}
+	return p.Checksum
+}
+
+// downloadPaste serves the paste as a file to save, which works without
+// JavaScript unlike saving from the editor.
+func downloadPaste(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	checksum := vars["checksum"]
+
+	if !pastebin.IsChecksum(checksum) || !service.Authorize(checksum, vars["key"]) {
+		http.NotFound(w, r)
+		return
+	}
+	p, err := retrievePaste(checksum, vars["key"])
+	if err != nil {
+		log.Println(err)
+		if storageUnavailable(w, err) {
+			return
+		}
+		http.NotFound(w, r)
+		return
+	}
+
+	views.Add(service.Scope(checksum, vars["key"]))
+	accesses.Add(checksum, r)
+
+	w.Header().Set("Content-Type", p.ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename(p)+`"`)
+	http.ServeContent(w, r, checksum, time.Time{}, strings.NewReader(p.Content))
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Responses are throttled in chunks of this many bytes.
+const egressChunk = 16 << 10
+
+// egressClient is what a client downloaded today, and when it may be sent
+// more bytes within -egress-rate.
+type egressClient struct {
+	day   string
+	bytes int64
+	next  time.Time
+}
+
+// egressLimiter keeps the downloads of each client, by clientKey. Clients
+// are forgotten the day after their last download.
+type egressLimiter struct {
+	sync.Mutex
+	clients map[string]*egressClient
+	day     string
+}
+
+var egress = egressLimiter{clients: map[string]*egressClient{}}
+
+func (l *egressLimiter) client(key string) *egressClient {
+	if day := today(); day != l.day {
+		l.day = day
+		for k, c := range l.clients {
+			if c.day != day && time.Now().After(c.next) {
+				delete(l.clients, k)
+			}
+		}
+	}
+	c, ok := l.clients[key]
+	if !ok {
+		c = &egressClient{}
+		l.clients[key] = c
+	}
+	if c.day != l.day {
+		c.day = l.day
+		c.bytes = 0
+	}
+	return c
+}
+
+// Used returns the bytes the client downloaded today.
+func (l *egressLimiter) Used(key string) int64 {
+	l.Lock()
+	defer l.Unlock()
+	return l.client(key).bytes
+}
+
+// Wait counts n bytes sent to the client, and waits until they can be
+// sent within -egress-rate, shared by all the downloads of the client.
+func (l *egressLimiter) Wait(ctx context.Context, key string, n int) error {
+	rate := egressRateFlag.Get()
+	l.Lock()
+	c := l.client(key)
+	c.bytes += int64(n)
+	var delay time.Duration
+	if rate > 0 {
+		now := time.Now()
+		if c.next.Before(now) {
+			c.next = now
+		}
+		delay = c.next.Sub(now)
+		c.next = c.next.Add(time.Duration(int64(n) * int64(time.Second) / rate))
+	}
+	l.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// throttledWriter sends the response at the rate of the client.
+type throttledWriter struct {
+	http.ResponseWriter
+	ctx context.Context
+	key string
+}
+
+func (w *throttledWriter) Write(b []byte) (int, error) {
+	written := 0
+	for len(b) > 0 {
+		chunk := b
+		if len(chunk) > egressChunk {
+			chunk = chunk[:egressChunk]
+		}
+		if err := egress.Wait(w.ctx, w.key, len(chunk)); err != nil {
+			return written, err
+		}
+		n, err := w.ResponseWriter.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		b = b[n:]
+	}
+	return written, nil
+}
+
+// egressLimited throttles the downloads of each client IP to -egress-rate
+// bytes per second, and refuses them with 429 once the client downloaded
+// -egress-quota bytes today, so that the bandwidth of a public pastebin
+// stays predictable. A download started within the quota is completed.
+func egressLimited(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rate, quota := egressRateFlag.Get(), egressQuotaFlag.Get()
+		if rate <= 0 && quota <= 0 {
+			h(w, r)
+			return
+		}
+		key := clientKey(clientIP(r))
+		if quota > 0 && egress.Used(key) >= quota {
+			now := time.Now().UTC()
+			tomorrow := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+			w.Header().Set("Retry-After", strconv.Itoa(int(tomorrow.Sub(now).Seconds())+1))
+			http.Error(w, "The daily download quota of this address is used up, try again tomorrow", http.StatusTooManyRequests)
+			return
+		}
+		h(&throttledWriter{ResponseWriter: w, ctx: r.Context(), key: key}, r)
+	}
+}
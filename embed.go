@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/espebra/pastebin/pastebin"
+	"github.com/gorilla/mux"
+)
+
+// Default size of embedded pastes.
+const (
+	embedWidth  = 600
+	embedHeight = 400
+)
+
+// frameOptions denies framing of every page except the embeds, which are
+// meant to be shown in iframes on other sites.
+func frameOptions(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/embed/") {
+			w.Header().Set("X-Frame-Options", "DENY")
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+func embedPaste(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	checksum := vars["checksum"]
+
+	var p Paste
+	var err error
+	if service.Authorize(checksum, vars["key"]) {
+		p, err = retrievePaste(checksum, vars["key"])
+	} else {
+		err = fmt.Errorf("invalid key for %s", checksum)
+	}
+	if err != nil {
+		log.Println(err)
+		w.WriteHeader(http.StatusNotFound)
+		p.Message = "Paste " + checksum + " does not exist."
+		p.Status = "error"
+	} else {
+		views.Add(service.Scope(checksum, vars["key"]))
+		accesses.Add(checksum, r)
+	}
+	renderTemplate(w, r, "templates/embed.html", "embed", p)
+}
+
+// OEmbed is an oEmbed response of the rich type.
+type OEmbed struct {
+	Version      string `json:"version"`
+	Type         string `json:"type"`
+	ProviderName string `json:"provider_name"`
+	ProviderURL  string `json:"provider_url"`
+	Title        string `json:"title"`
+	HTML         string `json:"html"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+}
+
+// pasteFromURL returns the checksum and key of the paste at the URL of a
+// paste page, resolving slugs.
+func pasteFromURL(s string) (string, string, bool) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return "", "", false
+	}
+	path := strings.TrimPrefix(u.Path, basePath())
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) > 2 || parts[0] == "" {
+		return "", "", false
+	}
+	checksum, key := parts[0], ""
+	if len(parts) == 2 {
+		key = parts[1]
+	}
+	if !pastebin.IsChecksum(checksum) {
+		resolved, err := resolveSlug(checksum)
+		if err != nil {
+			return "", "", false
+		}
+		checksum = resolved
+	}
+	return checksum, key, true
+}
+
+func oembed(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if format := q.Get("format"); format != "" && format != "json" {
+		http.Error(w, "Only the json format is supported", http.StatusNotImplemented)
+		return
+	}
+
+	checksum, key, ok := pasteFromURL(q.Get("url"))
+	if !ok || !service.Authorize(checksum, key) || !available(checksum) {
+		http.NotFound(w, r)
+		return
+	}
+
+	width, height := embedWidth, embedHeight
+	if n, err := strconv.Atoi(q.Get("maxwidth")); err == nil && n > 0 && n < width {
+		width = n
+	}
+	if n, err := strconv.Atoi(q.Get("maxheight")); err == nil && n > 0 && n < height {
+		height = n
+	}
+
+	p := Paste{Checksum: checksum, Key: key}
+	src := requestURL(r, "/embed"+p.Location())
+	html := `<iframe src="` + template.HTMLEscapeString(src) + `" width="` + strconv.Itoa(width) +
+		`" height="` + strconv.Itoa(height) + `" frameborder="0"></iframe>`
+
+	writeJSON(w, http.StatusOK, OEmbed{
+		Version:      "1.0",
+		Type:         "rich",
+		ProviderName: branding(r).Name,
+		ProviderURL:  requestURL(r, "/"),
+		Title:        "Paste " + checksum,
+		HTML:         html,
+		Width:        width,
+		Height:       height,
+	})
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestOEmbedProviderName(t *testing.T) {
+	h := newTestServer(t)
+	path, _ := createPlain(t, h, "embedded")
+	hostsMu.Lock()
+	virtualHosts = map[string]map[string]string{"paste.example.org": {"instance-name": "Example Paste"}}
+	hostsMu.Unlock()
+	t.Cleanup(func() {
+		hostsMu.Lock()
+		virtualHosts = map[string]map[string]string{}
+		hostsMu.Unlock()
+	})
+
+	for host, want := range map[string]string{"example.com": *instanceNameFlag, "paste.example.org": "Example Paste"} {
+		req := httptest.NewRequest("GET", "/oembed?url="+url.QueryEscape("http://"+host+path), nil)
+		req.Host = host
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET /oembed on %s returned %d: %s", host, rec.Code, rec.Body.String())
+		}
+		var o OEmbed
+		if err := json.Unmarshal(rec.Body.Bytes(), &o); err != nil {
+			t.Fatal(err)
+		}
+		if o.ProviderName != want {
+			t.Errorf("The oEmbed provider on %s is %q, want %q", host, o.ProviderName, want)
+		}
+	}
+}
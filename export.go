@@ -0,0 +1,135 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/espebra/pastebin/apitypes"
+)
+
+// exportPastes writes every paste found below the data directories to w as a
+// tar.gz archive with one file per paste, named by its checksum. The
+// storage provider can not list its objects, so the directory is walked
+// and only files whose content matches their storage key are exported.
+func exportPastes(w io.Writer) (int, error) {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	count := 0
+	err := walkPastes(func(dir, checksum string, data []byte, fi os.FileInfo) error {
+		hdr := &tar.Header{
+			Name:    checksum,
+			Mode:    0644,
+			Size:    int64(len(data)),
+			ModTime: fi.ModTime(),
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		return count, err
+	}
+
+	if err := tw.Close(); err != nil {
+		return count, err
+	}
+	return count, gz.Close()
+}
+
+// importPastes stores every paste in the tar.gz archive read from r.
+func importPastes(r io.Reader) (int, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return 0, err
+	}
+	tr := tar.NewReader(gz)
+
+	count := 0
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return count, nil
+		}
+		if err != nil {
+			return count, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		var buf bytes.Buffer
+		if _, err := io.Copy(&buf, tr); err != nil {
+			return count, err
+		}
+
+		var p Paste
+		p.Content = buf.String()
+		p.Checksum = p.GetName()
+		if p.Checksum != filepath.Base(hdr.Name) {
+			return count, fmt.Errorf("%s: content does not match checksum", hdr.Name)
+		}
+		if _, _, err := storePaste(p); err != nil {
+			return count, err
+		}
+		count++
+	}
+}
+
+// runCommand runs the subcommand given on the command line, if any, and
+// reports whether one was run.
+func runCommand(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+
+	switch args[0] {
+	case "export":
+		n, err := exportPastes(os.Stdout)
+		if err != nil {
+			log.Fatalf("Export failed after %d pastes: %s\n", n, err)
+		}
+		log.Printf("Exported %d pastes\n", n)
+	case "migrate-keys":
+		n, err := migrateKeys()
+		if err != nil {
+			log.Fatalf("Migration failed after %d pastes: %s\n", n, err)
+		}
+		log.Printf("Migrated %d pastes to the current storage key and shard\n", n)
+	case "fsck":
+		runFsck(args[1:])
+	case "index-trash":
+		n, err := indexTrash()
+		if err != nil {
+			log.Fatalf("Indexing the trash failed after %d pastes: %s\n", n, err)
+		}
+		log.Printf("Indexed %d deleted pastes by the hour they were deleted in\n", n)
+	case "import":
+		n, err := importPastes(os.Stdin)
+		if err != nil {
+			log.Fatalf("Import failed after %d pastes: %s\n", n, err)
+		}
+		log.Printf("Imported %d pastes\n", n)
+	case "openapi":
+		spec, err := json.MarshalIndent(apitypes.OpenAPI(absURL("")), "", "  ")
+		if err != nil {
+			log.Fatalf("Unable to describe the API: %s\n", err)
+		}
+		os.Stdout.Write(append(spec, '\n'))
+	default:
+		log.Fatalf("Unknown command %s\n", args[0])
+	}
+	return true
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/espebra/blobstore/common"
+)
+
+// fileStorage is the filesystem provider of a storage directory, which
+// keeps every object in a file named by its key in the directory.
+type fileStorage struct {
+	common.Provider
+	dir string
+}
+
+// Delete removes the file of the object. The provider has no delete of
+// its own, so the file is removed directly. Keys of derived objects have
+// their prefix as a directory.
+func (s fileStorage) Delete(key string) error {
+	if !filepath.IsLocal(key) {
+		return &os.PathError{Op: "delete", Path: key, Err: os.ErrInvalid}
+	}
+	err := os.Remove(filepath.Join(s.dir, key))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/espebra/pastebin/pastebin"
+)
+
+// Prefixes of the records kept next to a paste under its storage key.
+// Thumbnails are derived objects, kept under the derived/ prefix.
+var sidecarPrefixes = []string{"meta-", "views-", "access-", "comments-", thumbnailPrefix}
+
+// FsckResult is the outcome of checking the storage for inconsistencies.
+type FsckResult struct {
+	Pastes int
+	// Checksums of pastes without metadata
+	MissingMeta []string
+	// Paths of records whose paste does not exist
+	Orphans  []string
+	Repaired int
+}
+
+// sidecarKey returns the storage key of the paste a sidecar record belongs
+// to, or an empty string if name, the path of the record in its data
+// directory, is not a sidecar record. Records kept under a scope of the
+// paste have the name of the scope after its key.
+func sidecarKey(name string) string {
+	for _, prefix := range sidecarPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			key := strings.TrimPrefix(name, prefix)
+			if i := strings.IndexByte(key, '-'); i >= 0 {
+				key = key[:i]
+			}
+			return key
+		}
+	}
+	return ""
+}
+
+// fsck walks the data directories for pastes without metadata and for
+// records left behind by pastes that are not stored, which happens when a
+// store fails half way. With repair, metadata is created for pastes that
+// lack it and orphaned records are removed.
+func fsck(repair bool) (FsckResult, error) {
+	var result FsckResult
+	keys := map[string]bool{}
+	checksums := map[string]string{}
+	err := walkPastes(func(dir, checksum string, data []byte, fi os.FileInfo) error {
+		result.Pastes++
+		for _, key := range service.ObjectKeys(checksum) {
+			keys[key] = true
+			checksums[key] = checksum
+		}
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+
+	hasMeta := map[string]bool{}
+	for _, dir := range dataDirs() {
+		err := filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			key := sidecarKey(filepath.ToSlash(rel))
+			if !fi.Mode().IsRegular() || key == "" {
+				return nil
+			}
+			if keys[key] {
+				if strings.HasPrefix(fi.Name(), "meta-") {
+					hasMeta[checksums[key]] = true
+				}
+				return nil
+			}
+			result.Orphans = append(result.Orphans, path)
+			if repair {
+				if err := os.Remove(path); err != nil {
+					log.Printf("Unable to remove %s: %s\n", path, err)
+				} else {
+					result.Repaired++
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return result, err
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, checksum := range checksums {
+		if hasMeta[checksum] || seen[checksum] {
+			continue
+		}
+		seen[checksum] = true
+		result.MissingMeta = append(result.MissingMeta, checksum)
+		if repair {
+			if err := repairMeta(checksum); err != nil {
+				log.Printf("Unable to create metadata of %s: %s\n", checksum, err)
+			} else {
+				result.Repaired++
+			}
+		}
+	}
+	return result, nil
+}
+
+// repairMeta creates the metadata of a paste from its content. Other
+// properties, such as tags and delete tokens, are lost with the record.
+// The visibility is lost as well, so the paste is made private rather than
+// served to anyone when it may have been private.
+func repairMeta(checksum string) error {
+	p, err := retrievePaste(checksum, "")
+	if err != nil {
+		return err
+	}
+	m := Meta{Visibility: "private", Size: int64(len(p.Content))}
+	m.ContentType, m.Binary = pastebin.DetectContentType(p.Content)
+	return service.StoreMeta(checksum, m)
+}
+
+func (r FsckResult) log() {
+	for _, checksum := range r.MissingMeta {
+		log.Printf("Paste %s has no metadata\n", checksum)
+	}
+	for _, path := range r.Orphans {
+		log.Printf("Record %s belongs to no paste\n", path)
+	}
+	log.Printf("Checked %d pastes: %d without metadata, %d orphaned records, %d repaired\n",
+		r.Pastes, len(r.MissingMeta), len(r.Orphans), r.Repaired)
+}
+
+// runFsck runs the fsck subcommand.
+func runFsck(args []string) {
+	fs := flag.NewFlagSet("fsck", flag.ExitOnError)
+	repair := fs.Bool("repair", false, "Create missing metadata, which makes the pastes private, and remove orphaned records")
+	fs.Parse(args)
+
+	result, err := fsck(*repair)
+	if err != nil {
+		log.Fatalf("Check failed: %s\n", err)
+	}
+	result.log()
+}
+
+// runFsckPeriodically reports inconsistencies in the storage at the given
+// interval, without repairing them.
+func runFsckPeriodically(interval time.Duration) {
+	for range time.Tick(interval) {
+		result, err := fsck(false)
+		if err != nil {
+			log.Printf("Storage check failed: %s\n", err)
+			continue
+		}
+		result.log()
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/espebra/pastebin/pastebin"
+)
+
+func TestRepairMetaPrivate(t *testing.T) {
+	h := newTestServer(t)
+	checksum := pastebin.Checksum("lost its metadata")
+	if _, err := storage.Store(service.ObjectKey(checksum), strings.NewReader("lost its metadata")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repairMeta(checksum); err != nil {
+		t.Fatalf("Unable to repair the metadata: %s", err)
+	}
+	m, err := service.Meta(checksum)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Visibility != "private" || m.ContentType == "" {
+		t.Errorf("Repaired the metadata as %+v, want a private paste with its content type", m)
+	}
+	// The paste may have been private, so it is not served to anyone
+	if rec := request(h, "GET", "/raw/"+checksum, "", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("GET /raw/%s returned %d after its metadata was repaired, want 404", checksum, rec.Code)
+	}
+}
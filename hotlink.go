@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// parseHotlinkHosts reads -hotlink-allow, given as host names separated by
+// commas, where *.example.com matches the subdomains of example.com.
+func parseHotlinkHosts(s string) ([]string, error) {
+	var hosts []string
+	for _, host := range strings.Split(s, ",") {
+		host = strings.ToLower(strings.TrimSpace(host))
+		if host == "" {
+			continue
+		}
+		name := strings.TrimPrefix(host, "*.")
+		if name == "" || strings.ContainsAny(name, "*/:@ ") {
+			return nil, fmt.Errorf("hotlink host %q must be a host name like example.com or *.example.com", host)
+		}
+		hosts = append(hosts, host)
+	}
+	return hosts, nil
+}
+
+// hostname returns the host without its port, in lower case.
+func hostname(hostport string) string {
+	if host, _, err := net.SplitHostPort(hostport); err == nil {
+		hostport = host
+	}
+	return strings.ToLower(strings.Trim(hostport, "[]"))
+}
+
+// hotlinkAllowed reports whether the raw paste may be served to the page
+// that linked to it. With -hotlink-protection, pages on other sites than
+// the pastebin and those in -hotlink-allow are refused, so they can not
+// embed large pastes at the expense of the bandwidth of the pastebin.
+// Requests without a Referer, such as from curl or from browsers that do
+// not send one, are always allowed.
+func hotlinkAllowed(r *http.Request) bool {
+	if !*hotlinkProtectionFlag {
+		return true
+	}
+	ref, err := url.Parse(r.Referer())
+	if r.Referer() == "" || err == nil && ref.Host == "" {
+		return true
+	}
+	if err != nil {
+		return false
+	}
+	host := hostname(ref.Host)
+	if host == hostname(r.Host) {
+		return true
+	}
+	if base, err := url.Parse(*baseURLFlag); err == nil && base.Host != "" && host == hostname(base.Host) {
+		return true
+	}
+	hosts, _ := parseHotlinkHosts(*hotlinkAllowFlag)
+	for _, allowed := range hosts {
+		if host == allowed || strings.HasPrefix(allowed, "*.") && strings.HasSuffix(host, allowed[1:]) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Locales the web interface is translated to, the first is the default.
+var locales = []string{"en", "de", "nb"}
+
+// Names of the locales, in their own language.
+var localeNames = map[string]string{
+	"en": "English",
+	"de": "Deutsch",
+	"nb": "Norsk bokmål",
+}
+
+// Date formats of the locales.
+var dateFormats = map[string]string{
+	"en": "2006-01-02 15:04:05 MST",
+	"de": "02.01.2006 15:04:05 MST",
+	"nb": "02.01.2006 15:04:05 MST",
+}
+
+// translations maps the English strings of the templates to their
+// translations. Strings without a translation are shown in English.
+var translations = map[string]map[string]string{
+	"de": {
+		"Color theme":                            "Farbschema",
+		"Auto":                                   "Automatisch",
+		"Light":                                  "Hell",
+		"Dark":                                   "Dunkel",
+		"Highlight theme":                        "Syntaxhervorhebung",
+		"Language":                               "Sprache",
+		"Apply":                                  "Anwenden",
+		"Leave this field empty":                 "Dieses Feld leer lassen",
+		"Binary file (%s, %d bytes).":            "Binärdatei (%s, %d Bytes).",
+		"Download":                               "Herunterladen",
+		"Image %s":                               "Bild %s",
+		"Some text here...":                      "Etwas Text hier...",
+		"Default visibility":                     "Standardsichtbarkeit",
+		"Public, shown in listings":              "Öffentlich, in Listen angezeigt",
+		"Unlisted, anyone with the link":         "Nicht gelistet, für alle mit dem Link",
+		"Private, only with the secret link":     "Privat, nur mit dem geheimen Link",
+		"Image (optional, replaces the text)":    "Bild (optional, ersetzt den Text)",
+		"Large file (resumable upload)":          "Große Datei (fortsetzbarer Upload)",
+		"Encrypt in the browser":                 "Im Browser verschlüsseln",
+		"Encrypted, decrypted by your browser.":  "Verschlüsselt, von Ihrem Browser entschlüsselt.",
+		"Wrong or missing key in the link.":      "Falscher oder fehlender Schlüssel im Link.",
+		"Tags, comma separated (optional)":       "Tags, durch Kommas getrennt (optional)",
+		"Custom URL (optional)":                  "Eigene URL (optional)",
+		"Delete passphrase (optional)":           "Lösch-Passphrase (optional)",
+		"Save":                                   "Speichern",
+		"Raw":                                    "Rohtext",
+		"%d views":                               "%d Aufrufe",
+		"%d bytes":                               "%d Bytes",
+		"%d lines":                               "%d Zeilen",
+		"public":                                 "öffentlich",
+		"unlisted":                               "nicht gelistet",
+		"private":                                "privat",
+		"Clone":                                  "Klonen",
+		"Expires after, e.g. 24h":                "Läuft ab nach, z. B. 24h",
+		"Max views":                              "Max. Aufrufe",
+		"Create share link":                      "Freigabelink erstellen",
+		"Delete token or passphrase":             "Löschtoken oder Passphrase",
+		"Delete":                                 "Löschen",
+		"Access log":                             "Zugriffsprotokoll",
+		"Reason":                                 "Grund",
+		"Your email (optional)":                  "Deine E-Mail (optional)",
+		"Report":                                 "Melden",
+		"Comments":                               "Kommentare",
+		"Anonymous":                              "Anonym",
+		"Reply":                                  "Antworten",
+		"Name (optional)":                        "Name (optional)",
+		"Hide":                                   "Ausblenden",
+		"Delete token of the paste":              "Löschtoken des Pastes",
+		"Comment":                                "Kommentar",
+		"Add comment":                            "Kommentar hinzufügen",
+		"Pastes tagged %s":                       "Pastes mit dem Tag %s",
+		"Thumbnail of %s":                        "Vorschaubild von %s",
+		"No pastes are tagged %s.":               "Keine Pastes haben den Tag %s.",
+		"This stream has ended and is stored as": "Dieser Stream ist beendet und gespeichert als",
+		"Live, started %s.":                      "Live, gestartet %s.",
+		"View paste":                             "Paste ansehen",
+		"Access log of %s":                       "Zugriffsprotokoll von %s",
+		"Time":                                   "Zeit",
+		"Network":                                "Netzwerk",
+		"User agent":                             "User-Agent",
+		"No accesses are logged.":                "Keine Zugriffe protokolliert.",
+		"Skip to content":                        "Zum Inhalt springen",
+		"Paste content":                          "Inhalt des Pastes",
+		"Visibility":                             "Sichtbarkeit",
+		"Copy":                                   "Kopieren",
+		"Wrap lines":                             "Zeilen umbrechen",
+		"Don't wrap lines":                       "Zeilen nicht umbrechen",
+		"Show whitespace":                        "Leerzeichen anzeigen",
+		"Hide whitespace":                        "Leerzeichen ausblenden",
+		"Font size":                              "Schriftgröße",
+		"Small":                                  "Klein",
+		"Normal":                                 "Normal",
+		"Large":                                  "Groß",
+		"Download all (zip)":                     "Alle herunterladen (zip)",
+		"Download all (tar.gz)":                  "Alle herunterladen (tar.gz)",
+		"Formatted view (%s)":                    "Formatierte Ansicht (%s)",
+		"Create a new paste":                     "Neues Paste erstellen",
+		"The page you are looking for does not exist.": "Die gesuchte Seite existiert nicht.",
+	},
+	"nb": {
+		"Color theme":                            "Fargetema",
+		"Auto":                                   "Automatisk",
+		"Light":                                  "Lyst",
+		"Dark":                                   "Mørkt",
+		"Highlight theme":                        "Syntaksfarger",
+		"Language":                               "Språk",
+		"Apply":                                  "Bruk",
+		"Leave this field empty":                 "La dette feltet stå tomt",
+		"Binary file (%s, %d bytes).":            "Binærfil (%s, %d byte).",
+		"Download":                               "Last ned",
+		"Image %s":                               "Bilde %s",
+		"Some text here...":                      "Litt tekst her...",
+		"Default visibility":                     "Standard synlighet",
+		"Public, shown in listings":              "Offentlig, vises i lister",
+		"Unlisted, anyone with the link":         "Ulistet, alle med lenken",
+		"Private, only with the secret link":     "Privat, kun med den hemmelige lenken",
+		"Image (optional, replaces the text)":    "Bilde (valgfritt, erstatter teksten)",
+		"Large file (resumable upload)":          "Stor fil (gjenopptakbar opplasting)",
+		"Encrypt in the browser":                 "Krypter i nettleseren",
+		"Encrypted, decrypted by your browser.":  "Kryptert, dekrypteres av nettleseren din.",
+		"Wrong or missing key in the link.":      "Feil eller manglende nøkkel i lenken.",
+		"Tags, comma separated (optional)":       "Stikkord, kommaseparert (valgfritt)",
+		"Custom URL (optional)":                  "Egen URL (valgfritt)",
+		"Delete passphrase (optional)":           "Slettepassfrase (valgfritt)",
+		"Save":                                   "Lagre",
+		"Raw":                                    "Rå",
+		"%d views":                               "%d visninger",
+		"%d bytes":                               "%d byte",
+		"%d lines":                               "%d linjer",
+		"public":                                 "offentlig",
+		"unlisted":                               "ulistet",
+		"private":                                "privat",
+		"Clone":                                  "Klon",
+		"Expires after, e.g. 24h":                "Utløper etter, f.eks. 24h",
+		"Max views":                              "Maks visninger",
+		"Create share link":                      "Lag delingslenke",
+		"Delete token or passphrase":             "Slettenøkkel eller passfrase",
+		"Delete":                                 "Slett",
+		"Access log":                             "Tilgangslogg",
+		"Reason":                                 "Begrunnelse",
+		"Your email (optional)":                  "Din e-post (valgfritt)",
+		"Report":                                 "Rapporter",
+		"Comments":                               "Kommentarer",
+		"Anonymous":                              "Anonym",
+		"Reply":                                  "Svar",
+		"Name (optional)":                        "Navn (valgfritt)",
+		"Hide":                                   "Skjul",
+		"Delete token of the paste":              "Slettenøkkelen til pasten",
+		"Comment":                                "Kommentar",
+		"Add comment":                            "Legg til kommentar",
+		"Pastes tagged %s":                       "Paster merket %s",
+		"Thumbnail of %s":                        "Miniatyrbilde av %s",
+		"No pastes are tagged %s.":               "Ingen paster er merket %s.",
+		"This stream has ended and is stored as": "Denne strømmen er avsluttet og lagret som",
+		"Live, started %s.":                      "Direkte, startet %s.",
+		"View paste":                             "Vis paste",
+		"Access log of %s":                       "Tilgangslogg for %s",
+		"Time":                                   "Tid",
+		"Network":                                "Nettverk",
+		"User agent":                             "Brukeragent",
+		"No accesses are logged.":                "Ingen tilganger er logget.",
+		"Skip to content":                        "Hopp til innholdet",
+		"Paste content":                          "Innholdet i pasten",
+		"Visibility":                             "Synlighet",
+		"Copy":                                   "Kopier",
+		"Wrap lines":                             "Bryt linjer",
+		"Don't wrap lines":                       "Ikke bryt linjer",
+		"Show whitespace":                        "Vis mellomrom",
+		"Hide whitespace":                        "Skjul mellomrom",
+		"Font size":                              "Skriftstørrelse",
+		"Small":                                  "Liten",
+		"Normal":                                 "Normal",
+		"Large":                                  "Stor",
+		"Download all (zip)":                     "Last ned alle (zip)",
+		"Download all (tar.gz)":                  "Last ned alle (tar.gz)",
+		"Formatted view (%s)":                    "Formatert visning (%s)",
+		"Create a new paste":                     "Lag en ny paste",
+		"The page you are looking for does not exist.": "Siden du leter etter finnes ikke.",
+	},
+}
+
+// Language tags that are served by one of the locales.
+var localeAliases = map[string]string{
+	"no": "nb",
+	"nn": "nb",
+}
+
+// matchLocale returns the locale serving the language tag, if any.
+func matchLocale(tag string) (string, bool) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	if alias, ok := localeAliases[tag]; ok {
+		tag = alias
+	}
+	return tag, contains(locales, tag)
+}
+
+// negotiateLocale picks the locale from an Accept-Language header, in the
+// order of the quality values given.
+func negotiateLocale(header string) string {
+	type candidate struct {
+		tag string
+		q   float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		c := candidate{tag: fields[0], q: 1}
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					c.q = q
+				}
+			}
+		}
+		candidates = append(candidates, c)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].q > candidates[j].q
+	})
+	for _, c := range candidates {
+		if locale, ok := matchLocale(c.tag); ok && c.q > 0 {
+			return locale
+		}
+	}
+	return locales[0]
+}
+
+// requestLocale returns the locale chosen in the settings, or negotiated
+// from the Accept-Language header of the request.
+func requestLocale(r *http.Request) string {
+	if c, err := r.Cookie("lang"); err == nil && contains(locales, c.Value) {
+		return c.Value
+	}
+	return negotiateLocale(r.Header.Get("Accept-Language"))
+}
+
+// translate returns the translation of s, formatted with args if any.
+func translate(locale, s string, args ...interface{}) string {
+	if t, ok := translations[locale][s]; ok {
+		s = t
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(s, args...)
+	}
+	return s
+}
+
+func formatDate(locale string, t time.Time) string {
+	return t.Format(dateFormats[locale])
+}
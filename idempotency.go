@@ -0,0 +1,257 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	idempotencyHeader = "Idempotency-Key"
+
+	// Longest Idempotency-Key accepted
+	maxIdempotencyKey = 255
+
+	// Largest response that is kept. Larger responses are not sent again,
+	// so retries of their requests are made again.
+	maxIdempotentBody = 64 << 10
+
+	// The record of when expired responses were last purged
+	idempotencyPurgedKey = "idempotency-purged"
+	idempotencyHour      = "2006-01-02T15"
+)
+
+// IdempotentResponse is the response to a request with an Idempotency-Key,
+// sent again when the request is retried with the same key.
+type IdempotentResponse struct {
+	Request string            `json:"request"` // Hash of the method, URL and body
+	Status  int               `json:"status"`
+	Header  map[string]string `json:"header,omitempty"`
+	Body    []byte            `json:"body"`
+	Expires time.Time         `json:"expires"`
+}
+
+// Headers of the response that are sent again.
+var idempotentHeaders = []string{
+	"Content-Type", "Location", "X-Delete-Token", "X-Duplicate", "Upload-Offset", "Upload-Length",
+}
+
+// Each response is stored as a record of its own, named by the hash of
+// the client and the key, and indexed in a record per hour it expires in,
+// so that expired responses are found by reading the records of the hours
+// since they were last purged. The requests in flight are kept in memory.
+var (
+	idempotencyMu       sync.Mutex
+	idempotencyInFlight = map[string]bool{}
+)
+
+func idempotentResponseKey(id string) string {
+	return "idempotency-" + id
+}
+
+func idempotencyExpiringKey(hour string) string {
+	return "idempotency-expiring-" + hour
+}
+
+func retrieveIdempotentResponse(id string) (IdempotentResponse, bool, error) {
+	var resp IdempotentResponse
+	err := retrieveJSON(idempotentResponseKey(id), &resp)
+	if isNotFound(err) {
+		return resp, false, nil
+	}
+	return resp, err == nil, err
+}
+
+// storeIdempotentResponse stores the response and adds it to the index of
+// the hour it expires in.
+func storeIdempotentResponse(id string, resp IdempotentResponse) error {
+	if err := storeJSON(idempotentResponseKey(id), resp); err != nil {
+		return err
+	}
+	key := idempotencyExpiringKey(resp.Expires.UTC().Format(idempotencyHour))
+	var ids []string
+	if err := retrieveJSON(key, &ids); err != nil && !isNotFound(err) {
+		return err
+	}
+	return storeJSON(key, append(ids, id))
+}
+
+// purgeIdempotentResponses removes the responses that expired in the
+// hours that ended since they were last purged, along with the index of
+// those hours.
+func purgeIdempotentResponses(ctx context.Context) (int, error) {
+	idempotencyMu.Lock()
+	defer idempotencyMu.Unlock()
+
+	now := time.Now().UTC()
+	last := now.Truncate(time.Hour).Add(-*idempotencyTTLFlag - time.Hour)
+	var keys []string
+	err := retrieveJSON(idempotencyPurgedKey, &last)
+	if isNotFound(err) {
+		// Responses were kept in a single record before
+		keys = append(keys, "idempotency")
+	} else if err != nil {
+		return 0, err
+	}
+
+	hour := last.Add(time.Hour)
+	for ; !hour.Add(time.Hour).After(now); hour = hour.Add(time.Hour) {
+		key := idempotencyExpiringKey(hour.Format(idempotencyHour))
+		var ids []string
+		if err := retrieveJSON(key, &ids); err != nil {
+			if isNotFound(err) {
+				continue
+			}
+			return 0, err
+		}
+		for _, id := range ids {
+			// Responses stored again since expire later
+			if resp, found, err := retrieveIdempotentResponse(id); err == nil && found && now.After(resp.Expires) {
+				keys = append(keys, idempotentResponseKey(id))
+			}
+		}
+		keys = append(keys, key)
+	}
+
+	n, err := removeKeys(ctx, keys, nil)
+	if err != nil {
+		return n, err
+	}
+	return n, storeJSON(idempotencyPurgedKey, hour.Add(-time.Hour))
+}
+
+// idempotencyID returns the record name of the Idempotency-Key. Keys are
+// scoped to the signed in user, or to the address of clients that are not
+// signed in, so that clients can not see the responses of each other.
+func idempotencyID(r *http.Request, key string) string {
+	principal := "client " + clientKey(clientIP(r))
+	if role, ok := requestRole(r); ok && role > roleAnonymous {
+		user, _, _ := r.BasicAuth()
+		principal = "user " + user
+	}
+	sum := sha256.Sum256([]byte(principal + "\n" + key))
+	return hex.EncodeToString(sum[:])
+}
+
+// recordingWriter keeps a copy of the response, unless it is longer than
+// maxIdempotentBody.
+type recordingWriter struct {
+	http.ResponseWriter
+	status    int
+	body      bytes.Buffer
+	truncated bool
+}
+
+func (w *recordingWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if w.body.Len()+len(b) > maxIdempotentBody {
+		w.truncated = true
+	} else if !w.truncated {
+		w.body.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// idempotent sends the response to the first request with an
+// Idempotency-Key again when the request is retried with the same key
+// within -idempotency-ttl, instead of creating the pastes again. A key
+// reused for another request is refused. Responses with server errors, and
+// those too large to keep, are not kept, so that those requests can be
+// retried. It wraps the API routes, not form posts, which browsers do not
+// send the header with.
+func idempotent(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(idempotencyHeader)
+		if key == "" || *idempotencyTTLFlag <= 0 {
+			h(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKey {
+			writeJSON(w, http.StatusBadRequest, BatchResult{Status: "error", Message: "The Idempotency-Key is too long"})
+			return
+		}
+		body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, requestBodyLimit()))
+		if err != nil {
+			writeJSON(w, http.StatusRequestEntityTooLarge, BatchResult{Status: "error", Message: "Unable to read the request: " + err.Error()})
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		sum := sha256.Sum256(append([]byte(r.Method+" "+r.URL.RequestURI()+"\n"), body...))
+		request := hex.EncodeToString(sum[:])
+		id := idempotencyID(r, key)
+
+		idempotencyMu.Lock()
+		resp, found, err := retrieveIdempotentResponse(id)
+		inFlight := idempotencyInFlight[id]
+		if err == nil && !inFlight && (!found || time.Now().After(resp.Expires)) {
+			idempotencyInFlight[id] = true
+		}
+		idempotencyMu.Unlock()
+
+		switch {
+		case err != nil:
+			log.Printf("Unable to read idempotent responses: %s\n", err)
+			if storageUnavailable(w, err) {
+				return
+			}
+			writeJSON(w, http.StatusInternalServerError, BatchResult{Status: "error", Message: "Unable to read the Idempotency-Key"})
+			return
+		case inFlight:
+			writeJSON(w, http.StatusConflict, BatchResult{Status: "error", Message: "A request with this Idempotency-Key is in progress"})
+			return
+		case found && time.Now().Before(resp.Expires):
+			if resp.Request != request {
+				writeJSON(w, http.StatusUnprocessableEntity, BatchResult{Status: "error", Message: "The Idempotency-Key was used for another request"})
+				return
+			}
+			for name, value := range resp.Header {
+				w.Header().Set(name, value)
+			}
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(resp.Status)
+			w.Write(resp.Body)
+			return
+		}
+
+		rw := &recordingWriter{ResponseWriter: w}
+		defer func() {
+			idempotencyMu.Lock()
+			defer idempotencyMu.Unlock()
+			delete(idempotencyInFlight, id)
+			if rw.status == 0 || rw.status >= 500 || rw.truncated {
+				return
+			}
+			resp := IdempotentResponse{
+				Request: request,
+				Status:  rw.status,
+				Header:  map[string]string{},
+				Body:    rw.body.Bytes(),
+				Expires: time.Now().Add(*idempotencyTTLFlag).UTC(),
+			}
+			for _, name := range idempotentHeaders {
+				if value := w.Header().Get(name); value != "" {
+					resp.Header[name] = value
+				}
+			}
+			if err := storeIdempotentResponse(id, resp); err != nil {
+				log.Printf("Unable to keep the idempotent response: %s\n", err)
+			}
+		}()
+		h(rw, r)
+	}
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/espebra/pastebin/pastebin"
+	"github.com/gorilla/mux"
+)
+
+// Longest side of thumbnails, in pixels.
+const thumbnailSize = 320
+
+// Most pixels of images that thumbnails are made of. A small image can
+// claim to be huge, and would take that much memory to decode.
+const maxThumbnailPixels = 50 << 20
+
+// Image types that can be uploaded and are shown inline.
+var imageTypes = []string{"image/png", "image/jpeg", "image/webp"}
+
+var (
+	errImageTooLarge   = errors.New("the image is too large")
+	errImageType       = errors.New("only PNG, JPEG and WebP images can be uploaded")
+	errImagesDisabled  = errors.New("image uploads are disabled")
+	errNoThumbnailType = errors.New("thumbnails can not be made of this image type")
+	errImagePixels     = errors.New("the image has too many pixels to make a thumbnail of")
+)
+
+func isImage(contentType string) bool {
+	return contains(imageTypes, contentType)
+}
+
+// Prefix of the storage keys of thumbnails.
+const thumbnailPrefix = "derived/thumb-"
+
+// thumbnailKey is the storage key of the thumbnail of the paste. Derived
+// objects are kept under their own prefix, apart from pastes and records.
+func thumbnailKey(checksum string) string {
+	return thumbnailPrefix + service.ObjectKey(checksum)
+}
+
+// readImageUpload returns the content of the image uploaded with the form,
+// or an empty string if there is none.
+func readImageUpload(r *http.Request) (string, error) {
+	f, _, err := r.FormFile("image")
+	if err == http.ErrMissingFile || err == http.ErrNotMultipart {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if imageMaxSizeFlag.Get() <= 0 {
+		return "", errImagesDisabled
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(f, imageMaxSizeFlag.Get()+1))
+	if err != nil {
+		return "", err
+	}
+	if int64(len(data)) > imageMaxSizeFlag.Get() {
+		return "", errImageTooLarge
+	}
+	if !isImage(http.DetectContentType(data)) {
+		return "", errImageType
+	}
+	return string(data), nil
+}
+
+// scale returns img scaled down to fit within size by size pixels, by
+// averaging the pixels that make up each pixel of the result.
+func scale(img image.Image, size int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= size && h <= size {
+		return img
+	}
+	tw, th := size, h*size/w
+	if h > w {
+		tw, th = w*size/h, size
+	}
+	if tw < 1 {
+		tw = 1
+	}
+	if th < 1 {
+		th = 1
+	}
+
+	thumb := image.NewRGBA(image.Rect(0, 0, tw, th))
+	for y := 0; y < th; y++ {
+		y0, y1 := b.Min.Y+y*h/th, b.Min.Y+(y+1)*h/th
+		for x := 0; x < tw; x++ {
+			x0, x1 := b.Min.X+x*w/tw, b.Min.X+(x+1)*w/tw
+			var r, g, bl, a, n uint32
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := img.At(sx, sy).RGBA()
+					r, g, bl, a, n = r+pr, g+pg, bl+pb, a+pa, n+1
+				}
+			}
+			i := thumb.PixOffset(x, y)
+			thumb.Pix[i+0] = uint8(r / n >> 8)
+			thumb.Pix[i+1] = uint8(g / n >> 8)
+			thumb.Pix[i+2] = uint8(bl / n >> 8)
+			thumb.Pix[i+3] = uint8(a / n >> 8)
+		}
+	}
+	return thumb
+}
+
+// makeThumbnail returns a PNG thumbnail of a PNG or JPEG image. WebP can not
+// be decoded with the standard library.
+func makeThumbnail(content string) ([]byte, error) {
+	cfg, _, err := image.DecodeConfig(strings.NewReader(content))
+	if err == image.ErrFormat {
+		return nil, errNoThumbnailType
+	}
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || int64(cfg.Width)*int64(cfg.Height) > maxThumbnailPixels {
+		return nil, errImagePixels
+	}
+	img, _, err := image.Decode(strings.NewReader(content))
+	if err == image.ErrFormat {
+		return nil, errNoThumbnailType
+	}
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	err = png.Encode(&buf, scale(img, thumbnailSize))
+	return buf.Bytes(), err
+}
+
+// thumbnail returns the thumbnail of the paste, from the storage if it has
+// been made before.
+func thumbnail(p Paste) ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := storage.Retrieve(thumbnailKey(p.Checksum), &buf); err == nil {
+		return buf.Bytes(), nil
+	}
+	data, err := makeThumbnail(p.Content)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := storage.Store(thumbnailKey(p.Checksum), bytes.NewReader(data)); err != nil {
+		log.Printf("Unable to store thumbnail of %s: %s\n", p.Checksum, err)
+	}
+	return data, nil
+}
+
+func thumbnailImage(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	checksum := vars["checksum"]
+
+	if !pastebin.IsChecksum(checksum) || !service.Authorize(checksum, vars["key"]) {
+		http.NotFound(w, r)
+		return
+	}
+	p, err := retrievePaste(checksum, vars["key"])
+	if err != nil || !isImage(p.ContentType) {
+		if err != nil {
+			log.Println(err)
+		}
+		http.NotFound(w, r)
+		return
+	}
+
+	data, err := thumbnail(p)
+	if err == errNoThumbnailType {
+		// Show the image itself, scaled by the browser
+		http.Redirect(w, r, basePath()+"/raw"+p.Location(), http.StatusFound)
+		return
+	}
+	if err == errImagePixels {
+		http.Error(w, "The image is too large to make a thumbnail of", http.StatusUnprocessableEntity)
+		return
+	}
+	if err != nil {
+		log.Printf("Unable to make thumbnail of %s: %s\n", checksum, err)
+		http.Error(w, "Unable to make thumbnail", http.StatusInternalServerError)
+		return
+	}
+
+	if m, _ := service.Meta(checksum); service.RequiresKey(m) {
+		w.Header().Set("Cache-Control", "private, max-age=31536000, immutable")
+	} else {
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Write(data)
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"testing"
+)
+
+// pngOf returns a PNG image of the size.
+func pngOf(t *testing.T, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, w, h))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestThumbnailOversizedImage(t *testing.T) {
+	if _, err := makeThumbnail(string(pngOf(t, 400, 300))); err != nil {
+		t.Fatalf("Unable to make a thumbnail of an image of 400 by 300 pixels: %s", err)
+	}
+
+	// Claim 100000 by 100000 pixels in the header of a tiny image, which
+	// follows the 8 byte signature and the length and type of its chunk
+	data := pngOf(t, 1, 1)
+	binary.BigEndian.PutUint32(data[16:], 100000)
+	binary.BigEndian.PutUint32(data[20:], 100000)
+	binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+	if _, err := makeThumbnail(string(data)); err != errImagePixels {
+		t.Errorf("Making a thumbnail of an image of 100000 by 100000 pixels returned %v, want %v", err, errImagePixels)
+	}
+}
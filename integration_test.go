@@ -0,0 +1,135 @@
+//go:build integration
+
+// The end-to-end tests run the pastebin on the filesystem provider in a
+// temporary directory, as it is deployed, rather than on MemoryStorage:
+//
+//	go test -tags integration -run Integration .
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/espebra/pastebin/pastebin"
+	"github.com/gorilla/mux"
+)
+
+// newIntegrationServer returns the pastebin storing pastes in a temporary
+// directory, and the directory.
+func newIntegrationServer(t *testing.T) (http.Handler, string) {
+	t.Helper()
+	dir := t.TempDir()
+	setFlag(t, dataDirFlag, dir)
+	setFlag(t, replicaDirFlag, "")
+	storage = newStorage([]string{dir})
+	service = newService(storage)
+	jobQueue = newJobQueue(storage)
+	return RegisterRoutes(mux.NewRouter(), nil), dir
+}
+
+// pasteFile returns the path of the file the paste is stored in, or an
+// empty string if there is none.
+func pasteFile(t *testing.T, dir, checksum string) string {
+	t.Helper()
+	var found string
+	err := filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.Mode().IsRegular() && fi.Name() == service.ObjectKey(checksum) {
+			found = path
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return found
+}
+
+func TestIntegrationLifecycle(t *testing.T) {
+	h, dir := newIntegrationServer(t)
+	content := "Stored on disk\n"
+	checksum := pastebin.Checksum(content)
+	path, token := createPlain(t, h, content)
+	if pasteFile(t, dir, checksum) == "" {
+		t.Fatalf("Paste %s is not stored below %s", checksum, dir)
+	}
+
+	rec := request(h, "GET", path, "", map[string]string{"User-Agent": "Mozilla/5.0", "Accept": "text/html"})
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Stored on disk") {
+		t.Errorf("GET %s returned %d, want 200 and the page of the paste", path, rec.Code)
+	}
+	rec = request(h, "GET", "/raw"+path, "", nil)
+	if rec.Code != http.StatusOK || rec.Body.String() != content {
+		t.Errorf("GET /raw%s returned %d %q, want %q", path, rec.Code, rec.Body.String(), content)
+	}
+
+	rec = request(h, "DELETE", "/api/v1/pastes"+path, "", map[string]string{"X-Delete-Token": token})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Delete returned %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := request(h, "GET", path, "", map[string]string{"User-Agent": "Mozilla/5.0"}); rec.Code != http.StatusGone {
+		t.Errorf("GET %s returned %d after it was deleted, want 410", path, rec.Code)
+	}
+	// Deleted pastes are kept in the trash until they are purged
+	if pasteFile(t, dir, checksum) == "" {
+		t.Error("The deleted paste was removed before the trash was purged")
+	}
+
+	// The trash is purged of the hours that ended -trash-retention ago, so
+	// the paste is taken as deleted two hours ago
+	deletedAt := time.Now().UTC().Add(-2 * time.Hour)
+	err := service.UpdateMeta(checksum, func(m *Meta) { m.DeletedAt = deletedAt })
+	if err == nil {
+		err = service.IndexDeleted(checksum, deletedAt)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	retention := *trashRetentionFlag
+	*trashRetentionFlag = time.Hour
+	defer func() { *trashRetentionFlag = retention }()
+	n, err := purgeTrash(context.Background())
+	if err != nil {
+		t.Fatalf("Unable to purge the trash: %s", err)
+	}
+	if n != 1 {
+		t.Errorf("Purged %d pastes, want 1", n)
+	}
+	if file := pasteFile(t, dir, checksum); file != "" {
+		t.Errorf("The purged paste is still stored in %s", file)
+	}
+	if rec := request(h, "GET", "/raw"+path, "", nil); rec.Code == http.StatusOK {
+		t.Errorf("GET /raw%s returned 200 after it was purged", path)
+	}
+}
+
+func TestIntegrationCorruptPaste(t *testing.T) {
+	h, dir := newIntegrationServer(t)
+	content := "Intact content\n"
+	path, _ := createPlain(t, h, content)
+	file := pasteFile(t, dir, pastebin.Checksum(content))
+	if file == "" {
+		t.Fatalf("Paste %s is not stored below %s", path, dir)
+	}
+	if err := ioutil.WriteFile(file, []byte("Corrupt content\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, target := range []string{"/raw" + path, "/api/v1/pastes" + path} {
+		if rec := request(h, "GET", target, "", nil); rec.Code == http.StatusOK {
+			t.Errorf("GET %s returned 200 %q for a corrupt paste", target, rec.Body.String())
+		}
+	}
+	rec := request(h, "GET", path, "", map[string]string{"User-Agent": "Mozilla/5.0"})
+	if strings.Contains(rec.Body.String(), "Corrupt content") {
+		t.Errorf("GET %s shows the corrupt paste", path)
+	}
+}
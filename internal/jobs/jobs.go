@@ -0,0 +1,415 @@
+// Package jobs runs background work on a pool of workers. Failed jobs are
+// retried with backoff, and queued jobs of persistent kinds are kept in
+// the storage so that they survive a restart.
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Statuses of jobs.
+const (
+	Queued  = "queued"
+	Running = "running"
+	Done    = "done"
+	Failed  = "failed"
+)
+
+// Number of finished jobs kept for the status view.
+const maxFinished = 100
+
+// Number of jobs that can wait for a worker before new jobs are refused.
+const queueSize = 10000
+
+var ErrQueueFull = errors.New("the job queue is full")
+
+// Storage is where the queue is kept between restarts.
+type Storage interface {
+	Store(key string, r io.Reader) (int64, error)
+	Retrieve(key string, w io.Writer) (int64, error)
+}
+
+// Job is a unit of background work.
+type Job struct {
+	ID       string          `json:"id"`
+	Kind     string          `json:"kind"`
+	Payload  json.RawMessage `json:"payload,omitempty"`
+	Status   string          `json:"status"`
+	Attempts int             `json:"attempts"`
+	Error    string          `json:"error,omitempty"`
+	Progress json.RawMessage `json:"progress,omitempty"`
+	Created  time.Time       `json:"created"`
+	Started  *time.Time      `json:"started,omitempty"`
+	Finished *time.Time      `json:"finished,omitempty"`
+}
+
+// Handler does the work of a job. It can report progress, which is shown
+// in the status of the job, as often as it likes. Long running handlers
+// should return promptly once ctx is cancelled, when the queue shuts down.
+type Handler func(ctx context.Context, payload json.RawMessage, progress func(v interface{})) error
+
+// Kind describes how jobs of a kind are run.
+type Kind struct {
+	Handler Handler
+	// Number of times a job is tried before it fails, at least once
+	Attempts int
+	// Delay before the first retry, doubled for every retry
+	Backoff time.Duration
+	// Keep queued jobs in the storage. Jobs that are cheap to lose, or
+	// that are created by storage writes themselves, should not be.
+	Persist bool
+}
+
+// Queue runs jobs on a pool of workers.
+type Queue struct {
+	storage Storage
+	key     string
+
+	mu      sync.Mutex
+	kinds   map[string]Kind
+	jobs    map[string]*Job
+	pending chan string
+
+	// Cancelled on shutdown, after which no more jobs are started
+	ctx     context.Context
+	cancel  context.CancelFunc
+	running sync.WaitGroup
+
+	// Saves are serialized, so an older snapshot never overwrites a newer
+	saveMu sync.Mutex
+}
+
+// New returns a queue that keeps persistent jobs in storage under key.
+func New(storage Storage, key string) *Queue {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Queue{
+		storage: storage,
+		key:     key,
+		kinds:   map[string]Kind{},
+		jobs:    map[string]*Job{},
+		pending: make(chan string, queueSize),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
+
+// Register sets how jobs of the kind are run. Kinds must be registered
+// before jobs of the kind are enqueued or loaded.
+func (q *Queue) Register(name string, k Kind) {
+	if k.Attempts < 1 {
+		k.Attempts = 1
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.kinds[name] = k
+}
+
+func newID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Enqueue adds a job of the kind with the payload, which is encoded as
+// JSON.
+func (q *Queue) Enqueue(kind string, payload interface{}) (Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Job{}, err
+	}
+	id, err := newID()
+	if err != nil {
+		return Job{}, err
+	}
+
+	q.mu.Lock()
+	k, ok := q.kinds[kind]
+	if !ok {
+		q.mu.Unlock()
+		return Job{}, fmt.Errorf("unknown job kind %s", kind)
+	}
+	j := &Job{ID: id, Kind: kind, Payload: data, Status: Queued, Created: time.Now().UTC()}
+	select {
+	case q.pending <- id:
+	default:
+		q.mu.Unlock()
+		return Job{}, ErrQueueFull
+	}
+	q.jobs[id] = j
+	snapshot := *j
+	q.mu.Unlock()
+
+	if k.Persist {
+		q.save()
+	}
+	return snapshot, nil
+}
+
+// Load reads the jobs kept in the storage and queues those that had not
+// finished, including those that were running when the queue stopped.
+func (q *Queue) Load() error {
+	var buf bytes.Buffer
+	if _, err := q.storage.Retrieve(q.key, &buf); err != nil {
+		return err
+	}
+	var jobs []*Job
+	if err := json.Unmarshal(buf.Bytes(), &jobs); err != nil {
+		return err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, j := range jobs {
+		if _, ok := q.jobs[j.ID]; ok {
+			continue
+		}
+		if j.Status == Queued || j.Status == Running {
+			j.Status = Queued
+			select {
+			case q.pending <- j.ID:
+			default:
+				log.Printf("Job queue is full, job %s is dropped\n", j.ID)
+				continue
+			}
+		}
+		q.jobs[j.ID] = j
+	}
+	return nil
+}
+
+// save stores the persistent jobs.
+func (q *Queue) save() {
+	q.saveMu.Lock()
+	defer q.saveMu.Unlock()
+
+	q.mu.Lock()
+	var jobs []*Job
+	for _, j := range q.jobs {
+		if q.kinds[j.Kind].Persist {
+			jobs = append(jobs, j)
+		}
+	}
+	data, err := json.Marshal(jobs)
+	q.mu.Unlock()
+	if err != nil {
+		log.Printf("Unable to encode the job queue: %s\n", err)
+		return
+	}
+	if _, err := q.storage.Store(q.key, bytes.NewReader(data)); err != nil {
+		log.Printf("Unable to store the job queue: %s\n", err)
+	}
+}
+
+// Start runs the given number of workers.
+func (q *Queue) Start(workers int) {
+	if workers < 1 {
+		workers = 1
+	}
+	for i := 0; i < workers; i++ {
+		go q.work()
+	}
+}
+
+func (q *Queue) work() {
+	for {
+		select {
+		case <-q.ctx.Done():
+			return
+		case id := <-q.pending:
+			q.run(id)
+		}
+	}
+}
+
+// Shutdown cancels the running jobs and waits for their handlers to
+// return, or for ctx to be done. Jobs interrupted by the shutdown are
+// queued again, so that persistent ones run after a restart.
+func (q *Queue) Shutdown(ctx context.Context) error {
+	q.mu.Lock()
+	q.cancel()
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// update applies fn to the job while holding the lock, and stores the
+// queue if the job is persistent.
+func (q *Queue) update(j *Job, persist bool, fn func(j *Job)) {
+	q.mu.Lock()
+	fn(j)
+	q.mu.Unlock()
+	if persist {
+		q.save()
+	}
+}
+
+func (q *Queue) run(id string) {
+	q.mu.Lock()
+	j, ok := q.jobs[id]
+	var k Kind
+	if ok {
+		k = q.kinds[j.Kind]
+	}
+	// Checked with the lock held, so Shutdown waits for every job that
+	// starts
+	if q.ctx.Err() != nil {
+		ok = false
+	} else {
+		q.running.Add(1)
+		defer q.running.Done()
+	}
+	q.mu.Unlock()
+	if !ok || k.Handler == nil {
+		return
+	}
+
+	q.update(j, k.Persist, func(j *Job) {
+		now := time.Now().UTC()
+		j.Status = Running
+		j.Attempts++
+		j.Started = &now
+	})
+	progress := func(v interface{}) {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return
+		}
+		q.update(j, false, func(j *Job) { j.Progress = data })
+	}
+	err := k.Handler(q.ctx, j.Payload, progress)
+
+	if err != nil && q.ctx.Err() != nil {
+		q.update(j, k.Persist, func(j *Job) {
+			j.Status = Queued
+			j.Error = "interrupted by shutdown: " + err.Error()
+		})
+		return
+	}
+	retry := err != nil && j.Attempts < k.Attempts
+	q.update(j, k.Persist, func(j *Job) {
+		if retry {
+			j.Status = Queued
+			j.Error = err.Error()
+			return
+		}
+		now := time.Now().UTC()
+		j.Finished = &now
+		j.Status = Done
+		j.Error = ""
+		if err != nil {
+			j.Status = Failed
+			j.Error = err.Error()
+		}
+	})
+	if retry {
+		delay := k.Backoff << uint(j.Attempts-1)
+		time.AfterFunc(delay, func() {
+			select {
+			case q.pending <- id:
+			default:
+				log.Printf("Job queue is full, job %s is not retried\n", id)
+			}
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Job %s of kind %s failed: %s\n", id, j.Kind, err)
+	}
+	q.prune()
+}
+
+// prune forgets the oldest finished jobs beyond maxFinished.
+func (q *Queue) prune() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var finished []*Job
+	for _, j := range q.jobs {
+		if j.Finished != nil {
+			finished = append(finished, j)
+		}
+	}
+	if len(finished) <= maxFinished {
+		return
+	}
+	sort.Slice(finished, func(i, k int) bool {
+		return finished[i].Finished.After(*finished[k].Finished)
+	})
+	for _, j := range finished[maxFinished:] {
+		delete(q.jobs, j.ID)
+	}
+}
+
+// Get returns the job with the ID.
+func (q *Queue) Get(id string) (Job, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	j, ok := q.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return *j, true
+}
+
+// List returns the jobs, newest first.
+func (q *Queue) List() []Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	jobs := make([]Job, 0, len(q.jobs))
+	for _, j := range q.jobs {
+		jobs = append(jobs, *j)
+	}
+	sort.Slice(jobs, func(i, k int) bool {
+		return jobs[i].Created.After(jobs[k].Created)
+	})
+	return jobs
+}
+
+// Active reports whether a job of the kind is queued or running.
+func (q *Queue) Active(kind string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, j := range q.jobs {
+		if j.Kind == kind && (j.Status == Queued || j.Status == Running) {
+			return true
+		}
+	}
+	return false
+}
+
+// Every enqueues a job of the kind without payload at the interval,
+// unless one is already queued or running.
+func (q *Queue) Every(kind string, interval time.Duration) {
+	go func() {
+		for range time.Tick(interval) {
+			if q.Active(kind) {
+				continue
+			}
+			if _, err := q.Enqueue(kind, nil); err != nil {
+				log.Printf("Unable to queue %s: %s\n", kind, err)
+			}
+		}
+	}()
+}
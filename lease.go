@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/espebra/pastebin/pastebin"
+)
+
+// Lease is the content of a lock file, held by one instance of the
+// pastebin at a time until it expires.
+type Lease struct {
+	Holder  string    `json:"holder"`
+	Expires time.Time `json:"expires"`
+}
+
+// instanceID identifies this instance as the holder of leases.
+var instanceID = func() string {
+	host, _ := os.Hostname()
+	token, _ := pastebin.NewToken()
+	return host + "-" + token
+}()
+
+func leasePath(name string) string {
+	return filepath.Join(dataDirs()[0], "lock-"+name)
+}
+
+func readLease(path string) (Lease, error) {
+	var l Lease
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return l, err
+	}
+	err = json.Unmarshal(data, &l)
+	return l, err
+}
+
+// acquireLease takes the lease with the given name for ttl, so that when
+// several instances share the data directories only one of them runs
+// cleanup that removes files. The lease is a lock file created
+// exclusively in the first data directory. An expired lease, left by an
+// instance that stopped, is broken by renaming it away, which only one
+// instance can do. It reports whether the lease was taken, and returns a
+// function releasing it.
+func acquireLease(name string, ttl time.Duration) (func(), bool) {
+	// Without data directories there is nothing to share
+	if len(dataDirs()) == 0 {
+		return func() {}, true
+	}
+	path := leasePath(name)
+	data, err := json.Marshal(Lease{Holder: instanceID, Expires: time.Now().Add(ttl)})
+	if err != nil {
+		return nil, false
+	}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err == nil {
+			_, err = f.Write(data)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				os.Remove(path)
+				return nil, false
+			}
+			return func() { releaseLease(path) }, true
+		}
+		if !os.IsExist(err) {
+			return nil, false
+		}
+
+		l, err := readLease(path)
+		if err == nil && time.Now().Before(l.Expires) {
+			return nil, false
+		}
+		stale := path + "." + instanceID
+		if err := os.Rename(path, stale); err != nil {
+			return nil, false
+		}
+		// Another instance may have replaced the stale lease in between,
+		// in which case its lease is put back
+		if l, err := readLease(stale); err == nil && time.Now().Before(l.Expires) {
+			os.Rename(stale, path)
+			return nil, false
+		}
+		os.Remove(stale)
+	}
+	return nil, false
+}
+
+// releaseLease removes the lock file if this instance still holds it.
+func releaseLease(path string) {
+	if l, err := readLease(path); err == nil && l.Holder == instanceID {
+		os.Remove(path)
+	}
+}
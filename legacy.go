@@ -0,0 +1,114 @@
+package main
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// Expiry values of the pastebin.com API. Pastes do not expire here, so
+// they are accepted but pastes are kept until they are deleted.
+var legacyExpiry = map[string]bool{
+	"N": true, "10M": true, "1H": true, "1D": true, "1W": true, "2W": true, "1M": true, "6M": true, "1Y": true,
+}
+
+// Visibility of pastes by the api_paste_private values of the
+// pastebin.com API.
+var legacyVisibility = map[string]string{
+	"0": "public",
+	"1": "unlisted",
+	"2": "private",
+}
+
+// validLegacyKey reports whether key is one of -legacy-api-keys.
+func validLegacyKey(key string) bool {
+	valid := false
+	for _, k := range strings.Split(*legacyAPIKeysFlag, ",") {
+		k = strings.TrimSpace(k)
+		if k != "" && subtle.ConstantTimeCompare([]byte(key), []byte(k)) == 1 {
+			valid = true
+		}
+	}
+	return valid
+}
+
+// legacyError answers like the pastebin.com API does, with status 200 and
+// the reason in the body.
+func legacyError(w http.ResponseWriter, reason string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintf(w, "Bad API request, %s", reason)
+}
+
+// legacyPost creates pastes for tools speaking the api_post.php protocol
+// of pastebin.com, and responds with the URL of the paste. Only the paste
+// option is supported. The paste name and format are not stored.
+func legacyPost(w http.ResponseWriter, r *http.Request) {
+	if *legacyAPIKeysFlag == "" {
+		http.NotFound(w, r)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, requestBodyLimit())
+	if err := r.ParseForm(); err != nil {
+		legacyError(w, "unable to read the request")
+		return
+	}
+	if !validLegacyKey(r.PostFormValue("api_dev_key")) {
+		legacyError(w, "invalid api_dev_key")
+		return
+	}
+	if r.PostFormValue("api_option") != "paste" {
+		legacyError(w, "invalid api_option")
+		return
+	}
+	content := r.PostFormValue("api_paste_code")
+	if content == "" {
+		legacyError(w, "api_paste_code was empty")
+		return
+	}
+	if int64(len(content)) > maxPasteSizeFlag.Get() {
+		legacyError(w, "maximum paste file size exceeded")
+		return
+	}
+	if expiry := r.PostFormValue("api_paste_expire_date"); expiry != "" && !legacyExpiry[expiry] {
+		legacyError(w, "invalid api_expire_date")
+		return
+	}
+
+	visibility := *defaultVisibilityFlag
+	if private := r.PostFormValue("api_paste_private"); private != "" {
+		var ok bool
+		if visibility, ok = legacyVisibility[private]; !ok {
+			legacyError(w, "invalid api_paste_private")
+			return
+		}
+	}
+	if visibility == "private" && *secretFlag == "" {
+		legacyError(w, "private pastes are not enabled")
+		return
+	}
+
+	var p Paste
+	p.Content = content
+	p.Checksum = p.GetName()
+	if _, _, err := createPaste(&p, visibility, nil); err != nil {
+		log.Printf("Unable to write data: %s\n", err)
+		if err == errBlocked {
+			legacyError(w, "this content is not allowed")
+			return
+		}
+		if err == errSpam {
+			legacyError(w, "the paste looks like spam")
+			return
+		}
+		if storageUnavailable(w, err) || insufficientStorage(w, err) {
+			return
+		}
+		http.Error(w, "Unable to save "+p.Checksum, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte(requestURL(r, p.Location())))
+}
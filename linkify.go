@@ -0,0 +1,66 @@
+package main
+
+import (
+	"html"
+	"html/template"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// URLs are matched up to the first character that can not be part of
+// one in plain text. Only http and https are linked, so that the links
+// can not run scripts.
+var linkPattern = regexp.MustCompile("https?://[^\\s<>\"'`]+")
+
+// hasURL reports whether the paste has a URL to link.
+func hasURL(s string) bool {
+	return *linkifyFlag && linkPattern.MatchString(s)
+}
+
+// trimURL removes punctuation that ends the sentence around the URL
+// rather than the URL, and closing brackets without an opening one in
+// the URL.
+func trimURL(s string) string {
+	for len(s) > 0 {
+		switch c := s[len(s)-1]; c {
+		case '.', ',', ';', ':', '!', '?':
+		case ')':
+			if strings.Count(s, "(") >= strings.Count(s, ")") {
+				return s
+			}
+		case ']':
+			if strings.Count(s, "[") >= strings.Count(s, "]") {
+				return s
+			}
+		default:
+			return s
+		}
+		s = s[:len(s)-1]
+	}
+	return s
+}
+
+// linkify escapes the text, with the URLs in it as links that do not give
+// the linked page access to the window or ranking from the link.
+func linkify(s string) template.HTML {
+	var b strings.Builder
+	last := 0
+	for _, m := range linkPattern.FindAllStringIndex(s, -1) {
+		link := trimURL(s[m[0]:m[1]])
+		if u, err := url.Parse(link); err != nil || u.Host == "" {
+			continue
+		}
+		b.WriteString(html.EscapeString(s[last:m[0]]))
+		b.WriteString(`<a href="` + html.EscapeString(link) + `" rel="noopener nofollow" target="_blank">`)
+		b.WriteString(html.EscapeString(link) + `</a>`)
+		last = m[0] + len(link)
+	}
+	b.WriteString(html.EscapeString(s[last:]))
+	return template.HTML(b.String())
+}
+
+// renderLinks shows the text with the URLs in it as links.
+func renderLinks(s string) (template.HTML, error) {
+	return `<pre class="linkified">` + linkify(s) + `</pre>`, nil
+}
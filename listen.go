@@ -0,0 +1,54 @@
+package main
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// listen opens a listener for a single address from the -listen flag.
+// Addresses prefixed with unix: are bound as Unix domain sockets, while
+// IPv4 and IPv6 literals are bound to their own address family so that
+// 0.0.0.0:8080 and [::]:8080 can be served side by side.
+func listen(addr string) (net.Listener, error) {
+	if strings.HasPrefix(addr, "unix:") {
+		return listenUnix(strings.TrimPrefix(addr, "unix:"))
+	}
+
+	network := "tcp"
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		if ip.To4() != nil {
+			network = "tcp4"
+		} else {
+			network = "tcp6"
+		}
+	}
+	return net.Listen(network, addr)
+}
+
+func listenUnix(path string) (net.Listener, error) {
+	mode, err := strconv.ParseUint(*socketModeFlag, 8, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	// Remove a stale socket left behind by a previous run
+	if fi, err := os.Stat(path); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		os.Remove(path)
+	}
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, os.FileMode(mode)); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
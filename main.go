@@ -3,79 +3,491 @@ package main
 import (
 	"bytes"
 	"flag"
-	"crypto/sha256"
-	"encoding/hex"
+	"fmt"
 	"github.com/espebra/blobstore"
 	"github.com/espebra/blobstore/common"
+	"github.com/espebra/pastebin/pastebin"
 	"github.com/gorilla/mux"
 	"html/template"
-	"io"
 	"log"
+	"net"
 	"net/http"
+	"net/url"
+	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
 var (
         bindHostFlag = flag.String("host", "127.0.0.1", "Bind host")
         bindPortFlag = flag.Int("port", 8080, "Bind port")
-        dataDirFlag = flag.String("directory", "/var/lib/pastebin", "Directory to store pastes")
+        dataDirFlag = flag.String("directory", "/var/lib/pastebin", "Directory to store pastes. Pastes are sharded over comma separated directories")
+        listenFlag = flag.String("listen", "", "Comma separated list of addresses to listen on, e.g. 0.0.0.0:8080,[::]:8080,unix:/run/pastebin.sock. Overrides -host and -port, and is overridden by sockets passed by systemd socket activation")
+        socketModeFlag = flag.String("socket-mode", "0660", "File mode of Unix domain sockets")
+        reservedSlugsFlag = flag.String("reserved-slugs", "", "Comma separated list of slugs that can not be claimed")
+        privateFlag = flag.Bool("private", false, "Require a secret key in paste URLs in addition to the checksum")
+        secretFlag = flag.String("secret", "", "Server secret used to sign URLs and challenges, and to derive the keys of private pastes created before every paste got a random key")
+        replicaDirFlag = flag.String("replica-directory", "", "Directory to replicate pastes to in the background, and to read from when the primary storage fails")
+        storageSecretsFlag = flag.String("storage-secrets", "", "Comma separated list of secrets used to derive storage keys and seal the checksums in indexes, current first. Older secrets are only used to read and migrate pastes")
+        viewsFlushFlag = flag.Duration("views-flush-interval", time.Minute, "How often buffered view counts are written to the storage")
+        adminUserFlag = flag.String("admin-user", "admin", "Username for the admin area")
+        adminPasswordFlag = flag.String("admin-password", "", "Password for the admin area. The admin area is disabled when empty, unless there are accounts in -users")
+        captchaProviderFlag = flag.String("captcha-provider", "", "CAPTCHA required to create pastes without signing in, hcaptcha or turnstile. Clients other than the form send its response in the X-Captcha-Response header")
+        captchaSiteKeyFlag = flag.String("captcha-site-key", "", "CAPTCHA site key")
+        captchaSecretFlag = flag.String("captcha-secret", "", "CAPTCHA secret key")
+        spamActionFlag = flag.String("spam-action", "flag", "What to do with pastes that look like spam, and form submissions that look like bots: reject, flag for review or off")
+        spamMinSubmitTimeFlag = flag.Duration("spam-min-submit-time", 2*time.Second, "Minimum time between showing and submitting the form")
+        spamMaxURLRatioFlag = flag.Float64("spam-max-url-ratio", 0.5, "Maximum share of words in a paste that can be links")
+        defaultVisibilityFlag = flag.String("default-visibility", "unlisted", "Visibility of pastes that do not specify one: public, unlisted or private")
+        tcpListenFlag = flag.String("tcp-listen", "", "Address to accept pastes over plain TCP on, e.g. :9999 for use with nc")
+        tcpTimeoutFlag = flag.Duration("tcp-timeout", 30*time.Second, "Maximum time to receive a paste over TCP")
+        tcpIdleTimeoutFlag = flag.Duration("tcp-idle-timeout", 2*time.Second, "Idle time after which a paste received over TCP is complete")
+        tcpRateFlag = reloadableIntFlag("tcp-rate", 10, "Maximum number of pastes per minute and client IP over TCP")
+        tcpMaxConnectionsFlag = flag.Int("tcp-max-connections", 100, "Maximum number of pastes received over TCP at once. Connections beyond it are turned away")
+        tcpMaxBufferedFlag = flag.Int64("tcp-max-buffered", 256<<20, "Maximum number of bytes of the pastes being received over TCP at once. Connections are turned away while it is reached")
+        baseURLFlag = flag.String("base-url", "", "Public URL the pastebin is served from, e.g. https://example.com/paste/")
+        commentsFlag = flag.Bool("comments", false, "Allow comments on pastes")
+        commentRateFlag = reloadableIntFlag("comment-rate", 5, "Maximum number of comments per minute and client IP")
+        maxPasteSizeFlag = reloadableIntFlag("max-paste-size", 32<<20, "Maximum size of a paste in bytes. Request bodies are limited to what a paste of this size needs")
+        readTimeoutFlag = flag.Duration("read-timeout", 10*time.Second, "Maximum time to read a request, including the body")
+        writeTimeoutFlag = flag.Duration("write-timeout", 10*time.Second, "Maximum time to write a response")
+        idleTimeoutFlag = flag.Duration("idle-timeout", 2*time.Minute, "Maximum time to wait for the next request on a keep-alive connection")
+        anonymousFlag = flag.Bool("anonymous", false, "Anonymity friendly mode for onion services: no view counts, analytics or access logs, client addresses removed from logs, and no features that need JavaScript")
+        powDifficultyFlag = reloadableIntFlag("pow-difficulty", 0, "Number of leading zero bits of the proof of work required to create pastes. Disabled when 0")
+        storageRetriesFlag = flag.Int("storage-retries", 2, "Number of times a failed storage call is retried")
+        storageBackoffFlag = flag.Duration("storage-backoff", 100*time.Millisecond, "Delay before the first retry of a failed storage call, doubled for every retry")
+        storageBreakerThresholdFlag = flag.Int("storage-breaker-threshold", 5, "Consecutive storage failures after which storage calls fail fast. Disabled when 0")
+        storageBreakerCooldownFlag = flag.Duration("storage-breaker-cooldown", 30*time.Second, "How long storage calls fail fast after too many failures")
+        fsckIntervalFlag = flag.Duration("fsck-interval", 0, "How often to check the storage for pastes without metadata and orphaned records. Disabled when 0")
+        compressThresholdFlag = flag.Int("compress-threshold", 16<<10, "Pastes of at least this many bytes are stored gzip compressed. Compression is disabled when 0")
+        imageMaxSizeFlag = reloadableIntFlag("image-max-size", 10<<20, "Maximum size in bytes of uploaded images. Image uploads are disabled when 0")
+        accessLogFlag = flag.Bool("access-log", false, "Log accesses to each paste with truncated client IP and user agent, for the owner to see")
+        middlewareFlag = flag.String("middleware", "concurrency,ratelimit,auth,cors", "Comma separated list of middleware to run requests through, the first one outermost. Available: logging, concurrency, ratelimit, auth, cors, compression")
+        configFlag = flag.String("config", "", "TOML file with options, like max-paste-size = 1000000. Options on the command line and in PASTEBIN_* environment variables take precedence. Limits are reloaded on SIGHUP")
+        jobWorkersFlag = flag.Int("job-workers", 2, "Number of workers running background jobs, such as replication and bulk deletes")
+        trashRetentionFlag = flag.Duration("trash-retention", 30*24*time.Hour, "How long deleted pastes can be restored by the admin before they are removed from the storage. Kept forever when 0")
+        rateLimitFlag = reloadableIntFlag("rate-limit", 0, "Maximum number of requests per client IP per minute, with the ratelimit middleware. Disabled when 0")
+        authFlag = flag.String("auth", "", "Require these credentials, given as user:password, for every request with the auth middleware")
+        corsOriginsFlag = flag.String("cors-origins", "", "Comma separated list of origins allowed to use the API and raw pastes from browsers with the cors middleware, or * for any")
+        devFlag = flag.Bool("dev", false, "Run for development without a data directory. Pastes are kept in memory and lost on exit, every request is logged and cookies can be read by scripts")
+        devSeedFlag = flag.Bool("dev-seed", false, "Create a few example pastes at startup, with -dev")
+        tombstoneRetentionFlag = flag.Duration("tombstone-retention", 365*24*time.Hour, "How long to remember that purged pastes were deleted, to tell visitors instead of that they do not exist. Kept forever when 0")
+        legacyAPIKeysFlag = flag.String("legacy-api-keys", "", "Comma separated list of api_dev_key values accepted by the pastebin.com compatible /api/api_post.php. Disabled when empty")
+        templateDirFlag = flag.String("template-dir", "", "Directory with templates that override the embedded ones of the same name")
+        instanceNameFlag = flag.String("instance-name", "Pastebin", "Name of the instance shown on every page")
+        logoURLFlag = flag.String("logo-url", "", "URL of a logo shown next to the instance name")
+        footerLinksFlag = flag.String("footer-links", "", "Comma separated list of links shown at the bottom of the public pages, given as title=URL")
+        contactEmailFlag = flag.String("contact-email", "", "Contact address shown at the bottom of the public pages")
+        assetsDirFlag = flag.String("assets-dir", "", "Source directory to read the templates and static files from instead of the embedded ones, picking up changes without a rebuild. For development")
+        virtualHostsDirFlag = flag.String("virtual-hosts-dir", "", "Directory with a TOML file per hostname, like paste.example.com.toml, setting instance-name, logo-url, footer-links or contact-email for requests to that host")
+        usersFlag = flag.String("users", "", "Comma separated list of accounts, given as user:password:role with the role user, moderator or admin. The -admin-user account is an admin")
+        permissionsFlag = flag.String("permissions", "", "Comma separated list of permission=role pairs changing the role required for create, admin, moderate, stats, jobs, config, audit or debug, e.g. stats=moderator")
+        signedURLMaxTTLFlag = flag.Duration("signed-url-max-ttl", 7*24*time.Hour, "Longest time a signed raw URL, made with -secret, can be valid for. Signed URLs are disabled when 0")
+        uploadExpiryFlag = flag.Duration("upload-expiry", 24*time.Hour, "How long an unfinished chunked upload can be resumed before its chunks are removed. Kept until finished when 0")
+        linkifyFlag = flag.Bool("linkify", true, "Show text pastes with URLs in them next to a view with the URLs as links")
+        idempotencyTTLFlag = flag.Duration("idempotency-ttl", 24*time.Hour, "How long the response to a request with an Idempotency-Key is sent again to retries of the request, instead of creating the pastes again. Disabled when 0")
+        concurrencyLimitsFlag = flag.String("concurrency-limits", "/api/v1/archive=4,/set/=32,/raw/=128,/stream/=256,*=512", "Maximum number of requests in flight per path prefix, given as prefix=limit separated by commas, with * for the paths not listed, with the concurrency middleware")
+        concurrencyWaitFlag = flag.Duration("concurrency-wait", 100*time.Millisecond, "How long a request over the limit of -concurrency-limits waits for another to finish, before it is answered with 503")
+        debugListenFlag = flag.String("debug-listen", "", "Loopback address to serve the pprof, expvar and runtime debug endpoints on without signing in, e.g. 127.0.0.1:6060. They are also served under /debug/ to users with the debug permission")
+        storageUsageIntervalFlag = flag.Duration("storage-usage-interval", time.Hour, "How often to count the pastes and bytes in the data directories, correcting the usage counted as pastes are created and purged. Disabled when 0")
+        storageMaxBytesFlag = reloadableIntFlag("storage-max-bytes", 0, "Bytes the pastes in the data directories can take up before no more pastes can be created. Disabled when 0")
+        purgeWorkersFlag = flag.Int("purge-workers", 4, "Number of objects removed in parallel when purging deleted pastes and finished uploads")
+        purgeRateFlag = reloadableIntFlag("purge-rate", 0, "Maximum number of objects removed per second when purging deleted pastes and finished uploads, so that large purges do not slow down serving pastes. Unlimited when 0")
+        notFoundTTLFlag = flag.Duration("not-found-ttl", 10*time.Second, "How long pastes that were not found are answered as not found without reading the storage again, to blunt scans probing for checksums. Disabled when 0")
+        hotlinkProtectionFlag = flag.Bool("hotlink-protection", false, "Refuse raw pastes to pages of other sites than the pastebin and -hotlink-allow, unless the raw URL is signed. Requests without a Referer are served")
+        hotlinkAllowFlag = flag.String("hotlink-allow", "", "Comma separated list of hosts, like example.com or *.example.com, whose pages may link to raw pastes with -hotlink-protection")
+        egressRateFlag = reloadableIntFlag("egress-rate", 0, "Maximum number of bytes per second sent to each client IP by the raw and download endpoints. Unlimited when 0")
+        egressQuotaFlag = reloadableIntFlag("egress-quota", 0, "Maximum number of bytes per day sent to each client IP by the raw and download endpoints, after which they answer 429 until midnight UTC. Unlimited when 0")
+        trustedProxiesFlag = flag.String("trusted-proxies", "", "Comma separated list of addresses and networks, like 10.0.0.0/8, of load balancers whose X-Forwarded-For or X-Real-IP header gives the client IP used for rate limits, quotas and logs, with unix for Unix domain sockets. Otherwise the client IP is the address of the connection")
+        proxyProtocolFlag = flag.Bool("proxy-protocol", false, "Read the client address from the PROXY protocol header, version 1 or 2, that -trusted-proxies send at the start of every connection, including over -tcp-listen")
+        ipv6PrefixFlag = flag.Int("ipv6-prefix", 64, "Length of the network IPv6 clients are rate limited by, as one user can have a whole /64. IPv4 clients are limited by their address")
+        captchaExemptAPIFlag = flag.Bool("captcha-exempt-api", false, "Only require the CAPTCHA from the form, not from curl, API clients and -tcp-listen, which can be required to do proof of work instead")
 )
 
-var storage common.Provider
+// Storage is the part of the storage providers used by the pastebin.
+type Storage = pastebin.Storage
+
+var storage Storage
+
+// service stores and reads pastes in the storage.
+var service *pastebin.Service
+
+// newService returns the service for the storage, configured by the
+// command line flags.
+func newService(storage Storage) *pastebin.Service {
+	return pastebin.New(storage, pastebin.Options{
+		StorageSecrets:    storageSecrets(),
+		KeySecret:         *secretFlag,
+		Private:           *privateFlag,
+		DefaultVisibility: *defaultVisibilityFlag,
+		CompressThreshold: *compressThresholdFlag,
+		NotFoundTTL:       *notFoundTTLFlag,
+		Allow: func(p pastebin.Paste) bool {
+			return blocked.Allows(Paste{Checksum: p.Checksum, Content: p.Content})
+		},
+	})
+}
 
 type Paste struct {
-	Content  string `json:"content"`
-	Checksum string `json:"checksum"`
-	Message  string `json:"message"`
-	Status   string `json:"status"`
+	Content     string   `json:"content"`
+	Checksum    string   `json:"checksum"`
+	Key         string   `json:"key,omitempty"`
+	Views       int64    `json:"views"`
+	Tags        []string `json:"tags,omitempty"`
+	Visibility  string   `json:"visibility,omitempty"`
+	ContentType string   `json:"content_type,omitempty"`
+	Binary      bool     `json:"binary,omitempty"`
+	Encrypted   bool     `json:"encrypted,omitempty"`
+	Lines       int      `json:"lines,omitempty"`
+	Language    string   `json:"language,omitempty"`
+	Charset     string   `json:"charset,omitempty"`
+	// Only read when creating the paste
+	DeletePassphrase string `json:"-"`
+	// Only set in the response to creating the paste
+	DeleteToken string `json:"delete_token,omitempty"`
+	Duplicate   bool   `json:"duplicate,omitempty"`
+	// Comments arranged in threads, when comments are enabled
+	Comments []*Comment `json:"-"`
+	Message  string     `json:"message"`
+	Status   string     `json:"status"`
 }
 
 func (v Paste) GetName() string {
-	hasher := sha256.New()
-	hasher.Write([]byte(v.Content))
-	return hex.EncodeToString(hasher.Sum(nil))
+	return pastebin.Checksum(v.Content)
+}
+
+// retrievePaste returns the paste, with key as its key if it requires
+// one. The key is not checked.
+func retrievePaste(checksum, key string) (Paste, error) {
+	p, _, err := retrieveStoredPaste(checksum, key)
+	return p, err
+}
+
+// retrieveStoredPaste returns the paste along with the object it is stored
+// as, which is compressed for large pastes.
+func retrieveStoredPaste(checksum, key string) (Paste, []byte, error) {
+	if blocked.Contains(checksum) {
+		return Paste{}, nil, errBlocked
+	}
+	sp, stored, err := service.Retrieve(checksum)
+	if err != nil {
+		return Paste{}, nil, err
+	}
+	if !service.RequiresKey(Meta{Visibility: sp.Visibility}) {
+		key = ""
+	}
+	return Paste{
+		Content:     sp.Content,
+		Checksum:    sp.Checksum,
+		Key:         key,
+		Tags:        sp.Tags,
+		Visibility:  sp.Visibility,
+		ContentType: sp.ContentType,
+		Binary:      sp.Binary,
+		Encrypted:   sp.Encrypted,
+		Lines:       sp.Lines,
+		Language:    sp.Language,
+		Charset:     sp.Charset,
+	}, stored, nil
+}
+
+// basePath returns the path prefix of -base-url without a trailing slash,
+// or an empty string when the pastebin is served from the root.
+func basePath() string {
+	u, err := url.Parse(*baseURLFlag)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(u.Path, "/")
+}
+
+// absURL returns path as seen by clients, including the scheme and host of
+// -base-url when it is set.
+func absURL(path string) string {
+	return strings.TrimSuffix(*baseURLFlag, "/") + path
+}
+
+// storePaste stores the paste without touching its metadata, and returns
+// the number of bytes stored and the encoding they are stored with.
+func storePaste(p Paste) (int64, string, error) {
+	if !blocked.Allows(p) {
+		return 0, "", errBlocked
+	}
+	data, encoding := service.Encode([]byte(p.Content))
+	nBytes, err := storage.Store(service.ObjectKey(p.Checksum), bytes.NewReader(data))
+	return nBytes, encoding, err
+}
+
+// createPaste stores the paste and updates its metadata, and returns a new
+// delete token for it. The key of the paste is set if it requires one.
+func createPaste(p *Paste, visibility string, tags []string) (int64, string, error) {
+	if storageFull() {
+		return 0, "", errStorageFull
+	}
+	if checkContent(*p) {
+		return 0, "", errSpam
+	}
+	created, err := service.Create(p.Content, pastebin.CreateOptions{
+		Visibility:       visibility,
+		Tags:             tags,
+		Encrypted:        p.Encrypted,
+		DeletePassphrase: p.DeletePassphrase,
+		Language:         p.Language,
+	})
+	if err != nil {
+		return 0, "", err
+	}
+	analytics.Created(len(p.Content))
+	if !created.Duplicate {
+		usage.Added(created.Stored)
+		addRecent(created.Checksum, created.Stored)
+	}
+	p.Key = created.Key
+	p.Duplicate = created.Duplicate
+	return created.Stored, created.DeleteToken, nil
+}
+
+// renderTemplate executes the template defined as name in the template
+// file.
+func renderTemplate(w http.ResponseWriter, r *http.Request, file, name string, data interface{}) {
+	asset, err := readTemplate(file)
+	if err != nil {
+		log.Printf("Asset not found: %s\n", err)
+		http.Error(w, "Unable to render the page", http.StatusInternalServerError)
+		return
+	}
+
+	locale := requestLocale(r)
+	w.Header().Set("Content-Language", locale)
+
+	t := template.New(name).Funcs(template.FuncMap{
+		"base":    basePath,
+		"url":     absURL,
+		"preview": preview,
+		"hexdump": hexdump,
+		"isImage": isImage,
+		"can": func(permission string) bool {
+			return allowed(r, permission)
+		},
+		"brand": func() Branding {
+			return branding(r)
+		},
+		"rendered": renderPasteContent,
+		"anonymous": func() bool {
+			return *anonymousFlag
+		},
+		"pow": func() *PowChallenge {
+			if !powEnabled() {
+				return nil
+			}
+			c := newPowChallenge()
+			return &c
+		},
+		"captcha": func() captchaProvider {
+			p, _ := captcha()
+			return p
+		},
+		"captchaSiteKey": func() string {
+			return *captchaSiteKeyFlag
+		},
+		"now": func() int64 {
+			return time.Now().Unix()
+		},
+		"settings": func() Settings {
+			return readSettings(r)
+		},
+		"T": func(s string, args ...interface{}) string {
+			return translate(locale, s, args...)
+		},
+		"lang": func() string {
+			return locale
+		},
+		"whitespace": showWhitespace,
+		"date": func(t time.Time) string {
+			return formatDate(locale, t)
+		},
+		"locales": func() map[string]string {
+			return localeNames
+		},
+		"highlightThemes": func() []string {
+			return highlightThemes
+		},
+		"comments": func() bool {
+			return *commentsFlag
+		},
+		"accessLog": func() bool {
+			return *accessLogFlag
+		},
+	})
+	t, err = t.Parse(string(asset))
+	if err != nil {
+		log.Printf("Unable to parse template: %s\n", err)
+		http.Error(w, "Unable to render the page", http.StatusInternalServerError)
+		return
+	}
+	// The response may be partly written, so the error is only logged
+	err = t.Execute(w, data)
+	if err != nil {
+		log.Printf("Unable to execute template %s: %s\n", file, err)
+	}
+}
+
+func renderPaste(w http.ResponseWriter, r *http.Request, p Paste) {
+	saveViewerSettings(w, r)
+	renderTemplate(w, r, "templates/pastebin.html", "paste", p)
 }
 
 func savePaste(w http.ResponseWriter, r *http.Request) {
+	if plainTextClient(r) {
+		savePlainPaste(w, r)
+		return
+	}
+
 	var p Paste
 
+	r.Body = http.MaxBytesReader(w, r.Body, requestBodyLimit())
 	p.Content = r.FormValue("content")
+	if int64(len(p.Content)) > maxPasteSizeFlag.Get() {
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		p.Content = ""
+		p.Message = "The paste is larger than the limit of " + strconv.FormatInt(maxPasteSizeFlag.Get(), 10) + " bytes."
+		p.Status = "error"
+		renderPaste(w, r, p)
+		return
+	}
+	image, err := readImageUpload(r)
+	if err != nil {
+		log.Printf("Unable to read uploaded image: %s\n", err)
+		p.Message = "Unable to upload the image: " + err.Error()
+		p.Status = "error"
+		renderPaste(w, r, p)
+		return
+	}
+	if image != "" {
+		p.Content = image
+	}
+	if err := verifyChecksum(expectedChecksum(r), p.Content); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		p.Content = ""
+		p.Message = err.Message
+		p.Status = "error"
+		renderPaste(w, r, p)
+		return
+	}
 	p.Checksum = p.GetName()
+	p.Encrypted = r.FormValue("encrypted") != ""
+	p.DeletePassphrase = r.FormValue("delete_passphrase")
 
 	if r.FormValue("save") != "" {
-		reader := io.Reader(
-			bytes.NewReader([]byte(p.Content)),
-		)
+		if p.Encrypted && (image != "" || !pastebin.ValidCiphertext(p.Content)) {
+			w.WriteHeader(http.StatusBadRequest)
+			p.Content = ""
+			p.Message = "The paste was not encrypted by the browser, and was not saved."
+			p.Status = "error"
+			renderPaste(w, r, p)
+			return
+		}
+		if err := checkCaptcha(r, false); err != nil {
+			log.Printf("CAPTCHA verification failed: %s\n", err)
+			p.Message = "Please complete the CAPTCHA to save the paste."
+			p.Status = "error"
+			renderPaste(w, r, p)
+			return
+		}
+		if err := checkPow(r); err != nil {
+			log.Printf("Proof of work failed: %s\n", err)
+			w.WriteHeader(http.StatusForbidden)
+			p.Message = "Unable to save the paste: " + err.Error() + "."
+			p.Status = "error"
+			renderPaste(w, r, p)
+			return
+		}
 
-		nBytes, err := storage.Store(p.Checksum, reader)
+		tags, err := pastebin.ParseTags(r.FormValue("tags"))
 		if err != nil {
-			log.Println("Unable to write data: %s\n", err)
+			p.Message = err.Error()
+			p.Status = "error"
+			renderPaste(w, r, p)
+			return
+		}
+
+		visibility := r.FormValue("visibility")
+		if visibility == "" {
+			visibility = *defaultVisibilityFlag
+		}
+		if !pastebin.ValidVisibility(visibility) || (visibility == "private" && *secretFlag == "") {
+			p.Message = "Invalid visibility " + visibility
+			p.Status = "error"
+			renderPaste(w, r, p)
+			return
+		}
+
+		if checkSpam(r, p) {
+			p.Message = "The paste looks like spam and was not saved."
+			p.Status = "error"
+			renderPaste(w, r, p)
+			return
+		}
+
+		nBytes, token, err := createPaste(&p, visibility, tags)
+		if err != nil {
+			log.Printf("Unable to write data: %s\n", err)
 			p.Message = "Unable to save " + p.Checksum
+			if err == errBlocked {
+				p.Message = "This content is not allowed."
+			}
+			if err == errSpam {
+				p.Message = "The paste looks like spam and was not saved."
+			}
+			if err == pastebin.ErrShortPassphrase {
+				p.Message = "The delete passphrase must have at least " + strconv.Itoa(pastebin.MinPassphraseLength) + " characters."
+			}
+			if err == errStorageUnavailable {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				p.Message = "The storage is unavailable, try again later."
+			}
+			if err == errStorageFull {
+				w.WriteHeader(http.StatusInsufficientStorage)
+				p.Message = "The storage is full, no pastes can be created."
+			}
 			p.Status = "error"
 		} else {
 			p.Message = strconv.FormatInt(nBytes, 10) + " bytes saved as " + p.GetName()
 			p.Status = "success"
+			p.DeleteToken = token
+
+			location := p.Location()
+			if slug := r.FormValue("slug"); slug != "" {
+				if err := claimSlug(slug, p.Checksum); err != nil {
+					log.Printf("Unable to claim slug %s: %s\n", slug, err)
+					p.Message = "Saved as " + p.Checksum + ", but the slug " + slug + " could not be used: " + err.Error()
+					p.Status = "warning"
+					renderPaste(w, r, p)
+					return
+				}
+				location = "/" + slug
+				if p.Key != "" {
+					location += "/" + p.Key
+				}
+			}
+			query := url.Values{}
+			if token != "" {
+				query.Set("delete_token", token)
+			}
+			if p.Duplicate {
+				query.Set("duplicate", "1")
+			}
+			if len(query) > 0 {
+				location += "?" + query.Encode()
+			}
 
-			http.Redirect(w, r, "/"+p.Checksum, 302)
+			http.Redirect(w, r, basePath()+location, 302)
 			return
 		}
 	}
 
-	data, err := Asset("templates/pastebin.html")
-	if err != nil {
-		log.Fatalf("Asset not found: %s\n", err)
-	}
-
-	t := template.New("paste")
-	t, err = t.Parse(string(data))
-	if err != nil {
-		log.Fatalf("Unable to parse template: %s\n", err)
-	}
-	err = t.Execute(w, p)
-	if err != nil {
-		log.Fatalf("Unable to parse template: %s\n", err)
-	}
+	renderPaste(w, r, p)
 }
 
 func readPaste(w http.ResponseWriter, r *http.Request) {
@@ -85,55 +497,387 @@ func readPaste(w http.ResponseWriter, r *http.Request) {
 	var p Paste
 
 	if checksum != "" {
-		var buf bytes.Buffer
-		_, err := storage.Retrieve(checksum, &buf)
+		var err error
+		if !pastebin.IsChecksum(checksum) {
+			if resolved, err := resolveSlug(checksum); err == nil {
+				checksum = resolved
+			}
+		}
+		// Slugs point at pastes that may require a key as well
+		if !service.Authorize(checksum, vars["key"]) {
+			w.WriteHeader(http.StatusNotFound)
+			p.Message = "Paste " + checksum + " does not exist."
+			p.Status = "error"
+			renderPaste(w, r, p)
+			return
+		}
+
+		// The page may change with the template, so clients revalidate,
+		// and it is translated and themed according to the request
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Vary", "Accept-Language, Cookie")
+		etag := `W/"` + checksum + `-html"`
+		scope := service.Scope(checksum, vars["key"])
+		var comments Comments
+		if *commentsFlag {
+			comments, _ = retrieveComments(scope)
+			etag = `W/"` + checksum + `-html-` + strconv.Itoa(comments.Revision) + `"`
+		}
+		if available(checksum) && notModified(w, r, etag) {
+			views.Add(scope)
+			accesses.Add(checksum, r)
+			return
+		}
+
+		p, err = retrievePaste(checksum, vars["key"])
 		if err != nil {
 			log.Println(err)
+			w.Header().Del("ETag")
 			p.Message = "Paste " + checksum + " does not exist."
 			p.Status = "error"
+			if err == errStorageUnavailable {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				p.Message = "The storage is unavailable, try again later."
+			} else if message, ok := goneMessage(checksum, err); ok {
+				w.WriteHeader(http.StatusGone)
+				p.Message = message
+			}
+		} else {
+			views.Add(scope)
+			accesses.Add(checksum, r)
+			p.Views = views.Get(scope)
+			p.Comments = comments.thread()
+			if token := r.FormValue("delete_token"); token != "" {
+				p.DeleteToken = token
+				p.Message = "Keep the delete token " + token + " to delete the paste later."
+				if r.FormValue("duplicate") != "" {
+					p.Message = "This content was pasted before, so this is the existing paste. Keep the delete token " + token + " to release it later, which deletes it once everyone who pasted it has."
+				}
+				p.Status = "info"
+			}
 		}
-		p.Content = buf.String()
-		p.Checksum = p.GetName()
 	}
 
-	data, err := Asset("templates/pastebin.html")
+	renderPaste(w, r, p)
+}
+
+func clonePaste(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	checksum := vars["checksum"]
+
+	var p Paste
+	var err error
+	if service.Authorize(checksum, r.FormValue("key")) {
+		p, err = retrievePaste(checksum, r.FormValue("key"))
+	} else {
+		err = fmt.Errorf("invalid key for %s", checksum)
+	}
 	if err != nil {
-		log.Fatalf("Asset not found: %s\n", err)
+		log.Println(err)
+		p.Message = "Paste " + checksum + " does not exist."
+		p.Status = "error"
+	} else {
+		// Submit the form as a new paste
+		p.Checksum = ""
+		p.Key = ""
+		p.Message = "Cloned from " + checksum + ". Edit and save to create a new paste."
+		p.Status = "info"
 	}
 
-	t := template.New("paste")
-	t, err = t.Parse(string(data))
-	if err != nil {
-		log.Fatalf("Unable to parse template: %s\n", err)
+	renderPaste(w, r, p)
+}
+
+// newStorage sets up a filesystem provider for each directory, sharding
+// over them when there is more than one.
+func newStorage(dirs []string) Storage {
+	sharded := &shardedStorage{}
+	for _, dir := range dirs {
+		provider := blobstore.New("filesystem", &common.ProviderData{})
+		cfg := map[string]string{}
+		cfg["basedir"] = strings.TrimSpace(dir)
+		log.Println("Using basedir " + cfg["basedir"])
+		provider.Setup(cfg)
+		sharded.names = append(sharded.names, cfg["basedir"])
+		sharded.shards = append(sharded.shards, newResilientStorage(cfg["basedir"], fileStorage{provider, cfg["basedir"]}))
 	}
-	err = t.Execute(w, p)
-	if err != nil {
-		log.Fatalf("Unable to parse template: %s\n", err)
+	switch len(sharded.shards) {
+	case 0:
+		log.Fatal("No storage directory given")
+	case 1:
+		return sharded.shards[0]
 	}
+	return sharded
 }
 
-func main() {
-	flag.Parse()
-	r := mux.NewRouter()
+// RegisterRoutes registers the handlers of the pastebin on r, and returns
+// r wrapped with the middleware, the first one outermost. Headers that
+// protect visitors are always set, whatever the middleware.
+func RegisterRoutes(r *mux.Router, middleware []Middleware) http.Handler {
+	r.HandleFunc("/raw/{checksum}", egressLimited(rawPaste)).Methods("GET", "HEAD")
+	r.HandleFunc("/raw/{checksum}/{key}", egressLimited(rawPaste)).Methods("GET", "HEAD")
+	r.HandleFunc("/s/{token}", readShare).Methods("GET")
+	r.HandleFunc("/tags/{tag}", readTag).Methods("GET")
+	r.HandleFunc("/embed/{checksum}", embedPaste).Methods("GET")
+	r.HandleFunc("/embed/{checksum}/{key}", embedPaste).Methods("GET")
+	r.HandleFunc("/oembed", oembed).Methods("GET")
+	r.HandleFunc("/download/{checksum}", egressLimited(downloadPaste)).Methods("GET")
+	r.HandleFunc("/download/{checksum}/{key}", egressLimited(downloadPaste)).Methods("GET")
+	r.HandleFunc("/card/{checksum}", cardImage).Methods("GET")
+	r.HandleFunc("/thumb/{checksum}", thumbnailImage).Methods("GET")
+	r.HandleFunc("/thumb/{checksum}/{key}", thumbnailImage).Methods("GET")
+	r.HandleFunc("/card/{checksum}/{key}", cardImage).Methods("GET")
+	r.HandleFunc("/settings", saveSettings).Methods("POST")
+	r.HandleFunc("/stream", requirePermission("create", createStream)).Methods("POST")
+	r.HandleFunc("/stream/{id}", readStream).Methods("GET")
+	r.HandleFunc("/stream/{id}", requirePermission("create", appendStream)).Methods("POST", "PUT")
+	r.HandleFunc("/stream/{id}/events", streamEvents).Methods("GET")
+	r.HandleFunc("/admin", requirePermission("admin", adminIndex)).Methods("GET")
+	r.HandleFunc("/admin/stats", requirePermission("stats", adminAnalytics)).Methods("GET")
+	r.HandleFunc("/admin/reports", requirePermission("moderate", adminReports)).Methods("GET")
+	r.HandleFunc("/admin/access/{checksum}", requirePermission("stats", adminAccessLog)).Methods("GET")
+	r.HandleFunc("/admin/reports", requirePermission("moderate", adminResolveReport)).Methods("POST")
+	r.HandleFunc("/admin/pin", requirePermission("moderate", adminPin)).Methods("POST")
+	r.HandleFunc("/admin/delete", requirePermission("moderate", adminDelete)).Methods("POST")
+	r.HandleFunc("/admin/jobs", requirePermission("jobs", adminJobs)).Methods("GET")
+	r.HandleFunc("/admin/trash", requirePermission("moderate", adminTrash)).Methods("GET")
+	r.HandleFunc("/admin/trash", requirePermission("moderate", adminResolveTrash)).Methods("POST")
+	r.HandleFunc("/admin/cleanup", requirePermission("jobs", adminCleanup)).Methods("POST")
+	r.HandleFunc("/api/v1/admin/blocklist", requirePermission("moderate", apiBlocklist)).Methods("GET", "POST", "DELETE")
+	r.HandleFunc("/api/v1/admin/stats", requirePermission("stats", apiAnalytics)).Methods("GET")
+	r.HandleFunc("/metrics", requirePermission("stats", metrics)).Methods("GET")
+	r.HandleFunc("/api/v1/admin/config", requirePermission("config", apiConfig)).Methods("GET")
+	r.HandleFunc("/api/v1/admin/pastes/delete", requirePermission("moderate", apiBulkDelete)).Methods("POST")
+	r.HandleFunc("/api/v1/admin/pastes/{checksum}/pin", requirePermission("moderate", apiPin)).Methods("PUT", "DELETE")
+	r.HandleFunc("/api/v1/admin/jobs", requirePermission("jobs", apiJobs)).Methods("GET")
+	r.HandleFunc("/api/v1/admin/jobs/{id}", requirePermission("jobs", apiJob)).Methods("GET")
+	r.HandleFunc("/api/v1/admin/audit", requirePermission("audit", apiAudit)).Methods("GET")
+	r.PathPrefix("/debug/").Handler(requirePermission("debug", debugHandler().ServeHTTP))
+	r.HandleFunc("/api/v1/challenge", powChallenge).Methods("GET")
+	r.HandleFunc("/api/v1/openapi.json", apiOpenAPI).Methods("GET")
+	r.HandleFunc("/api/v1/pastes", apiListPastes).Methods("GET")
+	r.HandleFunc("/api/v1/pastes/batch", requirePermission("create", idempotent(apiBatchCreate))).Methods("POST")
+	r.HandleFunc("/api/v1/pastes/{checksum}", apiReadPaste).Methods("GET")
+	r.HandleFunc("/api/v1/pastes/{checksum}", apiDeletePaste).Methods("DELETE")
+	r.HandleFunc("/api/v1/pastes/{checksum}/access", apiAccessLog).Methods("GET")
+	r.HandleFunc("/api/v1/pastes/{checksum}/meta", apiPasteMeta).Methods("GET")
+	r.HandleFunc("/api/v1/pastes/{checksum}/{key}/meta", apiPasteMeta).Methods("GET")
+	r.HandleFunc("/api/v1/pastes/{checksum}/signed-url", apiSignURL).Methods("POST")
+	r.HandleFunc("/api/v1/pastes/{checksum}/{key}/signed-url", apiSignURL).Methods("POST")
+	r.HandleFunc("/api/v1/pastes/{checksum}/clone", apiClonePaste).Methods("POST")
+	r.HandleFunc("/api/v1/pastes/{checksum}/{key}/clone", apiClonePaste).Methods("POST")
+	r.HandleFunc("/api/v1/pastes/{checksum}/{key}", apiReadPaste).Methods("GET")
+	r.HandleFunc("/api/v1/archive", egressLimited(downloadArchive)).Methods("GET")
+	r.HandleFunc("/api/api_post.php", requirePermission("create", legacyPost)).Methods("POST")
+	r.HandleFunc("/q", requirePermission("create", idempotent(quickPaste))).Methods("PUT")
+	r.HandleFunc("/api/v1/sets", requirePermission("create", idempotent(apiCreateSet))).Methods("POST")
+	r.HandleFunc("/api/v1/uploads", requirePermission("create", idempotent(apiCreateUpload))).Methods("POST")
+	r.HandleFunc("/api/v1/uploads/{id}", apiUpload).Methods("GET", "HEAD")
+	r.HandleFunc("/api/v1/uploads/{id}", requirePermission("create", apiUploadChunk)).Methods("PATCH")
+	r.HandleFunc("/api/v1/sets/{id}", apiSet).Methods("GET")
+	r.HandleFunc("/set/{id}", readSet).Methods("GET")
+	r.HandleFunc("/set/{id}/raw/{name}", egressLimited(rawSetFile)).Methods("GET")
+	r.HandleFunc("/set/{id}/archive", downloadSet).Methods("GET")
+	r.PathPrefix("/static/").Handler(http.StripPrefix("/static/", http.FileServer(staticFiles())))
+
+	// Paths with variable first segments are registered after the fixed
+	// ones above, which they would otherwise shadow
 	r.HandleFunc("/", readPaste).Methods("GET")
-	r.HandleFunc("/", savePaste).Methods("POST")
+	r.HandleFunc("/", requirePermission("create", savePaste)).Methods("POST")
 	r.HandleFunc("/{checksum}", readPaste).Methods("GET")
-	r.HandleFunc("/{checksum}", savePaste).Methods("POST")
-	r.PathPrefix("/static/").Handler(http.StripPrefix("/static/", http.FileServer(assetFS())))
+	r.HandleFunc("/{checksum}", requirePermission("create", savePaste)).Methods("POST")
+	r.HandleFunc("/{checksum}", deletePasteForm).Methods("DELETE")
+	r.HandleFunc("/{checksum}/delete", deletePasteForm).Methods("POST")
+	r.HandleFunc("/{checksum}/clone", clonePaste).Methods("GET")
+	r.HandleFunc("/{checksum}/share", createShare).Methods("POST")
+	r.HandleFunc("/{checksum}/report", reportPaste).Methods("POST")
+	r.HandleFunc("/{checksum}/comments", commentPaste).Methods("POST")
+	r.HandleFunc("/{checksum}/access", readAccessLog).Methods("POST")
+	r.HandleFunc("/{checksum}/comments/{id}/hide", moderateComment).Methods("POST")
+	r.HandleFunc("/{checksum}/{key}", readPaste).Methods("GET")
+	r.NotFoundHandler = http.HandlerFunc(notFound)
+	reserveRouteSlugs(r)
+	allowCORSMethods(r)
+
+	var h http.Handler = frameOptions(r)
+	if *anonymousFlag {
+		h = anonymousHeaders(h)
+	}
+	h = chain(h, middleware)
+	if prefix := basePath(); prefix != "" {
+		h = http.StripPrefix(prefix, h)
+	}
+	return h
+}
+
+func main() {
+	flag.Parse()
+	if runConfigCommand(flag.Args()) {
+		return
+	}
+	if err := initConfig(); err != nil {
+		log.Fatalf("Unable to load the configuration: %s\n", err)
+	}
+	if *anonymousFlag {
+		log.SetOutput(redactingWriter{os.Stderr})
+	}
+	if err := validateConfig(); err != nil {
+		log.Fatalf("Invalid configuration: %s\n", err)
+	}
+
+	if *devFlag {
+		// Nothing is read from or written to the directories, which
+		// also keeps the trash purge and leases away from them
+		log.SetFlags(log.LstdFlags | log.Lshortfile)
+		log.Println("Running in development mode, pastes are kept in memory")
+		*dataDirFlag, *replicaDirFlag = "", ""
+		storage = pastebin.NewMemoryStorage()
+	} else {
+		storage = newStorage(dataDirs())
+		if *replicaDirFlag != "" {
+			storage = newReplicatedStorage(storage, newStorage(strings.Split(*replicaDirFlag, ",")))
+		}
+	}
+	service = newService(storage)
+	jobQueue = newJobQueue(storage)
+	startJobQueue()
+
+	if runCommand(flag.Args()) {
+		return
+	}
+
+	if err := blocked.Load(); err != nil {
+		log.Printf("No blocklist loaded: %s\n", err)
+	}
+
+	go views.Run(*viewsFlushFlag)
+	go accesses.Run(*viewsFlushFlag)
+	go analytics.Run(*viewsFlushFlag)
+	go usage.Run(*viewsFlushFlag)
+	if *fsckIntervalFlag > 0 {
+		go runFsckPeriodically(*fsckIntervalFlag)
+	}
+	if *trashRetentionFlag > 0 || *tombstoneRetentionFlag > 0 || *uploadExpiryFlag > 0 {
+		jobQueue.Every("purge-trash", time.Hour)
+	}
+	if *storageUsageIntervalFlag > 0 {
+		jobQueue.Every("storage-usage", *storageUsageIntervalFlag)
+		if u, err := storedUsage(); err == nil && u.Counted.IsZero() {
+			jobQueue.Enqueue("storage-usage", nil)
+		}
+	}
+	commentLimiter = newRateLimiter(commentRateFlag.Int, time.Minute)
+	go reloadOnSignal()
+
+	middleware, err := middlewareChain()
+	if err != nil {
+		log.Fatalf("Unable to set up middleware: %s\n", err)
+	}
+	if *devFlag {
+		middleware = devMiddleware(middleware)
+		if *devSeedFlag {
+			seedExamples()
+		}
+	}
+	h := RegisterRoutes(mux.NewRouter(), middleware)
 
 	srv := &http.Server{
-		Handler:      r,
-		Addr:         *bindHostFlag + ":" + strconv.Itoa(*bindPortFlag),
-		WriteTimeout: 10 * time.Second,
-		ReadTimeout:  10 * time.Second,
+		Handler:      h,
+		WriteTimeout: *writeTimeoutFlag,
+		ReadTimeout:  *readTimeoutFlag,
+		IdleTimeout:  *idleTimeoutFlag,
+	}
+	if *anonymousFlag {
+		srv.ErrorLog = log.New(redactingWriter{os.Stderr}, "", log.LstdFlags)
 	}
 
-	storage = blobstore.New("filesystem", &common.ProviderData{})
-	cfg := map[string]string{}
-	cfg["basedir"] = *dataDirFlag
-	log.Println("Using basedir " + cfg["basedir"])
-	storage.Setup(cfg)
+	addrs := []string{net.JoinHostPort(*bindHostFlag, strconv.Itoa(*bindPortFlag))}
+	if *listenFlag != "" {
+		addrs = strings.Split(*listenFlag, ",")
+	}
+
+	// Sockets passed by a restart or by systemd replace the addresses to
+	// listen on
+	inherited, err := inheritedListeners()
+	if err != nil {
+		log.Fatalf("Unable to use the inherited sockets: %s\n", err)
+	}
+	var listeners, served []namedListener
+	var tcpListener, debugListener net.Listener
+	for _, l := range inherited {
+		switch l.Name {
+		case listenerTCP:
+			tcpListener = l.Listener
+		case listenerDebug:
+			debugListener = l.Listener
+		default:
+			listeners = append(listeners, l)
+		}
+	}
+
+	errs := make(chan error)
+	if *tcpListenFlag != "" {
+		if *baseURLFlag == "" {
+			log.Fatal("A base URL is required to answer pastes received over TCP")
+		}
+		if tcpListener == nil {
+			if tcpListener, err = net.Listen("tcp", *tcpListenFlag); err != nil {
+				log.Fatalf("Unable to listen on %s: %s\n", *tcpListenFlag, err)
+			}
+		}
+		log.Println("Accepting pastes over TCP on " + tcpListener.Addr().String())
+		served = append(served, namedListener{Name: listenerTCP, Listener: tcpListener})
+		go func() {
+			errs <- serveTCP(withProxyProtocol(tcpListener), *tcpMaxConnectionsFlag, *tcpMaxBufferedFlag)
+		}()
+	}
+	if *debugListenFlag != "" {
+		if debugListener == nil {
+			if debugListener, err = net.Listen("tcp", *debugListenFlag); err != nil {
+				log.Fatalf("Unable to listen on %s: %s\n", *debugListenFlag, err)
+			}
+		}
+		served = append(served, namedListener{Name: listenerDebug, Listener: debugListener})
+		go func() {
+			if err := serveDebug(debugListener); err != nil {
+				log.Printf("Unable to serve debug endpoints on %s: %s\n", *debugListenFlag, err)
+			}
+		}()
+	}
+	if len(listeners) == 0 {
+		for _, addr := range addrs {
+			l, err := listen(strings.TrimSpace(addr))
+			if err != nil {
+				log.Fatalf("Unable to listen on %s: %s\n", addr, err)
+			}
+			listeners = append(listeners, namedListener{Name: listenerHTTP, Listener: l})
+		}
+	}
+	var listening []string
+	for _, l := range listeners {
+		l := l
+		log.Println("Listening on " + l.Addr().String())
+		listening = append(listening, l.Addr().String())
+		served = append(served, l)
+		go func() {
+			errs <- srv.Serve(withProxyProtocol(l))
+		}()
+	}
+	go func() {
+		errs <- waitForShutdown(srv)
+	}()
+	go restartOnSignal(served)
+	sdNotify("READY=1\nSTATUS=Listening on " + strings.Join(listening, ", "))
+	restartDone()
+	go runWatchdog()
 
-	log.Println("Listening...")
-	log.Fatal(srv.ListenAndServe())
+	// Every listener returns ErrServerClosed on shutdown, before the
+	// shutdown itself is done
+	err = <-errs
+	for err == http.ErrServerClosed {
+		err = <-errs
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Println("Stopped")
 }
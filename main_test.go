@@ -0,0 +1,305 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/espebra/pastebin/pastebin"
+	"github.com/gorilla/mux"
+)
+
+// newTestServer returns the handler of a pastebin keeping its pastes in
+// memory, like -dev, with the embedded templates.
+func newTestServer(t *testing.T) http.Handler {
+	t.Helper()
+	*dataDirFlag, *replicaDirFlag = "", ""
+	storage = pastebin.NewMemoryStorage()
+	service = newService(storage)
+	jobQueue = newJobQueue(storage)
+	return RegisterRoutes(mux.NewRouter(), nil)
+}
+
+// setFlag sets the flag for the test, and back when it is done.
+func setFlag(t *testing.T, p *string, value string) {
+	t.Helper()
+	old := *p
+	*p = value
+	t.Cleanup(func() { *p = old })
+}
+
+// request sends a request to h, as curl does unless header says otherwise.
+func request(h http.Handler, method, target, body string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("User-Agent", "curl/8.0")
+	for name, value := range header {
+		req.Header.Set(name, value)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+// createPlain creates a paste with a plain text POST and returns its path
+// and delete token.
+func createPlain(t *testing.T, h http.Handler, content string) (string, string) {
+	t.Helper()
+	rec := request(h, "POST", "/", content, nil)
+	if rec.Code != http.StatusCreated && rec.Code != http.StatusOK {
+		t.Fatalf("POST / returned %d: %s", rec.Code, rec.Body.String())
+	}
+	url := strings.TrimSpace(rec.Body.String())
+	return strings.TrimPrefix(url, "http://example.com"), rec.Header().Get("X-Delete-Token")
+}
+
+func TestCreateAndRead(t *testing.T) {
+	h := newTestServer(t)
+	content := "Hello, world\n"
+	path, token := createPlain(t, h, content)
+	if path != "/"+pastebin.Checksum(content) {
+		t.Errorf("Created paste at %s, want its checksum", path)
+	}
+	if token == "" {
+		t.Error("No delete token returned")
+	}
+
+	rec := request(h, "GET", "/raw"+path, "", nil)
+	if rec.Code != http.StatusOK || rec.Body.String() != content {
+		t.Errorf("GET /raw%s returned %d %q, want %q", path, rec.Code, rec.Body.String(), content)
+	}
+
+	rec = request(h, "GET", path, "", map[string]string{"User-Agent": "Mozilla/5.0", "Accept": "text/html"})
+	if rec.Code != http.StatusOK {
+		t.Errorf("GET %s returned %d", path, rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "Hello, world") {
+		t.Errorf("The page of the paste does not show its content")
+	}
+}
+
+func TestCreateDuplicate(t *testing.T) {
+	h := newTestServer(t)
+	path, token := createPlain(t, h, "same")
+
+	rec := request(h, "POST", "/", "same", nil)
+	if rec.Code != http.StatusOK || rec.Header().Get("X-Duplicate") != "true" {
+		t.Errorf("Creating the paste again returned %d with X-Duplicate %q, want 200 and true", rec.Code, rec.Header().Get("X-Duplicate"))
+	}
+	second := rec.Header().Get("X-Delete-Token")
+	if second == "" || second == token {
+		t.Fatalf("Creating the paste again returned the delete token %q, want one of its own", second)
+	}
+
+	// The paste is kept for the second creator until they release it
+	rec = request(h, "DELETE", "/api/v1/pastes"+path, "", map[string]string{"X-Delete-Token": token})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Delete returned %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := request(h, "GET", "/raw"+path, "", nil); rec.Code != http.StatusOK {
+		t.Errorf("GET /raw%s returned %d after the first creator deleted it, want 200", path, rec.Code)
+	}
+	rec = request(h, "DELETE", "/api/v1/pastes"+path, "", map[string]string{"X-Delete-Token": second})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Delete returned %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := request(h, "GET", "/raw"+path, "", nil); rec.Code == http.StatusOK {
+		t.Errorf("GET /raw%s returned 200 after both creators deleted it", path)
+	}
+}
+
+func TestDeleteWithAnonymousReference(t *testing.T) {
+	h := newTestServer(t)
+	path, token := createPlain(t, h, "created again anonymously")
+
+	// Pastes created again before every creation got a delete token have
+	// references no one can release
+	err := service.UpdateMeta(pastebin.Checksum("created again anonymously"), func(m *Meta) {
+		m.References = append(m.References, pastebin.Reference{})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := request(h, "DELETE", "/api/v1/pastes"+path, "", map[string]string{"X-Delete-Token": token})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Delete returned %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := request(h, "GET", "/raw"+path, "", nil); rec.Code == http.StatusOK {
+		t.Errorf("GET /raw%s returned 200 after its owner deleted it", path)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	h := newTestServer(t)
+	path, token := createPlain(t, h, "to be deleted")
+
+	rec := request(h, "DELETE", "/api/v1/pastes"+path, "", map[string]string{"X-Delete-Token": "wrong"})
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("Delete with a wrong token returned %d, want 403", rec.Code)
+	}
+	rec = request(h, "DELETE", "/api/v1/pastes"+path, "", map[string]string{"X-Delete-Token": token})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Delete returned %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := request(h, "GET", "/raw"+path, "", nil); rec.Code == http.StatusOK {
+		t.Errorf("GET /raw%s returned 200 after it was deleted", path)
+	}
+	if rec := request(h, "GET", path, "", map[string]string{"User-Agent": "Mozilla/5.0"}); rec.Code != http.StatusGone {
+		t.Errorf("GET %s returned %d after it was deleted, want 410", path, rec.Code)
+	}
+}
+
+func TestPrivatePasteRequiresKey(t *testing.T) {
+	setFlag(t, secretFlag, "test secret")
+	h := newTestServer(t)
+	rec := request(h, "POST", "/?visibility=private", "private", nil)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST / returned %d: %s", rec.Code, rec.Body.String())
+	}
+	path := strings.TrimPrefix(strings.TrimSpace(rec.Body.String()), "http://example.com")
+	parts := strings.Split(path, "/")
+	if len(parts) != 3 {
+		t.Fatalf("Private paste created at %s, want a path with its key", path)
+	}
+	checksum := parts[1]
+
+	for _, target := range []string{"/" + checksum, "/raw/" + checksum, "/" + checksum + "/wrong", "/api/v1/pastes/" + checksum} {
+		if rec := request(h, "GET", target, "", nil); rec.Code != http.StatusNotFound {
+			t.Errorf("GET %s returned %d without the key, want 404", target, rec.Code)
+		}
+	}
+	rec = request(h, "GET", "/raw"+path, "", nil)
+	if rec.Code != http.StatusOK || rec.Body.String() != "private" {
+		t.Errorf("GET /raw%s returned %d %q with the key, want 200 and the paste", path, rec.Code, rec.Body.String())
+	}
+}
+
+func TestPrivateDuplicateKey(t *testing.T) {
+	setFlag(t, secretFlag, "test secret")
+	h := newTestServer(t)
+	create := func() string {
+		t.Helper()
+		rec := request(h, "POST", "/?visibility=private", "guessed", nil)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("POST / returned %d: %s", rec.Code, rec.Body.String())
+		}
+		return strings.TrimPrefix(strings.TrimSpace(rec.Body.String()), "http://example.com")
+	}
+	first, second := create(), create()
+	if first == second {
+		t.Fatalf("Creating a private paste again returned its key in %s", second)
+	}
+	for _, path := range []string{first, second} {
+		if rec := request(h, "GET", "/raw"+path, "", nil); rec.Code != http.StatusOK {
+			t.Errorf("GET /raw%s returned %d, want 200", path, rec.Code)
+		}
+	}
+
+	// Pastes created before every creation got a key of its own are read
+	// with the key derived from their checksum
+	checksum := pastebin.Checksum("legacy")
+	if _, err := storage.Store(service.ObjectKey(checksum), strings.NewReader("legacy")); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.StoreMeta(checksum, Meta{Visibility: "private", ContentType: "text/plain"}); err != nil {
+		t.Fatal(err)
+	}
+	mac := hmac.New(sha256.New, []byte(*secretFlag))
+	mac.Write([]byte(checksum))
+	key := hex.EncodeToString(mac.Sum(nil))[:32]
+	if rec := request(h, "GET", "/raw/"+checksum+"/"+key, "", nil); rec.Code != http.StatusOK {
+		t.Errorf("GET of a legacy private paste with its derived key returned %d, want 200", rec.Code)
+	}
+	if rec := request(h, "GET", "/raw/"+checksum+"/"+strings.Repeat("0", 32), "", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("GET of a legacy private paste with a wrong key returned %d, want 404", rec.Code)
+	}
+}
+
+func TestPrivateDuplicateLikeNew(t *testing.T) {
+	setFlag(t, secretFlag, "test secret")
+	h := newTestServer(t)
+	create := func(content string) *httptest.ResponseRecorder {
+		t.Helper()
+		return request(h, "POST", "/?visibility=private", content, nil)
+	}
+	meta := func(path string) PasteMeta {
+		t.Helper()
+		rec := request(h, "GET", "/api/v1/pastes"+path+"/meta", "", nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET /api/v1/pastes%s/meta returned %d: %s", path, rec.Code, rec.Body.String())
+		}
+		var pm PasteMeta
+		if err := json.Unmarshal(rec.Body.Bytes(), &pm); err != nil {
+			t.Fatal(err)
+		}
+		return pm
+	}
+	path := func(rec *httptest.ResponseRecorder) string {
+		return strings.TrimPrefix(strings.TrimSpace(rec.Body.String()), "http://example.com")
+	}
+
+	// Someone pasted the content before, and it was read
+	first := path(create("guessed"))
+	request(h, "GET", "/raw"+first, "", nil)
+	views.Flush()
+
+	created := create("guessed")
+	fresh := create("never pasted")
+	if created.Code != fresh.Code || len(created.Body.String()) != len(fresh.Body.String()) {
+		t.Errorf("Creating an existing private paste returned %d %q, a new one %d %q", created.Code, created.Body.String(), fresh.Code, fresh.Body.String())
+	}
+	for _, name := range []string{"X-Duplicate", "Content-Type", "Location"} {
+		if (created.Header().Get(name) == "") != (fresh.Header().Get(name) == "") || name != "Location" && created.Header().Get(name) != fresh.Header().Get(name) {
+			t.Errorf("Creating an existing private paste returned %s %q, a new one %q", name, created.Header().Get(name), fresh.Header().Get(name))
+		}
+	}
+
+	again, other := meta(path(created)), meta(path(fresh))
+	if again.Views != other.Views {
+		t.Errorf("The existing private paste has %d views, a new one %d", again.Views, other.Views)
+	}
+	if again.Created == nil || !again.Created.Equal(*again.Updated) || again.Created.Before(*meta(first).Created) {
+		t.Errorf("The existing private paste was created %v and updated %v, want when it was created again", again.Created, again.Updated)
+	}
+
+	// Deleting it does not tell that it is kept for the first creator
+	rec := request(h, "DELETE", "/api/v1/pastes/"+pastebin.Checksum("guessed"), "", map[string]string{"X-Delete-Token": created.Header().Get("X-Delete-Token")})
+	if rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), "others") {
+		t.Errorf("Deleting the existing private paste returned %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := request(h, "GET", "/raw"+first, "", nil); rec.Code != http.StatusOK {
+		t.Errorf("GET /raw%s returned %d after the second creator deleted it, want 200", first, rec.Code)
+	}
+}
+
+func TestAPIClone(t *testing.T) {
+	h := newTestServer(t)
+	rec := request(h, "PUT", "/q?lang=go&tags=fork", "package main\n", nil)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("PUT /q returned %d: %s", rec.Code, rec.Body.String())
+	}
+	checksum := pastebin.Checksum("package main\n")
+
+	rec = request(h, "POST", "/api/v1/pastes/"+checksum+"/clone", "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Clone returned %d: %s", rec.Code, rec.Body.String())
+	}
+	var draft Paste
+	if err := json.Unmarshal(rec.Body.Bytes(), &draft); err != nil {
+		t.Fatal(err)
+	}
+	if draft.Content != "package main\n" || draft.Language != "go" || strings.Join(draft.Tags, ",") != "fork" {
+		t.Errorf("Cloned %q in %q with tags %v, want the content, language and tags of the paste", draft.Content, draft.Language, draft.Tags)
+	}
+	if draft.Checksum != "" || draft.Key != "" {
+		t.Errorf("The draft has the checksum %q and key %q of the original", draft.Checksum, draft.Key)
+	}
+
+	if rec := request(h, "POST", "/api/v1/pastes/"+strings.Repeat("0", 64)+"/clone", "", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("Cloning a paste that does not exist returned %d, want 404", rec.Code)
+	}
+}
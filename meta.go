@@ -0,0 +1,9 @@
+package main
+
+import (
+	"github.com/espebra/pastebin/pastebin"
+)
+
+// Meta holds what is known about a paste besides its content. It is
+// stored as a record next to the paste.
+type Meta = pastebin.Meta
@@ -0,0 +1,317 @@
+package main
+
+import (
+	"compress/gzip"
+	"crypto/subtle"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Middleware wraps a handler with behavior common to all requests.
+type Middleware func(http.Handler) http.Handler
+
+// middlewares are the middleware that can be listed in -middleware, by
+// name. Middleware that is not configured by its own flags passes
+// requests through unchanged.
+var middlewares = map[string]func() Middleware{
+	"logging":     func() Middleware { return logRequests },
+	"concurrency": concurrencyMiddleware,
+	"ratelimit":   rateLimitMiddleware,
+	"auth":        authMiddleware,
+	"cors":        corsMiddleware,
+	"compression": func() Middleware { return compressResponses },
+}
+
+// RegisterMiddleware makes middleware available to -middleware under
+// name. It is the hook for builds that add their own middleware, from an
+// init function in a file added to the package.
+func RegisterMiddleware(name string, m func() Middleware) {
+	if _, ok := middlewares[name]; ok {
+		log.Fatalf("Middleware %s is already registered\n", name)
+	}
+	middlewares[name] = m
+}
+
+// middlewareChain returns the middleware listed in -middleware, in order.
+func middlewareChain() ([]Middleware, error) {
+	var chain []Middleware
+	for _, name := range strings.Split(*middlewareFlag, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		m, ok := middlewares[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown middleware %s", name)
+		}
+		chain = append(chain, m())
+	}
+	return chain, nil
+}
+
+// chain wraps h with the middleware, the first one outermost.
+func chain(h http.Handler, ms []Middleware) http.Handler {
+	for i := len(ms) - 1; i >= 0; i-- {
+		h = ms[i](h)
+	}
+	return h
+}
+
+func passThrough(h http.Handler) http.Handler {
+	return h
+}
+
+// statusWriter records the status of the response for logging. Flushing
+// is passed through, so that streamed responses are not held back.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// logRequests logs the method, path, status, size and duration of every
+// request. Client addresses are left out, as the pastes are the only
+// thing worth logging per client and they are logged elsewhere.
+func logRequests(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w}
+		h.ServeHTTP(sw, r)
+		if sw.status == 0 {
+			sw.status = http.StatusOK
+		}
+		log.Printf("%s %s %d %d %s\n", r.Method, r.URL.Path, sw.status, sw.bytes, time.Since(start).Round(time.Millisecond))
+	})
+}
+
+// rateLimitMiddleware limits the requests per client IP per minute to
+// -rate-limit.
+func rateLimitMiddleware() Middleware {
+	limiter := newRateLimiter(rateLimitFlag.Int, time.Minute)
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !limiter.Allow(clientKey(clientIP(r))) {
+				w.Header().Set("Retry-After", "60")
+				http.Error(w, "Too many requests, try again later", http.StatusTooManyRequests)
+				return
+			}
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// authMiddleware requires the user and password in -auth, given as
+// user:password, for every request. It is meant for pastebins that are
+// not public at all.
+func authMiddleware() Middleware {
+	if *authFlag == "" {
+		return passThrough
+	}
+	i := strings.Index(*authFlag, ":")
+	if i < 0 {
+		log.Fatal("The credentials in -auth must be given as user:password")
+	}
+	wantUser, wantPassword := (*authFlag)[:i], (*authFlag)[i+1:]
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, password, ok := r.BasicAuth()
+			if !ok ||
+				subtle.ConstantTimeCompare([]byte(user), []byte(wantUser)) != 1 ||
+				subtle.ConstantTimeCompare([]byte(password), []byte(wantPassword)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Basic realm="pastebin"`)
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// corsMethods are the methods of the routes that cross-origin requests can
+// be made to, as read from the router by RegisterRoutes.
+var corsMethods string
+
+// corsHeaders are the request headers the API and raw pastes read, other
+// than those browsers send cross-origin without asking.
+var corsHeaders = []string{
+	"Content-Type",
+	"Range",
+	"If-None-Match",
+	"X-Delete-Token",
+	"X-Delete-Passphrase",
+	"X-PoW-Challenge",
+	"X-PoW-Nonce",
+	"X-Captcha-Response",
+	"X-Stream-Token",
+	"Upload-Offset",
+	idempotencyHeader,
+	checksumHeader,
+}
+
+// corsPath reports whether cross-origin requests can be made to the path.
+func corsPath(path string) bool {
+	return strings.HasPrefix(path, "/api/") || strings.HasPrefix(path, "/raw/")
+}
+
+// allowCORSMethods allows the methods of every route of r that
+// cross-origin requests can be made to, so that methods of routes added
+// later are allowed as well.
+func allowCORSMethods(r *mux.Router) {
+	seen := map[string]bool{}
+	var methods []string
+	r.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		template, err := route.GetPathTemplate()
+		if err != nil || !corsPath(template) {
+			return nil
+		}
+		routeMethods, _ := route.GetMethods()
+		for _, method := range routeMethods {
+			if !seen[method] {
+				seen[method] = true
+				methods = append(methods, method)
+			}
+		}
+		return nil
+	})
+	sort.Strings(methods)
+	corsMethods = strings.Join(methods, ", ")
+}
+
+// corsMiddleware allows the API to be used from pages on the origins in
+// -cors-origins, or from any origin with *. Credentials are not allowed,
+// so other sites can not act with the cookies or admin credentials of
+// visitors.
+func corsMiddleware() Middleware {
+	if *corsOriginsFlag == "" {
+		return passThrough
+	}
+	allowed := map[string]bool{}
+	for _, origin := range strings.Split(*corsOriginsFlag, ",") {
+		allowed[strings.TrimSpace(origin)] = true
+	}
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" || !corsPath(r.URL.Path) {
+				h.ServeHTTP(w, r)
+				return
+			}
+			w.Header().Add("Vary", "Origin")
+			if !allowed["*"] && !allowed[origin] {
+				h.ServeHTTP(w, r)
+				return
+			}
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			if r.Method == "OPTIONS" && r.Header.Get("Access-Control-Request-Method") != "" {
+				w.Header().Set("Access-Control-Allow-Methods", corsMethods)
+				w.Header().Set("Access-Control-Allow-Headers", strings.Join(corsHeaders, ", "))
+				w.Header().Set("Access-Control-Max-Age", "86400")
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// gzipWriter compresses the response unless the handler already encoded
+// it, or it is a partial or streamed response.
+type gzipWriter struct {
+	http.ResponseWriter
+	gz      *gzip.Writer
+	decided bool
+}
+
+func (w *gzipWriter) WriteHeader(status int) {
+	if !w.decided {
+		w.decided = true
+		header := w.Header()
+		if status == http.StatusOK &&
+			header.Get("Content-Encoding") == "" &&
+			header.Get("Content-Range") == "" &&
+			!strings.HasPrefix(header.Get("Content-Type"), "text/event-stream") &&
+			!strings.HasPrefix(header.Get("Content-Type"), "image/") {
+			header.Set("Content-Encoding", "gzip")
+			header.Del("Content-Length")
+			if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+				header.Set("ETag", "W/"+etag)
+			}
+			w.gz = gzip.NewWriter(w.ResponseWriter)
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *gzipWriter) Write(b []byte) (int, error) {
+	if !w.decided {
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", http.DetectContentType(b))
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.gz != nil {
+		return w.gz.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *gzipWriter) Flush() {
+	if w.gz != nil {
+		w.gz.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *gzipWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// compressResponses gzip compresses responses for clients that accept it.
+func compressResponses(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if !acceptsGzip(r) || r.Method == "HEAD" {
+			h.ServeHTTP(w, r)
+			return
+		}
+		gw := &gzipWriter{ResponseWriter: w}
+		h.ServeHTTP(gw, r)
+		if gw.gz != nil {
+			gw.gz.Close()
+		}
+	})
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestCORSPreflight(t *testing.T) {
+	setFlag(t, corsOriginsFlag, "https://app.example")
+	newTestServer(t)
+	h := RegisterRoutes(mux.NewRouter(), []Middleware{corsMiddleware()})
+
+	rec := request(h, "OPTIONS", "/api/v1/pastes", "", map[string]string{
+		"Origin":                        "https://app.example",
+		"Access-Control-Request-Method": "PUT",
+	})
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("The preflight request returned %d", rec.Code)
+	}
+	methods := strings.Split(rec.Header().Get("Access-Control-Allow-Methods"), ", ")
+	for _, method := range []string{"GET", "POST", "PUT", "PATCH", "DELETE"} {
+		if !contains(methods, method) {
+			t.Errorf("The preflight request allowed the methods %v, without %s", methods, method)
+		}
+	}
+	headers := strings.Split(rec.Header().Get("Access-Control-Allow-Headers"), ", ")
+	for _, header := range []string{"X-Delete-Token", idempotencyHeader, "X-Captcha-Response", checksumHeader} {
+		if !contains(headers, header) {
+			t.Errorf("The preflight request allowed the headers %v, without %s", headers, header)
+		}
+	}
+}
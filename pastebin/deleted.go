@@ -0,0 +1,135 @@
+package pastebin
+
+import (
+	"errors"
+	"io/fs"
+	"sort"
+	"time"
+)
+
+// Deleted pastes are indexed in a record per hour they were deleted in, so
+// that the pastes deleted before a time are found by reading the records
+// of the hours before it, rather than the metadata of every paste. The
+// hours with a record are listed in another record, as the storage can not
+// list its objects.
+const (
+	deletedHoursKey = "deleted-hours"
+	deletedHour     = "2006-01-02T15"
+)
+
+func deletedKey(hour string) string {
+	return "deleted-" + hour
+}
+
+// DeletedHours returns the hours with pastes deleted in them that ended
+// before the time, oldest first, as given to DeletedIn.
+func (s *Service) DeletedHours(before time.Time) ([]string, error) {
+	var hours []string
+	err := s.retrieveJSON(deletedHoursKey, &hours)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var ended []string
+	for _, hour := range hours {
+		start, err := time.Parse(deletedHour, hour)
+		if err != nil || start.Add(time.Hour).After(before) {
+			continue
+		}
+		ended = append(ended, hour)
+	}
+	return ended, nil
+}
+
+// DeletedIn returns the checksums of the pastes deleted in the hour. They
+// may have been restored or deleted again since, which their metadata
+// tells.
+func (s *Service) DeletedIn(hour string) ([]string, error) {
+	var sealed []string
+	err := s.retrieveJSON(deletedKey(hour), &sealed)
+	return s.openChecksums(sealed), err
+}
+
+// storeDeletedIn stores the index of the hour with the checksums sealed.
+func (s *Service) storeDeletedIn(hour string, checksums []string) error {
+	sealed, err := s.sealChecksums(checksums)
+	if err != nil {
+		return err
+	}
+	return s.storeJSON(deletedKey(hour), sealed)
+}
+
+// DeletedHourOf returns the hour a paste deleted at t is indexed in.
+func DeletedHourOf(t time.Time) string {
+	return t.UTC().Format(deletedHour)
+}
+
+// IndexDeleted adds the paste to the index of the hour it was deleted in,
+// for pastes deleted before they were indexed.
+func (s *Service) IndexDeleted(checksum string, at time.Time) error {
+	s.deletedMu.Lock()
+	defer s.deletedMu.Unlock()
+	hour := DeletedHourOf(at)
+	checksums, _ := s.DeletedIn(hour)
+	for _, c := range checksums {
+		if c == checksum {
+			return nil
+		}
+	}
+	if err := s.storeDeletedIn(hour, append(checksums, checksum)); err != nil {
+		return err
+	}
+
+	var hours []string
+	s.retrieveJSON(deletedHoursKey, &hours)
+	i := sort.SearchStrings(hours, hour)
+	if i < len(hours) && hours[i] == hour {
+		return nil
+	}
+	hours = append(hours, "")
+	copy(hours[i+1:], hours[i:])
+	hours[i] = hour
+	return s.storeJSON(deletedHoursKey, hours)
+}
+
+// UnindexDeleted removes the pastes from the index of the hour, once they
+// are purged or no longer deleted in that hour. The hour is forgotten when
+// no pastes are left in it.
+func (s *Service) UnindexDeleted(hour string, checksums []string) error {
+	s.deletedMu.Lock()
+	defer s.deletedMu.Unlock()
+	remove := map[string]bool{}
+	for _, c := range checksums {
+		remove[c] = true
+	}
+	indexed, err := s.DeletedIn(hour)
+	if err != nil {
+		return err
+	}
+	kept := indexed[:0]
+	for _, c := range indexed {
+		if !remove[c] {
+			kept = append(kept, c)
+		}
+	}
+	if err := s.storeDeletedIn(hour, kept); err != nil {
+		return err
+	}
+	if len(kept) > 0 {
+		return nil
+	}
+
+	var hours []string
+	if err := s.retrieveJSON(deletedHoursKey, &hours); err != nil {
+		return err
+	}
+	left := hours[:0]
+	for _, h := range hours {
+		if h != hour {
+			left = append(left, h)
+		}
+	}
+	return s.storeJSON(deletedHoursKey, left)
+}
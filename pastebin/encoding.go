@@ -0,0 +1,110 @@
+package pastebin
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// Encodings pastes are stored with. Pastes stored without compression have
+// no encoding.
+const GzipEncoding = "gzip"
+
+// IsGzip reports whether data starts like a gzip stream.
+func IsGzip(data []byte) bool {
+	return len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b
+}
+
+// Encode returns the content as it is stored, compressed if it is at
+// least the compress threshold and compression makes it smaller, along
+// with its encoding.
+func (s *Service) Encode(content []byte) ([]byte, string) {
+	if s.opts.CompressThreshold <= 0 || len(content) < s.opts.CompressThreshold {
+		return content, ""
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(content); err != nil {
+		return content, ""
+	}
+	if err := zw.Close(); err != nil || buf.Len() >= len(content) {
+		return content, ""
+	}
+	return buf.Bytes(), GzipEncoding
+}
+
+// Decode returns the content of a stored paste. Compressed pastes are
+// recognized by not matching their checksum, so reading does not depend on
+// the metadata, and uploaded gzip files are returned as they are.
+func Decode(checksum string, data []byte) ([]byte, error) {
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) == checksum || !IsGzip(data) {
+		return data, nil
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return ioutil.ReadAll(zr)
+}
+
+// The content type of text pastes. Text is always served as plain text, so
+// that a paste can never be rendered as a page of the site.
+const TextContentType = "text/plain; charset=utf-8"
+
+// DetectContentType sniffs the content type of the paste, and reports
+// whether it is binary rather than UTF-8 text.
+func DetectContentType(content string) (string, bool) {
+	sniffed := http.DetectContentType([]byte(content))
+	if utf8.ValidString(content) && !strings.ContainsRune(content, 0) &&
+		(strings.HasPrefix(sniffed, "text/") || sniffed == "application/octet-stream") {
+		return TextContentType, false
+	}
+	return sniffed, true
+}
+
+// Size of the previews of text pastes.
+const (
+	PreviewLines = 10
+	PreviewBytes = 1024
+)
+
+// Preview returns the first PreviewLines lines of text content, cut to at
+// most PreviewBytes bytes without splitting a character. Binary content
+// has no preview.
+func Preview(content string, binary bool) string {
+	if binary {
+		return ""
+	}
+	s := content
+	for i, n := 0, 0; i < len(s); i++ {
+		if s[i] == '\n' {
+			if n++; n == PreviewLines {
+				s = s[:i+1]
+				break
+			}
+		}
+	}
+	if len(s) > PreviewBytes {
+		s = s[:PreviewBytes]
+		for len(s) > 0 && !utf8.ValidString(s) {
+			s = s[:len(s)-1]
+		}
+	}
+	return s
+}
+
+// ContentType returns the content type recorded for the paste, sniffing it
+// for pastes stored before content types were recorded.
+func ContentType(m Meta, content string) (string, bool) {
+	if m.ContentType != "" {
+		return m.ContentType, m.Binary
+	}
+	return DetectContentType(content)
+}
@@ -0,0 +1,32 @@
+package pastebin
+
+import (
+	"encoding/base64"
+	"strings"
+)
+
+// CiphertextPrefix starts the content of pastes encrypted by the browser.
+// The content is the prefix, the base64 encoded 12 byte AES-GCM nonce, a
+// colon and the base64 encoded ciphertext. The key is only in the fragment
+// of the paste URL, which browsers do not send to the server.
+const CiphertextPrefix = "pbe1:"
+
+// ValidCiphertext reports whether the content is in the format of
+// encrypted pastes. Whether it is really encrypted can not be known
+// without the key.
+func ValidCiphertext(content string) bool {
+	if !strings.HasPrefix(content, CiphertextPrefix) {
+		return false
+	}
+	nonce, ciphertext, ok := strings.Cut(strings.TrimPrefix(content, CiphertextPrefix), ":")
+	if !ok {
+		return false
+	}
+	n, err := base64.StdEncoding.DecodeString(nonce)
+	if err != nil || len(n) != 12 {
+		return false
+	}
+	// The ciphertext includes the 16 byte authentication tag
+	c, err := base64.StdEncoding.DecodeString(ciphertext)
+	return err == nil && len(c) >= 16
+}
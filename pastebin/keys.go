@@ -0,0 +1,171 @@
+package pastebin
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"log"
+	"strings"
+)
+
+// ObjectKey returns the storage key of a paste. With storage secrets
+// configured the checksum is hashed with the current secret, so that
+// anyone with read access to the storage can not confirm whether some
+// content exists by computing its checksum.
+func (s *Service) ObjectKey(checksum string) string {
+	if len(s.opts.StorageSecrets) == 0 {
+		return checksum
+	}
+	return hmacKey(checksum, s.opts.StorageSecrets[0])
+}
+
+func hmacKey(checksum, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(checksum))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ObjectKeys returns every key the paste may be stored under, starting
+// with the current one and ending with the plain checksum.
+func (s *Service) ObjectKeys(checksum string) []string {
+	var keys []string
+	for _, secret := range s.opts.StorageSecrets {
+		keys = append(keys, hmacKey(checksum, secret))
+	}
+	return append(keys, checksum)
+}
+
+// RetrieveObject reads the paste as stored from whichever key it is
+// stored under. Pastes found under an old key are copied to the current
+// key, which migrates them as they are read.
+func (s *Service) RetrieveObject(checksum string) ([]byte, error) {
+	if s.missing(checksum) {
+		return nil, errMissing(checksum)
+	}
+	err := errors.New("no storage keys")
+	for i, key := range s.ObjectKeys(checksum) {
+		var buf bytes.Buffer
+		if _, err = s.storage.Retrieve(key, &buf); err != nil {
+			continue
+		}
+		if i > 0 {
+			if _, err := s.storage.Store(s.ObjectKey(checksum), bytes.NewReader(buf.Bytes())); err != nil {
+				log.Printf("Unable to migrate %s to the current storage key: %s\n", checksum, err)
+			}
+		}
+		return buf.Bytes(), nil
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		s.rememberMissing(checksum)
+	}
+	return nil, err
+}
+
+// derivedKey returns the key derived from the checksum with the key
+// secret, which pastes created before every creation got a random key
+// require.
+func (s *Service) derivedKey(checksum string) string {
+	mac := hmac.New(sha256.New, []byte(s.opts.KeySecret))
+	mac.Write([]byte(checksum))
+	return hex.EncodeToString(mac.Sum(nil))[:32]
+}
+
+// RequiresKey reports whether the paste can only be accessed with its key,
+// either because all pastes require one or because the paste is private.
+func (s *Service) RequiresKey(m Meta) bool {
+	return s.opts.Private || s.Visibility(m) == "private"
+}
+
+// Authorize reports whether key grants access to the paste, which is the
+// key of one of its creations, or the key derived from its checksum for
+// pastes created before creations got a key of their own.
+func (s *Service) Authorize(checksum, key string) bool {
+	m, _ := s.Meta(checksum)
+	if !s.RequiresKey(m) {
+		return true
+	}
+	if key == "" {
+		return false
+	}
+	m.References = append([]Reference{}, m.References...)
+	m.migrateReferences()
+	if m.keyReference(key) >= 0 {
+		return true
+	}
+	return s.opts.KeySecret != "" && m.acceptsDerivedKey() && hmac.Equal([]byte(key), []byte(s.derivedKey(checksum)))
+}
+
+// scopeLength is the length of the hash of the key that scopes are named
+// with.
+const scopeLength = 16
+
+// Scope returns the name of what is kept for the readers of the paste with
+// key, like its views and comments. Every creation of a paste that
+// requires a key has a scope of its own, so that readers can not tell from
+// them whether the content was pasted before. Other pastes have one scope,
+// named after their checksum.
+func (s *Service) Scope(checksum, key string) string {
+	m, _ := s.Meta(checksum)
+	if !s.RequiresKey(m) {
+		return checksum
+	}
+	if i := m.keyReference(key); i >= 0 {
+		return checksum + "-" + m.References[i].Key[:scopeLength]
+	}
+	return checksum
+}
+
+// TokenScope returns the scope of the creation of the paste that token is
+// the delete token or passphrase of, and false if it is neither.
+func (s *Service) TokenScope(checksum, token string) (string, bool) {
+	m, err := s.Meta(checksum)
+	if err != nil {
+		return "", false
+	}
+	m.References = append([]Reference{}, m.References...)
+	m.migrateReferences()
+	i := m.reference(token)
+	if i < 0 {
+		return "", false
+	}
+	if s.RequiresKey(m) && m.References[i].Key != "" {
+		return checksum + "-" + m.References[i].Key[:scopeLength], true
+	}
+	return checksum, true
+}
+
+// Scopes returns every scope of the paste, to remove what is kept under
+// them along with it.
+func (s *Service) Scopes(checksum string, m Meta) []string {
+	scopes := []string{checksum}
+	for _, r := range m.References {
+		if r.Key != "" {
+			scopes = append(scopes, checksum+"-"+r.Key[:scopeLength])
+		}
+	}
+	return scopes
+}
+
+// ScopeKey returns the storage key that the records kept under the scope
+// are stored under, which is the storage key of the paste followed by the
+// name of the scope.
+func (s *Service) ScopeKey(scope string) string {
+	return s.ScopeKeys(scope)[0]
+}
+
+// ScopeKeys returns every key the records kept under the scope may be
+// stored under, like ObjectKeys.
+func (s *Service) ScopeKeys(scope string) []string {
+	checksum, suffix := scope, ""
+	if i := strings.IndexByte(scope, '-'); i >= 0 {
+		checksum, suffix = scope[:i], scope[i:]
+	}
+	keys := s.ObjectKeys(checksum)
+	for i := range keys {
+		keys[i] += suffix
+	}
+	return keys
+}
@@ -0,0 +1,136 @@
+package pastebin
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// Charsets of text pastes. Binary pastes have no charset.
+const (
+	ASCIICharset = "us-ascii"
+	UTF8Charset  = "utf-8"
+)
+
+// Charset returns the charset of the paste, which is US-ASCII for text
+// without other characters, as it is also valid in most other charsets.
+func Charset(content string, binary bool) string {
+	if binary {
+		return ""
+	}
+	for i := 0; i < len(content); i++ {
+		if content[i] >= 0x80 {
+			return UTF8Charset
+		}
+	}
+	return ASCIICharset
+}
+
+// CountLines returns the number of lines of the text, counting a last line
+// without a line break. Binary pastes have no lines.
+func CountLines(content string, binary bool) int {
+	if binary || content == "" {
+		return 0
+	}
+	n := strings.Count(content, "\n")
+	if !strings.HasSuffix(content, "\n") {
+		n++
+	}
+	return n
+}
+
+// Interpreters of scripts starting with #!, by the name of the program.
+var interpreters = map[string]string{
+	"sh":      "shell",
+	"bash":    "shell",
+	"zsh":     "shell",
+	"python":  "python",
+	"python3": "python",
+	"perl":    "perl",
+	"ruby":    "ruby",
+	"node":    "javascript",
+	"php":     "php",
+}
+
+// languageRule recognizes a language by a pattern in the first part of
+// the paste.
+type languageRule struct {
+	Language string
+	Pattern  *regexp.Regexp
+}
+
+// The rules are tried in order, so the more specific ones come first.
+var languageRules = []languageRule{
+	{"php", regexp.MustCompile(`^\s*<\?php`)},
+	{"xml", regexp.MustCompile(`^\s*<\?xml`)},
+	{"html", regexp.MustCompile(`(?i)^\s*(<!doctype html|<html)`)},
+	{"diff", regexp.MustCompile(`(?m)^(diff --git |--- \S.*\n\+\+\+ \S|@@ -\d+(,\d+)? \+\d+(,\d+)? @@)`)},
+	{"go", regexp.MustCompile(`(?m)^package \w+$[\s\S]*^(import|func|type|var|const)\b`)},
+	{"rust", regexp.MustCompile(`(?m)^\s*(pub )?(fn \w+|use \w+(::\w+)+;|impl\b)`)},
+	{"c", regexp.MustCompile(`(?m)^#include\s*[<"]`)},
+	{"java", regexp.MustCompile(`(?m)^\s*(public |private )?(final )?class \w+[\s\S]*\b(public|private) (static )?\w+`)},
+	{"python", regexp.MustCompile(`(?m)^\s*(def \w+\(.*\):|class \w+(\(.*\))?:|from [\w.]+ import |import \w+$)`)},
+	{"javascript", regexp.MustCompile(`(?m)^\s*(const|let|var) \w+ = |\bfunction\s*\w*\(.*\)\s*\{|=> \{|require\(['"]`)},
+	{"sql", regexp.MustCompile(`(?im)^\s*(select .+ from |insert into |create table |update \w+ set )`)},
+	{"shell", regexp.MustCompile(`(?m)^\s*(\$ \w|export \w+=|if \[ )`)},
+	{"yaml", regexp.MustCompile(`(?m)^(---$|[\w-]+:( .+)?$)[\s\S]*^([\w-]+:|\s+- )`)},
+	{"markdown", regexp.MustCompile(`(?m)^(#{1,6} \S|` + "```" + `)`)},
+}
+
+// languagePattern matches the names of languages given by clients, such as
+// the file types of editors like cpp, objective-c and c#.
+var languagePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9+#._-]{0,31}$`)
+
+// ValidLanguage reports whether the language can be given to a paste
+// instead of the detected one. Languages are in lower case.
+func ValidLanguage(language string) bool {
+	return languagePattern.MatchString(language)
+}
+
+// Part of the paste the language is detected from, so that detecting it
+// is quick for large pastes.
+const languageSample = 16 << 10
+
+// DetectLanguage guesses the language of text pastes from a #! line,
+// whether they are JSON, and patterns typical of the language. It returns
+// an empty string for binary pastes and when there is no good guess.
+func DetectLanguage(content string, binary bool) string {
+	if binary || strings.TrimSpace(content) == "" {
+		return ""
+	}
+	if strings.HasPrefix(content, "#!") {
+		line := strings.SplitN(content[2:], "\n", 2)[0]
+		fields := strings.Fields(line)
+		if len(fields) > 0 {
+			name := fields[0][strings.LastIndex(fields[0], "/")+1:]
+			if name == "env" && len(fields) > 1 {
+				name = fields[1]
+			}
+			if language, ok := interpreters[name]; ok {
+				return language
+			}
+		}
+	}
+	if s := strings.TrimSpace(content); (strings.HasPrefix(s, "{") || strings.HasPrefix(s, "[")) && json.Valid([]byte(s)) {
+		return "json"
+	}
+	sample := content
+	if len(sample) > languageSample {
+		sample = sample[:languageSample]
+	}
+	for _, rule := range languageRules {
+		if rule.Pattern.MatchString(sample) {
+			return rule.Language
+		}
+	}
+	return ""
+}
+
+// setTextStats records the preview, line count, language and charset of
+// the content of a text paste.
+func (m *Meta) setTextStats(content string) {
+	m.Preview = Preview(content, m.Binary)
+	m.Lines = CountLines(content, m.Binary)
+	m.Language = DetectLanguage(content, m.Binary)
+	m.Charset = Charset(content, m.Binary)
+}
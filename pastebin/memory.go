@@ -0,0 +1,57 @@
+package pastebin
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"sync"
+)
+
+// MemoryStorage is a Storage keeping everything in memory, for tests and
+// for running without a data directory. Its contents are lost when the
+// process exits.
+type MemoryStorage struct {
+	mu      sync.RWMutex
+	objects map[string][]byte
+}
+
+// NewMemoryStorage returns an empty MemoryStorage.
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{objects: map[string][]byte{}}
+}
+
+// Store reads r to the end and stores it under key, replacing what was
+// stored before.
+func (s *MemoryStorage) Store(key string, r io.Reader) (int64, error) {
+	var buf bytes.Buffer
+	n, err := buf.ReadFrom(r)
+	if err != nil {
+		return n, err
+	}
+	s.mu.Lock()
+	s.objects[key] = buf.Bytes()
+	s.mu.Unlock()
+	return n, nil
+}
+
+// Retrieve writes what is stored under key to w. Keys that were never
+// stored give an error matching fs.ErrNotExist, like the filesystem
+// storage.
+func (s *MemoryStorage) Retrieve(key string, w io.Writer) (int64, error) {
+	s.mu.RLock()
+	data, ok := s.objects[key]
+	s.mu.RUnlock()
+	if !ok {
+		return 0, &fs.PathError{Op: "retrieve", Path: key, Err: fs.ErrNotExist}
+	}
+	n, err := w.Write(data)
+	return int64(n), err
+}
+
+// Delete removes what is stored under key.
+func (s *MemoryStorage) Delete(key string) error {
+	s.mu.Lock()
+	delete(s.objects, key)
+	s.mu.Unlock()
+	return nil
+}
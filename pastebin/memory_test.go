@@ -0,0 +1,14 @@
+package pastebin_test
+
+import (
+	"testing"
+
+	"github.com/espebra/pastebin/pastebin"
+	"github.com/espebra/pastebin/pastebin/storagetest"
+)
+
+func TestMemoryStorage(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) pastebin.Storage {
+		return pastebin.NewMemoryStorage()
+	})
+}
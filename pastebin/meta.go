@@ -0,0 +1,285 @@
+package pastebin
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"time"
+)
+
+// Meta holds what is known about a paste besides its content. It is
+// stored as a record next to the paste.
+type Meta struct {
+	Tags       []string `json:"tags,omitempty"`
+	Visibility string   `json:"visibility,omitempty"`
+
+	// Sniffed when the paste is created
+	ContentType string `json:"content_type,omitempty"`
+	Binary      bool   `json:"binary,omitempty"`
+
+	// Counted and guessed when text pastes are created, or read if they
+	// were created before they were recorded
+	Lines    int    `json:"lines,omitempty"`
+	Language string `json:"language,omitempty"`
+	Charset  string `json:"charset,omitempty"`
+
+	// Encoding the paste is stored with, if it is compressed
+	Encoding string `json:"encoding,omitempty"`
+
+	// Size of the content in bytes, and when the paste was first and last
+	// created. Not known for pastes created before they were recorded.
+	Size    int64     `json:"size,omitempty"`
+	Created time.Time `json:"created,omitempty"`
+	Updated time.Time `json:"updated,omitempty"`
+
+	// The first lines of text pastes, for listings that should not read
+	// every paste. Not known for pastes created before they were recorded
+	// until they are read.
+	Preview string `json:"preview,omitempty"`
+
+	// Creations of the paste. It is only deleted when the last of them
+	// is released.
+	References []Reference `json:"references,omitempty"`
+
+	// Hashes of the tokens and passphrases of pastes created before
+	// references were recorded, which count as a reference each
+	DeleteTokens      []string `json:"delete_tokens,omitempty"`
+	DeletePassphrases []string `json:"delete_passphrases,omitempty"`
+
+	Deleted   bool      `json:"deleted,omitempty"`
+	DeletedAt time.Time `json:"deleted_at,omitempty"`
+	DeletedBy string    `json:"deleted_by,omitempty"` // DeletedByOwner or DeletedByAdmin
+
+	// Pinned pastes can not be deleted or purged until they are unpinned,
+	// e.g. when they must be retained during an incident
+	Pinned bool `json:"pinned,omitempty"`
+
+	// Encrypted pastes were encrypted by the browser, with a key the
+	// server never sees, so the content is ciphertext
+	Encrypted bool `json:"encrypted,omitempty"`
+}
+
+// Who deleted a paste.
+const (
+	DeletedByOwner = "owner"
+	DeletedByAdmin = "admin"
+)
+
+// Visibility levels of pastes, from least to most restrictive. Public
+// pastes are shown in listings, unlisted pastes are available to anyone
+// with the link and private pastes only to those with the link including
+// the key.
+var visibilities = []string{"public", "unlisted", "private"}
+
+// ValidVisibility reports whether v is a visibility level.
+func ValidVisibility(v string) bool {
+	for _, visibility := range visibilities {
+		if v == visibility {
+			return true
+		}
+	}
+	return false
+}
+
+// MoreRestrictive returns the more restrictive of two visibility levels.
+func MoreRestrictive(a, b string) string {
+	for i := len(visibilities) - 1; i >= 0; i-- {
+		if a == visibilities[i] || b == visibilities[i] {
+			return visibilities[i]
+		}
+	}
+	return a
+}
+
+// Visibility returns the visibility of the paste, which is the default
+// visibility for pastes created before visibilities were recorded.
+func (s *Service) Visibility(m Meta) string {
+	if m.Visibility == "" {
+		return s.opts.DefaultVisibility
+	}
+	return m.Visibility
+}
+
+// HashToken returns the hash a token is recorded as.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewToken returns a random token, for delete tokens and the other
+// secrets handed out to clients.
+func NewToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Reference is a creation of a paste. Identical content shares one paste,
+// so deleting it releases the reference of its creator, with the hash of
+// the delete token or salted hash of the passphrase chosen by the creator.
+//
+// Pastes that require a key get a random key for every creation, of which
+// the hash is recorded with the reference, so that knowing or guessing the
+// content of a paste is not enough to read it, and the key is revoked
+// along with the reference. The creation time is recorded for them as
+// well, which is what readers with the key see as the time the paste was
+// created.
+type Reference struct {
+	Token      string    `json:"token,omitempty"`
+	Passphrase string    `json:"passphrase,omitempty"`
+	Key        string    `json:"key,omitempty"`
+	Created    time.Time `json:"created,omitempty"`
+}
+
+// matches reports whether token is the delete token or passphrase of the
+// reference.
+func (r Reference) matches(token, hash string) bool {
+	if r.Token != "" && subtle.ConstantTimeCompare([]byte(r.Token), []byte(hash)) == 1 {
+		return true
+	}
+	return r.Passphrase != "" && matchPassphrase(r.Passphrase, token)
+}
+
+// addReference records a creation of the paste with the hashes of its
+// delete token and key, if the paste requires one, and the hashed
+// passphrase, if any. A paste deleted earlier is restored, since saving it
+// again creates it anew.
+func (m *Meta) addReference(token, key, passphrase string) {
+	if m.Deleted {
+		m.Deleted = false
+		m.DeletedBy = ""
+		m.References = nil
+		m.DeleteTokens = nil
+		m.DeletePassphrases = nil
+	}
+	m.migrateReferences()
+	r := Reference{Token: HashToken(token), Passphrase: passphrase}
+	if key != "" {
+		r.Key = HashToken(key)
+		r.Created = time.Now().UTC()
+	}
+	m.References = append(m.References, r)
+}
+
+// migrateReferences records the delete tokens and passphrases of pastes
+// created before references were as references. References recorded
+// without either, for pastes created again before every creation got a
+// delete token, are dropped, as no one can release them.
+func (m *Meta) migrateReferences() {
+	references := m.References[:0]
+	for _, r := range m.References {
+		if r.Token != "" || r.Passphrase != "" {
+			references = append(references, r)
+		}
+	}
+	m.References = references
+	for _, h := range m.DeleteTokens {
+		m.References = append(m.References, Reference{Token: h})
+	}
+	for _, h := range m.DeletePassphrases {
+		m.References = append(m.References, Reference{Passphrase: h})
+	}
+	m.DeleteTokens, m.DeletePassphrases = nil, nil
+}
+
+// reference returns the index of the reference that token is the delete
+// token or passphrase of, or -1.
+func (m Meta) reference(token string) int {
+	if token == "" {
+		return -1
+	}
+	hash := HashToken(token)
+	for i, r := range m.References {
+		if r.matches(token, hash) {
+			return i
+		}
+	}
+	return -1
+}
+
+// keyReference returns the index of the reference that key is the key
+// of, or -1.
+func (m Meta) keyReference(key string) int {
+	if key == "" {
+		return -1
+	}
+	hash := HashToken(key)
+	for i, r := range m.References {
+		if r.Key != "" && subtle.ConstantTimeCompare([]byte(r.Key), []byte(hash)) == 1 {
+			return i
+		}
+	}
+	return -1
+}
+
+// KeyCreated returns when the paste was created with key, for pastes that
+// require a key, and false if key is not the key of one of its creations.
+func (m Meta) KeyCreated(key string) (time.Time, bool) {
+	i := m.keyReference(key)
+	if i < 0 {
+		return time.Time{}, false
+	}
+	return m.References[i].Created, true
+}
+
+// acceptsDerivedKey reports whether the paste is read with the key derived
+// from its checksum, as it was created before creations got a random key, or
+// created without a key when it did not require one.
+func (m Meta) acceptsDerivedKey() bool {
+	if len(m.References) == 0 {
+		return true
+	}
+	for _, r := range m.References {
+		if r.Key == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidDeleteToken reports whether token is one of the delete tokens of
+// the paste, or one of the passphrases chosen when it was created.
+func (m Meta) ValidDeleteToken(token string) bool {
+	m.References = append([]Reference{}, m.References...)
+	m.migrateReferences()
+	return m.reference(token) >= 0
+}
+
+func metaKey(key string) string {
+	return "meta-" + key
+}
+
+// Meta reads the metadata of the paste from whichever storage key it is
+// stored under.
+func (s *Service) Meta(checksum string) (Meta, error) {
+	var m Meta
+	if s.missing(checksum) {
+		return m, errMissing(checksum)
+	}
+	var err error
+	for _, key := range s.ObjectKeys(checksum) {
+		if err = s.retrieveJSON(metaKey(key), &m); err == nil {
+			return m, nil
+		}
+	}
+	return m, err
+}
+
+// StoreMeta replaces the metadata of the paste.
+func (s *Service) StoreMeta(checksum string, m Meta) error {
+	s.forgetMissing(checksum)
+	return s.storeJSON(metaKey(s.ObjectKey(checksum)), m)
+}
+
+// UpdateMeta applies fn to the metadata of the paste and stores the
+// result. Pastes without metadata start out with an empty record.
+func (s *Service) UpdateMeta(checksum string, fn func(m *Meta)) error {
+	s.metaMu.Lock()
+	defer s.metaMu.Unlock()
+	m, _ := s.Meta(checksum)
+	fn(&m)
+	return s.StoreMeta(checksum, m)
+}
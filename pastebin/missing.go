@@ -0,0 +1,69 @@
+package pastebin
+
+import (
+	"io/fs"
+	"sync"
+	"time"
+)
+
+// Most checksums remembered as not found. Scans probing more checksums
+// than this within NotFoundTTL are cached only in part.
+const maxMissing = 100000
+
+// missingCache remembers the checksums of pastes that were not found, so
+// that probing them again answers without calling the storage, once for
+// the paste and once for its metadata under each storage key.
+type missingCache struct {
+	mu    sync.Mutex
+	until map[string]time.Time
+}
+
+func errMissing(checksum string) error {
+	return &fs.PathError{Op: "retrieve", Path: checksum, Err: fs.ErrNotExist}
+}
+
+// missing reports whether the paste was not found within NotFoundTTL.
+func (s *Service) missing(checksum string) bool {
+	if s.opts.NotFoundTTL <= 0 {
+		return false
+	}
+	s.notFound.mu.Lock()
+	defer s.notFound.mu.Unlock()
+	until, ok := s.notFound.until[checksum]
+	if ok && time.Now().After(until) {
+		delete(s.notFound.until, checksum)
+		return false
+	}
+	return ok
+}
+
+// rememberMissing remembers that the paste was not found.
+func (s *Service) rememberMissing(checksum string) {
+	if s.opts.NotFoundTTL <= 0 {
+		return
+	}
+	s.notFound.mu.Lock()
+	defer s.notFound.mu.Unlock()
+	now := time.Now()
+	if s.notFound.until == nil {
+		s.notFound.until = map[string]time.Time{}
+	}
+	if len(s.notFound.until) >= maxMissing {
+		for c, until := range s.notFound.until {
+			if now.After(until) {
+				delete(s.notFound.until, c)
+			}
+		}
+		if len(s.notFound.until) >= maxMissing {
+			return
+		}
+	}
+	s.notFound.until[checksum] = now.Add(s.opts.NotFoundTTL)
+}
+
+// forgetMissing forgets that the paste was not found, once it is stored.
+func (s *Service) forgetMissing(checksum string) {
+	s.notFound.mu.Lock()
+	defer s.notFound.mu.Unlock()
+	delete(s.notFound.until, checksum)
+}
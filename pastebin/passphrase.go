@@ -0,0 +1,71 @@
+package pastebin
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MinPassphraseLength is the length delete passphrases must have at least.
+const MinPassphraseLength = 8
+
+// Iterations of PBKDF2 passphrases are hashed with, so that guessing
+// passphrases from the metadata is slow.
+const passphraseIterations = 100000
+
+// pbkdf2 derives a 32 byte key from the passphrase with PBKDF2-HMAC-SHA256.
+// One block of output is all that is needed.
+func pbkdf2(passphrase, salt []byte, iterations int) []byte {
+	mac := hmac.New(sha256.New, passphrase)
+	mac.Write(salt)
+	binary.Write(mac, binary.BigEndian, uint32(1))
+	u := mac.Sum(nil)
+	key := append([]byte(nil), u...)
+	for i := 1; i < iterations; i++ {
+		mac.Reset()
+		mac.Write(u)
+		u = mac.Sum(u[:0])
+		for j := range key {
+			key[j] ^= u[j]
+		}
+	}
+	return key
+}
+
+// HashPassphrase returns the salted hash a delete passphrase is recorded
+// as, given as pbkdf2-sha256$iterations$salt$hash.
+func HashPassphrase(passphrase string) (string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := pbkdf2([]byte(passphrase), salt, passphraseIterations)
+	return fmt.Sprintf("pbkdf2-sha256$%d$%x$%x", passphraseIterations, salt, key), nil
+}
+
+// matchPassphrase reports whether the passphrase has the recorded hash.
+func matchPassphrase(hash, passphrase string) bool {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 4 || parts[0] != "pbkdf2-sha256" {
+		return false
+	}
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations < 1 {
+		return false
+	}
+	salt, err := hex.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	want, err := hex.DecodeString(parts[3])
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare(pbkdf2([]byte(passphrase), salt, iterations), want) == 1
+}
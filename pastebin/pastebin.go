@@ -0,0 +1,540 @@
+// Package pastebin stores and reads pastes the way the pastebin server
+// does, so that other Go programs can embed the pastebin without running
+// its HTTP server. Pastes are named by the SHA-256 checksum of their
+// content, and what is known about a paste besides its content is kept in
+// a metadata record next to it.
+package pastebin
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"time"
+)
+
+var (
+	ErrBlocked            = errors.New("paste is blocked")
+	ErrCorrupt            = errors.New("paste does not match its checksum")
+	ErrDeleted            = errors.New("paste is deleted")
+	ErrInvalidDeleteToken = errors.New("invalid delete token")
+	ErrInvalidKey         = errors.New("invalid key")
+	ErrInvalidVisibility  = errors.New("unknown visibility")
+	ErrInvalidCiphertext  = errors.New("the encrypted content is not in the expected format")
+	ErrShortPassphrase    = errors.New("the delete passphrase is too short")
+	ErrPinned             = errors.New("paste is pinned")
+	ErrShared             = errors.New("paste was created by others as well and is kept for them")
+	ErrTagRequired        = errors.New("listing pastes requires a tag")
+)
+
+// Storage is the part of the storage providers used by the pastebin.
+// Deleting a key that is not stored is not an error.
+type Storage interface {
+	Store(key string, r io.Reader) (int64, error)
+	Retrieve(key string, w io.Writer) (int64, error)
+	Delete(key string) error
+}
+
+// Options configure a Service. The zero value stores public pastes under
+// their checksum, uncompressed.
+type Options struct {
+	// Secrets used to derive the storage keys of pastes, the current one
+	// first. Older secrets are only used to read pastes.
+	StorageSecrets []string
+
+	// Secret the keys of pastes requiring one were derived with, before
+	// every creation got a random key. Pastes requiring a key can not be
+	// read with Get without it.
+	KeySecret string
+
+	// Private makes every paste require its key, not only private ones.
+	Private bool
+
+	// Visibility of pastes created without one, public when empty.
+	DefaultVisibility string
+
+	// Pastes of at least this many bytes are stored gzip compressed.
+	// Compression is disabled when 0.
+	CompressThreshold int
+
+	// Allow reports whether a paste may be stored and served. Pastes in
+	// listings are passed without their content. Everything is allowed
+	// when it is nil.
+	Allow func(p Paste) bool
+
+	// How long pastes that were not found are answered as not found
+	// without calling the storage again, which blunts scans probing for
+	// checksums. Pastes created meanwhile by other services sharing the
+	// storage are not found until then. Disabled when 0.
+	NotFoundTTL time.Duration
+}
+
+// Service creates, reads, deletes and lists pastes in a storage.
+type Service struct {
+	storage Storage
+	opts    Options
+
+	// Updates of metadata and tag indexes read, modify and write the
+	// record, so they are serialized.
+	metaMu    sync.Mutex
+	tagsMu    sync.Mutex
+	deletedMu sync.Mutex
+
+	notFound missingCache
+}
+
+// New returns a service storing pastes in storage.
+func New(storage Storage, opts Options) *Service {
+	if opts.DefaultVisibility == "" {
+		opts.DefaultVisibility = "public"
+	}
+	return &Service{storage: storage, opts: opts}
+}
+
+// Paste is a paste as stored by the service.
+type Paste struct {
+	Checksum string
+	Content  string
+	// Set by Create to the key of the creation, and by Get to the key
+	// given, for pastes that require one
+	Key         string
+	Tags        []string
+	Visibility  string
+	ContentType string
+	Binary      bool
+	Encrypted   bool
+	Lines       int
+	Language    string
+	Charset     string
+
+	// The first lines of the content, set by List instead of the content
+	Preview string
+
+	// Number of bytes stored, which is less than the size of the content
+	// for compressed pastes. Only set by Create.
+	Stored int64
+
+	// Set by Create when the content was already stored, in which case
+	// nothing was stored again
+	Duplicate bool
+
+	// Only set by Create
+	DeleteToken string
+}
+
+// Checksum returns the name of a paste with the content.
+func Checksum(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsChecksum reports whether s looks like a paste checksum, so that other
+// objects in the storage can not be read as pastes.
+func IsChecksum(s string) bool {
+	if len(s) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+func (s *Service) allows(p Paste) bool {
+	return s.opts.Allow == nil || s.opts.Allow(p)
+}
+
+// CreateOptions are the options of a new paste.
+type CreateOptions struct {
+	// Visibility of the paste, the default visibility when empty
+	Visibility string
+	Tags       []string
+	// The content is ciphertext made by the client, see ValidCiphertext
+	Encrypted bool
+	// Passphrase that authorizes deleting the paste like its delete
+	// token, of at least MinPassphraseLength characters. Optional.
+	DeletePassphrase string
+	// Language of text pastes, instead of the detected one, see
+	// ValidLanguage. Optional.
+	Language string
+}
+
+// exists reports whether the paste is stored under its current key, and
+// whether it is stored but deleted. That is known from its metadata, which
+// is written after the paste is stored. Metadata without a content type
+// may have been written without the paste, e.g. by a failed attempt to
+// delete it, so it does not count.
+func (s *Service) exists(checksum string) (exists, deleted bool) {
+	var m Meta
+	if err := s.retrieveJSON(metaKey(s.ObjectKey(checksum)), &m); err != nil || m.ContentType == "" {
+		return false, false
+	}
+	return !m.Deleted, m.Deleted
+}
+
+// Create stores the content as a paste and returns it with a new delete
+// token. Identical content shares one paste, so creating it again does not
+// store it again, and returns the paste as it is, with the options
+// ignored. Every creation gets a delete token of its own, which releases
+// the paste for its creator.
+func (s *Service) Create(content string, opts CreateOptions) (Paste, error) {
+	p := Paste{Checksum: Checksum(content), Content: content}
+	if opts.Visibility == "" {
+		opts.Visibility = s.opts.DefaultVisibility
+	}
+	if !ValidVisibility(opts.Visibility) {
+		return p, ErrInvalidVisibility
+	}
+	if opts.Encrypted && !ValidCiphertext(content) {
+		return p, ErrInvalidCiphertext
+	}
+	var passphrase string
+	if opts.DeletePassphrase != "" {
+		if len(opts.DeletePassphrase) < MinPassphraseLength {
+			return p, ErrShortPassphrase
+		}
+		var err error
+		if passphrase, err = HashPassphrase(opts.DeletePassphrase); err != nil {
+			return p, err
+		}
+	}
+	if !s.allows(p) {
+		return p, ErrBlocked
+	}
+	token, err := NewToken()
+	if err != nil {
+		return p, err
+	}
+	key, err := NewToken()
+	if err != nil {
+		return p, err
+	}
+
+	var encoding string
+	var deleted bool
+	p.Duplicate, deleted = s.exists(p.Checksum)
+	stored := !p.Duplicate
+	if stored {
+		var data []byte
+		data, encoding = s.Encode([]byte(content))
+		nBytes, err := s.storage.Store(s.ObjectKey(p.Checksum), bytes.NewReader(data))
+		if err != nil {
+			return p, err
+		}
+		p.Stored = nBytes
+	}
+	s.forgetMissing(p.Checksum)
+
+	err = s.UpdateMeta(p.Checksum, func(m *Meta) {
+		if p.Duplicate && !m.Deleted {
+			// The paste is someone else's as well, so it is answered as
+			// it is, and kept for this creator until they release it
+			m.Updated = time.Now().UTC()
+		} else {
+			p.Duplicate = false
+			s.createMeta(m, content, opts)
+			if stored {
+				m.Encoding = encoding
+			}
+		}
+		// Creations of pastes that exist get a key of their own, as
+		// answering with the key of the paste would give it to anyone
+		// guessing its content
+		if s.RequiresKey(*m) {
+			p.Key = key
+		}
+		m.addReference(token, p.Key, passphrase)
+		p.DeleteToken = token
+		p.Tags = m.Tags
+		p.Visibility = s.Visibility(*m)
+		p.ContentType, p.Binary = m.ContentType, m.Binary
+		p.Encrypted = m.Encrypted
+		p.Lines, p.Language, p.Charset = m.Lines, m.Language, m.Charset
+	})
+	if err != nil {
+		// Without its metadata the paste would be served with the default
+		// visibility, whatever it was created with
+		if stored && !deleted {
+			if err := s.storage.Delete(s.ObjectKey(p.Checksum)); err != nil {
+				log.Printf("Unable to remove %s without metadata: %s\n", p.Checksum, err)
+			}
+		}
+		return Paste{Checksum: p.Checksum, Content: content}, err
+	}
+	if p.Duplicate {
+		// Telling that a paste requiring a key exists would tell its
+		// content to anyone guessing it
+		if p.Key != "" {
+			p.Duplicate = false
+		}
+		return p, nil
+	}
+	if err := s.index(p.Checksum, opts.Tags); err != nil {
+		log.Printf("Unable to tag %s: %s\n", p.Checksum, err)
+	}
+	return p, nil
+}
+
+// createMeta records the metadata of a paste that is stored anew, either
+// for the first time or after it was deleted.
+func (s *Service) createMeta(m *Meta, content string, opts CreateOptions) {
+	if m.Created.IsZero() || m.Deleted {
+		// A new paste has no visibility of its own yet to keep
+		m.Visibility = opts.Visibility
+	} else {
+		m.Visibility = MoreRestrictive(s.Visibility(*m), opts.Visibility)
+	}
+	m.ContentType, m.Binary = DetectContentType(content)
+	now := time.Now().UTC()
+	if m.Created.IsZero() || m.Deleted {
+		m.Created = now
+	}
+	m.Updated = now
+	m.Size = int64(len(content))
+	m.Tags = mergeTags(m.Tags, opts.Tags)
+	if opts.Encrypted {
+		m.Encrypted = true
+	}
+	m.Preview = ""
+	m.Lines, m.Language, m.Charset = 0, "", ""
+	if !m.Encrypted {
+		m.setTextStats(content)
+		if opts.Language != "" && !m.Binary {
+			m.Language = opts.Language
+		}
+	}
+}
+
+// Get returns the paste. Pastes that require a key are only returned
+// along with their key.
+func (s *Service) Get(checksum, key string) (Paste, error) {
+	if !s.Authorize(checksum, key) {
+		return Paste{}, ErrInvalidKey
+	}
+	p, _, err := s.Retrieve(checksum)
+	if err == nil && s.RequiresKey(Meta{Visibility: p.Visibility}) {
+		p.Key = key
+	}
+	return p, err
+}
+
+// Retrieve returns the paste without checking its key, for callers that
+// authorize access themselves, along with the object it is stored as,
+// which is compressed for large pastes.
+func (s *Service) Retrieve(checksum string) (Paste, []byte, error) {
+	var p Paste
+	if !IsChecksum(checksum) {
+		return p, nil, fmt.Errorf("invalid checksum %s", checksum)
+	}
+
+	stored, err := s.RetrieveObject(checksum)
+	if err != nil {
+		return p, nil, err
+	}
+	data, err := Decode(checksum, stored)
+	if err != nil {
+		return p, nil, err
+	}
+	p.Content = string(data)
+	p.Checksum = Checksum(p.Content)
+	if p.Checksum != checksum {
+		return Paste{}, nil, ErrCorrupt
+	}
+	if !s.allows(p) {
+		return Paste{}, nil, ErrBlocked
+	}
+	m, _ := s.Meta(p.Checksum)
+	if m.Deleted {
+		return Paste{}, nil, ErrDeleted
+	}
+	p.Tags = m.Tags
+	p.Visibility = s.Visibility(m)
+	p.ContentType, p.Binary = ContentType(m, p.Content)
+	p.Encrypted = m.Encrypted
+	// Pastes created before previews and line counts were recorded get
+	// them when read
+	if m.ContentType != "" && (m.Preview == "" || m.Charset == "") && !p.Binary && !p.Encrypted {
+		m.setTextStats(p.Content)
+		if err := s.UpdateMeta(p.Checksum, func(m *Meta) { m.setTextStats(p.Content) }); err != nil {
+			log.Printf("Unable to record the preview of %s: %s\n", p.Checksum, err)
+		}
+	}
+	p.Lines, p.Language, p.Charset = m.Lines, m.Language, m.Charset
+	return p, stored, nil
+}
+
+// errReleased is how Delete tells that it released the reference of a
+// creation of a paste requiring a key, which is answered as a deletion.
+var errReleased = errors.New("reference released")
+
+// Delete releases the reference of the creator that token is the delete
+// token or passphrase of, and marks the paste as deleted when it was the
+// last one. ErrShared is returned when others created the paste as well,
+// so it is kept for them, unless the paste requires a key, as telling so
+// would tell that its content was pasted before. The content stays in the storage but is no
+// longer served, so that the paste can be restored until it is purged.
+func (s *Service) Delete(checksum, token string) error {
+	if !IsChecksum(checksum) {
+		return ErrInvalidDeleteToken
+	}
+	var err error
+	var deletedAt time.Time
+	updateErr := s.UpdateMeta(checksum, func(m *Meta) {
+		m.migrateReferences()
+		i := m.reference(token)
+		if i < 0 {
+			err = ErrInvalidDeleteToken
+			return
+		}
+		if m.Pinned {
+			err = ErrPinned
+			return
+		}
+		if len(m.References) > 1 {
+			// Telling that a paste requiring a key is kept for others
+			// would tell that its content was pasted before
+			keyed := s.RequiresKey(*m) && m.References[i].Key != ""
+			m.References = append(m.References[:i], m.References[i+1:]...)
+			err = ErrShared
+			if keyed {
+				err = errReleased
+			}
+			return
+		}
+		// The last reference is kept, so that its token deletes the
+		// paste again if it is restored
+		m.Deleted = true
+		m.DeletedAt = time.Now().UTC()
+		m.DeletedBy = DeletedByOwner
+		deletedAt = m.DeletedAt
+	})
+	if err != nil && err != ErrShared && err != errReleased {
+		return err
+	}
+	if updateErr != nil {
+		return updateErr
+	}
+	if err == ErrShared {
+		return err
+	}
+	if err == errReleased {
+		return nil
+	}
+	if err := s.IndexDeleted(checksum, deletedAt); err != nil {
+		log.Printf("Unable to index the deletion of %s: %s\n", checksum, err)
+	}
+	return nil
+}
+
+// Trash marks the paste as deleted without a delete token, for admins and
+// moderators. Pinned pastes are not deleted.
+func (s *Service) Trash(checksum string) error {
+	if !IsChecksum(checksum) {
+		return fmt.Errorf("invalid checksum %s", checksum)
+	}
+	var err error
+	var deletedAt time.Time
+	updateErr := s.UpdateMeta(checksum, func(m *Meta) {
+		if m.Pinned {
+			err = ErrPinned
+			return
+		}
+		m.Deleted = true
+		m.DeletedAt = time.Now().UTC()
+		m.DeletedBy = DeletedByAdmin
+		deletedAt = m.DeletedAt
+	})
+	if err != nil {
+		return err
+	}
+	if updateErr != nil {
+		return updateErr
+	}
+	if err := s.IndexDeleted(checksum, deletedAt); err != nil {
+		log.Printf("Unable to index the deletion of %s: %s\n", checksum, err)
+	}
+	return nil
+}
+
+// Pin sets whether the paste is pinned, which keeps it from being deleted.
+func (s *Service) Pin(checksum string, pinned bool) error {
+	if !IsChecksum(checksum) {
+		return fmt.Errorf("invalid checksum %s", checksum)
+	}
+	if _, err := s.Meta(checksum); err != nil {
+		return err
+	}
+	return s.UpdateMeta(checksum, func(m *Meta) {
+		m.Pinned = pinned
+	})
+}
+
+// Restore undoes the deletion of a paste that is still stored. Its delete
+// tokens stay valid.
+func (s *Service) Restore(checksum string) error {
+	if !IsChecksum(checksum) {
+		return fmt.Errorf("invalid checksum %s", checksum)
+	}
+	if _, err := s.RetrieveObject(checksum); err != nil {
+		return err
+	}
+	var err error
+	updateErr := s.UpdateMeta(checksum, func(m *Meta) {
+		if !m.Deleted {
+			err = errors.New("paste is not deleted")
+			return
+		}
+		m.Deleted = false
+		m.DeletedAt = time.Time{}
+		m.DeletedBy = ""
+	})
+	if err != nil {
+		return err
+	}
+	return updateErr
+}
+
+// ListOptions select the pastes to list.
+type ListOptions struct {
+	// Tag the pastes are tagged with, which is required as there is no
+	// index of all pastes.
+	Tag string
+}
+
+// List returns the public pastes matching the options, without their
+// content.
+func (s *Service) List(opts ListOptions) ([]Paste, error) {
+	if opts.Tag == "" {
+		return nil, ErrTagRequired
+	}
+	checksums, err := s.Tagged(opts.Tag)
+	if err != nil {
+		return nil, err
+	}
+	pastes := []Paste{}
+	for _, checksum := range checksums {
+		m, _ := s.Meta(checksum)
+		if m.Deleted || s.Visibility(m) != "public" || s.RequiresKey(m) {
+			continue
+		}
+		p := Paste{
+			Checksum:    checksum,
+			Tags:        m.Tags,
+			Visibility:  s.Visibility(m),
+			ContentType: m.ContentType,
+			Binary:      m.Binary,
+			Encrypted:   m.Encrypted,
+			Lines:       m.Lines,
+			Language:    m.Language,
+			Charset:     m.Charset,
+			Preview:     m.Preview,
+		}
+		if !s.allows(p) {
+			continue
+		}
+		pastes = append(pastes, p)
+	}
+	return pastes, nil
+}
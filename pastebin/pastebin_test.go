@@ -0,0 +1,36 @@
+package pastebin_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/espebra/pastebin/pastebin"
+)
+
+// metaFailing is a storage failing to store metadata records.
+type metaFailing struct {
+	*pastebin.MemoryStorage
+}
+
+func (s metaFailing) Store(key string, r io.Reader) (int64, error) {
+	if strings.HasPrefix(key, "meta-") {
+		return 0, errors.New("disk full")
+	}
+	return s.MemoryStorage.Store(key, r)
+}
+
+func TestCreateWithoutMeta(t *testing.T) {
+	storage := metaFailing{pastebin.NewMemoryStorage()}
+	s := pastebin.New(storage, pastebin.Options{KeySecret: "secret"})
+	p, err := s.Create("private", pastebin.CreateOptions{Visibility: "private"})
+	if err == nil {
+		t.Fatalf("Create returned %+v without storing its metadata", p)
+	}
+	var buf bytes.Buffer
+	if _, err := storage.Retrieve(s.ObjectKey(pastebin.Checksum("private")), &buf); err == nil {
+		t.Error("The paste is stored without its metadata, and would be served with the default visibility")
+	}
+}
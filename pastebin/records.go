@@ -0,0 +1,25 @@
+package pastebin
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// storeJSON stores v as a JSON record under key.
+func (s *Service) storeJSON(key string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = s.storage.Store(key, bytes.NewReader(data))
+	return err
+}
+
+// retrieveJSON reads the JSON record stored under key into v.
+func (s *Service) retrieveJSON(key string, v interface{}) error {
+	var buf bytes.Buffer
+	if _, err := s.storage.Retrieve(key, &buf); err != nil {
+		return err
+	}
+	return json.Unmarshal(buf.Bytes(), v)
+}
@@ -0,0 +1,103 @@
+package pastebin
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"log"
+)
+
+// errUnsealed is returned for sealed checksums none of the storage secrets
+// open.
+var errUnsealed = errors.New("the checksum is sealed with an unknown secret")
+
+// sealKey derives the key checksums are sealed with from a storage secret,
+// apart from the storage keys derived from the same secret.
+func sealKey(secret string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("sealed checksums"))
+	return mac.Sum(nil)
+}
+
+func sealAEAD(secret string) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(sealKey(secret))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// SealChecksum returns the checksum as recorded in the indexes and records
+// that refer to pastes, like the tag indexes and share links. With storage
+// secrets configured it is encrypted with the current secret, so that
+// anyone with read access to the storage can not confirm whether some
+// content exists by finding its checksum in them either.
+func (s *Service) SealChecksum(checksum string) (string, error) {
+	if len(s.opts.StorageSecrets) == 0 {
+		return checksum, nil
+	}
+	aead, err := sealAEAD(s.opts.StorageSecrets[0])
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(aead.Seal(nonce, nonce, []byte(checksum), nil)), nil
+}
+
+// OpenChecksum returns the checksum that SealChecksum sealed with any of
+// the storage secrets. Checksums recorded before they were sealed are
+// returned as they are.
+func (s *Service) OpenChecksum(sealed string) (string, error) {
+	if IsChecksum(sealed) {
+		return sealed, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(sealed)
+	if err != nil {
+		return "", errUnsealed
+	}
+	for _, secret := range s.opts.StorageSecrets {
+		aead, err := sealAEAD(secret)
+		if err != nil || len(data) < aead.NonceSize() {
+			continue
+		}
+		nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+		if checksum, err := aead.Open(nil, nonce, ciphertext, nil); err == nil {
+			return string(checksum), nil
+		}
+	}
+	return "", errUnsealed
+}
+
+// sealChecksums seals every checksum of an index.
+func (s *Service) sealChecksums(checksums []string) ([]string, error) {
+	sealed := make([]string, len(checksums))
+	for i, checksum := range checksums {
+		var err error
+		if sealed[i], err = s.SealChecksum(checksum); err != nil {
+			return nil, err
+		}
+	}
+	return sealed, nil
+}
+
+// openChecksums opens every checksum of an index, leaving out those sealed
+// with a secret that is no longer configured.
+func (s *Service) openChecksums(sealed []string) []string {
+	var checksums []string
+	for _, v := range sealed {
+		checksum, err := s.OpenChecksum(v)
+		if err != nil {
+			log.Printf("Unable to open a checksum in an index: %s\n", err)
+			continue
+		}
+		checksums = append(checksums, checksum)
+	}
+	return checksums
+}
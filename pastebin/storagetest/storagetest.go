@@ -0,0 +1,149 @@
+// Package storagetest checks that a pastebin.Storage behaves the way the
+// pastebin expects, so that every storage backend can be tested with the
+// same suite.
+package storagetest
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/espebra/pastebin/pastebin"
+)
+
+// Run runs the conformance suite against storages returned by newStorage,
+// which is called once for every subtest and should return an empty
+// storage.
+func Run(t *testing.T, newStorage func(t *testing.T) pastebin.Storage) {
+	t.Run("RoundTrip", func(t *testing.T) {
+		s := newStorage(t)
+		store(t, s, "key", []byte("Hello, world"))
+		expect(t, s, "key", []byte("Hello, world"))
+	})
+
+	t.Run("Overwrite", func(t *testing.T) {
+		s := newStorage(t)
+		store(t, s, "key", []byte("a longer first version"))
+		store(t, s, "key", []byte("second"))
+		expect(t, s, "key", []byte("second"))
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		s := newStorage(t)
+		var buf bytes.Buffer
+		_, err := s.Retrieve("missing", &buf)
+		if !errors.Is(err, fs.ErrNotExist) {
+			t.Fatalf("Retrieve of a missing key returned %v, want an error matching fs.ErrNotExist", err)
+		}
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		s := newStorage(t)
+		store(t, s, "empty", nil)
+		expect(t, s, "empty", nil)
+	})
+
+	t.Run("Binary", func(t *testing.T) {
+		s := newStorage(t)
+		data := make([]byte, 256)
+		for i := range data {
+			data[i] = byte(i)
+		}
+		store(t, s, "binary", data)
+		expect(t, s, "binary", data)
+	})
+
+	t.Run("Large", func(t *testing.T) {
+		s := newStorage(t)
+		data := make([]byte, 4<<20)
+		if _, err := rand.Read(data); err != nil {
+			t.Fatal(err)
+		}
+		store(t, s, "large", data)
+		expect(t, s, "large", data)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		s := newStorage(t)
+		store(t, s, "key", []byte("Hello, world"))
+		store(t, s, "other", []byte("kept"))
+		if err := s.Delete("key"); err != nil {
+			t.Fatalf("Delete: %s", err)
+		}
+		var buf bytes.Buffer
+		if _, err := s.Retrieve("key", &buf); !errors.Is(err, fs.ErrNotExist) {
+			t.Fatalf("Retrieve of a deleted key returned %v, want an error matching fs.ErrNotExist", err)
+		}
+		expect(t, s, "other", []byte("kept"))
+		if err := s.Delete("missing"); err != nil {
+			t.Fatalf("Delete of a missing key: %s", err)
+		}
+	})
+
+	t.Run("KeysAreIndependent", func(t *testing.T) {
+		s := newStorage(t)
+		keys := []string{"a", "b", "a.meta", "a-1", strings.Repeat("c", 128)}
+		for _, key := range keys {
+			store(t, s, key, []byte("content of "+key))
+		}
+		for _, key := range keys {
+			expect(t, s, key, []byte("content of "+key))
+		}
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		s := newStorage(t)
+		var wg sync.WaitGroup
+		errs := make(chan error, 16)
+		for i := 0; i < 16; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				key := fmt.Sprintf("key-%d", i)
+				if _, err := s.Store(key, strings.NewReader(key)); err != nil {
+					errs <- err
+				}
+			}(i)
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			t.Fatalf("Concurrent Store failed: %s", err)
+		}
+		for i := 0; i < 16; i++ {
+			key := fmt.Sprintf("key-%d", i)
+			expect(t, s, key, []byte(key))
+		}
+	})
+}
+
+func store(t *testing.T, s pastebin.Storage, key string, data []byte) {
+	t.Helper()
+	n, err := s.Store(key, bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Store %s: %s", key, err)
+	}
+	if n != int64(len(data)) {
+		t.Fatalf("Store %s wrote %d bytes, want %d", key, n, len(data))
+	}
+}
+
+func expect(t *testing.T, s pastebin.Storage, key string, want []byte) {
+	t.Helper()
+	var buf bytes.Buffer
+	n, err := s.Retrieve(key, &buf)
+	if err != nil {
+		t.Fatalf("Retrieve %s: %s", key, err)
+	}
+	if n != int64(buf.Len()) {
+		t.Fatalf("Retrieve %s returned %d bytes, but wrote %d", key, n, buf.Len())
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("Retrieve %s returned %d bytes that differ from the %d stored", key, buf.Len(), len(want))
+	}
+}
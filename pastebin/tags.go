@@ -0,0 +1,131 @@
+package pastebin
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Maximum number of tags on a paste.
+const maxTags = 10
+
+var tagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+var ErrInvalidTags = errors.New("tags must be 1 to 32 characters of a-z, 0-9, _ and -, and at most 10 per paste")
+
+func tagKey(tag string) string {
+	return "tag-" + tag
+}
+
+// ParseTags splits and validates comma separated tags.
+func ParseTags(s string) ([]string, error) {
+	var tags []string
+	seen := map[string]bool{}
+	for _, tag := range strings.Split(s, ",") {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if !tagPattern.MatchString(tag) {
+			return nil, ErrInvalidTags
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	if len(tags) > maxTags {
+		return nil, ErrInvalidTags
+	}
+	sort.Strings(tags)
+	return tags, nil
+}
+
+// mergeTags returns the tags of a paste with more tags added, leaving the
+// tags unchanged if there would be too many.
+func mergeTags(tags, more []string) []string {
+	if len(more) == 0 {
+		return tags
+	}
+	merged, err := ParseTags(strings.Join(append(append([]string{}, tags...), more...), ","))
+	if err != nil {
+		return tags
+	}
+	return merged
+}
+
+// Tagged returns the checksums of the pastes with the tag.
+func (s *Service) Tagged(tag string) ([]string, error) {
+	var sealed []string
+	err := s.retrieveJSON(tagKey(tag), &sealed)
+	return s.openChecksums(sealed), err
+}
+
+// storeTagged stores the index of the tag with the checksums sealed.
+func (s *Service) storeTagged(tag string, checksums []string) error {
+	sealed, err := s.sealChecksums(checksums)
+	if err != nil {
+		return err
+	}
+	return s.storeJSON(tagKey(tag), sealed)
+}
+
+// Tag adds the tags to the metadata of the paste and the paste to the
+// index of each tag.
+func (s *Service) Tag(checksum string, tags []string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	err := s.UpdateMeta(checksum, func(m *Meta) {
+		m.Tags = mergeTags(m.Tags, tags)
+	})
+	if err != nil {
+		return err
+	}
+	return s.index(checksum, tags)
+}
+
+// Untag removes the paste from the index of each tag, for pastes that are
+// removed from the storage.
+func (s *Service) Untag(checksum string, tags []string) error {
+	s.tagsMu.Lock()
+	defer s.tagsMu.Unlock()
+	for _, tag := range tags {
+		checksums, err := s.Tagged(tag)
+		if err != nil {
+			continue
+		}
+		kept := checksums[:0]
+		for _, c := range checksums {
+			if c != checksum {
+				kept = append(kept, c)
+			}
+		}
+		if err := s.storeTagged(tag, kept); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// index adds the paste to the index of each tag.
+func (s *Service) index(checksum string, tags []string) error {
+	s.tagsMu.Lock()
+	defer s.tagsMu.Unlock()
+	for _, tag := range tags {
+		checksums, _ := s.Tagged(tag)
+		found := false
+		for _, c := range checksums {
+			if c == checksum {
+				found = true
+				break
+			}
+		}
+		if found {
+			continue
+		}
+		if err := s.storeTagged(tag, append(checksums, checksum)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"io/ioutil"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/espebra/pastebin/pastebin"
+)
+
+// plainTextClient reports whether the client wants a plain text response,
+// like curl and wget, rather than the HTML pages.
+func plainTextClient(r *http.Request) bool {
+	ua := r.Header.Get("User-Agent")
+	if strings.HasPrefix(ua, "curl/") || strings.HasPrefix(ua, "Wget/") {
+		return true
+	}
+	accept := r.Header.Get("Accept")
+	return strings.Contains(accept, "text/plain") && !strings.Contains(accept, "text/html")
+}
+
+// requestURL returns the URL of path as seen by the client. Without
+// -base-url it is derived from the request.
+func requestURL(r *http.Request, path string) string {
+	if *baseURLFlag != "" {
+		return absURL(path)
+	}
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + path
+}
+
+// plainContent returns the paste content of a plain text request, and the
+// values of its options. Form posts with a content field are supported,
+// with the options in their fields or in the query, and any other body is
+// taken as the paste itself, as sent by curl --data-binary @file, with the
+// options in the query.
+func plainContent(w http.ResponseWriter, r *http.Request) (string, url.Values, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		r.Body = http.MaxBytesReader(w, r.Body, requestBodyLimit())
+		content := r.FormValue("content")
+		return content, r.Form, nil
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, requestBodyLimit()))
+	if err != nil {
+		return "", nil, err
+	}
+	if mediaType == "application/x-www-form-urlencoded" {
+		if values, err := url.ParseQuery(string(body)); err == nil {
+			if content, ok := values["content"]; ok && len(content) > 0 {
+				for name, v := range r.URL.Query() {
+					if _, ok := values[name]; !ok {
+						values[name] = v
+					}
+				}
+				return content[0], values, nil
+			}
+		}
+	}
+	return string(body), r.URL.Query(), nil
+}
+
+// savePlainPaste creates a paste from a plain text request and responds
+// with its URL. The delete token is returned in the X-Delete-Token header.
+func savePlainPaste(w http.ResponseWriter, r *http.Request) {
+	if err := checkPow(r); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if err := checkCaptcha(r, true); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	content, options, err := plainContent(w, r)
+	if err != nil {
+		http.Error(w, "Unable to read the paste: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	createPlainPaste(w, r, content, options)
+}
+
+// quickPaste creates a paste of the raw body of PUT /q and responds with
+// its URL in plain text, whatever the client, for editor plugins and shell
+// aliases. It takes the query parameters of createPlainPaste. Pastes do
+// not expire, so a ttl is refused rather than silently ignored.
+func quickPaste(w http.ResponseWriter, r *http.Request) {
+	if ttl := r.URL.Query().Get("ttl"); ttl != "" {
+		http.Error(w, "Pastes do not expire on this pastebin, so ttl is not supported", http.StatusBadRequest)
+		return
+	}
+	if err := checkPow(r); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if err := checkCaptcha(r, true); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, requestBodyLimit()))
+	if err != nil {
+		http.Error(w, "Unable to read the paste: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	createPlainPaste(w, r, string(body), r.URL.Query())
+}
+
+// createPlainPaste creates the paste with the tags, visibility and lang
+// (language) in options, and responds with its URL.
+func createPlainPaste(w http.ResponseWriter, r *http.Request, content string, options url.Values) {
+	if content == "" {
+		http.Error(w, "Empty paste", http.StatusBadRequest)
+		return
+	}
+	if int64(len(content)) > maxPasteSizeFlag.Get() {
+		http.Error(w, errPasteTooLarge.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err := verifyChecksum(expectedChecksum(r), content); err != nil {
+		http.Error(w, err.Message, http.StatusBadRequest)
+		return
+	}
+
+	tags, err := pastebin.ParseTags(options.Get("tags"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	visibility := options.Get("visibility")
+	if visibility == "" {
+		visibility = *defaultVisibilityFlag
+	}
+	if !pastebin.ValidVisibility(visibility) || (visibility == "private" && *secretFlag == "") {
+		http.Error(w, "Invalid visibility "+visibility, http.StatusBadRequest)
+		return
+	}
+	language := strings.ToLower(options.Get("lang"))
+	if language != "" && !pastebin.ValidLanguage(language) {
+		http.Error(w, "Invalid language "+language, http.StatusBadRequest)
+		return
+	}
+
+	var p Paste
+	p.Content = content
+	p.Checksum = p.GetName()
+	p.DeletePassphrase = r.Header.Get("X-Delete-Passphrase")
+	p.Language = language
+	_, token, err := createPaste(&p, visibility, tags)
+	if err != nil {
+		log.Printf("Unable to write data: %s\n", err)
+		if err == errBlocked {
+			http.Error(w, "This content is not allowed.", http.StatusForbidden)
+			return
+		}
+		if err == errSpam {
+			http.Error(w, "The paste looks like spam and was not saved.", http.StatusForbidden)
+			return
+		}
+		if err == pastebin.ErrShortPassphrase {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if storageUnavailable(w, err) || insufficientStorage(w, err) {
+			return
+		}
+		http.Error(w, "Unable to save "+p.Checksum, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if token != "" {
+		w.Header().Set("X-Delete-Token", token)
+	}
+	// Duplicates answer with the existing paste, which was not created now
+	if p.Duplicate {
+		w.Header().Set("X-Duplicate", "true")
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusCreated)
+	}
+	w.Write([]byte(requestURL(r, p.Location()) + "\n"))
+}
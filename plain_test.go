@@ -0,0 +1,73 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/espebra/pastebin/pastebin"
+)
+
+func TestQuickPaste(t *testing.T) {
+	h := newTestServer(t)
+	content := "#!/bin/sh\necho raw body, not a form: content=x&y=z\n"
+	rec := request(h, "PUT", "/q", content, map[string]string{"User-Agent": "Mozilla/5.0", "Accept": "text/html"})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("PUT /q returned %d: %s", rec.Code, rec.Body.String())
+	}
+	checksum := pastebin.Checksum(content)
+	if want := "http://example.com/" + checksum + "\n"; rec.Body.String() != want {
+		t.Errorf("PUT /q answered %q, want the plain URL %q whatever the client", rec.Body.String(), want)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("PUT /q answered with Content-Type %s, want text/plain", ct)
+	}
+	token := rec.Header().Get("X-Delete-Token")
+	if token == "" {
+		t.Fatal("PUT /q returned no X-Delete-Token")
+	}
+
+	if rec := request(h, "GET", "/raw/"+checksum, "", nil); rec.Body.String() != content {
+		t.Errorf("The paste is %q, want the raw body %q", rec.Body.String(), content)
+	}
+	rec = request(h, "DELETE", "/api/v1/pastes/"+checksum, "", map[string]string{"X-Delete-Token": token})
+	if rec.Code != http.StatusOK {
+		t.Errorf("Deleting with the X-Delete-Token returned %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestQuickPasteOptions(t *testing.T) {
+	h := newTestServer(t)
+	rec := request(h, "PUT", "/q?lang=Go&visibility=public&tags=a,b", "x := 1\n", nil)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("PUT /q returned %d: %s", rec.Code, rec.Body.String())
+	}
+	m, err := service.Meta(pastebin.Checksum("x := 1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Language != "go" {
+		t.Errorf("Language is %q, want go from ?lang", m.Language)
+	}
+	if m.Visibility != "public" {
+		t.Errorf("Visibility is %q, want public from ?visibility", m.Visibility)
+	}
+	if strings.Join(m.Tags, ",") != "a,b" {
+		t.Errorf("Tags are %v, want a and b", m.Tags)
+	}
+}
+
+func TestQuickPasteRefused(t *testing.T) {
+	h := newTestServer(t)
+	for _, target := range []string{"/q?ttl=1h", "/q?visibility=secret", "/q?lang=no%20such%20language"} {
+		if rec := request(h, "PUT", target, "refused", nil); rec.Code != http.StatusBadRequest {
+			t.Errorf("PUT %s returned %d, want 400", target, rec.Code)
+		}
+	}
+	if _, err := service.Meta(pastebin.Checksum("refused")); err == nil {
+		t.Error("A refused paste was created")
+	}
+	if rec := request(h, "PUT", "/q", "", nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("PUT /q of an empty body returned %d, want 400", rec.Code)
+	}
+}
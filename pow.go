@@ -0,0 +1,212 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math/bits"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/espebra/pastebin/apitypes"
+)
+
+// How long a proof-of-work challenge can be solved and used.
+const powChallengeTTL = 10 * time.Minute
+
+var errProofOfWork = errors.New("proof of work is missing or invalid")
+
+// Challenges are signed, so they need no server side state until used.
+// They are signed with -secret, as signed URLs are, so that instances
+// sharing it accept each other's challenges, or with powKey when no secret
+// is set, which invalidates outstanding challenges on restart.
+var powKey = func() []byte {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return b
+}()
+
+// Challenges that have been used, kept until they expire so each can only
+// be used once. Expired challenges are swept at most once per
+// powChallengeTTL. Instances sharing the data directories also record the
+// challenges they accept there, so that a challenge accepted by one is
+// refused by the others.
+var (
+	powUsedMu  sync.Mutex
+	powUsed    = map[string]time.Time{}
+	powSweptAt time.Time
+)
+
+// PowChallenge is a challenge to find a nonce such that the SHA-256 of the
+// challenge, a colon and the nonce starts with Difficulty zero bits.
+type PowChallenge = apitypes.PowChallenge
+
+func powEnabled() bool {
+	return powDifficultyFlag.Int() > 0
+}
+
+func powSign(payload string) string {
+	key := powKey
+	if *secretFlag != "" {
+		key = []byte(*secretFlag)
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("pow\n" + payload))
+	return hex.EncodeToString(mac.Sum(nil))[:32]
+}
+
+// newPowChallenge returns a signed challenge of the form
+// timestamp.random.signature.
+func newPowChallenge() PowChallenge {
+	b := make([]byte, 8)
+	rand.Read(b)
+	payload := strconv.FormatInt(time.Now().Unix(), 10) + "." + hex.EncodeToString(b)
+	return PowChallenge{
+		Challenge:  payload + "." + powSign(payload),
+		Difficulty: powDifficultyFlag.Int(),
+	}
+}
+
+// leadingZeroBits returns the number of leading zero bits of sum.
+func leadingZeroBits(sum []byte) int {
+	n := 0
+	for _, b := range sum {
+		if b != 0 {
+			return n + bits.LeadingZeros8(b)
+		}
+		n += 8
+	}
+	return n
+}
+
+// verifyPow checks a solved challenge, and marks it as used.
+func verifyPow(challenge, nonce string) error {
+	parts := strings.Split(challenge, ".")
+	if len(parts) != 3 || nonce == "" {
+		return errProofOfWork
+	}
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(powSign(payload))) {
+		return errProofOfWork
+	}
+	ts, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || time.Since(time.Unix(ts, 0)) > powChallengeTTL {
+		return fmt.Errorf("%s: the challenge has expired", errProofOfWork)
+	}
+
+	sum := sha256.Sum256([]byte(challenge + ":" + nonce))
+	if leadingZeroBits(sum[:]) < powDifficultyFlag.Int() {
+		return errProofOfWork
+	}
+
+	powUsedMu.Lock()
+	defer powUsedMu.Unlock()
+	if now := time.Now(); now.Sub(powSweptAt) >= powChallengeTTL {
+		powSweptAt = now
+		for c, expires := range powUsed {
+			if now.After(expires) {
+				delete(powUsed, c)
+			}
+		}
+		sweepUsedPow(now)
+	}
+	if _, ok := powUsed[challenge]; ok || !claimPow(challenge) {
+		return fmt.Errorf("%s: the challenge is already used", errProofOfWork)
+	}
+	powUsed[challenge] = time.Unix(ts, 0).Add(powChallengeTTL)
+	return nil
+}
+
+// powUsedDir is the directory the challenges used by any instance sharing
+// the data directories are recorded in, or an empty string without data
+// directories.
+func powUsedDir() string {
+	if len(dataDirs()) == 0 {
+		return ""
+	}
+	return filepath.Join(dataDirs()[0], "pow-used")
+}
+
+// claimPow records the challenge as used in the data directory, like a
+// lease, and reports false if another instance used it first. Challenges
+// are accepted when they can not be recorded, as they are by this instance.
+func claimPow(challenge string) bool {
+	dir := powUsedDir()
+	if dir == "" {
+		return true
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		log.Printf("Unable to record used challenges: %s\n", err)
+		return true
+	}
+	sum := sha256.Sum256([]byte(challenge))
+	f, err := os.OpenFile(filepath.Join(dir, hex.EncodeToString(sum[:16])), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if os.IsExist(err) {
+		return false
+	}
+	if err != nil {
+		log.Printf("Unable to record a used challenge: %s\n", err)
+		return true
+	}
+	f.Close()
+	return true
+}
+
+// sweepUsedPow removes the records of used challenges that have expired
+// since, which were recorded longer than powChallengeTTL ago.
+func sweepUsedPow(now time.Time) {
+	dir := powUsedDir()
+	if dir == "" {
+		return
+	}
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, fi := range entries {
+		if now.Sub(fi.ModTime()) > powChallengeTTL {
+			os.Remove(filepath.Join(dir, fi.Name()))
+		}
+	}
+}
+
+// checkPow verifies the proof of work of a request creating pastes, given
+// in the X-PoW-Challenge and X-PoW-Nonce headers or the pow_challenge and
+// pow_nonce form fields. It succeeds when proof of work is disabled.
+func checkPow(r *http.Request) error {
+	if !powEnabled() {
+		return nil
+	}
+	challenge, nonce := r.Header.Get("X-PoW-Challenge"), r.Header.Get("X-PoW-Nonce")
+	if challenge == "" {
+		challenge, nonce = r.FormValue("pow_challenge"), r.FormValue("pow_nonce")
+	}
+	return verifyPow(challenge, nonce)
+}
+
+// powChallenge hands out a challenge, as JSON or as plain text for command
+// line clients in the form "challenge difficulty".
+func powChallenge(w http.ResponseWriter, r *http.Request) {
+	if !powEnabled() {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	c := newPowChallenge()
+	if plainTextClient(r) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintf(w, "%s %d\n", c.Challenge, c.Difficulty)
+		return
+	}
+	writeJSON(w, http.StatusOK, c)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"crypto/sha256"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// solvePow returns a nonce solving the challenge.
+func solvePow(c PowChallenge) string {
+	for n := 0; ; n++ {
+		nonce := strconv.Itoa(n)
+		sum := sha256.Sum256([]byte(c.Challenge + ":" + nonce))
+		if leadingZeroBits(sum[:]) >= c.Difficulty {
+			return nonce
+		}
+	}
+}
+
+func TestVerifyPow(t *testing.T) {
+	difficulty := powDifficultyFlag.String()
+	powDifficultyFlag.Set("4")
+	t.Cleanup(func() { powDifficultyFlag.Set(difficulty) })
+
+	for _, secret := range []string{"", "pow secret"} {
+		setFlag(t, secretFlag, secret)
+		c := newPowChallenge()
+		nonce := solvePow(c)
+		if err := verifyPow(c.Challenge, nonce); err != nil {
+			t.Errorf("The solved challenge was refused with secret %q: %s", secret, err)
+		}
+		if err := verifyPow(c.Challenge, nonce); err == nil {
+			t.Error("The challenge was accepted twice")
+		}
+
+		parts := strings.Split(newPowChallenge().Challenge, ".")
+		forged := parts[0] + "." + strings.Repeat("0", len(parts[1])) + "." + parts[2]
+		if err := verifyPow(forged, solvePow(PowChallenge{Challenge: forged, Difficulty: 4})); err == nil {
+			t.Error("A challenge with a forged signature was accepted")
+		}
+	}
+}
+
+func TestVerifyPowSharedDataDirectory(t *testing.T) {
+	difficulty := powDifficultyFlag.String()
+	powDifficultyFlag.Set("4")
+	t.Cleanup(func() { powDifficultyFlag.Set(difficulty) })
+	setFlag(t, secretFlag, "shared secret")
+	setFlag(t, dataDirFlag, t.TempDir())
+
+	c := newPowChallenge()
+	nonce := solvePow(c)
+	if err := verifyPow(c.Challenge, nonce); err != nil {
+		t.Fatalf("The solved challenge was refused: %s", err)
+	}
+	// Another instance sharing the data directory does not have the
+	// challenge among those it used itself
+	powUsedMu.Lock()
+	delete(powUsed, c.Challenge)
+	powUsedMu.Unlock()
+	if err := verifyPow(c.Challenge, nonce); err == nil {
+		t.Error("The challenge was accepted again by another instance")
+	}
+}
@@ -0,0 +1,10 @@
+package main
+
+// Location returns the path of the paste, including the key when it is
+// required.
+func (v Paste) Location() string {
+	if v.Key == "" {
+		return "/" + v.Checksum
+	}
+	return "/" + v.Checksum + "/" + v.Key
+}
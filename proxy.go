@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// How long a trusted proxy has to send the PROXY protocol header.
+const proxyHeaderTimeout = 5 * time.Second
+
+// Signature starting a version 2 PROXY protocol header.
+var proxyV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+var errProxyHeader = errors.New("invalid PROXY protocol header")
+
+// proxyList is the load balancers and proxies in -trusted-proxies.
+type proxyList struct {
+	nets []*net.IPNet
+	unix bool
+}
+
+// parseTrustedProxies reads -trusted-proxies, given as addresses and
+// networks separated by commas, with unix for Unix domain sockets.
+func parseTrustedProxies(s string) (proxyList, error) {
+	var proxies proxyList
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		switch {
+		case item == "":
+		case item == "unix":
+			proxies.unix = true
+		case strings.Contains(item, "/"):
+			_, ipNet, err := net.ParseCIDR(item)
+			if err != nil {
+				return proxies, fmt.Errorf("trusted proxy %q is not a valid network", item)
+			}
+			proxies.nets = append(proxies.nets, ipNet)
+		default:
+			ip := net.ParseIP(item)
+			if ip == nil {
+				return proxies, fmt.Errorf("trusted proxy %q is not a valid address", item)
+			}
+			bits := 8 * len(ip.To16())
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			proxies.nets = append(proxies.nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+		}
+	}
+	return proxies, nil
+}
+
+var (
+	trustedOnce sync.Once
+	trusted     proxyList
+)
+
+// trustedProxies returns the proxies in -trusted-proxies, which are
+// checked when the configuration is validated.
+func trustedProxies() proxyList {
+	trustedOnce.Do(func() {
+		trusted, _ = parseTrustedProxies(*trustedProxiesFlag)
+	})
+	return trusted
+}
+
+func (p proxyList) trustsIP(ip net.IP) bool {
+	for _, n := range p.nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// trusts reports whether the address of a connection is a trusted proxy.
+// Connections over Unix domain sockets have no address.
+func (p proxyList) trusts(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return p.unix
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return p.unix
+	}
+	return p.trustsIP(ip)
+}
+
+// clientIP returns the address of the client, used for rate limits,
+// quotas and access logs. Behind trusted proxies it is the last address
+// in X-Forwarded-For that is not of a trusted proxy, as the addresses
+// before it may be made up by the client, or else X-Real-IP.
+func clientIP(r *http.Request) string {
+	host := r.RemoteAddr
+	if h, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		host = h
+	}
+	proxies := trustedProxies()
+	if !proxies.trusts(r.RemoteAddr) {
+		return host
+	}
+	if forwarded := r.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+		addrs := strings.Split(strings.Join(forwarded, ","), ",")
+		for i := len(addrs) - 1; i >= 0; i-- {
+			ip := net.ParseIP(strings.TrimSpace(addrs[i]))
+			if ip == nil {
+				break
+			}
+			host = ip.String()
+			if !proxies.trustsIP(ip) {
+				break
+			}
+		}
+		return host
+	}
+	if ip := net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP"))); ip != nil {
+		return ip.String()
+	}
+	return host
+}
+
+// proxyListener reads the PROXY protocol header of the connections from
+// trusted proxies with -proxy-protocol, so that the address of a
+// connection is that of the client rather than of the proxy.
+type proxyListener struct {
+	net.Listener
+}
+
+// withProxyProtocol returns the listener reading PROXY protocol headers
+// if -proxy-protocol is set, or else the listener itself.
+func withProxyProtocol(l net.Listener) net.Listener {
+	if !*proxyProtocolFlag {
+		return l
+	}
+	return proxyListener{l}
+}
+
+func (l proxyListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &proxyConn{Conn: conn}, nil
+}
+
+// proxyConn reads the PROXY protocol header when it is first read from or
+// asked for its address, which is done by the goroutine serving it rather
+// than the one accepting connections.
+type proxyConn struct {
+	net.Conn
+	once   sync.Once
+	r      *bufio.Reader
+	remote net.Addr
+	err    error
+}
+
+func (c *proxyConn) init() {
+	c.once.Do(func() {
+		c.r = bufio.NewReader(c.Conn)
+		c.remote = c.Conn.RemoteAddr()
+		if !trustedProxies().trusts(c.remote.String()) {
+			return
+		}
+		c.Conn.SetReadDeadline(time.Now().Add(proxyHeaderTimeout))
+		addr, err := readProxyHeader(c.r)
+		c.Conn.SetReadDeadline(time.Time{})
+		if err != nil {
+			log.Printf("Unable to read the PROXY protocol header from %s: %s\n", c.remote, err)
+			c.err = err
+			return
+		}
+		if addr != nil {
+			c.remote = addr
+		}
+	})
+}
+
+func (c *proxyConn) Read(b []byte) (int, error) {
+	c.init()
+	if c.err != nil {
+		return 0, c.err
+	}
+	return c.r.Read(b)
+}
+
+func (c *proxyConn) RemoteAddr() net.Addr {
+	c.init()
+	return c.remote
+}
+
+// readProxyHeader reads a version 1 or 2 PROXY protocol header and returns
+// the address of the client, or nil when the proxy does not tell it, such
+// as for health checks.
+func readProxyHeader(r *bufio.Reader) (net.Addr, error) {
+	if sig, err := r.Peek(len(proxyV2Signature)); err == nil && bytes.Equal(sig, proxyV2Signature) {
+		return readProxyHeaderV2(r)
+	}
+
+	// Version 1 headers are a line of at most 107 bytes
+	line, err := r.ReadSlice('\n')
+	if err != nil || len(line) > 107 || !bytes.HasSuffix(line, []byte("\r\n")) {
+		return nil, errProxyHeader
+	}
+	fields := strings.Fields(string(line))
+	if len(fields) < 2 || fields[0] != "PROXY" {
+		return nil, errProxyHeader
+	}
+	if fields[1] == "UNKNOWN" {
+		return nil, nil
+	}
+	if len(fields) != 6 || fields[1] != "TCP4" && fields[1] != "TCP6" {
+		return nil, errProxyHeader
+	}
+	ip := net.ParseIP(fields[2])
+	port, err := strconv.Atoi(fields[4])
+	if ip == nil || err != nil || port < 0 || port > 65535 {
+		return nil, errProxyHeader
+	}
+	return &net.TCPAddr{IP: ip, Port: port}, nil
+}
+
+func readProxyHeaderV2(r *bufio.Reader) (net.Addr, error) {
+	header := make([]byte, 16)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, errProxyHeader
+	}
+	if header[12]>>4 != 2 {
+		return nil, errProxyHeader
+	}
+	body := make([]byte, binary.BigEndian.Uint16(header[14:16]))
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, errProxyHeader
+	}
+	// LOCAL connections are made by the proxy itself
+	if header[12]&0x0f == 0 {
+		return nil, nil
+	}
+	switch header[13] {
+	case 0x11: // TCP over IPv4
+		if len(body) < 12 {
+			return nil, errProxyHeader
+		}
+		return &net.TCPAddr{IP: net.IP(body[0:4]), Port: int(binary.BigEndian.Uint16(body[8:10]))}, nil
+	case 0x21: // TCP over IPv6
+		if len(body) < 36 {
+			return nil, errProxyHeader
+		}
+		return &net.TCPAddr{IP: net.IP(body[0:16]), Port: int(binary.BigEndian.Uint16(body[32:34]))}, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/espebra/pastebin/internal/jobs"
+	"github.com/gorilla/mux"
+)
+
+// jobQueue runs the background work of the pastebin.
+var jobQueue *jobs.Queue
+
+// newJobQueue returns a queue kept in the storage, with the kinds of jobs
+// the pastebin runs. Replication jobs are created by every write to the
+// storage, so they are not kept.
+func newJobQueue(storage Storage) *jobs.Queue {
+	q := jobs.New(storage, "jobs")
+	q.Register("replicate", jobs.Kind{Handler: replicateJob, Attempts: 3, Backoff: time.Second})
+	q.Register("purge-trash", jobs.Kind{Handler: purgeTrashJob})
+	q.Register("storage-usage", jobs.Kind{Handler: storageUsageJob})
+	q.Register("bulk-delete", jobs.Kind{Handler: bulkDeleteJob, Attempts: 3, Backoff: time.Minute, Persist: true})
+	return q
+}
+
+// AdminJobs is the data shown on the jobs page.
+type AdminJobs struct {
+	Jobs    []jobs.Job
+	Message string
+	Status  string
+}
+
+func adminJobs(w http.ResponseWriter, r *http.Request) {
+	renderTemplate(w, r, "templates/jobs.html", "jobs", AdminJobs{Jobs: jobQueue.List()})
+}
+
+func apiJobs(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, jobQueue.List())
+}
+
+// apiJob returns the status and progress of a job.
+func apiJob(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	j, ok := jobQueue.Get(id)
+	if !ok {
+		writeJSON(w, http.StatusNotFound, BatchResult{Status: "error", Message: "Job " + id + " does not exist"})
+		return
+	}
+	if j.Status == jobs.Queued || j.Status == jobs.Running {
+		w.Header().Set("Retry-After", "1")
+	}
+	writeJSON(w, http.StatusOK, j)
+}
+
+// startJobQueue loads the jobs left from the last run and starts the
+// workers.
+func startJobQueue() {
+	if err := jobQueue.Load(); err != nil {
+		log.Printf("No job queue loaded: %s\n", err)
+	}
+	jobQueue.Start(*jobWorkersFlag)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"net"
+	"sync"
+	"time"
+)
+
+// rateLimiter allows a number of events per key within a fixed window of
+// time, e.g. pastes per client IP per minute.
+type rateLimiter struct {
+	sync.Mutex
+	limit   func() int
+	window  time.Duration
+	windows map[string]*rateWindow
+	swept   time.Time
+}
+
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+// newRateLimiter returns a limiter with the limit returned by limit, which
+// may change while the limiter is in use.
+func newRateLimiter(limit func() int, window time.Duration) *rateLimiter {
+	return &rateLimiter{
+		limit:   limit,
+		window:  window,
+		windows: map[string]*rateWindow{},
+	}
+}
+
+// Allow records an event for key and reports whether it is within the
+// limit. A limit of zero or less allows everything.
+func (l *rateLimiter) Allow(key string) bool {
+	limit := l.limit()
+	if limit <= 0 {
+		return true
+	}
+
+	l.Lock()
+	defer l.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.swept) >= l.window {
+		l.sweep(now)
+	}
+	w, ok := l.windows[key]
+	if !ok || now.Sub(w.start) >= l.window {
+		w = &rateWindow{start: now}
+		l.windows[key] = w
+	}
+	w.count++
+	return w.count <= limit
+}
+
+// sweep forgets windows that have ended, so the map does not grow with
+// every client ever seen. It runs at most once per window, rather than
+// walking every client on each new one.
+func (l *rateLimiter) sweep(now time.Time) {
+	l.swept = now
+	for key, w := range l.windows {
+		if now.Sub(w.start) >= l.window {
+			delete(l.windows, key)
+		}
+	}
+}
+
+// clientKey returns what clients are limited by: the address of IPv4
+// clients, and the -ipv6-prefix network of IPv6 clients, as a single user
+// usually has a whole /64 to pick addresses from.
+func clientKey(addr string) string {
+	ip := net.ParseIP(addr)
+	if ip == nil || ip.To4() != nil {
+		return addr
+	}
+	mask := net.CIDRMask(*ipv6PrefixFlag, 128)
+	return (&net.IPNet{IP: ip.Mask(mask), Mask: mask}).String()
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	l := newRateLimiter(func() int { return 2 }, 50*time.Millisecond)
+	for i, want := range []bool{true, true, false} {
+		if got := l.Allow("a"); got != want {
+			t.Errorf("Event %d allowed: %v, want %v", i+1, got, want)
+		}
+	}
+	if !l.Allow("b") {
+		t.Error("The limit of one key applied to another")
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if !l.Allow("a") {
+		t.Error("The limit applied after the window ended")
+	}
+	if _, ok := l.windows["b"]; ok {
+		t.Error("The ended window of b was not swept")
+	}
+}
+
+func TestRateLimiterSweepsOncePerWindow(t *testing.T) {
+	l := newRateLimiter(func() int { return 1 }, time.Hour)
+	l.Allow("first")
+	swept := l.swept
+	for i := 0; i < 100; i++ {
+		l.Allow(strconv.Itoa(i))
+	}
+	if l.swept != swept {
+		t.Error("New keys swept the windows again within the window")
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/espebra/pastebin/pastebin"
+	"github.com/gorilla/mux"
+)
+
+// etagMatch reports whether the If-None-Match header value matches etag,
+// using the weak comparison required for If-None-Match.
+func etagMatch(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// notModified sets the ETag header and responds with 304 Not Modified if
+// the client already has the current representation. Pastes are immutable
+// and content-addressed, so the checksum alone decides the ETag.
+func notModified(w http.ResponseWriter, r *http.Request, etag string) bool {
+	w.Header().Set("ETag", etag)
+	if etagMatch(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return true
+	}
+	return false
+}
+
+// setRawHeaders sets the content type of a raw paste. Binary pastes other
+// than images are downloaded rather than shown, so a paste can never be
+// rendered as a page of the site.
+func setRawHeaders(w http.ResponseWriter, checksum, contentType string, binary bool) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if binary && !isImage(contentType) {
+		w.Header().Set("Content-Disposition", `attachment; filename="`+checksum+`"`)
+	}
+}
+
+func rawPaste(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	checksum := vars["checksum"]
+
+	if !pastebin.IsChecksum(checksum) {
+		http.NotFound(w, r)
+		return
+	}
+	expires, signed := validSignature(r, checksum)
+	if !signed && !service.Authorize(checksum, vars["key"]) {
+		http.NotFound(w, r)
+		return
+	}
+	if *hotlinkProtectionFlag {
+		// Caches must not serve the paste to pages of other sites
+		w.Header().Add("Vary", "Referer")
+		if !signed && !hotlinkAllowed(r) {
+			http.Error(w, "Raw pastes can not be linked to from other sites", http.StatusForbidden)
+			return
+		}
+	}
+
+	m, _ := service.Meta(checksum)
+	// Browsers are sent to the page that decrypts the paste, keeping the
+	// key in the fragment
+	if m.Encrypted && !signed && !plainTextClient(r) {
+		p := Paste{Checksum: checksum, Key: vars["key"]}
+		http.Redirect(w, r, basePath()+p.Location(), http.StatusFound)
+		return
+	}
+	if signed {
+		// Caches must not serve the paste after the signature expires
+		w.Header().Set("Cache-Control", "private, max-age="+strconv.Itoa(int(time.Until(expires).Seconds())))
+	} else if service.RequiresKey(m) {
+		w.Header().Set("Cache-Control", "private, max-age=31536000, immutable")
+	} else {
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	}
+
+	// Compressed pastes are sent as stored to clients that accept gzip,
+	// except for range requests, which refer to the decompressed content
+	etag := `"` + checksum + `"`
+	passthrough := false
+	if m.Encoding == pastebin.GzipEncoding {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if acceptsGzip(r) && r.Header.Get("Range") == "" {
+			passthrough = true
+			etag = `"` + checksum + `-gzip"`
+		}
+	}
+	if available(checksum) && notModified(w, r, etag) {
+		views.Add(service.Scope(checksum, vars["key"]))
+		accesses.Add(checksum, r)
+		return
+	}
+
+	// HEAD requests, e.g. from monitoring, are answered from the metadata
+	// when it has the size, without reading the paste. They are not views.
+	if r.Method == "HEAD" && m.Size > 0 && !passthrough && available(checksum) {
+		setRawHeaders(w, checksum, m.ContentType, m.Binary)
+		w.Header().Set("ETag", `"`+checksum+`"`)
+		w.Header().Set("Accept-Ranges", "bytes")
+		w.Header().Set("Content-Length", strconv.FormatInt(m.Size, 10))
+		return
+	}
+
+	p, stored, err := retrieveStoredPaste(checksum, vars["key"])
+	if err != nil {
+		log.Println(err)
+		w.Header().Del("Cache-Control")
+		w.Header().Del("ETag")
+		if storageUnavailable(w, err) {
+			return
+		}
+		if message, ok := goneMessage(checksum, err); ok {
+			http.Error(w, message, http.StatusGone)
+			return
+		}
+		http.NotFound(w, r)
+		return
+	}
+
+	views.Add(service.Scope(checksum, vars["key"]))
+	accesses.Add(checksum, r)
+
+	setRawHeaders(w, checksum, p.ContentType, p.Binary)
+
+	if passthrough && string(stored) != p.Content {
+		w.Header().Set("Content-Encoding", pastebin.GzipEncoding)
+		w.Header().Set("Content-Length", strconv.Itoa(len(stored)))
+		w.Write(stored)
+		return
+	}
+	w.Header().Set("ETag", `"`+checksum+`"`)
+
+	// ServeContent answers Range and If-Range requests using the ETag
+	http.ServeContent(w, r, checksum, time.Time{}, strings.NewReader(p.Content))
+}
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"html"
+	"html/template"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Pastes larger than this are only shown as text, since the formatted
+// views are built on every page view.
+const maxRenderSize = 1 << 20
+
+// Rows shown in the table view of CSV and TSV pastes.
+const maxRenderRows = 1000
+
+// Nesting shown in the tree view of JSON pastes.
+const maxRenderDepth = 100
+
+// Renderer shows text pastes of a format formatted, next to the text.
+// Render returns HTML, so it must escape everything it takes from the
+// paste.
+type Renderer struct {
+	Name   string
+	Detect func(content string) bool
+	Render func(content string) (template.HTML, error)
+}
+
+// renderers are tried in order, so more specific formats come first.
+var renderers []Renderer
+
+// RegisterRenderer adds a renderer, tried after those registered before.
+func RegisterRenderer(r Renderer) {
+	renderers = append(renderers, r)
+}
+
+func init() {
+	RegisterRenderer(Renderer{Name: "Notebook", Detect: isNotebook, Render: renderNotebook})
+	RegisterRenderer(Renderer{Name: "JSON", Detect: isJSON, Render: renderJSON})
+	RegisterRenderer(Renderer{Name: "CSV", Detect: func(s string) bool { return isTable(s, ',') }, Render: func(s string) (template.HTML, error) { return renderTable(s, ',') }})
+	RegisterRenderer(Renderer{Name: "TSV", Detect: func(s string) bool { return isTable(s, '\t') }, Render: func(s string) (template.HTML, error) { return renderTable(s, '\t') }})
+	RegisterRenderer(Renderer{Name: "Links", Detect: hasURL, Render: renderLinks})
+}
+
+// RenderedPaste is the formatted view of a paste.
+type RenderedPaste struct {
+	Format string
+	HTML   template.HTML
+}
+
+// renderPasteContent returns the formatted view of the paste by the first
+// renderer that recognizes it. Pastes no renderer recognizes, or that fail
+// to render, are only shown as text.
+func renderPasteContent(p Paste) *RenderedPaste {
+	if p.Binary || p.Encrypted || p.Content == "" || len(p.Content) > maxRenderSize {
+		return nil
+	}
+	for _, r := range renderers {
+		if !r.Detect(p.Content) {
+			continue
+		}
+		h, err := r.Render(p.Content)
+		if err != nil {
+			return nil
+		}
+		return &RenderedPaste{Format: r.Name, HTML: h}
+	}
+	return nil
+}
+
+var whitespaceReplacer = strings.NewReplacer(
+	" ", `<span class="cm-space"> </span>`,
+	"\t", "<span class=\"cm-tab\">\t</span>",
+)
+
+// showWhitespace escapes the text, with the spaces and tabs in it marked
+// to be made visible by the style sheet. The text copied from it is the
+// text of the paste.
+func showWhitespace(s string) template.HTML {
+	if len(s) > maxRenderSize {
+		return template.HTML(html.EscapeString(s))
+	}
+	return template.HTML(whitespaceReplacer.Replace(html.EscapeString(s)))
+}
+
+func isJSON(s string) bool {
+	s = strings.TrimSpace(s)
+	return (strings.HasPrefix(s, "{") || strings.HasPrefix(s, "[")) && json.Valid([]byte(s))
+}
+
+// renderJSON shows the JSON as a tree, where objects and arrays can be
+// collapsed. Keys are kept in the order of the paste.
+func renderJSON(s string) (template.HTML, error) {
+	var b strings.Builder
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	b.WriteString(`<div class="json-tree">`)
+	if err := renderJSONValue(dec, &b, 0); err != nil {
+		return "", err
+	}
+	b.WriteString(`</div>`)
+	return template.HTML(b.String()), nil
+}
+
+func renderJSONValue(dec *json.Decoder, b *strings.Builder, depth int) error {
+	if depth > maxRenderDepth {
+		return errors.New("too deeply nested")
+	}
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	switch v := tok.(type) {
+	case json.Delim:
+		end := "]"
+		if v == '{' {
+			end = "}"
+		}
+		b.WriteString(`<details open><summary>` + string(v) + `</summary><ul>`)
+		for dec.More() {
+			b.WriteString(`<li>`)
+			if v == '{' {
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				b.WriteString(`<span class="json-key">` + html.EscapeString(strconv.Quote(key.(string))) + `</span>: `)
+			}
+			if err := renderJSONValue(dec, b, depth+1); err != nil {
+				return err
+			}
+			b.WriteString(`</li>`)
+		}
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+		b.WriteString(`</ul>` + end + `</details>`)
+	case string:
+		b.WriteString(`<span class="json-string">` + html.EscapeString(strconv.Quote(v)) + `</span>`)
+	case json.Number:
+		b.WriteString(`<span class="json-number">` + html.EscapeString(v.String()) + `</span>`)
+	case bool:
+		b.WriteString(`<span class="json-literal">` + strconv.FormatBool(v) + `</span>`)
+	case nil:
+		b.WriteString(`<span class="json-literal">null</span>`)
+	}
+	return nil
+}
+
+// isTable reports whether the content is a table with the separator: at
+// least two rows, all with the same number of columns, and at least two
+// columns.
+func isTable(s string, sep rune) bool {
+	if !strings.ContainsRune(strings.SplitN(s, "\n", 2)[0], sep) {
+		return false
+	}
+	records, err := readTable(s, sep)
+	return err == nil && len(records) >= 2 && len(records[0]) >= 2
+}
+
+func readTable(s string, sep rune) ([][]string, error) {
+	r := csv.NewReader(strings.NewReader(s))
+	r.Comma = sep
+	if sep == '\t' {
+		r.LazyQuotes = true
+	}
+	var records [][]string
+	for len(records) <= maxRenderRows {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// renderTable shows the table with the first row as the header.
+func renderTable(s string, sep rune) (template.HTML, error) {
+	records, err := readTable(s, sep)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	b.WriteString(`<div class="table-responsive"><table class="table table-sm table-striped"><thead><tr>`)
+	for _, field := range records[0] {
+		b.WriteString(`<th>` + html.EscapeString(field) + `</th>`)
+	}
+	b.WriteString(`</tr></thead><tbody>`)
+	rows := records[1:]
+	if len(rows) > maxRenderRows-1 {
+		rows = rows[:maxRenderRows-1]
+	}
+	for _, record := range rows {
+		b.WriteString(`<tr>`)
+		for _, field := range record {
+			b.WriteString(`<td>` + html.EscapeString(field) + `</td>`)
+		}
+		b.WriteString(`</tr>`)
+	}
+	b.WriteString(`</tbody></table></div>`)
+	if len(records) > maxRenderRows {
+		b.WriteString(`<p class="text-muted">Only the first ` + strconv.Itoa(maxRenderRows-1) + ` rows are shown.</p>`)
+	}
+	return template.HTML(b.String()), nil
+}
+
+// notebook is the part of the Jupyter notebook format that is shown.
+type notebook struct {
+	NBFormat int `json:"nbformat"`
+	Cells    []struct {
+		CellType string          `json:"cell_type"`
+		Source   json.RawMessage `json:"source"`
+		Outputs  []struct {
+			OutputType string                     `json:"output_type"`
+			Text       json.RawMessage            `json:"text"`
+			Data       map[string]json.RawMessage `json:"data"`
+			EName      string                     `json:"ename"`
+			EValue     string                     `json:"evalue"`
+		} `json:"outputs"`
+	} `json:"cells"`
+}
+
+func parseNotebook(s string) (notebook, error) {
+	var nb notebook
+	err := json.Unmarshal([]byte(s), &nb)
+	if err == nil && (nb.NBFormat < 4 || nb.Cells == nil) {
+		err = errors.New("not a notebook")
+	}
+	return nb, err
+}
+
+func isNotebook(s string) bool {
+	if !strings.Contains(s, `"nbformat"`) {
+		return false
+	}
+	_, err := parseNotebook(s)
+	return err == nil
+}
+
+// multiline returns the text of a notebook field, which is either a
+// string or an array of lines.
+func multiline(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var lines []string
+	json.Unmarshal(raw, &lines)
+	return strings.Join(lines, "")
+}
+
+// Images in notebook outputs are shown inline.
+var notebookImages = []string{"image/png", "image/jpeg", "image/gif"}
+
+// renderNotebook shows the cells of a Jupyter notebook with their outputs.
+// Markdown cells are shown as text, and HTML outputs are not shown, as
+// they could run scripts.
+func renderNotebook(s string) (template.HTML, error) {
+	nb, err := parseNotebook(s)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	b.WriteString(`<div class="notebook">`)
+	for _, cell := range nb.Cells {
+		source := html.EscapeString(multiline(cell.Source))
+		switch cell.CellType {
+		case "code":
+			b.WriteString(`<pre class="notebook-code"><code>` + source + `</code></pre>`)
+		default:
+			b.WriteString(`<pre class="notebook-text">` + source + `</pre>`)
+		}
+		for _, out := range cell.Outputs {
+			renderNotebookOutput(&b, out.OutputType, out.Text, out.Data, out.EName, out.EValue)
+		}
+	}
+	b.WriteString(`</div>`)
+	return template.HTML(b.String()), nil
+}
+
+func renderNotebookOutput(b *strings.Builder, kind string, text json.RawMessage, data map[string]json.RawMessage, ename, evalue string) {
+	switch kind {
+	case "stream":
+		b.WriteString(`<pre class="notebook-output">` + html.EscapeString(multiline(text)) + `</pre>`)
+		return
+	case "error":
+		b.WriteString(`<pre class="notebook-output notebook-error">` + html.EscapeString(ename+": "+evalue) + `</pre>`)
+		return
+	}
+	for _, mediaType := range notebookImages {
+		raw, ok := data[mediaType]
+		if !ok {
+			continue
+		}
+		encoded := strings.Join(strings.Fields(multiline(raw)), "")
+		if _, err := base64.StdEncoding.DecodeString(encoded); err != nil {
+			continue
+		}
+		b.WriteString(`<img class="img-fluid notebook-output" src="data:` + mediaType + `;base64,` + encoded + `" alt="">`)
+		return
+	}
+	if raw, ok := data["text/plain"]; ok {
+		b.WriteString(`<pre class="notebook-output">` + html.EscapeString(multiline(raw)) + `</pre>`)
+	}
+}
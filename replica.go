@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+)
+
+// replicatedStorage writes to the primary storage and copies objects to
+// the secondary storage with background jobs. Reads fall back to the
+// secondary storage when the primary fails.
+type replicatedStorage struct {
+	primary   Storage
+	secondary Storage
+}
+
+// primaryStorage returns the storage pastes are written to first.
+func primaryStorage() Storage {
+	if s, ok := storage.(*replicatedStorage); ok {
+		return s.primary
+	}
+	return storage
+}
+
+func newReplicatedStorage(primary, secondary Storage) *replicatedStorage {
+	return &replicatedStorage{
+		primary:   primary,
+		secondary: secondary,
+	}
+}
+
+func (s *replicatedStorage) Store(key string, r io.Reader) (int64, error) {
+	n, err := s.primary.Store(key, r)
+	if err != nil {
+		return n, err
+	}
+	if _, err := jobQueue.Enqueue("replicate", key); err != nil {
+		log.Printf("Unable to queue replication of %s: %s\n", key, err)
+	}
+	return n, nil
+}
+
+func (s *replicatedStorage) Retrieve(key string, w io.Writer) (int64, error) {
+	// Buffer the primary read so a failure midway does not leave partial
+	// data in w before falling back
+	var buf bytes.Buffer
+	if _, err := s.primary.Retrieve(key, &buf); err == nil {
+		return io.Copy(w, &buf)
+	}
+	return s.secondary.Retrieve(key, w)
+}
+
+// Delete removes the object from both storages.
+func (s *replicatedStorage) Delete(key string) error {
+	if err := s.primary.Delete(key); err != nil {
+		return err
+	}
+	return s.secondary.Delete(key)
+}
+
+// replicateJob copies an object from the primary to the secondary
+// storage. The queue retries failed copies a few times.
+func replicateJob(ctx context.Context, payload json.RawMessage, progress func(v interface{})) error {
+	var key string
+	if err := json.Unmarshal(payload, &key); err != nil {
+		return err
+	}
+	s, ok := storage.(*replicatedStorage)
+	if !ok {
+		return nil
+	}
+	var buf bytes.Buffer
+	if _, err := s.primary.Retrieve(key, &buf); err != nil {
+		return err
+	}
+	_, err := s.secondary.Store(key, &buf)
+	return err
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/espebra/pastebin/pastebin"
+	"github.com/gorilla/mux"
+)
+
+const reportsKey = "reports"
+
+// Report is an abuse report filed against a paste.
+type Report struct {
+	ID       string    `json:"id"`
+	Checksum string    `json:"checksum"`
+	Reason   string    `json:"reason"`
+	Contact  string    `json:"contact"`
+	Created  time.Time `json:"created"`
+}
+
+// The report queue is stored as a single record, so updates are
+// serialized to avoid losing reports filed at the same time.
+var reportsMu sync.Mutex
+
+// retrieveReports returns the report queue, leaving out reports against
+// pastes sealed with a storage secret that is no longer configured.
+func retrieveReports() ([]Report, error) {
+	var stored []Report
+	err := retrieveJSON(reportsKey, &stored)
+	var reports []Report
+	for _, report := range stored {
+		checksum, err := service.OpenChecksum(report.Checksum)
+		if err != nil {
+			log.Printf("Unable to read report %s: %s\n", report.ID, err)
+			continue
+		}
+		report.Checksum = checksum
+		reports = append(reports, report)
+	}
+	return reports, err
+}
+
+// storeReports stores the report queue with the checksums of the pastes
+// sealed.
+func storeReports(reports []Report) error {
+	sealed := make([]Report, len(reports))
+	for i, report := range reports {
+		var err error
+		if report.Checksum, err = service.SealChecksum(report.Checksum); err != nil {
+			return err
+		}
+		sealed[i] = report
+	}
+	return storeJSON(reportsKey, sealed)
+}
+
+func addReport(report Report) error {
+	reportsMu.Lock()
+	defer reportsMu.Unlock()
+	reports, _ := retrieveReports()
+	return storeReports(append(reports, report))
+}
+
+// removeReports removes every report filed against the paste.
+func removeReports(checksum string) error {
+	reportsMu.Lock()
+	defer reportsMu.Unlock()
+	reports, err := retrieveReports()
+	if err != nil {
+		return err
+	}
+	var kept []Report
+	for _, report := range reports {
+		if report.Checksum != checksum {
+			kept = append(kept, report)
+		}
+	}
+	return storeReports(kept)
+}
+
+func reportPaste(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	checksum := vars["checksum"]
+
+	var p Paste
+	if !pastebin.IsChecksum(checksum) || !service.Authorize(checksum, r.FormValue("key")) {
+		w.WriteHeader(http.StatusNotFound)
+		p.Message = "Paste " + checksum + " does not exist."
+		p.Status = "error"
+		renderPaste(w, r, p)
+		return
+	}
+
+	report := Report{
+		Checksum: checksum,
+		Reason:   r.FormValue("reason"),
+		Contact:  r.FormValue("contact"),
+		Created:  time.Now().UTC(),
+	}
+	if report.Reason == "" {
+		p.Message = "Please give a reason for the report."
+		p.Status = "error"
+		renderPaste(w, r, p)
+		return
+	}
+
+	var err error
+	report.ID, err = pastebin.NewToken()
+	if err == nil {
+		err = addReport(report)
+	}
+	if err != nil {
+		log.Printf("Unable to store report: %s\n", err)
+		p.Message = "Unable to store the report, please try again later."
+		p.Status = "error"
+	} else {
+		p.Message = "Thank you, the paste has been reported."
+		p.Status = "success"
+	}
+	renderPaste(w, r, p)
+}
+
+// AdminReports is the data shown on the report queue page.
+type AdminReports struct {
+	Reports []Report
+	Message string
+	Status  string
+}
+
+func adminReports(w http.ResponseWriter, r *http.Request) {
+	var data AdminReports
+	reportsMu.Lock()
+	data.Reports, _ = retrieveReports()
+	reportsMu.Unlock()
+	renderTemplate(w, r, "templates/reports.html", "reports", data)
+}
+
+// adminResolveReport blocks the reported paste, moves it to the trash or
+// dismisses its reports. Blocked pastes are kept, but never served again.
+func adminResolveReport(w http.ResponseWriter, r *http.Request) {
+	checksum := r.FormValue("checksum")
+
+	var data AdminReports
+	var err error
+	switch r.FormValue("action") {
+	case "block":
+		if err = blocked.Add(checksum); err == nil {
+			err = removeReports(checksum)
+		}
+		data.Message = "Blocked " + checksum
+	case "delete":
+		if err = trashPaste(r, checksum); err == nil {
+			err = removeReports(checksum)
+		}
+		data.Message = "Deleted " + checksum
+	case "dismiss":
+		err = removeReports(checksum)
+		data.Message = "Dismissed reports of " + checksum
+	default:
+		http.Error(w, "Unknown action", http.StatusBadRequest)
+		return
+	}
+
+	if err != nil {
+		log.Printf("Unable to resolve reports of %s: %s\n", checksum, err)
+		data.Message = "Unable to resolve reports of " + checksum + ": " + err.Error()
+		data.Status = "error"
+	} else {
+		data.Status = "success"
+		// Deleting is audited as pastes are moved to the trash
+		switch r.FormValue("action") {
+		case "block":
+			audit(r, "block", checksum, "")
+		case "dismiss":
+			audit(r, "dismiss-reports", checksum, "")
+		}
+	}
+
+	reportsMu.Lock()
+	data.Reports, _ = retrieveReports()
+	reportsMu.Unlock()
+	renderTemplate(w, r, "templates/reports.html", "reports", data)
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// errStorageUnavailable is returned without calling the storage while the
+// circuit breaker is open.
+var errStorageUnavailable = errors.New("storage is unavailable")
+
+// resilientStorage retries failed storage calls with exponential backoff
+// and jitter, and stops calling the storage for a while after a number of
+// consecutive failures, so requests fail fast during an outage instead of
+// each waiting for the storage to time out.
+type resilientStorage struct {
+	name    string
+	storage Storage
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+func newResilientStorage(name string, s Storage) *resilientStorage {
+	return &resilientStorage{name: name, storage: s}
+}
+
+// isNotFound reports whether err means that the key does not exist, which
+// is an answer rather than a failure of the storage.
+func isNotFound(err error) bool {
+	return os.IsNotExist(err)
+}
+
+func (s *resilientStorage) allow() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return time.Now().After(s.openUntil)
+}
+
+func (s *resilientStorage) record(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err == nil || isNotFound(err) {
+		s.failures = 0
+		return
+	}
+	s.failures++
+	if *storageBreakerThresholdFlag > 0 && s.failures >= *storageBreakerThresholdFlag {
+		if time.Now().After(s.openUntil) {
+			log.Printf("Storage %s failed %d times in a row, pausing calls for %s\n", s.name, s.failures, *storageBreakerCooldownFlag)
+		}
+		s.openUntil = time.Now().Add(*storageBreakerCooldownFlag)
+	}
+}
+
+// backoff returns the delay before the given retry, doubling with every
+// attempt, with up to half of it added as jitter.
+func backoff(retry int) time.Duration {
+	d := *storageBackoffFlag << uint(retry)
+	if d <= 0 {
+		return 0
+	}
+	return d + time.Duration(rand.Int63n(int64(d)/2+1))
+}
+
+// call runs fn until it succeeds, the key is not found or the retries are
+// used up.
+func (s *resilientStorage) call(fn func() error) error {
+	var err error
+	for retry := 0; retry <= *storageRetriesFlag; retry++ {
+		if !s.allow() {
+			return errStorageUnavailable
+		}
+		if retry > 0 {
+			time.Sleep(backoff(retry - 1))
+		}
+		err = fn()
+		s.record(err)
+		if err == nil || isNotFound(err) {
+			return err
+		}
+	}
+	return err
+}
+
+func (s *resilientStorage) Store(key string, r io.Reader) (int64, error) {
+	// Read the object once so it can be written again on retries
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return 0, err
+	}
+	var n int64
+	err = s.call(func() error {
+		var err error
+		n, err = s.storage.Store(key, bytes.NewReader(data))
+		return err
+	})
+	return n, err
+}
+
+func (s *resilientStorage) Retrieve(key string, w io.Writer) (int64, error) {
+	// Buffer the object so a failed attempt leaves nothing in w
+	var buf bytes.Buffer
+	err := s.call(func() error {
+		buf.Reset()
+		_, err := s.storage.Retrieve(key, &buf)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return io.Copy(w, &buf)
+}
+
+func (s *resilientStorage) Delete(key string) error {
+	return s.call(func() error {
+		return s.storage.Delete(key)
+	})
+}
+
+// storageUnavailable responds with 503 Service Unavailable if err is caused
+// by the storage being unavailable, and reports whether it did.
+func storageUnavailable(w http.ResponseWriter, err error) bool {
+	if err != errStorageUnavailable {
+		return false
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(int(storageBreakerCooldownFlag.Seconds())))
+	http.Error(w, "The storage is unavailable, try again later", http.StatusServiceUnavailable)
+	return true
+}
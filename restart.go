@@ -0,0 +1,136 @@
+package main
+
+import (
+	"log"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// A restart with SIGUSR2 starts the binary again with the sockets of this
+// process passed along, named like those of systemd socket activation.
+// Both serve requests until the new process is ready, and then stops this
+// one with SIGTERM, which completes the requests in flight as in any other
+// shutdown. No connections are refused meanwhile. The variables passing
+// the sockets do not start with PASTEBIN_, which are options.
+const (
+	restartFdsEnv    = "RESTART_LISTEN_FDS"
+	restartNamesEnv  = "RESTART_LISTEN_FDNAMES"
+	restartParentEnv = "RESTART_PARENT_PID"
+)
+
+// The process that handed its sockets to this one, to stop once this one
+// is ready.
+var restartParent int
+
+// restartListeners returns the sockets passed by the process restarted
+// with SIGUSR2, or none when this process was not started by a restart.
+func restartListeners() ([]namedListener, error) {
+	defer os.Unsetenv(restartFdsEnv)
+	defer os.Unsetenv(restartNamesEnv)
+	defer os.Unsetenv(restartParentEnv)
+
+	n, err := strconv.Atoi(os.Getenv(restartFdsEnv))
+	if err != nil {
+		return nil, nil
+	}
+	if pid, err := strconv.Atoi(os.Getenv(restartParentEnv)); err == nil && pid == os.Getppid() {
+		restartParent = pid
+	}
+	return fileListeners(n, os.Getenv(restartNamesEnv))
+}
+
+// inheritedListeners returns the sockets passed by a restart or by
+// systemd socket activation.
+func inheritedListeners() ([]namedListener, error) {
+	listeners, err := restartListeners()
+	if err != nil || len(listeners) > 0 {
+		return listeners, err
+	}
+	return systemdListeners()
+}
+
+// restartDone stops the process this one was restarted from, now that
+// this one serves requests.
+func restartDone() {
+	if restartParent == 0 {
+		return
+	}
+	sdNotify("MAINPID=" + strconv.Itoa(os.Getpid()))
+	if err := syscall.Kill(restartParent, syscall.SIGTERM); err != nil {
+		log.Printf("Unable to stop the process %d restarted from: %s\n", restartParent, err)
+	}
+}
+
+// restartOnSignal starts the binary again on SIGUSR2, with the sockets
+// passed along. The binary is looked up again, so that a new build
+// installed in its place is started.
+func restartOnSignal(listeners []namedListener) {
+	usr2 := make(chan os.Signal, 1)
+	signal.Notify(usr2, syscall.SIGUSR2)
+	for range usr2 {
+		p, err := restart(listeners)
+		if err != nil {
+			log.Printf("Unable to restart: %s\n", err)
+			continue
+		}
+		log.Printf("Restarting as process %d, which stops this one when it is ready\n", p.Pid)
+
+		// Stopping this process must not remove Unix sockets the new one
+		// serves, unless it fails to start
+		setUnlinkOnClose(listeners, false)
+		go func() {
+			p.Wait()
+			log.Printf("The restarted process %d exited\n", p.Pid)
+			setUnlinkOnClose(listeners, true)
+		}()
+	}
+}
+
+func setUnlinkOnClose(listeners []namedListener, unlink bool) {
+	for _, l := range listeners {
+		if ul, ok := l.Listener.(*net.UnixListener); ok {
+			ul.SetUnlinkOnClose(unlink)
+		}
+	}
+}
+
+// restart starts the binary with the same arguments and the sockets.
+func restart(listeners []namedListener) (*os.Process, error) {
+	path, err := exec.LookPath(os.Args[0])
+	if err != nil {
+		return nil, err
+	}
+	files := []*os.File{os.Stdin, os.Stdout, os.Stderr}
+	var names []string
+	for _, l := range listeners {
+		fl, ok := l.Listener.(interface{ File() (*os.File, error) })
+		if !ok {
+			continue
+		}
+		f, err := fl.File()
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		files = append(files, f)
+		names = append(names, l.Name)
+	}
+
+	var env []string
+	for _, v := range os.Environ() {
+		if !strings.HasPrefix(v, "RESTART_LISTEN_") && !strings.HasPrefix(v, restartParentEnv+"=") {
+			env = append(env, v)
+		}
+	}
+	env = append(env,
+		restartFdsEnv+"="+strconv.Itoa(len(names)),
+		restartNamesEnv+"="+strings.Join(names, ":"),
+		restartParentEnv+"="+strconv.Itoa(os.Getpid()),
+	)
+	return os.StartProcess(path, os.Args, &os.ProcAttr{Env: env, Files: files})
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Roles, each allowed everything the ones before it are.
+const (
+	roleAnonymous = iota
+	roleUser
+	roleModerator
+	roleAdmin
+)
+
+var roleNames = []string{"anonymous", "user", "moderator", "admin"}
+
+// parseRole returns the role with the given name.
+func parseRole(name string) (int, error) {
+	for role, n := range roleNames {
+		if n == name {
+			return role, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown role %s", name)
+}
+
+// The role required for each permission, unless changed with
+// -permissions.
+var defaultPermissions = map[string]int{
+	// Creating pastes, streams and sets
+	"create": roleAnonymous,
+	// The admin pages
+	"admin": roleModerator,
+	// Reports, the trash, pins, the blocklist and deleting any paste
+	"moderate": roleModerator,
+	// Analytics and access logs
+	"stats": roleAdmin,
+	// Background jobs and cleanup
+	"jobs": roleAdmin,
+	// The effective configuration
+	"config": roleAdmin,
+	// The audit log of moderator and admin actions
+	"audit": roleAdmin,
+	// Profiles and runtime statistics under /debug/
+	"debug": roleAdmin,
+}
+
+// User is an account given with -users.
+type User struct {
+	Name     string
+	Password string
+	Role     int
+}
+
+// parseUsers reads -users, given as user:password:role separated by
+// commas.
+func parseUsers(s string) ([]User, error) {
+	var users []User
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.Split(item, ":")
+		if len(parts) != 3 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("user %q is not given as user:password:role", parts[0])
+		}
+		role, err := parseRole(parts[2])
+		if err != nil {
+			return nil, fmt.Errorf("user %s: %s", parts[0], err)
+		}
+		users = append(users, User{Name: parts[0], Password: parts[1], Role: role})
+	}
+	return users, nil
+}
+
+// parsePermissions returns the role required for each permission, with
+// the ones in -permissions, given as permission=role separated by commas,
+// replacing the defaults.
+func parsePermissions(s string) (map[string]int, error) {
+	permissions := map[string]int{}
+	for name, role := range defaultPermissions {
+		permissions[name] = role
+	}
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, roleName, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("permission %q is not given as permission=role", item)
+		}
+		name = strings.TrimSpace(name)
+		if _, ok := defaultPermissions[name]; !ok {
+			return nil, fmt.Errorf("unknown permission %s", name)
+		}
+		role, err := parseRole(strings.TrimSpace(roleName))
+		if err != nil {
+			return nil, fmt.Errorf("permission %s: %s", name, err)
+		}
+		permissions[name] = role
+	}
+	return permissions, nil
+}
+
+// accounts returns the users that can sign in: the admin, when
+// -admin-password is set, and those in -users.
+func accounts() []User {
+	users, _ := parseUsers(*usersFlag)
+	if *adminPasswordFlag != "" {
+		users = append(users, User{Name: *adminUserFlag, Password: *adminPasswordFlag, Role: roleAdmin})
+	}
+	return users
+}
+
+// requestRole returns the role of the user signed in with HTTP basic
+// authentication, and whether the credentials were valid. Requests
+// without credentials are anonymous.
+func requestRole(r *http.Request) (int, bool) {
+	name, password, ok := r.BasicAuth()
+	if !ok {
+		return roleAnonymous, true
+	}
+	role, valid := roleAnonymous, false
+	// Every account is compared, so the time taken does not tell which
+	// of them exist
+	for _, u := range accounts() {
+		if subtle.ConstantTimeCompare([]byte(name), []byte(u.Name)) == 1 &&
+			subtle.ConstantTimeCompare([]byte(password), []byte(u.Password)) == 1 {
+			role, valid = u.Role, true
+		}
+	}
+	return role, valid
+}
+
+// allowed reports whether the user of the request has the permission.
+func allowed(r *http.Request, permission string) bool {
+	permissions, _ := parsePermissions(*permissionsFlag)
+	role, ok := requestRole(r)
+	return ok && role >= permissions[permission]
+}
+
+// requirePermission wraps h with a check that the user signed in with
+// HTTP basic authentication has the permission. Pages that require
+// signing in are disabled when there are no accounts.
+func requirePermission(permission string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		permissions, _ := parsePermissions(*permissionsFlag)
+		required := permissions[permission]
+		if required == roleAnonymous {
+			h(w, r)
+			return
+		}
+		if len(accounts()) == 0 {
+			http.NotFound(w, r)
+			return
+		}
+		// Browsers send basic auth credentials along with cross-site
+		// form posts, so changes must come from the pages of the site
+		if r.Method != "GET" {
+			if origin, err := url.Parse(r.Header.Get("Origin")); err == nil && origin.Host != "" && origin.Host != r.Host {
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+		}
+		role, ok := requestRole(r)
+		if !ok || role == roleAnonymous {
+			w.Header().Set("WWW-Authenticate", `Basic realm="pastebin"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if role < required {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		h(w, r)
+	}
+}
@@ -0,0 +1,372 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/espebra/pastebin/pastebin"
+	"github.com/gorilla/mux"
+)
+
+// Maximum number of files in a set.
+const maxSetFiles = 50
+
+// PasteSet is a paste of several named files, like a small project. Each
+// file is stored as a paste of its own, and the set records their names.
+// Sets are found by a random ID, so that their links can not be guessed.
+type PasteSet struct {
+	ID      string    `json:"id"`
+	Files   []SetFile `json:"files"`
+	Created time.Time `json:"created"`
+}
+
+// SetFile is a file in a set.
+type SetFile struct {
+	Name     string `json:"name"`
+	Checksum string `json:"checksum"`
+	Key      string `json:"key,omitempty"`
+}
+
+// Location returns the path of the paste of the file.
+func (f SetFile) Location() string {
+	return Paste{Checksum: f.Checksum, Key: f.Key}.Location()
+}
+
+// SetRequest is the body of a request creating a set.
+type SetRequest struct {
+	Files []struct {
+		Name    string `json:"name"`
+		Content string `json:"content"`
+	} `json:"files"`
+	Visibility string `json:"visibility"`
+}
+
+// SetResult is the response to a request creating a set.
+type SetResult struct {
+	ID      string          `json:"id,omitempty"`
+	URL     string          `json:"url,omitempty"`
+	Archive string          `json:"archive,omitempty"`
+	Files   []SetFileResult `json:"files,omitempty"`
+	Status  string          `json:"status"`
+	Message string          `json:"message,omitempty"`
+}
+
+// SetFileResult is a file of a created set.
+type SetFileResult struct {
+	Name        string `json:"name"`
+	Checksum    string `json:"checksum"`
+	URL         string `json:"url"`
+	Raw         string `json:"raw"`
+	DeleteToken string `json:"delete_token,omitempty"`
+}
+
+// Set IDs are tokens from newToken.
+var setIDPattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+func setKey(id string) string {
+	return "set-" + id
+}
+
+func storeSet(s PasteSet) error {
+	return storeJSON(setKey(s.ID), s)
+}
+
+func retrieveSet(id string) (PasteSet, error) {
+	var s PasteSet
+	if !setIDPattern.MatchString(id) {
+		return s, fmt.Errorf("invalid set %s", id)
+	}
+	err := retrieveJSON(setKey(id), &s)
+	return s, err
+}
+
+// validSetName reports whether name can be used as a file name in a set,
+// and in the archives of the set.
+func validSetName(name string) bool {
+	if name == "" || len(name) > 255 || name == "." || name == ".." {
+		return false
+	}
+	return !strings.ContainsAny(name, "/\\\x00")
+}
+
+// readSetRequest returns the files of a request creating a set, given as
+// JSON or as files in a multipart form, like curl -F file=@main.go sends.
+func readSetRequest(w http.ResponseWriter, r *http.Request) (SetRequest, error) {
+	var req SetRequest
+	r.Body = http.MaxBytesReader(w, r.Body, requestBodyLimit())
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		err := json.NewDecoder(r.Body).Decode(&req)
+		return req, err
+	}
+
+	if err := r.ParseMultipartForm(requestBodyLimit()); err != nil {
+		return req, err
+	}
+	req.Visibility = r.FormValue("visibility")
+	for _, fh := range r.MultipartForm.File["file"] {
+		f, err := fh.Open()
+		if err != nil {
+			return req, err
+		}
+		data, err := ioutil.ReadAll(f)
+		f.Close()
+		if err != nil {
+			return req, err
+		}
+		req.Files = append(req.Files, struct {
+			Name    string `json:"name"`
+			Content string `json:"content"`
+		}{fh.Filename, string(data)})
+	}
+	return req, nil
+}
+
+// checkSetRequest returns why the files can not be made a set, if they
+// can not.
+func checkSetRequest(req SetRequest) error {
+	if len(req.Files) == 0 {
+		return errors.New("no files given")
+	}
+	if len(req.Files) > maxSetFiles {
+		return fmt.Errorf("at most %d files can be in a set", maxSetFiles)
+	}
+	names := map[string]bool{}
+	var size int64
+	for _, f := range req.Files {
+		if !validSetName(f.Name) {
+			return fmt.Errorf("invalid file name %q", f.Name)
+		}
+		if names[f.Name] {
+			return fmt.Errorf("file name %q is given more than once", f.Name)
+		}
+		names[f.Name] = true
+		if f.Content == "" {
+			return fmt.Errorf("file %q is empty", f.Name)
+		}
+		size += int64(len(f.Content))
+	}
+	if size > maxPasteSizeFlag.Get() {
+		return errPasteTooLarge
+	}
+	if req.Visibility != "" && !pastebin.ValidVisibility(req.Visibility) {
+		return errors.New("invalid visibility " + req.Visibility)
+	}
+	if req.Visibility == "private" && *secretFlag == "" {
+		return errors.New("private pastes are not enabled")
+	}
+	return nil
+}
+
+// apiCreateSet creates a set from the files in the request. Each file is
+// created as a paste, with its own delete token.
+func apiCreateSet(w http.ResponseWriter, r *http.Request) {
+	if err := checkPow(r); err != nil {
+		writeJSON(w, http.StatusForbidden, SetResult{Status: "error", Message: err.Error()})
+		return
+	}
+	if err := checkCaptcha(r, true); err != nil {
+		writeJSON(w, http.StatusForbidden, SetResult{Status: "error", Message: err.Error()})
+		return
+	}
+	req, err := readSetRequest(w, r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, SetResult{Status: "error", Message: "Invalid set: " + err.Error()})
+		return
+	}
+	if err := checkSetRequest(req); err != nil {
+		writeJSON(w, http.StatusBadRequest, SetResult{Status: "error", Message: err.Error()})
+		return
+	}
+	visibility := req.Visibility
+	if visibility == "" {
+		visibility = *defaultVisibilityFlag
+	}
+
+	s := PasteSet{Created: time.Now().UTC()}
+	if s.ID, err = pastebin.NewToken(); err != nil {
+		writeJSON(w, http.StatusInternalServerError, SetResult{Status: "error", Message: "Unable to create the set"})
+		return
+	}
+	result := SetResult{
+		ID:      s.ID,
+		URL:     requestURL(r, "/set/"+s.ID),
+		Archive: requestURL(r, "/set/"+s.ID+"/archive"),
+		Status:  "success",
+	}
+	for _, f := range req.Files {
+		var p Paste
+		p.Content = f.Content
+		p.Checksum = p.GetName()
+		_, token, err := createPaste(&p, visibility, nil)
+		if err != nil {
+			log.Printf("Unable to write data: %s\n", err)
+			if err == errBlocked || err == errSpam {
+				writeJSON(w, http.StatusForbidden, SetResult{Status: "error", Message: "File " + f.Name + " is not allowed."})
+				return
+			}
+			if storageUnavailable(w, err) {
+				return
+			}
+			if err == errStorageFull {
+				writeJSON(w, http.StatusInsufficientStorage, SetResult{Status: "error", Message: "The storage is full, no pastes can be created."})
+				return
+			}
+			writeJSON(w, http.StatusInternalServerError, SetResult{Status: "error", Message: "Unable to save " + f.Name})
+			return
+		}
+		file := SetFile{Name: f.Name, Checksum: p.Checksum, Key: p.Key}
+		s.Files = append(s.Files, file)
+		result.Files = append(result.Files, SetFileResult{
+			Name:        f.Name,
+			Checksum:    p.Checksum,
+			URL:         requestURL(r, "/set/"+s.ID+"?file="+url.QueryEscape(f.Name)),
+			Raw:         requestURL(r, "/set/"+s.ID+"/raw/"+url.PathEscape(f.Name)),
+			DeleteToken: token,
+		})
+	}
+
+	if err := storeSet(s); err != nil {
+		log.Printf("Unable to store set %s: %s\n", s.ID, err)
+		if storageUnavailable(w, err) {
+			return
+		}
+		writeJSON(w, http.StatusInternalServerError, SetResult{Status: "error", Message: "Unable to create the set"})
+		return
+	}
+	writeJSON(w, http.StatusCreated, result)
+}
+
+// apiSet returns the files of a set.
+func apiSet(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	s, err := retrieveSet(id)
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, SetResult{Status: "error", Message: "Set " + id + " does not exist."})
+		return
+	}
+	writeJSON(w, http.StatusOK, s)
+}
+
+// file returns the file of the set with the name, or the first file when
+// name is empty.
+func (s PasteSet) file(name string) (SetFile, bool) {
+	for _, f := range s.Files {
+		if f.Name == name || name == "" {
+			return f, true
+		}
+	}
+	return SetFile{}, false
+}
+
+// SetPage is the data shown on the page of a set, with the selected file
+// as the current tab.
+type SetPage struct {
+	ID      string
+	Files   []SetFile
+	Current string
+	Paste   Paste
+	Message string
+	Status  string
+}
+
+// readSet shows the files of a set as tabs. The tabs are links, so that
+// the page works without JavaScript.
+func readSet(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	s, err := retrieveSet(id)
+	if err != nil {
+		log.Println(err)
+		w.WriteHeader(http.StatusNotFound)
+		renderTemplate(w, r, "templates/set.html", "set", SetPage{ID: id, Message: "Set " + id + " does not exist.", Status: "error"})
+		return
+	}
+
+	data := SetPage{ID: s.ID, Files: s.Files}
+	f, ok := s.file(r.FormValue("file"))
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		data.Message = "File " + r.FormValue("file") + " is not in this set."
+		data.Status = "error"
+		renderTemplate(w, r, "templates/set.html", "set", data)
+		return
+	}
+	data.Current = f.Name
+
+	data.Paste, err = retrievePaste(f.Checksum, f.Key)
+	if err != nil {
+		log.Println(err)
+		data.Message = "File " + f.Name + " does not exist."
+		data.Status = "error"
+		if message, ok := goneMessage(f.Checksum, err); ok {
+			data.Message = message
+		}
+	} else {
+		views.Add(service.Scope(f.Checksum, f.Key))
+		accesses.Add(f.Checksum, r)
+	}
+	renderTemplate(w, r, "templates/set.html", "set", data)
+}
+
+// rawSetFile redirects to the raw URL of a file in a set.
+func rawSetFile(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	s, err := retrieveSet(vars["id"])
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	f, ok := s.file(vars["name"])
+	if !ok || vars["name"] == "" {
+		http.NotFound(w, r)
+		return
+	}
+	http.Redirect(w, r, basePath()+"/raw"+f.Location(), http.StatusFound)
+}
+
+// downloadSet returns the files of a set as a zip or tar.gz archive, with
+// their names. Files that can no longer be read are left out.
+func downloadSet(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	s, err := retrieveSet(id)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "tar.gz" {
+		format = "zip"
+	}
+	contentType := "application/zip"
+	if format == "tar.gz" {
+		contentType = "application/gzip"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", "attachment; filename=\"set-"+s.ID+"."+format+"\"")
+
+	a := newArchive(w, format)
+	for _, f := range s.Files {
+		p, err := retrievePaste(f.Checksum, f.Key)
+		if err != nil {
+			log.Printf("Leaving %s out of the archive of set %s: %s\n", f.Name, s.ID, err)
+			continue
+		}
+		if err := a.add(f.Name, []byte(p.Content)); err != nil {
+			log.Printf("Unable to write archive: %s\n", err)
+			return
+		}
+	}
+	if err := a.Close(); err != nil {
+		log.Printf("Unable to write archive: %s\n", err)
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Color schemes of the page. Auto follows the preference of the browser.
+var colorThemes = []string{"auto", "light", "dark"}
+
+// Syntax highlighting themes, named after the CodeMirror themes in
+// static/codemirror/theme.
+var highlightThemes = []string{
+	"default", "3024-day", "3024-night", "base16-dark", "base16-light",
+	"dracula", "eclipse", "material", "monokai", "solarized",
+	"tomorrow-night-eighties", "zenburn",
+}
+
+// Font sizes of the paste viewer.
+var fontSizes = []string{"small", "normal", "large"}
+
+// How long the settings cookies are kept by the browser.
+const settingsMaxAge = 365 * 24 * time.Hour
+
+// Settings are the display preferences of a visitor, kept in cookies so
+// that they apply when rendering pages on the server.
+type Settings struct {
+	Theme     string
+	Highlight string
+	Lang      string
+
+	// Viewer toggles, set with query parameters of the paste page.
+	Wrap       bool
+	Whitespace bool
+	FontSize   string
+
+	// Path of the current page, relative to the base path.
+	Path string
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// viewerSetting returns the value of a viewer toggle, from the query
+// parameter when it is given and otherwise from its cookie.
+func viewerSetting(r *http.Request, name string) string {
+	if v := r.URL.Query().Get(name); v != "" {
+		return v
+	}
+	if c, err := r.Cookie(name); err == nil {
+		return c.Value
+	}
+	return ""
+}
+
+// readSettings returns the settings from the request cookies, falling back
+// to the defaults for missing or unknown values.
+func readSettings(r *http.Request) Settings {
+	s := Settings{Theme: "auto", Highlight: "default", Lang: requestLocale(r), Path: r.URL.Path}
+	if c, err := r.Cookie("theme"); err == nil && contains(colorThemes, c.Value) {
+		s.Theme = c.Value
+	}
+	if c, err := r.Cookie("highlight"); err == nil && contains(highlightThemes, c.Value) {
+		s.Highlight = c.Value
+	}
+	s.Wrap = viewerSetting(r, "wrap") != "0"
+	s.Whitespace = viewerSetting(r, "whitespace") == "1"
+	s.FontSize = "normal"
+	if size := viewerSetting(r, "font-size"); contains(fontSizes, size) {
+		s.FontSize = size
+	}
+	return s
+}
+
+// saveViewerSettings keeps the viewer toggles given as query parameters in
+// cookies, so that they apply to the pastes viewed after.
+func saveViewerSettings(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	for _, name := range []string{"wrap", "whitespace"} {
+		if v := q.Get(name); v == "0" || v == "1" {
+			setSettingsCookie(w, name, v)
+		}
+	}
+	if size := q.Get("font-size"); contains(fontSizes, size) {
+		setSettingsCookie(w, "font-size", size)
+	}
+}
+
+func setSettingsCookie(w http.ResponseWriter, name, value string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     basePath() + "/",
+		MaxAge:   int(settingsMaxAge.Seconds()),
+		HttpOnly: !*devFlag,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// saveSettings stores the submitted settings in cookies and sends the
+// visitor back to the page the settings were changed from.
+func saveSettings(w http.ResponseWriter, r *http.Request) {
+	if theme := r.FormValue("theme"); contains(colorThemes, theme) {
+		setSettingsCookie(w, "theme", theme)
+	}
+	if highlight := r.FormValue("highlight"); contains(highlightThemes, highlight) {
+		setSettingsCookie(w, "highlight", highlight)
+	}
+	if lang := r.FormValue("lang"); contains(locales, lang) {
+		setSettingsCookie(w, "lang", lang)
+	}
+
+	// Only redirect to local paths, to not be usable as an open redirect.
+	next := r.FormValue("return")
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		next = "/"
+	}
+	http.Redirect(w, r, basePath()+next, http.StatusSeeOther)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+	"strings"
+)
+
+// shardedStorage spreads objects over several storage backends using
+// rendezvous hashing of the key, so adding a shard only moves the objects
+// that the new shard wins.
+type shardedStorage struct {
+	names  []string
+	shards []Storage
+}
+
+// dataDirs returns the directories given with -directory.
+func dataDirs() []string {
+	var dirs []string
+	for _, dir := range strings.Split(*dataDirFlag, ",") {
+		if dir = strings.TrimSpace(dir); dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// owner returns the index of the shard the key belongs to.
+func (s *shardedStorage) owner(key string) int {
+	best := 0
+	var bestScore uint64
+	for i, name := range s.names {
+		sum := sha256.Sum256([]byte(name + "/" + key))
+		score := binary.BigEndian.Uint64(sum[:8])
+		if i == 0 || score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	return best
+}
+
+func (s *shardedStorage) Store(key string, r io.Reader) (int64, error) {
+	return s.shards[s.owner(key)].Store(key, r)
+}
+
+// Retrieve reads the object from the shard it belongs to, and falls back
+// to the other shards for objects stored before the shards changed.
+func (s *shardedStorage) Retrieve(key string, w io.Writer) (int64, error) {
+	owner := s.owner(key)
+	n, err := s.shards[owner].Retrieve(key, w)
+	if err == nil {
+		return n, nil
+	}
+	for i, shard := range s.shards {
+		if i == owner {
+			continue
+		}
+		if n, err := shard.Retrieve(key, w); err == nil {
+			return n, nil
+		}
+	}
+	return n, err
+}
+
+// Delete removes the object from every shard, as objects stored before the
+// shards changed may be on others than the one they belong to.
+func (s *shardedStorage) Delete(key string) error {
+	for _, shard := range s.shards {
+		if err := shard.Delete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
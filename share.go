@@ -0,0 +1,242 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/espebra/pastebin/pastebin"
+	"github.com/gorilla/mux"
+)
+
+// Share is a secondary link to a paste that can expire and be limited to
+// a number of views, so the checksum URL does not have to be handed out.
+// Only pastes that require a key are hidden behind the link, as the URL of
+// other pastes is their checksum, which anyone with the content has.
+type Share struct {
+	Token    string    `json:"token"`
+	Checksum string    `json:"checksum"`
+	Expires  time.Time `json:"expires"`
+	Views    int       `json:"views"`
+	MaxViews int       `json:"max_views"`
+}
+
+// Shares that expire or have a view limit are listed in a single record,
+// so that they can be purged once they have expired.
+const sharesExpiringKey = "shares-expiring"
+
+func shareKey(token string) string {
+	return "share-" + token
+}
+
+// The views of shares and the list of those that expire are read, modified
+// and written, so they are serialized.
+var sharesMu sync.Mutex
+
+func (s Share) Expired() bool {
+	if !s.Expires.IsZero() && time.Now().After(s.Expires) {
+		return true
+	}
+	if s.MaxViews > 0 && s.Views >= s.MaxViews {
+		return true
+	}
+	return false
+}
+
+// storeShare stores the share with the checksum of its paste sealed.
+func storeShare(s Share) error {
+	var err error
+	if s.Checksum, err = service.SealChecksum(s.Checksum); err != nil {
+		return err
+	}
+	return storeJSON(shareKey(s.Token), s)
+}
+
+func retrieveShare(token string) (Share, error) {
+	var s Share
+	if err := retrieveJSON(shareKey(token), &s); err != nil {
+		return s, err
+	}
+	var err error
+	s.Checksum, err = service.OpenChecksum(s.Checksum)
+	return s, err
+}
+
+// addShare stores a new share, and lists it among those to purge once it
+// has expired when it can expire.
+func addShare(s Share) error {
+	sharesMu.Lock()
+	defer sharesMu.Unlock()
+	if err := storeShare(s); err != nil {
+		return err
+	}
+	if s.Expires.IsZero() && s.MaxViews == 0 {
+		return nil
+	}
+	var tokens []string
+	if err := retrieveJSON(sharesExpiringKey, &tokens); err != nil && !isNotFound(err) {
+		return err
+	}
+	return storeJSON(sharesExpiringKey, append(tokens, s.Token))
+}
+
+// useShare returns the share and counts a view of it, when it has not
+// expired. Views are counted before the paste is read, so that concurrent
+// views can not go past the limit.
+func useShare(token string) (Share, error) {
+	sharesMu.Lock()
+	defer sharesMu.Unlock()
+	s, err := retrieveShare(token)
+	if err != nil {
+		return s, err
+	}
+	if s.Expired() {
+		return s, fmt.Errorf("share link %s has expired", token)
+	}
+	if s.MaxViews > 0 {
+		s.Views++
+		if err := storeShare(s); err != nil {
+			return s, fmt.Errorf("unable to count a view of share link %s: %s", token, err)
+		}
+	}
+	return s, nil
+}
+
+// purgeShares removes the shares that have expired, by time or by views.
+func purgeShares(ctx context.Context) (int, error) {
+	sharesMu.Lock()
+	defer sharesMu.Unlock()
+	var tokens []string
+	err := retrieveJSON(sharesExpiringKey, &tokens)
+	if isNotFound(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var keys []string
+	var kept []string
+	for _, token := range tokens {
+		s, err := retrieveShare(token)
+		switch {
+		case isNotFound(err):
+		case err != nil:
+			return 0, err
+		case s.Expired():
+			keys = append(keys, shareKey(token))
+		default:
+			kept = append(kept, token)
+		}
+	}
+	if len(kept) == len(tokens) {
+		return 0, nil
+	}
+	n, err := removeKeys(ctx, keys, nil)
+	if err != nil {
+		return n, err
+	}
+	return n, storeJSON(sharesExpiringKey, kept)
+}
+
+func createShare(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	checksum := vars["checksum"]
+
+	if !sameOrigin(r) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	var p Paste
+	var err error
+	if service.Authorize(checksum, r.FormValue("key")) {
+		p, err = retrievePaste(checksum, r.FormValue("key"))
+	} else {
+		err = fmt.Errorf("invalid key for %s", checksum)
+	}
+	if err != nil {
+		log.Println(err)
+		p.Message = "Paste " + checksum + " does not exist."
+		p.Status = "error"
+		renderPaste(w, r, p)
+		return
+	}
+
+	s := Share{Checksum: checksum}
+	if v := r.FormValue("ttl"); v != "" {
+		ttl, err := time.ParseDuration(v)
+		if err != nil || ttl <= 0 {
+			p.Message = "Invalid expiry " + v
+			p.Status = "error"
+			renderPaste(w, r, p)
+			return
+		}
+		s.Expires = time.Now().Add(ttl)
+	}
+	if v := r.FormValue("views"); v != "" {
+		s.MaxViews, err = strconv.Atoi(v)
+		if err != nil || s.MaxViews < 0 {
+			p.Message = "Invalid view limit " + v
+			p.Status = "error"
+			renderPaste(w, r, p)
+			return
+		}
+	}
+
+	s.Token, err = pastebin.NewToken()
+	if err == nil {
+		err = addShare(s)
+	}
+	if err != nil {
+		log.Printf("Unable to create share link: %s\n", err)
+		p.Message = "Unable to create share link"
+		p.Status = "error"
+	} else {
+		p.Message = "Share link created: " + absURL("/s/"+s.Token)
+		if m, _ := service.Meta(checksum); !service.RequiresKey(m) {
+			p.Message += ". The paste does not require a key, so anyone with its content can find it without the link."
+		}
+		p.Status = "success"
+	}
+	renderPaste(w, r, p)
+}
+
+func readShare(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	token := vars["token"]
+
+	var p Paste
+	s, err := useShare(token)
+	if err != nil {
+		log.Println(err)
+		w.WriteHeader(http.StatusNotFound)
+		p.Message = "Share link " + token + " does not exist or has expired."
+		p.Status = "error"
+		renderPaste(w, r, p)
+		return
+	}
+
+	p, err = retrievePaste(s.Checksum, "")
+	if err != nil {
+		log.Println(err)
+		w.WriteHeader(http.StatusNotFound)
+		p.Message = "Share link " + token + " does not exist or has expired."
+		p.Status = "error"
+		renderPaste(w, r, p)
+		return
+	}
+
+	views.Add(s.Checksum)
+	accesses.Add(s.Checksum, r)
+	p.Views = views.Get(s.Checksum)
+
+	// Keep the canonical checksum out of the page
+	p.Checksum = ""
+	p.Key = ""
+	renderPaste(w, r, p)
+}
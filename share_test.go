@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"sync"
+	"testing"
+)
+
+var shareLink = regexp.MustCompile(`/s/([0-9a-f]{32})`)
+
+func TestShareViewLimit(t *testing.T) {
+	h := newTestServer(t)
+	path, _ := createPlain(t, h, "shared")
+	rec := request(h, "POST", path+"/share", "views=2", map[string]string{
+		"Content-Type": "application/x-www-form-urlencoded",
+		"User-Agent":   "Mozilla/5.0",
+	})
+	match := shareLink.FindStringSubmatch(rec.Body.String())
+	if match == nil {
+		t.Fatalf("No share link created: %d %s", rec.Code, rec.Body.String())
+	}
+
+	// Concurrent views must not go past the limit
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	served := 0
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if rec := request(h, "GET", match[0], "", map[string]string{"User-Agent": "Mozilla/5.0"}); rec.Code == http.StatusOK {
+				mu.Lock()
+				served++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if served != 2 {
+		t.Errorf("The share link was viewed %d times, want its limit of 2", served)
+	}
+
+	if _, err := purgeShares(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	var tokens []string
+	if err := retrieveJSON(sharesExpiringKey, &tokens); err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens) != 0 {
+		t.Errorf("Expired shares %v are still listed after the purge", tokens)
+	}
+}
+
+func TestShareCrossOrigin(t *testing.T) {
+	h := newTestServer(t)
+	path, _ := createPlain(t, h, "shared across origins")
+	rec := request(h, "POST", path+"/share", "views=2", map[string]string{
+		"Content-Type": "application/x-www-form-urlencoded",
+		"User-Agent":   "Mozilla/5.0",
+		"Origin":       "http://attacker.example",
+	})
+	if rec.Code != http.StatusForbidden || shareLink.MatchString(rec.Body.String()) {
+		t.Errorf("Creating a share link from another origin returned %d %s, want 403", rec.Code, rec.Body.String())
+	}
+}
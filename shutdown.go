@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// How long requests in flight and interrupted jobs get to finish when the
+// server is stopped.
+const shutdownTimeout = 30 * time.Second
+
+// waitForShutdown stops the server on SIGINT or SIGTERM. New connections
+// are refused, requests in flight are completed, running jobs are
+// cancelled and given time to record how far they got, and the buffered
+// counts are written.
+func waitForShutdown(srv *http.Server) error {
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	sig := <-stop
+	log.Printf("Received %s, shutting down\n", sig)
+	sdNotify("STOPPING=1")
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	err := srv.Shutdown(ctx)
+	if jobErr := jobQueue.Shutdown(ctx); err == nil {
+		err = jobErr
+	}
+	flushCounters()
+	return err
+}
+
+// flushCounters writes the counts buffered in memory to the storage, as
+// they are lost when the process exits. It runs once requests and jobs are
+// done, so that nothing counts after it.
+func flushCounters() {
+	views.Flush()
+	accesses.Flush()
+	analytics.Flush()
+	usage.Flush()
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/espebra/pastebin/apitypes"
+	"github.com/espebra/pastebin/pastebin"
+	"github.com/gorilla/mux"
+)
+
+// Signed URLs give access to the raw content of a paste until they
+// expire, without its key. They are signed with -secret, so they can only
+// be made when it is set, and all of them stop working when it changes.
+
+// SignedURL is the response of the signed URL API.
+type SignedURL = apitypes.SignedURL
+
+// rawSignature returns the signature of the raw URL of the paste that
+// expires at the given Unix time.
+func rawSignature(checksum string, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(*secretFlag))
+	mac.Write([]byte("raw\n" + checksum + "\n" + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// signedRawPath returns the path of the raw paste with a signature that
+// is valid until expires.
+func signedRawPath(checksum string, expires time.Time) string {
+	v := url.Values{}
+	v.Set("expires", strconv.FormatInt(expires.Unix(), 10))
+	v.Set("signature", rawSignature(checksum, expires.Unix()))
+	return "/raw/" + checksum + "?" + v.Encode()
+}
+
+// validSignature reports whether the request for the raw paste has a
+// signature that has not expired, and until when it is valid.
+func validSignature(r *http.Request, checksum string) (time.Time, bool) {
+	q := r.URL.Query()
+	if *secretFlag == "" || *signedURLMaxTTLFlag <= 0 || q.Get("signature") == "" {
+		return time.Time{}, false
+	}
+	expires, err := strconv.ParseInt(q.Get("expires"), 10, 64)
+	if err != nil || time.Now().Unix() >= expires {
+		return time.Time{}, false
+	}
+	if !hmac.Equal([]byte(q.Get("signature")), []byte(rawSignature(checksum, expires))) {
+		return time.Time{}, false
+	}
+	return time.Unix(expires, 0), true
+}
+
+// apiSignURL creates a signed raw URL of the paste, valid for ttl, given
+// as a duration like 1h, and at most -signed-url-max-ttl.
+func apiSignURL(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	checksum := vars["checksum"]
+
+	if *secretFlag == "" || *signedURLMaxTTLFlag <= 0 {
+		writeJSON(w, http.StatusNotFound, BatchResult{Status: "error", Message: "Signed URLs are disabled"})
+		return
+	}
+	notFound := BatchResult{Checksum: checksum, Status: "error", Message: "Paste " + checksum + " does not exist."}
+	if !pastebin.IsChecksum(checksum) || !service.Authorize(checksum, vars["key"]) || blocked.Contains(checksum) {
+		writeJSON(w, http.StatusNotFound, notFound)
+		return
+	}
+	if m, err := service.Meta(checksum); err != nil || m.Deleted {
+		if storageUnavailable(w, err) {
+			return
+		}
+		writeJSON(w, http.StatusNotFound, notFound)
+		return
+	}
+
+	ttl := *signedURLMaxTTLFlag
+	if v := r.FormValue("ttl"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 || d > *signedURLMaxTTLFlag {
+			writeJSON(w, http.StatusBadRequest, BatchResult{
+				Checksum: checksum,
+				Status:   "error",
+				Message:  "Invalid ttl " + v + ", expected a duration of at most " + signedURLMaxTTLFlag.String(),
+			})
+			return
+		}
+		ttl = d
+	}
+	expires := time.Now().Add(ttl).Truncate(time.Second).UTC()
+	writeJSON(w, http.StatusOK, SignedURL{
+		URL:     requestURL(r, signedRawPath(checksum, expires)),
+		Expires: expires,
+	})
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/espebra/pastebin/pastebin"
+	"github.com/gorilla/mux"
+)
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{2,62}$`)
+
+// Slugs that would shadow the routes, the fixed first path segments of
+// them, as read from the router by RegisterRoutes.
+var routeSlugs = map[string]bool{}
+
+var (
+	errInvalidSlug = errors.New("slugs must be 3 to 63 characters of a-z, 0-9 and -")
+	errSlugTaken   = errors.New("slug is already in use")
+)
+
+func slugKey(slug string) string {
+	return "slug-" + slug
+}
+
+// reserveRouteSlugs reserves the fixed first path segment of every route
+// of r as a slug, so that routes added later are reserved as well.
+func reserveRouteSlugs(r *mux.Router) {
+	slugs := map[string]bool{}
+	r.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		template, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		segment := strings.SplitN(strings.TrimPrefix(template, "/"), "/", 2)[0]
+		if segment != "" && !strings.HasPrefix(segment, "{") {
+			slugs[segment] = true
+		}
+		return nil
+	})
+	routeSlugs = slugs
+}
+
+func reservedSlug(slug string) bool {
+	if routeSlugs[slug] {
+		return true
+	}
+	for _, s := range strings.Split(*reservedSlugsFlag, ",") {
+		if strings.TrimSpace(s) == slug {
+			return true
+		}
+	}
+	return false
+}
+
+func validSlug(slug string) bool {
+	return slugPattern.MatchString(slug) && !pastebin.IsChecksum(slug) && !reservedSlug(slug)
+}
+
+// resolveSlug returns the checksum of the paste the slug points to.
+func resolveSlug(slug string) (string, error) {
+	if !validSlug(slug) {
+		return "", errInvalidSlug
+	}
+	var buf bytes.Buffer
+	if _, err := storage.Retrieve(slugKey(slug), &buf); err != nil {
+		return "", err
+	}
+	return service.OpenChecksum(buf.String())
+}
+
+// claimSlug points slug at checksum unless it already points at another
+// paste. The check and the write are not atomic, so two simultaneous
+// claims of the same slug may both succeed with the last one winning.
+func claimSlug(slug, checksum string) error {
+	if !validSlug(slug) {
+		return errInvalidSlug
+	}
+	if existing, err := resolveSlug(slug); err == nil && existing != checksum {
+		return errSlugTaken
+	}
+	sealed, err := service.SealChecksum(checksum)
+	if err != nil {
+		return err
+	}
+	_, err = storage.Store(slugKey(slug), strings.NewReader(sealed))
+	return err
+}
@@ -0,0 +1,16 @@
+package main
+
+import "testing"
+
+func TestReservedSlugs(t *testing.T) {
+	newTestServer(t)
+	setFlag(t, reservedSlugsFlag, "release, notes")
+	for _, slug := range []string{"metrics", "debug", "api", "static", "stream", "release", "notes"} {
+		if validSlug(slug) {
+			t.Errorf("Slug %s is not reserved", slug)
+		}
+	}
+	if !validSlug("my-release-notes") {
+		t.Error("Slug my-release-notes is reserved")
+	}
+}
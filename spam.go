@@ -0,0 +1,119 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/espebra/pastebin/pastebin"
+)
+
+// errSpam is returned for pastes rejected by -spam-action.
+var errSpam = errors.New("the paste looks like spam")
+
+// Name of the hidden form field that only bots fill in.
+const honeypotField = "website"
+
+var urlPattern = regexp.MustCompile(`(?i)\bhttps?://\S+`)
+
+// formSpamCheck returns the reason the form submission looks like it was
+// made by a bot, or an empty string if it does not.
+func formSpamCheck(r *http.Request) string {
+	if r.FormValue(honeypotField) != "" {
+		return "honeypot field filled in"
+	}
+
+	rendered, err := strconv.ParseInt(r.FormValue("ts"), 10, 64)
+	if err != nil || time.Since(time.Unix(rendered, 0)) < *spamMinSubmitTimeFlag {
+		return "form submitted too fast"
+	}
+	return ""
+}
+
+// contentSpamCheck returns the reason the content looks like spam, or an
+// empty string if it does not.
+func contentSpamCheck(content string) string {
+	words := len(strings.Fields(content))
+	urls := len(urlPattern.FindAllString(content, -1))
+	if words > 0 && float64(urls)/float64(words) > *spamMaxURLRatioFlag {
+		return "too many links"
+	}
+
+	if repeatedLines(content) {
+		return "repeated content"
+	}
+	return ""
+}
+
+// repeatedLines reports whether a single line makes up most of a
+// non-trivial paste.
+func repeatedLines(content string) bool {
+	lines := strings.Split(content, "\n")
+	if len(lines) < 20 {
+		return false
+	}
+	counts := map[string]int{}
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			counts[line]++
+		}
+	}
+	for _, n := range counts {
+		if n*2 > len(lines) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkSpam applies -spam-action to the form submission and reports
+// whether it should be rejected. Only the form has the fields telling
+// bots apart, while the content of every paste is checked by checkContent.
+func checkSpam(r *http.Request, p Paste) bool {
+	if *spamActionFlag == "off" {
+		return false
+	}
+	return flagSpam(p.Checksum, formSpamCheck(r))
+}
+
+// checkContent applies -spam-action to the content of a paste being
+// created and reports whether it should be rejected.
+func checkContent(p Paste) bool {
+	if *spamActionFlag == "off" {
+		return false
+	}
+	return flagSpam(p.Checksum, contentSpamCheck(p.Content))
+}
+
+// flagSpam applies -spam-action to a paste that matched the heuristics
+// for the reason, if any, and reports whether it should be rejected.
+// Flagged pastes are saved and added to the report queue for an admin to
+// review.
+func flagSpam(checksum, reason string) bool {
+	if reason == "" {
+		return false
+	}
+	log.Printf("Spam heuristics matched %s: %s\n", checksum, reason)
+	if *spamActionFlag == "reject" {
+		return true
+	}
+
+	report := Report{
+		Checksum: checksum,
+		Reason:   "Spam heuristics: " + reason,
+		Created:  time.Now().UTC(),
+	}
+	var err error
+	report.ID, err = pastebin.NewToken()
+	if err == nil {
+		err = addReport(report)
+	}
+	if err != nil {
+		log.Printf("Unable to flag %s: %s\n", checksum, err)
+	}
+	return false
+}
@@ -1,7 +1,260 @@
-var editor = CodeMirror.fromTextArea(document.getElementById("content"), {
+var content = document.getElementById("content");
+var editor = CodeMirror.fromTextArea(content, {
+  theme: content.getAttribute("data-highlight") || "default",
   styleActiveLine: true,
   lineNumbers: true,
-  lineWrapping: true,
+  lineWrapping: content.getAttribute("data-wrap") !== "false",
   viewportMargin: Infinity
 });
+editor.getWrapperElement().classList.add(content.className);
 
+// Spaces are marked by an overlay, tabs are marked by CodeMirror already.
+if (content.getAttribute("data-whitespace") === "true") {
+  editor.getWrapperElement().classList.add("show-whitespace");
+  editor.addOverlay({
+    token: function(stream) {
+      if (stream.eat(" ")) {
+        return "space";
+      }
+      stream.skipTo(" ") || stream.skipToEnd();
+      return null;
+    }
+  });
+}
+
+
+// Pastes can be encrypted by the browser before they are sent. The key
+// is put in the fragment of the URL the form is posted to, which the
+// browser keeps through the redirect to the paste, but never sends to the
+// server.
+function toBase64(bytes) {
+  var s = "";
+  for (var i = 0; i < bytes.length; i++) {
+    s += String.fromCharCode(bytes[i]);
+  }
+  return btoa(s);
+}
+
+function fromBase64(s) {
+  var bin = atob(s);
+  var bytes = new Uint8Array(bin.length);
+  for (var i = 0; i < bin.length; i++) {
+    bytes[i] = bin.charCodeAt(i);
+  }
+  return bytes;
+}
+
+async function encryptPaste(text) {
+  var key = await crypto.subtle.generateKey({name: "AES-GCM", length: 256}, true, ["encrypt"]);
+  var iv = crypto.getRandomValues(new Uint8Array(12));
+  var ciphertext = await crypto.subtle.encrypt({name: "AES-GCM", iv: iv}, key, new TextEncoder().encode(text));
+  var raw = new Uint8Array(await crypto.subtle.exportKey("raw", key));
+  return {
+    content: "pbe1:" + toBase64(iv) + ":" + toBase64(new Uint8Array(ciphertext)),
+    key: toBase64(raw).replace(/\+/g, "-").replace(/\//g, "_").replace(/=+$/, "")
+  };
+}
+
+async function decryptPaste(content, fragment) {
+  var parts = content.slice("pbe1:".length).split(":");
+  var raw = fromBase64(fragment.replace(/-/g, "+").replace(/_/g, "/"));
+  var key = await crypto.subtle.importKey("raw", raw, "AES-GCM", false, ["decrypt"]);
+  var plaintext = await crypto.subtle.decrypt({name: "AES-GCM", iv: fromBase64(parts[0])}, key, fromBase64(parts[1]));
+  return new TextDecoder().decode(plaintext);
+}
+
+var encryptField = document.getElementById("encrypt");
+if (encryptField && window.crypto && crypto.subtle) {
+  var encrypted = false;
+  // Registered before the proof of work, which is solved once the
+  // content is encrypted
+  content.form.addEventListener("submit", function(e) {
+    if (!encryptField.checked || encrypted) {
+      return;
+    }
+    e.preventDefault();
+    e.stopImmediatePropagation();
+    var form = content.form;
+    encryptPaste(editor.getValue()).then(function(result) {
+      encrypted = true;
+      editor.setValue(result.content);
+      form.action = form.action.split("#")[0] + "#" + result.key;
+      form.requestSubmit(e.submitter);
+    });
+  });
+} else if (encryptField) {
+  // WebCrypto is only available on secure origins
+  encryptField.checked = false;
+  encryptField.parentNode.style.display = "none";
+}
+
+if (content.getAttribute("data-encrypted") === "true" && content.value !== "") {
+  var decryptStatus = document.getElementById("decrypt-status");
+  decryptPaste(content.value, location.hash.slice(1)).then(function(text) {
+    editor.setValue(text);
+  }).catch(function() {
+    decryptStatus.className = "alert alert-danger";
+    decryptStatus.textContent = decryptStatus.getAttribute("data-error");
+  });
+}
+
+// Solve the proof of work challenge before the form is submitted, by
+// finding a nonce that gives a hash with enough leading zero bits.
+function leadingZeroBits(bytes) {
+  var n = 0;
+  for (var i = 0; i < bytes.length; i++) {
+    if (bytes[i] === 0) {
+      n += 8;
+      continue;
+    }
+    return n + Math.clz32(bytes[i]) - 24;
+  }
+  return n;
+}
+
+async function solve(challenge, difficulty) {
+  var encoder = new TextEncoder();
+  for (var nonce = 0; ; nonce++) {
+    var sum = await crypto.subtle.digest("SHA-256", encoder.encode(challenge + ":" + nonce));
+    if (leadingZeroBits(new Uint8Array(sum)) >= difficulty) {
+      return String(nonce);
+    }
+  }
+}
+
+var nonceField = content.form.querySelector('input[name="pow_nonce"]');
+if (nonceField) {
+  content.form.addEventListener("submit", function(e) {
+    if (nonceField.value !== "") {
+      return;
+    }
+    e.preventDefault();
+    var form = content.form;
+    var difficulty = parseInt(nonceField.getAttribute("data-difficulty"), 10);
+    solve(form.elements["pow_challenge"].value, difficulty).then(function(nonce) {
+      nonceField.value = nonce;
+      form.requestSubmit(e.submitter);
+    });
+  });
+}
+
+// The copy button needs JavaScript, so it is only shown when it works.
+document.documentElement.classList.add("js");
+var copy = document.getElementById("copy");
+if (copy && navigator.clipboard) {
+  copy.addEventListener("click", function() {
+    navigator.clipboard.writeText(editor.getValue());
+  });
+}
+
+// Large files are uploaded in chunks. A chunk that fails is retried from
+// the offset the server has, so a flaky connection only loses the chunk
+// in flight rather than the whole upload.
+var chunkSize = 1 << 20;
+
+function sleep(ms) {
+  return new Promise(function(resolve) { setTimeout(resolve, ms); });
+}
+
+// The server checks the upload against its SHA-256, so that a corrupted
+// upload is not saved. It can only be computed on secure origins.
+async function sha256Hex(file) {
+  if (!window.crypto || !crypto.subtle) {
+    return "";
+  }
+  var sum = new Uint8Array(await crypto.subtle.digest("SHA-256", await file.arrayBuffer()));
+  return Array.prototype.map.call(sum, function(b) {
+    return ("0" + b.toString(16)).slice(-2);
+  }).join("");
+}
+
+async function uploadFile(url, file, form, progress) {
+  var headers = {"Content-Type": "application/json"};
+  // The challenge of the form is left for the form
+  if (nonceField) {
+    var c = await (await fetch(uploadField.getAttribute("data-challenge-url"))).json();
+    headers["X-PoW-Challenge"] = c.challenge;
+    headers["X-PoW-Nonce"] = await solve(c.challenge, c.difficulty);
+  }
+  var captcha = form.querySelector("[name=h-captcha-response], [name=cf-turnstile-response]");
+  if (captcha) {
+    headers["X-Captcha-Response"] = captcha.value;
+  }
+  var res = await fetch(url, {
+    method: "POST",
+    headers: headers,
+    body: JSON.stringify({
+      length: file.size,
+      visibility: form.elements["visibility"].value,
+      tags: form.elements["tags"].value,
+      sha256: await sha256Hex(file)
+    })
+  });
+  var upload = await res.json();
+  if (!res.ok) {
+    throw new Error(upload.message);
+  }
+  url = url + "/" + upload.id;
+
+  var failures = 0;
+  while (!upload.checksum) {
+    progress(upload.offset / upload.length);
+    try {
+      res = await fetch(url, {
+        method: "PATCH",
+        headers: {"Upload-Offset": String(upload.offset)},
+        body: file.slice(upload.offset, upload.offset + chunkSize)
+      });
+      if (res.status >= 500 || res.status === 409) {
+        throw new Error(res.statusText);
+      }
+      var body = await res.json();
+      if (!res.ok) {
+        throw new Error(body.message);
+      }
+      upload = body;
+      failures = 0;
+    } catch (err) {
+      if (++failures > 5) {
+        throw err;
+      }
+      await sleep(1000 * failures);
+      res = await fetch(url);
+      if (res.ok) {
+        upload = await res.json();
+      }
+    }
+  }
+  progress(1);
+  return upload;
+}
+
+var uploadField = document.getElementById("upload");
+if (uploadField) {
+  uploadField.addEventListener("change", function() {
+    var file = uploadField.files[0];
+    var bar = document.getElementById("upload-progress");
+    var status = document.getElementById("upload-status");
+    if (!file) {
+      return;
+    }
+    uploadField.disabled = true;
+    bar.hidden = false;
+    status.textContent = "";
+    uploadFile(uploadField.getAttribute("data-url"), file, uploadField.form, function(done) {
+      bar.value = done;
+    }).then(function(upload) {
+      var link = document.createElement("a");
+      link.href = upload.url;
+      link.textContent = upload.url;
+      status.appendChild(link);
+      if (upload.delete_token) {
+        status.appendChild(document.createTextNode(" (delete token " + upload.delete_token + ")"));
+      }
+    }).catch(function(err) {
+      status.textContent = err.message;
+    }).finally(function() {
+      uploadField.disabled = false;
+    });
+  });
+}
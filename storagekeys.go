@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/espebra/pastebin/pastebin"
+)
+
+// storageSecrets returns the secrets given with -storage-secrets, the
+// current one first.
+func storageSecrets() []string {
+	var secrets []string
+	for _, s := range strings.Split(*storageSecretsFlag, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			secrets = append(secrets, s)
+		}
+	}
+	return secrets
+}
+
+// isPasteFile reports whether a file in the data directory holds the paste
+// with the given checksum, under its plain or any of its secret keys.
+func isPasteFile(name, checksum string) bool {
+	for _, key := range service.ObjectKeys(checksum) {
+		if name == key {
+			return true
+		}
+	}
+	return false
+}
+
+// walkPastes calls fn with the checksum and content of every paste found
+// below the data directories.
+func walkPastes(fn func(dir, checksum string, data []byte, fi os.FileInfo) error) error {
+	for _, dir := range dataDirs() {
+		if err := walkDir(dir, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkDir(dir string, fn func(dir, checksum string, data []byte, fi os.FileInfo) error) error {
+	return filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !fi.Mode().IsRegular() || !pastebin.IsChecksum(fi.Name()) {
+			return nil
+		}
+
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(data)
+		checksum := hex.EncodeToString(sum[:])
+		if !isPasteFile(fi.Name(), checksum) && pastebin.IsGzip(data) {
+			// Compressed pastes match their checksum once decompressed
+			if data, err = pastebin.Decode("", data); err != nil {
+				return nil
+			}
+			sum = sha256.Sum256(data)
+			checksum = hex.EncodeToString(sum[:])
+		}
+		if !isPasteFile(fi.Name(), checksum) {
+			return nil
+		}
+		return fn(dir, checksum, data, fi)
+	})
+}
+
+// migrateKeys copies every paste to its current storage key, and to the
+// shard that key belongs to.
+func migrateKeys() (int, error) {
+	count := 0
+	err := walkPastes(func(dir, checksum string, data []byte, fi os.FileInfo) error {
+		key := service.ObjectKey(checksum)
+		current := fi.Name() == key
+		if s, ok := primaryStorage().(*shardedStorage); ok {
+			current = current && s.names[s.owner(key)] == dir
+		}
+		if current {
+			return nil
+		}
+		stored, _ := service.Encode(data)
+		if _, err := storage.Store(key, bytes.NewReader(stored)); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	return count, err
+}
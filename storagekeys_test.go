@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/espebra/pastebin/pastebin"
+)
+
+func TestStorageSecretsSealIndexes(t *testing.T) {
+	setFlag(t, storageSecretsFlag, "current,old")
+	h := newTestServer(t)
+	content := "Listed under a tag\n"
+	checksum := pastebin.Checksum(content)
+	if rec := request(h, "PUT", "/q?tags=sealed&visibility=public", content, nil); rec.Code != http.StatusCreated {
+		t.Fatalf("PUT /q returned %d: %s", rec.Code, rec.Body.String())
+	}
+	if err := claimSlug("sealed-slug", checksum); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"tag-sealed", recentKey, slugKey("sealed-slug")} {
+		var buf bytes.Buffer
+		if _, err := storage.Retrieve(key, &buf); err != nil {
+			t.Fatalf("Unable to read %s: %s", key, err)
+		}
+		if strings.Contains(buf.String(), checksum) {
+			t.Errorf("%s has the checksum of the paste in it: %s", key, buf.String())
+		}
+	}
+
+	if pastes := listTagged("sealed"); len(pastes) != 1 || pastes[0].Checksum != checksum {
+		t.Errorf("Listed %+v under the tag, want the paste", pastes)
+	}
+	if got, err := resolveSlug("sealed-slug"); err != nil || got != checksum {
+		t.Errorf("The slug points at %q (%v), want the paste", got, err)
+	}
+
+	// Indexes sealed with an older secret are read after it is rotated
+	setFlag(t, storageSecretsFlag, "newer,current")
+	service = newService(storage)
+	if pastes := listTagged("sealed"); len(pastes) != 1 || pastes[0].Checksum != checksum {
+		t.Errorf("Listed %+v under the tag after rotating the secret, want the paste", pastes)
+	}
+}
@@ -0,0 +1,382 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/espebra/pastebin/pastebin"
+	"github.com/gorilla/mux"
+)
+
+// Stream is a paste that is appended to while it is being written, such
+// as the output of a running build. Viewers follow it live until it is
+// closed, at which point it is stored as a regular paste.
+type Stream struct {
+	ID        string    `json:"id"`
+	TokenHash string    `json:"token_hash"`
+	Content   string    `json:"content"`
+	Created   time.Time `json:"created"`
+	Closed    bool      `json:"closed"`
+	Checksum  string    `json:"checksum,omitempty"`
+	// Key of the paste, if it requires one, for the viewers of the stream
+	Key string `json:"key,omitempty"`
+}
+
+// StreamPage is the data of the stream template.
+type StreamPage struct {
+	Stream
+	Location string
+	Message  string
+	Status   string
+}
+
+func streamKey(id string) string {
+	return "stream-" + id
+}
+
+// liveStream holds a stream in memory together with the channels of the
+// viewers that follow it. Updates are only published to viewers connected
+// to the same instance; others see the content as last stored.
+type liveStream struct {
+	mu          sync.Mutex
+	stream      Stream
+	subscribers map[chan string]struct{}
+}
+
+var (
+	streamsMu sync.Mutex
+	streams   = make(map[string]*liveStream)
+)
+
+// getStream returns the live stream with the given id, loading it from
+// storage if it is not held in memory.
+func getStream(id string) (*liveStream, error) {
+	streamsMu.Lock()
+	defer streamsMu.Unlock()
+	if ls, ok := streams[id]; ok {
+		return ls, nil
+	}
+	var s Stream
+	if err := retrieveJSON(streamKey(id), &s); err != nil {
+		return nil, err
+	}
+	ls := &liveStream{stream: s, subscribers: make(map[chan string]struct{})}
+	if !s.Closed {
+		streams[id] = ls
+	}
+	return ls, nil
+}
+
+func (ls *liveStream) subscribe() (string, bool, chan string) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	ch := make(chan string, 64)
+	if !ls.stream.Closed {
+		ls.subscribers[ch] = struct{}{}
+	}
+	return ls.stream.Content, ls.stream.Closed, ch
+}
+
+func (ls *liveStream) unsubscribe(ch chan string) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	delete(ls.subscribers, ch)
+}
+
+// append adds data to the stream and publishes it to the viewers. Viewers
+// that do not keep up are dropped rather than blocking the writer.
+func (ls *liveStream) append(data string) error {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	if ls.stream.Closed {
+		return fmt.Errorf("stream %s is closed", ls.stream.ID)
+	}
+	if int64(len(ls.stream.Content)+len(data)) > maxPasteSizeFlag.Get() {
+		return fmt.Errorf("stream %s is too large", ls.stream.ID)
+	}
+	ls.stream.Content += data
+	for ch := range ls.subscribers {
+		select {
+		case ch <- data:
+		default:
+			delete(ls.subscribers, ch)
+			close(ch)
+		}
+	}
+	return nil
+}
+
+func (ls *liveStream) store() error {
+	ls.mu.Lock()
+	s := ls.stream
+	ls.mu.Unlock()
+	return storeJSON(streamKey(s.ID), s)
+}
+
+// close stores the content of the stream as a paste and ends the stream
+// for its viewers.
+func (ls *liveStream) close() (Paste, string, error) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	var p Paste
+	if ls.stream.Closed {
+		return p, "", fmt.Errorf("stream %s is closed", ls.stream.ID)
+	}
+	p.Content = ls.stream.Content
+	p.Checksum = p.GetName()
+	_, token, err := createPaste(&p, *defaultVisibilityFlag, nil)
+	if err != nil {
+		return p, "", err
+	}
+
+	ls.stream.Closed = true
+	ls.stream.Checksum = p.Checksum
+	ls.stream.Key = p.Key
+	for ch := range ls.subscribers {
+		close(ch)
+	}
+	ls.subscribers = nil
+
+	streamsMu.Lock()
+	delete(streams, ls.stream.ID)
+	streamsMu.Unlock()
+	return p, token, storeJSON(streamKey(ls.stream.ID), ls.stream)
+}
+
+// streamPasteLocation returns the location of the paste a closed stream
+// was stored as.
+func streamPasteLocation(s Stream) string {
+	return Paste{Checksum: s.Checksum, Key: s.Key}.Location()
+}
+
+func (ls *liveStream) authorize(token string) bool {
+	if token == "" {
+		return false
+	}
+	hash := pastebin.HashToken(token)
+	return subtle.ConstantTimeCompare([]byte(ls.stream.TokenHash), []byte(hash)) == 1
+}
+
+// createStream starts a new stream and returns its URL and the token that
+// authorizes appending to it.
+func createStream(w http.ResponseWriter, r *http.Request) {
+	if err := checkPow(r); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if err := checkCaptcha(r, true); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	id, err := pastebin.NewToken()
+	if err != nil {
+		log.Printf("Unable to create stream: %s\n", err)
+		http.Error(w, "Unable to create stream", http.StatusInternalServerError)
+		return
+	}
+	token, err := pastebin.NewToken()
+	if err != nil {
+		log.Printf("Unable to create stream: %s\n", err)
+		http.Error(w, "Unable to create stream", http.StatusInternalServerError)
+		return
+	}
+
+	s := Stream{
+		ID:        id,
+		TokenHash: pastebin.HashToken(token),
+		Created:   time.Now().UTC(),
+	}
+	if err := storeJSON(streamKey(id), s); err != nil {
+		log.Printf("Unable to store stream %s: %s\n", id, err)
+		http.Error(w, "Unable to create stream", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("X-Stream-Token", token)
+	if plainTextClient(r) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintln(w, absURL("/stream/"+id))
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]string{
+		"id":    id,
+		"url":   absURL("/stream/" + id),
+		"token": token,
+	})
+}
+
+// appendStream appends the request body to the stream as it arrives, so a
+// chunked upload such as "tail -f build.log | curl -T - ..." is followed
+// live. The stream is closed and stored as a paste when close=1 is given.
+func appendStream(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	ls, err := getStream(id)
+	if err != nil {
+		log.Println(err)
+		http.NotFound(w, r)
+		return
+	}
+
+	token := r.Header.Get("X-Stream-Token")
+	if token == "" {
+		token = r.URL.Query().Get("token")
+	}
+	if !ls.authorize(token) {
+		http.Error(w, "Invalid stream token", http.StatusForbidden)
+		return
+	}
+
+	// Streams last longer than -read-timeout allows for requests
+	http.NewResponseController(w).SetReadDeadline(time.Time{})
+	body := http.MaxBytesReader(w, r.Body, maxPasteSizeFlag.Get())
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := body.Read(buf)
+		if n > 0 {
+			if err := ls.append(string(buf[:n])); err != nil {
+				log.Println(err)
+				http.Error(w, err.Error(), http.StatusConflict)
+				return
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Printf("Unable to read stream %s: %s\n", id, err)
+			break
+		}
+	}
+	if err := ls.store(); err != nil {
+		log.Printf("Unable to store stream %s: %s\n", id, err)
+	}
+
+	if r.URL.Query().Get("close") != "1" {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	p, deleteToken, err := ls.close()
+	if err != nil {
+		log.Printf("Unable to close stream %s: %s\n", id, err)
+		if insufficientStorage(w, err) {
+			return
+		}
+		if err == errBlocked || err == errSpam {
+			http.Error(w, "The stream is not allowed: "+err.Error(), http.StatusForbidden)
+			return
+		}
+		http.Error(w, "Unable to close stream", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("X-Delete-Token", deleteToken)
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintln(w, absURL(p.Location()))
+}
+
+func readStream(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	ls, err := getStream(id)
+	if err != nil {
+		log.Println(err)
+		w.WriteHeader(http.StatusNotFound)
+		renderTemplate(w, r, "templates/stream.html", "stream", StreamPage{
+			Message: "Stream " + id + " does not exist.",
+			Status:  "error",
+		})
+		return
+	}
+
+	ls.mu.Lock()
+	s := ls.stream
+	ls.mu.Unlock()
+	s.TokenHash = ""
+	page := StreamPage{Stream: s}
+	if s.Closed {
+		page.Location = streamPasteLocation(s)
+	}
+	renderTemplate(w, r, "templates/stream.html", "stream", page)
+}
+
+// streamEvents sends the stream to the viewer as server-sent events. The
+// content so far is sent first as a snapshot event, followed by each
+// appended chunk, and a done event with the URL of the paste once the
+// stream is closed. Viewers that fall behind are disconnected, and get a
+// new snapshot when the browser reconnects.
+func streamEvents(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	ls, err := getStream(id)
+	if err != nil {
+		log.Println(err)
+		http.NotFound(w, r)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	// Viewers follow the stream longer than -write-timeout allows for
+	// responses
+	http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	content, closed, ch := ls.subscribe()
+	defer ls.unsubscribe(ch)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+
+	send := func(event, data string) {
+		encoded, _ := json.Marshal(data)
+		if event != "" {
+			fmt.Fprintf(w, "event: %s\n", event)
+		}
+		fmt.Fprintf(w, "data: %s\n\n", encoded)
+		flusher.Flush()
+	}
+	done := func() {
+		ls.mu.Lock()
+		s := ls.stream
+		ls.mu.Unlock()
+		send("done", absURL(streamPasteLocation(s)))
+	}
+
+	send("snapshot", content)
+	if closed {
+		done()
+		return
+	}
+
+	keepalive := time.NewTicker(30 * time.Second)
+	defer keepalive.Stop()
+	for {
+		select {
+		case data, ok := <-ch:
+			if !ok {
+				ls.mu.Lock()
+				closed := ls.stream.Closed
+				ls.mu.Unlock()
+				if closed {
+					done()
+				}
+				return
+			}
+			send("", data)
+		case <-keepalive.C:
+			fmt.Fprint(w, ": keepalive\n\n")
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// The first file descriptor of the sockets passed by systemd socket
+// activation, or by the process restarted with SIGUSR2.
+const listenFdsStart = 3
+
+// Names of the sockets by what they serve. Sockets passed by systemd with
+// other names, such as the default names, serve HTTP.
+const (
+	listenerHTTP  = "http"
+	listenerTCP   = "tcp"
+	listenerDebug = "debug"
+)
+
+// namedListener is a socket passed by another process, named by what it
+// serves.
+type namedListener struct {
+	Name string
+	net.Listener
+}
+
+// fileListeners returns the n sockets passed as file descriptors from
+// listenFdsStart on, with the names separated by colons.
+func fileListeners(n int, names string) ([]namedListener, error) {
+	fdNames := strings.Split(names, ":")
+	var listeners []namedListener
+	for i := 0; i < n; i++ {
+		fd := listenFdsStart + i
+		syscall.CloseOnExec(fd)
+		name := "LISTEN_FD_" + strconv.Itoa(fd)
+		if i < len(fdNames) && fdNames[i] != "" {
+			name = fdNames[i]
+		}
+		f := os.NewFile(uintptr(fd), name)
+		l, err := net.FileListener(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("socket %s: %s", name, err)
+		}
+		listeners = append(listeners, namedListener{Name: name, Listener: l})
+	}
+	return listeners, nil
+}
+
+// systemdListeners returns the sockets passed by systemd socket
+// activation, in the order of the socket unit, or none when the process
+// was not started by it. The sockets are kept open by systemd across
+// restarts, so connections are queued rather than refused meanwhile. A
+// socket with FileDescriptorName=tcp accepts pastes over plain TCP, and
+// one named debug serves the debug endpoints.
+func systemdListeners() ([]namedListener, error) {
+	// The variables are meant for this process only, not its children
+	defer os.Unsetenv("LISTEN_PID")
+	defer os.Unsetenv("LISTEN_FDS")
+	defer os.Unsetenv("LISTEN_FDNAMES")
+
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("invalid LISTEN_FDS %q", os.Getenv("LISTEN_FDS"))
+	}
+	return fileListeners(n, os.Getenv("LISTEN_FDNAMES"))
+}
+
+// sdNotify sends the state to systemd, when the service is of
+// Type=notify. It does nothing when not started by systemd.
+func sdNotify(state string) {
+	addr := os.Getenv("NOTIFY_SOCKET")
+	if addr == "" {
+		return
+	}
+	// Sockets starting with @ are in the abstract namespace
+	if strings.HasPrefix(addr, "@") {
+		addr = "\x00" + addr[1:]
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: addr, Net: "unixgram"})
+	if err != nil {
+		log.Printf("Unable to notify systemd: %s\n", err)
+		return
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte(state)); err != nil {
+		log.Printf("Unable to notify systemd: %s\n", err)
+	}
+}
+
+// runWatchdog tells systemd that the process is alive at half the
+// interval of WatchdogSec, so that systemd restarts it when it hangs.
+func runWatchdog() {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return
+	}
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return
+	}
+	for range time.Tick(time.Duration(usec) * time.Microsecond / 2) {
+		sdNotify("WATCHDOG=1")
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/espebra/pastebin/apitypes"
+	"github.com/gorilla/mux"
+)
+
+// PasteSummary is a paste as shown in listings.
+type PasteSummary = apitypes.PasteSummary
+
+// listTagged returns the public pastes with the tag, leaving out blocked
+// pastes.
+func listTagged(tag string) []PasteSummary {
+	checksums, err := service.Tagged(tag)
+	if err != nil {
+		return nil
+	}
+	pastes := []PasteSummary{}
+	for _, checksum := range checksums {
+		if blocked.Contains(checksum) {
+			continue
+		}
+		m, _ := service.Meta(checksum)
+		if m.Deleted || service.Visibility(m) != "public" {
+			continue
+		}
+		var p Paste
+		p.Checksum = checksum
+		summary := PasteSummary{
+			Checksum: checksum,
+			URL:      absURL(p.Location()),
+			Tags:     m.Tags,
+			Preview:  m.Preview,
+		}
+		if isImage(m.ContentType) {
+			summary.Thumbnail = absURL("/thumb" + p.Location())
+		}
+		pastes = append(pastes, summary)
+	}
+	return pastes
+}
+
+// TagPage is the data shown on the tag page.
+type TagPage struct {
+	Tag    string
+	Pastes []PasteSummary
+}
+
+// Listings would hand out every paste URL, so they are not available when
+// paste URLs are private.
+func tagsEnabled(w http.ResponseWriter, r *http.Request) bool {
+	if *privateFlag {
+		http.NotFound(w, r)
+		return false
+	}
+	return true
+}
+
+func readTag(w http.ResponseWriter, r *http.Request) {
+	if !tagsEnabled(w, r) {
+		return
+	}
+	tag := mux.Vars(r)["tag"]
+	renderTemplate(w, r, "templates/tag.html", "tag", TagPage{
+		Tag:    tag,
+		Pastes: listTagged(tag),
+	})
+}
+
+func apiListPastes(w http.ResponseWriter, r *http.Request) {
+	if !tagsEnabled(w, r) {
+		return
+	}
+	tag := r.URL.Query().Get("tag")
+	if tag == "" {
+		writeJSON(w, http.StatusBadRequest, BatchResult{
+			Status:  "error",
+			Message: "Listing pastes requires a tag",
+		})
+		return
+	}
+	writeJSON(w, http.StatusOK, listTagged(tag))
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"log"
+	"net"
+	"sync/atomic"
+	"time"
+)
+
+// tcpBuffered is the number of bytes of the pastes being received over TCP,
+// limited by -tcp-max-buffered.
+var tcpBuffered atomic.Int64
+
+// serveTCP accepts plain TCP connections and creates a paste from
+// whatever each client sends, like termbin. As nc does not always close
+// its side of the connection, a paste is complete when the client has
+// been idle for -tcp-idle-timeout. Connections beyond maxConnections, or
+// while maxBuffered bytes are being received, are turned away before
+// anything is read from them.
+func serveTCP(l net.Listener, maxConnections int, maxBuffered int64) error {
+	limiter := newRateLimiter(tcpRateFlag.Int, time.Minute)
+	slots := make(chan struct{}, maxConnections)
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		if tcpBuffered.Load() >= maxBuffered {
+			busyTCP(conn)
+			continue
+		}
+		select {
+		case slots <- struct{}{}:
+		default:
+			busyTCP(conn)
+			continue
+		}
+
+		// The address is read from the PROXY protocol header with
+		// -proxy-protocol, which must not hold up accepting others
+		go func() {
+			defer func() { <-slots }()
+			host, _, _ := net.SplitHostPort(conn.RemoteAddr().String())
+			if !limiter.Allow(clientKey(host)) {
+				conn.Write([]byte("Too many pastes, try again later\n"))
+				conn.Close()
+				return
+			}
+			handleTCP(conn, maxBuffered)
+		}()
+	}
+}
+
+// busyTCP turns the connection away without reading from it.
+func busyTCP(conn net.Conn) {
+	conn.SetWriteDeadline(time.Now().Add(*tcpIdleTimeoutFlag))
+	conn.Write([]byte("Too many pastes are being received, try again later\n"))
+	conn.Close()
+}
+
+func handleTCP(conn net.Conn, maxBuffered int64) {
+	defer conn.Close()
+	var buffered int64
+	defer func() { tcpBuffered.Add(-buffered) }()
+
+	deadline := time.Now().Add(*tcpTimeoutFlag)
+	buf := make([]byte, 0, 4096)
+	chunk := make([]byte, 4096)
+	for {
+		idle := time.Now().Add(*tcpIdleTimeoutFlag)
+		if idle.After(deadline) {
+			idle = deadline
+		}
+		conn.SetReadDeadline(idle)
+
+		n, err := conn.Read(chunk)
+		buf = append(buf, chunk[:n]...)
+		if int64(len(buf)) > maxPasteSizeFlag.Get() {
+			conn.Write([]byte("Paste is too large\n"))
+			return
+		}
+		buffered += int64(n)
+		if tcpBuffered.Add(int64(n)) > maxBuffered {
+			conn.Write([]byte("Too many pastes are being received, try again later\n"))
+			return
+		}
+		if err == nil {
+			continue
+		}
+		if ne, ok := err.(net.Error); ok && ne.Timeout() && !time.Now().Before(deadline) {
+			conn.Write([]byte("Timed out\n"))
+			return
+		}
+		// End of input or idle timeout
+		break
+	}
+
+	if len(buf) == 0 {
+		conn.Write([]byte("Empty paste\n"))
+		return
+	}
+
+	var p Paste
+	p.Content = string(buf)
+	p.Checksum = p.GetName()
+	if _, _, err := createPaste(&p, *defaultVisibilityFlag, nil); err != nil {
+		log.Printf("Unable to write data: %s\n", err)
+		if err == errBlocked {
+			conn.Write([]byte("This content is not allowed.\n"))
+			return
+		}
+		if err == errSpam {
+			conn.Write([]byte("The paste looks like spam and was not saved.\n"))
+			return
+		}
+		if err == errStorageFull {
+			conn.Write([]byte("The storage is full, no pastes can be created.\n"))
+			return
+		}
+		conn.Write([]byte("Unable to save the paste\n"))
+		return
+	}
+
+	conn.SetWriteDeadline(time.Now().Add(*tcpIdleTimeoutFlag))
+	conn.Write([]byte(absURL(p.Location()) + "\n"))
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// listenTCP serves pastes over TCP on a local port with the limits.
+func listenTCP(t *testing.T, maxConnections int, maxBuffered int64) net.Listener {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go serveTCP(l, maxConnections, maxBuffered)
+	return l
+}
+
+// dialTCP connects to the listener and sends content. The returned
+// function reads the first line of the answer, which comes once the paste
+// is complete or the connection is turned away.
+func dialTCP(t *testing.T, l net.Listener, content string) func() string {
+	t.Helper()
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	if content != "" {
+		conn.Write([]byte(content))
+	}
+	return func() string {
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		return line
+	}
+}
+
+func TestTCPMaxBuffered(t *testing.T) {
+	newTestServer(t)
+	l := listenTCP(t, 10, 5)
+
+	// A paste of as many bytes as may be buffered is received, and others
+	// are turned away meanwhile
+	answer := dialTCP(t, l, "12345")
+	time.Sleep(100 * time.Millisecond)
+	if line := dialTCP(t, l, "")(); !strings.Contains(line, "Too many") {
+		t.Errorf("Connecting while 5 bytes are buffered answered %q, want it turned away", line)
+	}
+	if line := answer(); !strings.HasPrefix(line, "/") {
+		t.Errorf("The paste of 5 bytes was answered with %q, want its URL", line)
+	}
+}
+
+func TestTCPMaxConnections(t *testing.T) {
+	newTestServer(t)
+	l := listenTCP(t, 2, 1<<20)
+	// Idle connections that send nothing, which end without a paste
+	dialTCP(t, l, "")
+	dialTCP(t, l, "")
+	time.Sleep(100 * time.Millisecond)
+	if line := dialTCP(t, l, "")(); !strings.Contains(line, "Too many") {
+		t.Errorf("Connecting beyond the maximum of 2 connections answered %q, want it turned away", line)
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"github.com/espebra/pastebin/pastebin"
+)
+
+const tombstonesKey = "tombstones"
+
+// Tombstone is what is kept of a paste that was purged from the storage,
+// so that links to it can say what happened instead of that it does not
+// exist.
+type Tombstone struct {
+	DeletedBy string    `json:"deleted_by,omitempty"`
+	DeletedAt time.Time `json:"deleted_at"`
+	Purged    time.Time `json:"purged"`
+}
+
+// The tombstones are stored as a single record, keyed by the storage key of
+// the paste rather than its checksum, like the paste was.
+var tombstonesMu sync.Mutex
+
+func retrieveTombstones() (map[string]Tombstone, error) {
+	tombstones := map[string]Tombstone{}
+	err := retrieveJSON(tombstonesKey, &tombstones)
+	return tombstones, err
+}
+
+// addTombstones records that the pastes with the metadata are purged.
+func addTombstones(metas map[string]Meta) error {
+	tombstonesMu.Lock()
+	defer tombstonesMu.Unlock()
+	tombstones, _ := retrieveTombstones()
+	now := time.Now().UTC()
+	for checksum, m := range metas {
+		tombstones[service.ObjectKey(checksum)] = Tombstone{DeletedBy: m.DeletedBy, DeletedAt: m.DeletedAt, Purged: now}
+	}
+	return storeJSON(tombstonesKey, tombstones)
+}
+
+// purgeTombstones forgets the pastes purged longer than
+// -tombstone-retention ago.
+func purgeTombstones() (int, error) {
+	if *tombstoneRetentionFlag <= 0 {
+		return 0, nil
+	}
+	tombstonesMu.Lock()
+	defer tombstonesMu.Unlock()
+	tombstones, err := retrieveTombstones()
+	if err != nil {
+		if isNotFound(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	n := 0
+	for key, t := range tombstones {
+		if time.Since(t.Purged) > *tombstoneRetentionFlag {
+			delete(tombstones, key)
+			n++
+		}
+	}
+	if n == 0 {
+		return 0, nil
+	}
+	return n, storeJSON(tombstonesKey, tombstones)
+}
+
+// goneMessage explains why the paste can not be read, when err says that
+// it is deleted or the paste was purged. It reports false for pastes that
+// never existed, as far as is known.
+func goneMessage(checksum string, err error) (string, bool) {
+	var by string
+	var at time.Time
+	if err == errDeleted {
+		m, _ := service.Meta(checksum)
+		by, at = m.DeletedBy, m.DeletedAt
+	} else if isNotFound(err) {
+		tombstonesMu.Lock()
+		tombstones, _ := retrieveTombstones()
+		tombstonesMu.Unlock()
+		var t Tombstone
+		ok := false
+		for _, key := range service.ObjectKeys(checksum) {
+			if t, ok = tombstones[key]; ok {
+				break
+			}
+		}
+		if !ok {
+			return "", false
+		}
+		by, at = t.DeletedBy, t.DeletedAt
+	} else {
+		return "", false
+	}
+
+	message := "Paste " + checksum + " was deleted"
+	switch by {
+	case pastebin.DeletedByOwner:
+		message += " by its owner"
+	case pastebin.DeletedByAdmin:
+		message += " by an administrator"
+	}
+	if !at.IsZero() {
+		message += " on " + at.Format("2006-01-02")
+	}
+	return message + ".", true
+}
@@ -0,0 +1,400 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/espebra/pastebin/pastebin"
+)
+
+// TrashedPaste is a deleted paste as listed in the trash.
+type TrashedPaste struct {
+	Checksum  string
+	Size      int
+	DeletedAt time.Time
+	// Zero when deleted pastes are kept forever, or the paste is pinned
+	PurgeAt time.Time
+	Pinned  bool
+}
+
+// AdminTrash is the data shown on the trash page.
+type AdminTrash struct {
+	Pastes  []TrashedPaste
+	Message string
+	Status  string
+}
+
+// listTrash walks the data directories for deleted pastes, most recently
+// deleted first.
+func listTrash() ([]TrashedPaste, error) {
+	var pastes []TrashedPaste
+	seen := map[string]bool{}
+	err := walkPastes(func(dir, checksum string, data []byte, fi os.FileInfo) error {
+		if seen[checksum] {
+			return nil
+		}
+		seen[checksum] = true
+		m, _ := service.Meta(checksum)
+		if !m.Deleted {
+			return nil
+		}
+		t := TrashedPaste{
+			Checksum:  checksum,
+			Size:      len(data),
+			DeletedAt: m.DeletedAt,
+			Pinned:    m.Pinned,
+		}
+		if *trashRetentionFlag > 0 && !m.DeletedAt.IsZero() && !m.Pinned {
+			t.PurgeAt = m.DeletedAt.Add(*trashRetentionFlag)
+		}
+		pastes = append(pastes, t)
+		return nil
+	})
+	sort.Slice(pastes, func(i, j int) bool {
+		return pastes[i].DeletedAt.After(pastes[j].DeletedAt)
+	})
+	return pastes, err
+}
+
+// purgePastes removes the pastes from the storage, along with the records
+// kept next to them and their entries in the tag indexes. It stops when
+// ctx is done, and returns how many pastes were removed so far.
+func purgePastes(ctx context.Context, checksums []string) (int, error) {
+	var keys []string
+	metas := map[string]Meta{}
+	// The size of the pastes in the primary storage, by storage key
+	sizes := map[string]int64{}
+	pastes := map[string]string{}
+	for _, checksum := range checksums {
+		m, _ := service.Meta(checksum)
+		metas[checksum] = m
+		if err := service.Untag(checksum, m.Tags); err != nil {
+			log.Printf("Unable to remove %s from its tags: %s\n", checksum, err)
+		}
+		for _, key := range service.ObjectKeys(checksum) {
+			if n, err := primaryStorage().Retrieve(key, ioutil.Discard); err == nil {
+				sizes[key] = n
+			}
+			pastes[key] = checksum
+			keys = append(keys, key)
+			for _, prefix := range sidecarPrefixes {
+				keys = append(keys, prefix+key)
+			}
+		}
+		// The records kept for every creation of pastes that require a key
+		for _, scope := range service.Scopes(checksum, m)[1:] {
+			for _, key := range service.ScopeKeys(scope) {
+				for _, prefix := range sidecarPrefixes {
+					keys = append(keys, prefix+key)
+				}
+			}
+		}
+	}
+
+	if err := addTombstones(metas); err != nil {
+		log.Printf("Unable to record tombstones: %s\n", err)
+	}
+
+	purged := map[string]bool{}
+	_, err := removeKeys(ctx, keys, func(key string) {
+		if size, ok := sizes[key]; ok {
+			usage.Removed(size)
+			purged[pastes[key]] = true
+		}
+	})
+	return len(purged), err
+}
+
+// purgeThrottle returns a function waiting until the next object can be
+// removed within -purge-rate, which reports false if ctx is done first,
+// and a function to call when done removing objects.
+func purgeThrottle(ctx context.Context) (func() bool, func()) {
+	rate := purgeRateFlag.Get()
+	if rate <= 0 || time.Second/time.Duration(rate) <= 0 {
+		return func() bool { return ctx.Err() == nil }, func() {}
+	}
+	ticker := time.NewTicker(time.Second / time.Duration(rate))
+	return func() bool {
+		select {
+		case <-ticker.C:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}, ticker.Stop
+}
+
+// removeKeys removes the objects with the keys from the storage, by
+// -purge-workers in parallel and at most -purge-rate per second, so that
+// large purges do not slow down serving pastes. Removed is called with
+// every key removed, if it is not nil. It stops when ctx is done, and
+// returns the number of keys removed so far.
+func removeKeys(ctx context.Context, keys []string, removed func(key string)) (int, error) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+	wait, stop := purgeThrottle(ctx)
+	defer stop()
+
+	var mu sync.Mutex
+	n := 0
+	queue := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < *purgeWorkersFlag; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range queue {
+				if !wait() {
+					continue
+				}
+				if err := storage.Delete(key); err != nil {
+					log.Printf("Unable to remove %s: %s\n", key, err)
+					continue
+				}
+				mu.Lock()
+				n++
+				if removed != nil {
+					removed(key)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+loop:
+	for _, key := range keys {
+		select {
+		case queue <- key:
+		case <-ctx.Done():
+			break loop
+		}
+	}
+	close(queue)
+	wg.Wait()
+	return n, ctx.Err()
+}
+
+// trashIndexedKey is the record of when the pastes deleted before
+// deletions were indexed were added to the index.
+const trashIndexedKey = "trash-indexed"
+
+// indexTrash adds every deleted paste to the index of the hour it was
+// deleted in, for pastes deleted before deletions were indexed. Pastes
+// deleted before the time was recorded are kept, as they always were.
+func indexTrash() (int, error) {
+	pastes, err := listTrash()
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, p := range pastes {
+		if p.DeletedAt.IsZero() {
+			continue
+		}
+		if err := service.IndexDeleted(p.Checksum, p.DeletedAt); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, storeJSON(trashIndexedKey, time.Now().UTC())
+}
+
+// purgeTrash removes the pastes that were deleted longer than
+// -trash-retention ago. Only the index of the hours they were deleted in
+// is read, rather than the metadata of every paste, once the pastes
+// deleted before deletions were indexed are added to it.
+func purgeTrash(ctx context.Context) (int, error) {
+	if *trashRetentionFlag <= 0 {
+		return 0, nil
+	}
+	var indexed time.Time
+	if err := retrieveJSON(trashIndexedKey, &indexed); isNotFound(err) {
+		n, err := indexTrash()
+		if err != nil {
+			return 0, err
+		}
+		log.Printf("Indexed %d pastes deleted before deletions were indexed\n", n)
+	} else if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	hours, err := service.DeletedHours(now.Add(-*trashRetentionFlag))
+	if err != nil {
+		return 0, err
+	}
+	// Hours are unindexed once their pastes are removed, and a purge that
+	// is interrupted leaves them for the next one, which finds the removed
+	// pastes gone.
+	var expired []string
+	done := map[string][]string{}
+	for _, hour := range hours {
+		checksums, err := service.DeletedIn(hour)
+		if err != nil {
+			return 0, err
+		}
+		for _, checksum := range checksums {
+			m, err := service.Meta(checksum)
+			switch {
+			case isNotFound(err) || err == nil && (!m.Deleted || pastebin.DeletedHourOf(m.DeletedAt) != hour):
+				// Purged or restored since, or deleted again and
+				// indexed in another hour
+				done[hour] = append(done[hour], checksum)
+			case err != nil:
+				return 0, err
+			case !m.Pinned && m.DeletedAt.Add(*trashRetentionFlag).Before(now):
+				expired = append(expired, checksum)
+				done[hour] = append(done[hour], checksum)
+			}
+		}
+	}
+	purged := 0
+	if len(expired) > 0 {
+		n, err := purgePastes(ctx, expired)
+		if err != nil {
+			return n, err
+		}
+		purged = n
+	}
+	for _, hour := range hours {
+		if len(done[hour]) == 0 {
+			continue
+		}
+		if err := service.UnindexDeleted(hour, done[hour]); err != nil {
+			return purged, err
+		}
+	}
+	return purged, nil
+}
+
+// purgeTrashJob empties the trash of expired pastes. Instances sharing the
+// data directories take turns, so that only one removes files at a time.
+func purgeTrashJob(ctx context.Context, payload json.RawMessage, progress func(v interface{})) error {
+	release, ok := acquireLease("purge-trash", time.Hour)
+	if !ok {
+		progress(map[string]string{"skipped": "another instance is purging the trash"})
+		return nil
+	}
+	defer release()
+
+	if n, err := purgeTombstones(); err != nil {
+		log.Printf("Unable to purge tombstones: %s\n", err)
+	} else if n > 0 {
+		log.Printf("Purged %d tombstones of purged pastes\n", n)
+	}
+
+	if n, err := purgeUploads(ctx); err != nil {
+		log.Printf("Unable to purge uploads: %s\n", err)
+	} else if n > 0 {
+		log.Printf("Purged the chunks of %d finished or abandoned uploads\n", n)
+	}
+
+	if n, err := purgeShares(ctx); err != nil {
+		log.Printf("Unable to purge share links: %s\n", err)
+	} else if n > 0 {
+		log.Printf("Purged %d expired share links\n", n)
+	}
+
+	if n, err := purgeIdempotentResponses(ctx); err != nil {
+		log.Printf("Unable to purge idempotent responses: %s\n", err)
+	} else if n > 0 {
+		log.Printf("Purged %d expired idempotent responses\n", n)
+	}
+
+	n, err := purgeTrash(ctx)
+	if ctx.Err() != nil {
+		progress(map[string]interface{}{"purged": n, "interrupted": true})
+		log.Printf("Purged %d deleted pastes from the trash before shutting down\n", n)
+		return err
+	}
+	progress(map[string]int{"purged": n})
+	if n > 0 {
+		log.Printf("Purged %d deleted pastes from the trash\n", n)
+	}
+	return err
+}
+
+func renderTrash(w http.ResponseWriter, r *http.Request, data AdminTrash) {
+	pastes, err := listTrash()
+	if err != nil {
+		log.Printf("Unable to list the trash: %s\n", err)
+		if data.Status == "" {
+			data.Message = "Unable to list the trash: " + err.Error()
+			data.Status = "error"
+		}
+	}
+	data.Pastes = pastes
+	renderTemplate(w, r, "templates/trash.html", "trash", data)
+}
+
+func adminTrash(w http.ResponseWriter, r *http.Request) {
+	renderTrash(w, r, AdminTrash{})
+}
+
+// adminResolveTrash restores a deleted paste or purges it right away.
+func adminResolveTrash(w http.ResponseWriter, r *http.Request) {
+	checksum := r.FormValue("checksum")
+
+	var data AdminTrash
+	var err error
+	switch r.FormValue("action") {
+	case "restore":
+		err = service.Restore(checksum)
+		data.Message = "Restored " + checksum
+	case "purge":
+		if !pastebin.IsChecksum(checksum) {
+			http.Error(w, "Invalid checksum", http.StatusBadRequest)
+			return
+		}
+		if m, _ := service.Meta(checksum); !m.Deleted || m.Pinned {
+			http.Error(w, "Only deleted pastes that are not pinned can be purged", http.StatusBadRequest)
+			return
+		}
+		_, err = purgePastes(r.Context(), []string{checksum})
+		data.Message = "Purged " + checksum
+	default:
+		http.Error(w, "Unknown action", http.StatusBadRequest)
+		return
+	}
+
+	if err != nil {
+		log.Printf("Unable to %s %s: %s\n", r.FormValue("action"), checksum, err)
+		data.Message = "Unable to " + r.FormValue("action") + " " + checksum + ": " + err.Error()
+		data.Status = "error"
+	} else {
+		data.Status = "success"
+		audit(r, r.FormValue("action"), checksum, "")
+	}
+	renderTrash(w, r, data)
+}
+
+// adminCleanup queues a purge of the trash now, rather than at the next
+// hourly run, and shows it on the jobs page.
+func adminCleanup(w http.ResponseWriter, r *http.Request) {
+	data := AdminJobs{Status: "success"}
+	status := http.StatusAccepted
+	if jobQueue.Active("purge-trash") {
+		data.Message = "The trash is already being purged"
+		data.Status = "error"
+		status = http.StatusConflict
+	} else if j, err := jobQueue.Enqueue("purge-trash", nil); err != nil {
+		log.Printf("Unable to queue a purge of the trash: %s\n", err)
+		data.Message = "Unable to queue a purge of the trash: " + err.Error()
+		data.Status = "error"
+		status = http.StatusServiceUnavailable
+	} else {
+		data.Message = "Queued job " + j.ID + " purging the trash"
+		audit(r, "cleanup", "job "+j.ID, "")
+	}
+	data.Jobs = jobQueue.List()
+	w.WriteHeader(status)
+	renderTemplate(w, r, "templates/jobs.html", "jobs", data)
+}
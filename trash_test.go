@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/espebra/pastebin/pastebin"
+)
+
+// trashExpired deletes the paste as if it was deleted two hours ago, which
+// the purge takes as expired with a retention of an hour.
+func trashExpired(t *testing.T, checksum string) {
+	t.Helper()
+	if err := service.Trash(checksum); err != nil {
+		t.Fatal(err)
+	}
+	deletedAt := time.Now().UTC().Add(-2 * time.Hour)
+	err := service.UpdateMeta(checksum, func(m *Meta) { m.DeletedAt = deletedAt })
+	if err == nil {
+		err = service.IndexDeleted(checksum, deletedAt)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPurgeTrash(t *testing.T) {
+	h := newTestServer(t)
+	retention := *trashRetentionFlag
+	*trashRetentionFlag = time.Hour
+	defer func() { *trashRetentionFlag = retention }()
+
+	content := "Purged from memory\n"
+	checksum := pastebin.Checksum(content)
+	createPlain(t, h, content)
+	if _, err := storage.Store(thumbnailKey(checksum), strings.NewReader("thumbnail")); err != nil {
+		t.Fatal(err)
+	}
+	trashExpired(t, checksum)
+
+	n, err := purgeTrash(context.Background())
+	if err != nil {
+		t.Fatalf("Unable to purge the trash: %s", err)
+	}
+	if n != 1 {
+		t.Errorf("Purged %d pastes, want 1", n)
+	}
+	for _, key := range []string{service.ObjectKey(checksum), "meta-" + service.ObjectKey(checksum), thumbnailKey(checksum)} {
+		var buf bytes.Buffer
+		if _, err := storage.Retrieve(key, &buf); !isNotFound(err) {
+			t.Errorf("%s is still stored after the purge: %v", key, err)
+		}
+	}
+}
+
+func TestRemoveKeysRate(t *testing.T) {
+	newTestServer(t)
+	rate := purgeRateFlag.String()
+	purgeRateFlag.Set("50")
+	defer purgeRateFlag.Set(rate)
+
+	var keys []string
+	for i := 0; i < 20; i++ {
+		key := "rate-" + strconv.Itoa(i)
+		if _, err := storage.Store(key, strings.NewReader(key)); err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, key)
+	}
+	start := time.Now()
+	n, err := removeKeys(context.Background(), keys, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != len(keys) {
+		t.Errorf("Removed %d keys, want %d", n, len(keys))
+	}
+	// 20 keys at 50 per second take at least 400ms, whatever the number of
+	// workers
+	if elapsed := time.Since(start); elapsed < 380*time.Millisecond {
+		t.Errorf("Removed %d keys in %s, faster than -purge-rate 50 allows", n, elapsed)
+	}
+}
+
+func TestFileStorageDeleteDerived(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "derived"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "derived", "thumb-purged")
+	if err := os.WriteFile(path, []byte("thumbnail"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	s := fileStorage{dir: dir}
+	if err := s.Delete("derived/thumb-purged"); err != nil {
+		t.Fatalf("Unable to delete a derived object: %s", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("The derived object is still stored after it was deleted: %v", err)
+	}
+	if err := s.Delete("../outside"); err == nil {
+		t.Error("Deleting a key outside the directory succeeded")
+	}
+}
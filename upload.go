@@ -0,0 +1,338 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/espebra/pastebin/apitypes"
+	"github.com/espebra/pastebin/pastebin"
+	"github.com/gorilla/mux"
+)
+
+const (
+	uploadsKey = "uploads"
+
+	// Largest chunk accepted in one request. Clients on flaky
+	// connections send smaller ones, so that less is lost when a request
+	// fails.
+	maxUploadChunk = 8 << 20
+)
+
+// Upload is a paste uploaded in chunks, which can be resumed from its
+// offset when a request fails. The paste is created when the last chunk
+// arrives.
+type Upload = apitypes.Upload
+
+// UploadRequest starts an upload of length bytes. The paste is checked
+// against the SHA-256, when given, before it is created.
+type UploadRequest = apitypes.UploadRequest
+
+func uploadChunkKey(id string, n int) string {
+	return "upload-" + id + "-" + strconv.Itoa(n)
+}
+
+// The uploads are stored as a single record, keyed by ID, and each chunk
+// as a record of its own.
+var uploadsMu sync.Mutex
+
+func retrieveUploads() (map[string]Upload, error) {
+	uploads := map[string]Upload{}
+	err := retrieveJSON(uploadsKey, &uploads)
+	if isNotFound(err) {
+		return uploads, nil
+	}
+	return uploads, err
+}
+
+// writeUpload responds with the upload, and its offset and length in the
+// headers used by resumable upload clients.
+func writeUpload(w http.ResponseWriter, status int, u Upload) {
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Upload-Offset", strconv.FormatInt(u.Offset, 10))
+	w.Header().Set("Upload-Length", strconv.FormatInt(u.Length, 10))
+	writeJSON(w, status, u)
+}
+
+// apiCreateUpload starts a chunked upload, with the length of the paste,
+// its visibility and tags in the JSON body.
+func apiCreateUpload(w http.ResponseWriter, r *http.Request) {
+	if err := checkPow(r); err != nil {
+		writeJSON(w, http.StatusForbidden, BatchResult{Status: "error", Message: err.Error()})
+		return
+	}
+	if err := checkCaptcha(r, true); err != nil {
+		writeJSON(w, http.StatusForbidden, BatchResult{Status: "error", Message: err.Error()})
+		return
+	}
+	var req UploadRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, requestOverhead)).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, BatchResult{Status: "error", Message: "Invalid upload: " + err.Error()})
+		return
+	}
+	if req.Length <= 0 {
+		writeJSON(w, http.StatusBadRequest, BatchResult{Status: "error", Message: "The length of the upload is required"})
+		return
+	}
+	if req.Length > maxPasteSizeFlag.Get() {
+		writeJSON(w, http.StatusRequestEntityTooLarge, BatchResult{Status: "error", Message: errPasteTooLarge.Error()})
+		return
+	}
+	if req.Visibility == "" {
+		req.Visibility = *defaultVisibilityFlag
+	}
+	if !pastebin.ValidVisibility(req.Visibility) || (req.Visibility == "private" && *secretFlag == "") {
+		writeJSON(w, http.StatusBadRequest, BatchResult{Status: "error", Message: "Invalid visibility " + req.Visibility})
+		return
+	}
+	tags, err := pastebin.ParseTags(req.Tags)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, BatchResult{Status: "error", Message: err.Error()})
+		return
+	}
+	if req.SHA256 == "" {
+		req.SHA256 = r.Header.Get(checksumHeader)
+	}
+	req.SHA256 = strings.ToLower(strings.TrimSpace(req.SHA256))
+	if req.SHA256 != "" && !pastebin.IsChecksum(req.SHA256) {
+		writeJSON(w, http.StatusBadRequest, BatchResult{Status: "error", Message: "Invalid checksum " + req.SHA256 + ", expected the SHA-256 of the paste in hex"})
+		return
+	}
+
+	id, err := pastebin.NewToken()
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, BatchResult{Status: "error", Message: "Unable to start the upload"})
+		return
+	}
+	now := time.Now().UTC()
+	u := Upload{ID: id, Length: req.Length, Visibility: req.Visibility, Tags: tags, SHA256: req.SHA256, Created: now, Updated: now}
+
+	uploadsMu.Lock()
+	uploads, err := retrieveUploads()
+	if err == nil {
+		uploads[id] = u
+		err = storeJSON(uploadsKey, uploads)
+	}
+	uploadsMu.Unlock()
+	if err != nil {
+		log.Printf("Unable to start an upload: %s\n", err)
+		if storageUnavailable(w, err) {
+			return
+		}
+		writeJSON(w, http.StatusInternalServerError, BatchResult{Status: "error", Message: "Unable to start the upload"})
+		return
+	}
+
+	w.Header().Set("Location", basePath()+"/api/v1/uploads/"+id)
+	writeUpload(w, http.StatusCreated, u)
+}
+
+// apiUpload returns the upload, so that a client can resume it from its
+// offset.
+func apiUpload(w http.ResponseWriter, r *http.Request) {
+	uploadsMu.Lock()
+	uploads, err := retrieveUploads()
+	uploadsMu.Unlock()
+	if err != nil {
+		if storageUnavailable(w, err) {
+			return
+		}
+		writeJSON(w, http.StatusInternalServerError, BatchResult{Status: "error", Message: "Unable to read the upload"})
+		return
+	}
+	u, ok := uploads[mux.Vars(r)["id"]]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, BatchResult{Status: "error", Message: "No such upload"})
+		return
+	}
+	writeUpload(w, http.StatusOK, u)
+}
+
+// apiUploadChunk appends the request body to the upload. The
+// Upload-Offset header must be the offset of the upload, so that chunks
+// that were received although the client saw the request fail are not
+// appended twice. The paste is created with the last chunk.
+func apiUploadChunk(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	offset, err := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, BatchResult{Status: "error", Message: "The Upload-Offset header is required"})
+		return
+	}
+
+	uploadsMu.Lock()
+	uploads, err := retrieveUploads()
+	uploadsMu.Unlock()
+	u, ok := uploads[id]
+	switch {
+	case err != nil:
+		if storageUnavailable(w, err) {
+			return
+		}
+		writeJSON(w, http.StatusInternalServerError, BatchResult{Status: "error", Message: "Unable to read the upload"})
+		return
+	case !ok:
+		writeJSON(w, http.StatusNotFound, BatchResult{Status: "error", Message: "No such upload"})
+		return
+	case offset != u.Offset || u.Checksum != "":
+		writeUpload(w, http.StatusConflict, u)
+		return
+	}
+
+	limit := u.Length - u.Offset
+	if limit > maxUploadChunk {
+		limit = maxUploadChunk
+	}
+	chunk, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, limit))
+	if err != nil {
+		writeJSON(w, http.StatusRequestEntityTooLarge, BatchResult{
+			Status:  "error",
+			Message: fmt.Sprintf("Chunks can be at most %d bytes, and not go past the length of the upload", limit),
+		})
+		return
+	}
+	// An empty chunk at the end retries creating the paste
+	if len(chunk) == 0 && u.Offset < u.Length {
+		writeUpload(w, http.StatusOK, u)
+		return
+	}
+
+	uploadsMu.Lock()
+	defer uploadsMu.Unlock()
+	uploads, err = retrieveUploads()
+	if err == nil {
+		// Another request may have appended the chunk meanwhile
+		if u = uploads[id]; offset != u.Offset || u.Checksum != "" {
+			writeUpload(w, http.StatusConflict, u)
+			return
+		}
+		if len(chunk) > 0 {
+			_, err = storage.Store(uploadChunkKey(id, u.Chunks), bytes.NewReader(chunk))
+		}
+	}
+	if err == nil && len(chunk) > 0 {
+		u.Offset += int64(len(chunk))
+		u.Chunks++
+		u.Updated = time.Now().UTC()
+		uploads[id] = u
+		err = storeJSON(uploadsKey, uploads)
+	}
+	if err != nil {
+		log.Printf("Unable to store a chunk of upload %s: %s\n", id, err)
+		if storageUnavailable(w, err) {
+			return
+		}
+		writeJSON(w, http.StatusInternalServerError, BatchResult{Status: "error", Message: "Unable to store the chunk"})
+		return
+	}
+	if u.Offset < u.Length {
+		writeUpload(w, http.StatusOK, u)
+		return
+	}
+
+	// The paste is complete
+	token, err := completeUpload(&u)
+	if cerr, ok := err.(*ChecksumError); ok {
+		// The chunks are corrupt, so the upload starts over
+		log.Printf("Upload %s does not match its checksum: %s\n", id, cerr)
+		u.Offset, u.Chunks = 0, 0
+		uploads[id] = u
+		if err := storeJSON(uploadsKey, uploads); err != nil {
+			log.Printf("Unable to restart upload %s: %s\n", id, err)
+		}
+		w.Header().Set("Upload-Offset", "0")
+		writeJSON(w, http.StatusBadRequest, cerr)
+		return
+	}
+	if err != nil {
+		log.Printf("Unable to create the paste of upload %s: %s\n", id, err)
+		if storageUnavailable(w, err) {
+			return
+		}
+		if err == errBlocked || err == errSpam {
+			writeJSON(w, http.StatusForbidden, BatchResult{Status: "error", Message: "The paste is not allowed: " + err.Error()})
+			return
+		}
+		if err == errStorageFull {
+			writeJSON(w, http.StatusInsufficientStorage, BatchResult{Status: "error", Message: "The storage is full, no pastes can be created."})
+			return
+		}
+		message := "Unable to save the paste"
+		if err == errBlocked {
+			message = "This content is not allowed."
+		}
+		writeJSON(w, http.StatusInternalServerError, BatchResult{Status: "error", Message: message})
+		return
+	}
+	uploads[id] = u
+	if err := storeJSON(uploadsKey, uploads); err != nil {
+		log.Printf("Unable to record the completion of upload %s: %s\n", id, err)
+	}
+	u.DeleteToken = token
+	writeUpload(w, http.StatusCreated, u)
+}
+
+// completeUpload assembles the chunks of the upload into a paste, and
+// returns its delete token. It returns a *ChecksumError when the paste does
+// not match the checksum of the upload.
+func completeUpload(u *Upload) (string, error) {
+	var content bytes.Buffer
+	for n := 0; n < u.Chunks; n++ {
+		if _, err := storage.Retrieve(uploadChunkKey(u.ID, n), &content); err != nil {
+			return "", err
+		}
+	}
+	if err := verifyChecksum(u.SHA256, content.String()); err != nil {
+		return "", err
+	}
+	var p Paste
+	p.Content = content.String()
+	p.Checksum = p.GetName()
+	_, token, err := createPaste(&p, u.Visibility, u.Tags)
+	if err != nil {
+		return "", err
+	}
+	u.Checksum = p.Checksum
+	u.URL = absURL(p.Location())
+	return token, nil
+}
+
+// purgeUploads removes the chunks of completed uploads and of uploads not
+// resumed within -upload-expiry.
+func purgeUploads(ctx context.Context) (int, error) {
+	uploadsMu.Lock()
+	defer uploadsMu.Unlock()
+	uploads, err := retrieveUploads()
+	if err != nil {
+		return 0, err
+	}
+	var keys []string
+	var done []string
+	for id, u := range uploads {
+		if u.Checksum == "" && (*uploadExpiryFlag == 0 || time.Since(u.Updated) < *uploadExpiryFlag) {
+			continue
+		}
+		for n := 0; n < u.Chunks; n++ {
+			keys = append(keys, uploadChunkKey(id, n))
+		}
+		done = append(done, id)
+	}
+	if len(done) == 0 {
+		return 0, nil
+	}
+	if _, err := removeKeys(ctx, keys, nil); err != nil {
+		return 0, err
+	}
+	for _, id := range done {
+		delete(uploads, id)
+	}
+	return len(done), storeJSON(uploadsKey, uploads)
+}
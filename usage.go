@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/espebra/pastebin/pastebin"
+)
+
+const usageKey = "usage"
+
+// errStorageFull is returned when creating a paste while the storage used
+// is over -storage-max-bytes.
+var errStorageFull = errors.New("storage is full")
+
+// Usage is the number of pastes stored in the data directories and the
+// bytes they take up, as stored, that is compressed or encrypted.
+type Usage struct {
+	Pastes int64 `json:"pastes"`
+	Bytes  int64 `json:"bytes"`
+	// When the pastes were last counted. Pastes created and purged since
+	// are added and subtracted as they are.
+	Counted time.Time `json:"counted"`
+}
+
+// usageCounter keeps the usage up to date without walking the data
+// directories on every paste. Changes are buffered in memory and added to
+// the record in the storage periodically, as the analytics are, so that
+// instances sharing the data directories count each other's pastes.
+type usageCounter struct {
+	sync.Mutex
+	pending Usage
+	stored  Usage
+}
+
+var usage usageCounter
+
+func storedUsage() (Usage, error) {
+	var u Usage
+	err := retrieveJSON(usageKey, &u)
+	if isNotFound(err) {
+		return u, nil
+	}
+	return u, err
+}
+
+// Added counts a paste stored with the given number of bytes.
+func (c *usageCounter) Added(stored int64) {
+	c.Lock()
+	defer c.Unlock()
+	c.pending.Pastes++
+	c.pending.Bytes += stored
+}
+
+// Removed counts a paste of the given number of bytes removed from the
+// data directories.
+func (c *usageCounter) Removed(stored int64) {
+	c.Lock()
+	defer c.Unlock()
+	c.pending.Pastes--
+	c.pending.Bytes -= stored
+}
+
+// Get returns the usage as last read from the storage together with the
+// changes that are not flushed yet.
+func (c *usageCounter) Get() Usage {
+	c.Lock()
+	defer c.Unlock()
+	u := c.stored
+	u.Pastes += c.pending.Pastes
+	u.Bytes += c.pending.Bytes
+	return u
+}
+
+// Flush adds the buffered changes to the record in the storage, and picks
+// up the changes of other instances.
+func (c *usageCounter) Flush() {
+	c.Lock()
+	pending := c.pending
+	c.pending = Usage{}
+	c.Unlock()
+
+	u, err := storedUsage()
+	if err == nil && (pending.Pastes != 0 || pending.Bytes != 0) {
+		u.Pastes += pending.Pastes
+		u.Bytes += pending.Bytes
+		err = storeJSON(usageKey, u)
+	}
+
+	c.Lock()
+	defer c.Unlock()
+	if err != nil {
+		log.Printf("Unable to update the storage usage: %s\n", err)
+		// Keep the changes for the next flush
+		c.pending.Pastes += pending.Pastes
+		c.pending.Bytes += pending.Bytes
+		return
+	}
+	c.stored = u
+}
+
+func (c *usageCounter) Run(interval time.Duration) {
+	c.Flush()
+	for range time.Tick(interval) {
+		c.Flush()
+	}
+}
+
+// countUsage counts the pastes in the data directories from the file
+// sizes, without reading the pastes. Sidecar records and other records are
+// not counted.
+func countUsage(ctx context.Context) (Usage, error) {
+	u := Usage{Counted: time.Now().UTC()}
+	for _, dir := range dataDirs() {
+		err := filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if fi.Mode().IsRegular() && pastebin.IsChecksum(fi.Name()) {
+				u.Pastes++
+				u.Bytes += fi.Size()
+			}
+			return nil
+		})
+		if err != nil {
+			return u, err
+		}
+	}
+	return u, nil
+}
+
+// storageUsageJob counts the pastes again, correcting any drift of the
+// usage counted as pastes are created and purged, such as from pastes
+// removed by hand. Instances sharing the data directories take turns.
+func storageUsageJob(ctx context.Context, payload json.RawMessage, progress func(v interface{})) error {
+	if len(dataDirs()) == 0 {
+		progress(map[string]string{"skipped": "there are no data directories"})
+		return nil
+	}
+	release, ok := acquireLease("storage-usage", time.Hour)
+	if !ok {
+		progress(map[string]string{"skipped": "another instance is counting the storage usage"})
+		return nil
+	}
+	defer release()
+
+	u, err := countUsage(ctx)
+	if err != nil {
+		return err
+	}
+	usage.Lock()
+	defer usage.Unlock()
+	if err := storeJSON(usageKey, u); err != nil {
+		return err
+	}
+	// Changes made while counting are most likely counted already
+	usage.pending = Usage{}
+	usage.stored = u
+	progress(u)
+	return nil
+}
+
+// storageFull reports whether the storage used is over
+// -storage-max-bytes, which blocks creating pastes.
+func storageFull() bool {
+	max := storageMaxBytesFlag.Get()
+	return max > 0 && usage.Get().Bytes >= max
+}
+
+// insufficientStorage responds with 507 Insufficient Storage if err is
+// caused by the storage being full, and reports whether it did.
+func insufficientStorage(w http.ResponseWriter, err error) bool {
+	if err != errStorageFull {
+		return false
+	}
+	http.Error(w, "The storage is full, no pastes can be created", http.StatusInsufficientStorage)
+	return true
+}
+
+// metrics serves the storage usage in the Prometheus text format.
+func metrics(w http.ResponseWriter, r *http.Request) {
+	u := usage.Get()
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	fmt.Fprintf(w, "# HELP pastebin_storage_pastes Number of pastes stored in the data directories.\n")
+	fmt.Fprintf(w, "# TYPE pastebin_storage_pastes gauge\n")
+	fmt.Fprintf(w, "pastebin_storage_pastes %d\n", u.Pastes)
+	fmt.Fprintf(w, "# HELP pastebin_storage_bytes Bytes taken up by the pastes in the data directories.\n")
+	fmt.Fprintf(w, "# TYPE pastebin_storage_bytes gauge\n")
+	fmt.Fprintf(w, "pastebin_storage_bytes %d\n", u.Bytes)
+	fmt.Fprintf(w, "# HELP pastebin_storage_max_bytes Bytes the pastes can take up before no more can be created, or 0 for no limit.\n")
+	fmt.Fprintf(w, "# TYPE pastebin_storage_max_bytes gauge\n")
+	fmt.Fprintf(w, "pastebin_storage_max_bytes %d\n", storageMaxBytesFlag.Get())
+	if !u.Counted.IsZero() {
+		fmt.Fprintf(w, "# HELP pastebin_storage_counted_timestamp_seconds When the pastes were last counted.\n")
+		fmt.Fprintf(w, "# TYPE pastebin_storage_counted_timestamp_seconds gauge\n")
+		fmt.Fprintf(w, "pastebin_storage_counted_timestamp_seconds %d\n", u.Counted.Unix())
+	}
+}
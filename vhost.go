@@ -0,0 +1,82 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Options that can be set per virtual host. The storage and every other
+// option are shared by all hosts served by the process.
+var hostOptions = map[string]bool{
+	"instance-name": true,
+	"logo-url":      true,
+	"footer-links":  true,
+	"contact-email": true,
+}
+
+// The options of each virtual host by hostname, guarded by hostsMu.
+var (
+	hostsMu      sync.RWMutex
+	virtualHosts = map[string]map[string]string{}
+)
+
+// loadVirtualHosts reads a config file per hostname from
+// -virtual-hosts-dir, named like paste.example.com.toml, with the options
+// that differ for requests to that host.
+func loadVirtualHosts() error {
+	hosts := map[string]map[string]string{}
+	if *virtualHostsDirFlag != "" {
+		files, err := ioutil.ReadDir(*virtualHostsDirFlag)
+		if err != nil {
+			return err
+		}
+		for _, fi := range files {
+			if fi.IsDir() || !strings.HasSuffix(fi.Name(), ".toml") {
+				continue
+			}
+			path := filepath.Join(*virtualHostsDirFlag, fi.Name())
+			values, err := readConfigFile(path)
+			if err != nil {
+				return err
+			}
+			for name, value := range values {
+				if !hostOptions[name] {
+					return fmt.Errorf("%s: %s can not be set per host", path, name)
+				}
+				if name == "footer-links" {
+					if _, err := parseFooterLinks(value); err != nil {
+						return fmt.Errorf("%s: %s", path, err)
+					}
+				}
+			}
+			hosts[strings.ToLower(strings.TrimSuffix(fi.Name(), ".toml"))] = values
+		}
+	}
+
+	hostsMu.Lock()
+	virtualHosts = hosts
+	hostsMu.Unlock()
+	return nil
+}
+
+// hostOption returns the value of the option for the host the request was
+// sent to, and otherwise the value of the flag.
+func hostOption(r *http.Request, name string) string {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	hostsMu.RLock()
+	value, ok := virtualHosts[strings.ToLower(host)][name]
+	hostsMu.RUnlock()
+	if ok {
+		return value
+	}
+	return flag.Lookup(name).Value.String()
+}
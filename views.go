@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// viewCounter buffers paste views in memory and adds them to the counts
+// in the storage periodically, so that a view does not cost a write.
+type viewCounter struct {
+	sync.Mutex
+	pending map[string]int64
+}
+
+var views = viewCounter{pending: map[string]int64{}}
+
+func viewsKey(scope string) string {
+	return "views-" + service.ScopeKey(scope)
+}
+
+// Add counts a view of the paste under the scope of its reader, which is
+// also counted in the daily analytics.
+func (c *viewCounter) Add(scope string) {
+	if *anonymousFlag {
+		return
+	}
+	c.Lock()
+	c.pending[scope]++
+	c.Unlock()
+	analytics.Viewed()
+}
+
+func storedViews(scope string) int64 {
+	var buf bytes.Buffer
+	if _, err := storage.Retrieve(viewsKey(scope), &buf); err != nil {
+		return 0
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(buf.String()), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// Get returns the number of views of the paste under the scope, including
+// views that are not flushed yet.
+func (c *viewCounter) Get(scope string) int64 {
+	c.Lock()
+	n := c.pending[scope]
+	c.Unlock()
+	return storedViews(scope) + n
+}
+
+// Flush adds the buffered views to the counts in the storage.
+func (c *viewCounter) Flush() {
+	c.Lock()
+	pending := c.pending
+	c.pending = map[string]int64{}
+	c.Unlock()
+
+	for scope, n := range pending {
+		total := storedViews(scope) + n
+		if _, err := storage.Store(viewsKey(scope), strings.NewReader(strconv.FormatInt(total, 10))); err != nil {
+			log.Printf("Unable to store view count of %s: %s\n", scope, err)
+			// Keep the views for the next flush
+			c.Lock()
+			c.pending[scope] += n
+			c.Unlock()
+		}
+	}
+}
+
+func (c *viewCounter) Run(interval time.Duration) {
+	for range time.Tick(interval) {
+		c.Flush()
+	}
+}